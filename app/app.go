@@ -73,6 +73,8 @@ var (
 		gov.ModuleName:                     {supply.Burner},
 		ibctransfer.GetModuleAccountName(): {supply.Minter, supply.Burner},
 		redaomint.ModuleName:               {supply.Minter},
+		ecocredit.ModuleName:               {supply.Minter, supply.Burner},
+		ecocredit.BondPoolName:             {supply.Burner},
 	}
 )
 
@@ -177,11 +179,17 @@ func NewGaiaApp(
 	)
 	app.crisisKeeper = crisis.NewKeeper(crisisSubspace, invCheckPeriod, app.supplyKeeper, auth.FeeCollectorName)
 
+	app.ibcKeeper = ibc.NewKeeper(app.cdc, keys[ibc.StoreKey], ibc.DefaultCodespace, app.bankKeeper, app.supplyKeeper)
+
+	ecocreditPort := app.ibcKeeper.PortKeeper.BindPort(ecocredit.PortID)
+	app.ecocreditKeeper = ecocredit.NewKeeper(cdc, keys[ecocredit.StoreKey], app.bankKeeper, app.supplyKeeper, app.ibcKeeper.ChannelKeeper, ecocreditPort)
+
 	// register the proposal types
 	govRouter := gov.NewRouter()
 	govRouter.AddRoute(gov.RouterKey, gov.ProposalHandler).
 		AddRoute(params.RouterKey, params.NewParamChangeProposalHandler(app.paramsKeeper)).
-		AddRoute(distr.RouterKey, distr.NewCommunityPoolSpendProposalHandler(app.distrKeeper))
+		AddRoute(distr.RouterKey, distr.NewCommunityPoolSpendProposalHandler(app.distrKeeper)).
+		AddRoute(ecocredit.RouterKey, ecocredit.NewSlashBondProposalHandler(app.ecocreditKeeper))
 	app.govKeeper = gov.NewKeeper(
 		app.cdc, keys[gov.StoreKey], govSubspace,
 		app.supplyKeeper, &stakingKeeper, gov.DefaultCodespace, govRouter,
@@ -193,9 +201,6 @@ func NewGaiaApp(
 		staking.NewMultiStakingHooks(app.distrKeeper.Hooks(), app.slashingKeeper.Hooks()),
 	)
 
-	app.ibcKeeper = ibc.NewKeeper(app.cdc, keys[ibc.StoreKey], ibc.DefaultCodespace, app.bankKeeper, app.supplyKeeper)
-
-	app.ecocreditKeeper = ecocredit.NewKeeper(cdc, keys[ecocredit.StoreKey])
 	app.redaomintKeeper = redaomint.NewKeeper(cdc, keys[redaomint.StoreKey], app.accountKeeper, app.bankKeeper, app.supplyKeeper, app.ecocreditKeeper, app.ibcKeeper, app.Router())
 
 	// NOTE: Any module instantiated in the module manager that is later modified
@@ -221,14 +226,14 @@ func NewGaiaApp(
 	// CanWithdrawInvariant invariant.
 	app.mm.SetOrderBeginBlockers(mint.ModuleName, distr.ModuleName, slashing.ModuleName, redaomint.ModuleName)
 
-	app.mm.SetOrderEndBlockers(crisis.ModuleName, gov.ModuleName, staking.ModuleName)
+	app.mm.SetOrderEndBlockers(ecocredit.ModuleName, crisis.ModuleName, gov.ModuleName, staking.ModuleName)
 
 	// NOTE: The genutils module must occur after staking so that pools are
 	// properly initialized with tokens from genesis accounts.
 	app.mm.SetOrderInitGenesis(
 		distr.ModuleName, staking.ModuleName, auth.ModuleName, bank.ModuleName,
 		slashing.ModuleName, gov.ModuleName, mint.ModuleName, supply.ModuleName,
-		crisis.ModuleName, genutil.ModuleName,
+		crisis.ModuleName, ecocredit.ModuleName, genutil.ModuleName,
 	)
 
 	app.mm.RegisterInvariants(&app.crisisKeeper)
@@ -0,0 +1,589 @@
+/* Package geo provides the planar geometry primitives needed to reason about the land parcels (geo-polygons) that
+ecosystem service credits and reDAOmint land allocations refer to.
+
+Coordinates are stored as integer microdegrees so that every computation which affects consensus (such as checking
+whether two parcels overlap) is exact and deterministic across platforms.
+*/
+package geo
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/big"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	// CoordinateScale is the number of integer coordinate units per degree
+	CoordinateScale = 1000000
+	// MaxVertices is the maximum number of vertices a polygon may have
+	MaxVertices = 500
+	// EarthRadius is the radius in meters of the sphere used to approximate the earth when computing areas
+	EarthRadius = 6378137
+)
+
+// Point is a longitude/latitude pair expressed in microdegrees
+type Point struct {
+	Lon int64 `json:"lon"`
+	Lat int64 `json:"lat"`
+}
+
+// Polygon is a simple polygon described by its exterior ring. The ring is implicitly closed, i.e. the last point is
+// connected back to the first one and is not repeated
+type Polygon []Point
+
+type geoJSONPolygon struct {
+	Type        string         `json:"type"`
+	Coordinates [][][2]float64 `json:"coordinates"`
+}
+
+// Parse parses a polygon either from a GeoJSON geometry object or from WKT, depending on the format of the input
+func Parse(s string) (Polygon, error) {
+	if strings.HasPrefix(strings.TrimSpace(s), "{") {
+		return ParseGeoJSON([]byte(s))
+	}
+	return ParseWKT(s)
+}
+
+// ParseGeoJSON parses a GeoJSON geometry object of type "Polygon". Interior rings (holes) are not supported
+func ParseGeoJSON(bz []byte) (Polygon, error) {
+	var geom geoJSONPolygon
+	err := json.Unmarshal(bz, &geom)
+	if err != nil {
+		return nil, err
+	}
+	if geom.Type != "Polygon" {
+		return nil, fmt.Errorf("expected a GeoJSON Polygon, got %q", geom.Type)
+	}
+	if len(geom.Coordinates) != 1 {
+		return nil, fmt.Errorf("expected a single polygon ring, got %d", len(geom.Coordinates))
+	}
+	ring := geom.Coordinates[0]
+	poly := make(Polygon, 0, len(ring))
+	for _, c := range ring {
+		poly = append(poly, Point{Lon: toMicrodegrees(c[0]), Lat: toMicrodegrees(c[1])})
+	}
+	return closedRing(poly)
+}
+
+// ParseWKT parses a well-known text POLYGON with a single ring, e.g. "POLYGON ((30 10, 40 40, 20 40, 30 10))"
+func ParseWKT(s string) (Polygon, error) {
+	s = strings.TrimSpace(s)
+	if len(s) < len("POLYGON") || !strings.EqualFold(s[:len("POLYGON")], "POLYGON") {
+		return nil, fmt.Errorf("expected a WKT POLYGON")
+	}
+	body := strings.TrimSpace(s[len("POLYGON"):])
+	if !strings.HasPrefix(body, "(") || !strings.HasSuffix(body, ")") {
+		return nil, fmt.Errorf("malformed WKT POLYGON")
+	}
+	body = strings.TrimSpace(body[1 : len(body)-1])
+	if !strings.HasPrefix(body, "(") || !strings.HasSuffix(body, ")") || strings.Count(body, "(") != 1 {
+		return nil, fmt.Errorf("expected a single polygon ring")
+	}
+	var poly Polygon
+	for _, pair := range strings.Split(body[1:len(body)-1], ",") {
+		coords := strings.Fields(pair)
+		if len(coords) != 2 {
+			return nil, fmt.Errorf("malformed WKT coordinate %q", pair)
+		}
+		lon, err := strconv.ParseFloat(coords[0], 64)
+		if err != nil {
+			return nil, err
+		}
+		lat, err := strconv.ParseFloat(coords[1], 64)
+		if err != nil {
+			return nil, err
+		}
+		poly = append(poly, Point{Lon: toMicrodegrees(lon), Lat: toMicrodegrees(lat)})
+	}
+	return closedRing(poly)
+}
+
+func toMicrodegrees(deg float64) int64 {
+	return int64(math.Round(deg * CoordinateScale))
+}
+
+// closedRing checks that the ring read by a parser is closed and drops the closing point
+func closedRing(poly Polygon) (Polygon, error) {
+	if len(poly) < 4 || poly[0] != poly[len(poly)-1] {
+		return nil, fmt.Errorf("polygon ring must be closed and have at least 3 distinct points")
+	}
+	return poly[:len(poly)-1], nil
+}
+
+// Validate checks that the polygon has between 3 and MaxVertices points, that all points are within the valid
+// longitude and latitude range, that the ring doesn't intersect itself and that the polygon has a non-zero area
+func (p Polygon) Validate() error {
+	if len(p) < 3 {
+		return fmt.Errorf("polygon must have at least 3 points")
+	}
+	if len(p) > MaxVertices {
+		return fmt.Errorf("polygon has %d points, the maximum is %d", len(p), MaxVertices)
+	}
+	for _, pt := range p {
+		if pt.Lon < -180*CoordinateScale || pt.Lon > 180*CoordinateScale ||
+			pt.Lat < -90*CoordinateScale || pt.Lat > 90*CoordinateScale {
+			return fmt.Errorf("point %v is out of bounds", pt)
+		}
+	}
+	n := len(p)
+	for i := 0; i < n; i++ {
+		a, b, c := p[i], p[(i+1)%n], p[(i+2)%n]
+		if a == b {
+			return fmt.Errorf("polygon has repeated point %v", a)
+		}
+		// adjacent edges may only share their common point
+		if cross(a, b, c) == 0 && (a.Lon-b.Lon)*(c.Lon-b.Lon)+(a.Lat-b.Lat)*(c.Lat-b.Lat) > 0 {
+			return fmt.Errorf("polygon is self-intersecting at %v", b)
+		}
+		for j := i + 2; j < n; j++ {
+			if i == 0 && j == n-1 {
+				continue
+			}
+			if segmentsIntersect(a, b, p[j], p[(j+1)%n]) {
+				return fmt.Errorf("polygon is self-intersecting")
+			}
+		}
+	}
+	if p.area2().Sign() == 0 {
+		return fmt.Errorf("polygon has no area")
+	}
+	return nil
+}
+
+// Canonical returns the unique representation of the polygon: points which are collinear with their neighbours are
+// dropped, the ring is wound counter-clockwise and it starts at its lowest point by longitude and then latitude
+func (p Polygon) Canonical() Polygon {
+	var res Polygon
+	n := len(p)
+	for i := range p {
+		if cross(p[(i+n-1)%n], p[i], p[(i+1)%n]) != 0 {
+			res = append(res, p[i])
+		}
+	}
+	if !res.isCounterClockwise() {
+		for i, j := 0, len(res)-1; i < j; i, j = i+1, j-1 {
+			res[i], res[j] = res[j], res[i]
+		}
+	}
+	first := 0
+	for i, pt := range res {
+		if pt.Lon < res[first].Lon || (pt.Lon == res[first].Lon && pt.Lat < res[first].Lat) {
+			first = i
+		}
+	}
+	return append(res[first:], res[:first]...)
+}
+
+// Marshal validates the polygon and returns the deterministic binary encoding of its canonical form: the number of
+// points followed by the longitude and latitude of each point, all encoded as varints
+func (p Polygon) Marshal() ([]byte, error) {
+	err := p.Validate()
+	if err != nil {
+		return nil, err
+	}
+	canonical := p.Canonical()
+	bz := make([]byte, 0, binary.MaxVarintLen64*(2*len(canonical)+1))
+	buf := make([]byte, binary.MaxVarintLen64)
+	bz = append(bz, buf[:binary.PutUvarint(buf, uint64(len(canonical)))]...)
+	for _, pt := range canonical {
+		bz = append(bz, buf[:binary.PutVarint(buf, pt.Lon)]...)
+		bz = append(bz, buf[:binary.PutVarint(buf, pt.Lat)]...)
+	}
+	return bz, nil
+}
+
+// Unmarshal decodes a polygon encoded with Marshal. It fails if the polygon is not valid or not in canonical form, so
+// each polygon has exactly one accepted encoding
+func Unmarshal(bz []byte) (Polygon, error) {
+	count, n := binary.Uvarint(bz)
+	if n <= 0 || count > MaxVertices {
+		return nil, fmt.Errorf("invalid polygon encoding")
+	}
+	rest := bz[n:]
+	readVarint := func() (int64, error) {
+		x, n := binary.Varint(rest)
+		if n <= 0 {
+			return 0, fmt.Errorf("invalid polygon encoding")
+		}
+		rest = rest[n:]
+		return x, nil
+	}
+	poly := make(Polygon, 0, count)
+	for i := uint64(0); i < count; i++ {
+		lon, err := readVarint()
+		if err != nil {
+			return nil, err
+		}
+		lat, err := readVarint()
+		if err != nil {
+			return nil, err
+		}
+		poly = append(poly, Point{Lon: lon, Lat: lat})
+	}
+	canonical, err := poly.Marshal()
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(canonical, bz) {
+		return nil, fmt.Errorf("polygon is not canonically encoded")
+	}
+	return poly, nil
+}
+
+// Area returns the approximate area of the polygon in square meters, computed on a sphere of radius EarthRadius
+func (p Polygon) Area() float64 {
+	toRadians := func(x int64) float64 {
+		return float64(x) / CoordinateScale * math.Pi / 180
+	}
+	var sum float64
+	n := len(p)
+	for i := range p {
+		a, b := p[i], p[(i+1)%n]
+		sum += (toRadians(b.Lon) - toRadians(a.Lon)) * (2 + math.Sin(toRadians(a.Lat)) + math.Sin(toRadians(b.Lat)))
+	}
+	return math.Abs(sum * EarthRadius * EarthRadius / 2)
+}
+
+// ExactArea returns twice the planar area of the polygon in square microdegrees. Unlike Area it is computed exactly,
+// so it can be used to compare and apportion areas of polygons in consensus-critical code
+func (p Polygon) ExactArea() *big.Int {
+	return new(big.Int).Abs(p.area2())
+}
+
+// cross returns the z component of the cross product of (b - a) and (c - a). It is positive if a, b, c make a
+// counter-clockwise turn, negative if they make a clockwise turn and zero if they are collinear
+func cross(a, b, c Point) int64 {
+	return (b.Lon-a.Lon)*(c.Lat-a.Lat) - (b.Lat-a.Lat)*(c.Lon-a.Lon)
+}
+
+// sign returns -1, 0 or 1 depending on the sign of x
+func sign(x int64) int {
+	switch {
+	case x < 0:
+		return -1
+	case x > 0:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// onSegment checks whether the point pt, which must be collinear with a and b, lies on the segment a-b
+func onSegment(a, b, pt Point) bool {
+	return pt.Lon >= min64(a.Lon, b.Lon) && pt.Lon <= max64(a.Lon, b.Lon) &&
+		pt.Lat >= min64(a.Lat, b.Lat) && pt.Lat <= max64(a.Lat, b.Lat)
+}
+
+func min64(a, b int64) int64 {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func max64(a, b int64) int64 {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+// segmentsIntersect checks whether the closed segments p1-p2 and q1-q2 have at least one point in common
+func segmentsIntersect(p1, p2, q1, q2 Point) bool {
+	d1, d2 := sign(cross(q1, q2, p1)), sign(cross(q1, q2, p2))
+	d3, d4 := sign(cross(p1, p2, q1)), sign(cross(p1, p2, q2))
+	if d1*d2 < 0 && d3*d4 < 0 {
+		return true
+	}
+	return (d1 == 0 && onSegment(q1, q2, p1)) || (d2 == 0 && onSegment(q1, q2, p2)) ||
+		(d3 == 0 && onSegment(p1, p2, q1)) || (d4 == 0 && onSegment(p1, p2, q2))
+}
+
+// area2 returns twice the signed planar area of the polygon in square microdegrees. It is positive if the ring is
+// wound counter-clockwise. A big.Int is used because the sum may overflow an int64 for large polygons
+func (p Polygon) area2() *big.Int {
+	sum := new(big.Int)
+	for i := 1; i+1 < len(p); i++ {
+		sum.Add(sum, big.NewInt(cross(p[0], p[i], p[i+1])))
+	}
+	return sum
+}
+
+// isCounterClockwise returns whether the ring of the polygon is wound counter-clockwise
+func (p Polygon) isCounterClockwise() bool {
+	return p.area2().Sign() > 0
+}
+
+// bounds returns the bounding box of the polygon
+func (p Polygon) bounds() (min Point, max Point) {
+	min, max = p[0], p[0]
+	for _, pt := range p[1:] {
+		if pt.Lon < min.Lon {
+			min.Lon = pt.Lon
+		}
+		if pt.Lat < min.Lat {
+			min.Lat = pt.Lat
+		}
+		if pt.Lon > max.Lon {
+			max.Lon = pt.Lon
+		}
+		if pt.Lat > max.Lat {
+			max.Lat = pt.Lat
+		}
+	}
+	return min, max
+}
+
+type triangle [3]Point
+
+// triangulate splits the polygon into counter-clockwise triangles using ear clipping
+func (p Polygon) triangulate() ([]triangle, error) {
+	if len(p) < 3 {
+		return nil, fmt.Errorf("polygon must have at least 3 points")
+	}
+	ring := make([]Point, len(p))
+	copy(ring, p)
+	if !p.isCounterClockwise() {
+		for i, j := 0, len(ring)-1; i < j; i, j = i+1, j-1 {
+			ring[i], ring[j] = ring[j], ring[i]
+		}
+	}
+	var triangles []triangle
+	for len(ring) >= 3 {
+		clipped := false
+		for i := range ring {
+			t := triangle{ring[(i+len(ring)-1)%len(ring)], ring[i], ring[(i+1)%len(ring)]}
+			turn := cross(t[0], t[1], t[2])
+			if turn < 0 || (turn > 0 && len(ring) > 3 && ringHasPointInTriangle(ring, t)) {
+				continue
+			}
+			// the vertex is either an ear or a degenerate collinear point, in both cases it can be clipped
+			if turn > 0 {
+				triangles = append(triangles, t)
+			}
+			ring = append(ring[:i], ring[i+1:]...)
+			clipped = true
+			break
+		}
+		if !clipped {
+			return nil, fmt.Errorf("polygon is not simple")
+		}
+	}
+	if len(triangles) == 0 {
+		return nil, fmt.Errorf("polygon has no area")
+	}
+	return triangles, nil
+}
+
+// ringHasPointInTriangle checks whether any point of the ring other than the triangle's own vertices lies inside or
+// on the boundary of the counter-clockwise triangle t
+func ringHasPointInTriangle(ring []Point, t triangle) bool {
+	for _, pt := range ring {
+		if pt == t[0] || pt == t[1] || pt == t[2] {
+			continue
+		}
+		if cross(t[0], t[1], pt) >= 0 && cross(t[1], t[2], pt) >= 0 && cross(t[2], t[0], pt) >= 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// separated checks whether the axis normal to the edge p-q separates the triangles a and b, allowing them to touch
+func separated(p, q Point, a, b triangle) bool {
+	nx, ny := q.Lat-p.Lat, p.Lon-q.Lon
+	project := func(t triangle) (min int64, max int64) {
+		min = t[0].Lon*nx + t[0].Lat*ny
+		max = min
+		for _, pt := range t[1:] {
+			d := pt.Lon*nx + pt.Lat*ny
+			if d < min {
+				min = d
+			}
+			if d > max {
+				max = d
+			}
+		}
+		return min, max
+	}
+	minA, maxA := project(a)
+	minB, maxB := project(b)
+	return maxA <= minB || maxB <= minA
+}
+
+// trianglesOverlap checks whether the interiors of two triangles intersect using the separating axis theorem.
+// Triangles which only share an edge or a vertex do not overlap
+func trianglesOverlap(a, b triangle) bool {
+	for _, t := range []triangle{a, b} {
+		for i := 0; i < 3; i++ {
+			if separated(t[i], t[(i+1)%3], a, b) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// Triangulation is a polygon cut into triangles by ear clipping. Triangulating a polygon once lets it be tested for
+// intersection with many other polygons, ear clipping takes up to a quadratic number of steps in the vertices
+type Triangulation struct {
+	polygon   Polygon
+	min, max  Point
+	triangles []triangle
+}
+
+// Triangulate triangulates the polygon
+func Triangulate(p Polygon) (Triangulation, error) {
+	if len(p) == 0 {
+		return Triangulation{}, fmt.Errorf("empty polygon")
+	}
+	triangles, err := p.triangulate()
+	if err != nil {
+		return Triangulation{}, err
+	}
+	min, max := p.bounds()
+	return Triangulation{polygon: p, min: min, max: max, triangles: triangles}, nil
+}
+
+// Polygon returns the triangulated polygon
+func (t Triangulation) Polygon() Polygon {
+	return t.polygon
+}
+
+// Intersects checks whether the interiors of the triangulated polygons intersect, see Intersects. It calls tested
+// before testing each pair of triangles, if it isn't nil, so that callers can meter the work done
+func (t Triangulation) Intersects(other Triangulation, tested func()) bool {
+	if t.max.Lon <= other.min.Lon || other.max.Lon <= t.min.Lon || t.max.Lat <= other.min.Lat || other.max.Lat <= t.min.Lat {
+		return false
+	}
+	for _, ta := range t.triangles {
+		for _, tb := range other.triangles {
+			if tested != nil {
+				tested()
+			}
+			if trianglesOverlap(ta, tb) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Intersects checks whether the interiors of two polygons intersect. Polygons which only share boundary segments or
+// points, like adjacent land parcels, do not intersect
+func Intersects(a Polygon, b Polygon) (bool, error) {
+	if len(a) == 0 || len(b) == 0 {
+		return false, fmt.Errorf("empty polygon")
+	}
+	minA, maxA := a.bounds()
+	minB, maxB := b.bounds()
+	if maxA.Lon <= minB.Lon || maxB.Lon <= minA.Lon || maxA.Lat <= minB.Lat || maxB.Lat <= minA.Lat {
+		return false, nil
+	}
+	ta, err := Triangulate(a)
+	if err != nil {
+		return false, err
+	}
+	tb, err := Triangulate(b)
+	if err != nil {
+		return false, err
+	}
+	return ta.Intersects(tb, nil), nil
+}
+
+// containsPoint checks whether the point lies inside or on the boundary of the polygon
+func (p Polygon) containsPoint(pt Point) bool {
+	inside := false
+	n := len(p)
+	for i := range p {
+		a, b := p[i], p[(i+1)%n]
+		turn := cross(a, b, pt)
+		if turn == 0 && onSegment(a, b, pt) {
+			return true
+		}
+		// count the edges crossing the horizontal ray from pt to the east
+		if (a.Lat > pt.Lat) != (b.Lat > pt.Lat) && (b.Lat > a.Lat) == (turn > 0) {
+			inside = !inside
+		}
+	}
+	return inside
+}
+
+// scaled returns the polygon with all coordinates multiplied by factor
+func (p Polygon) scaled(factor int64) Polygon {
+	res := make(Polygon, len(p))
+	for i, pt := range p {
+		res[i] = Point{Lon: pt.Lon * factor, Lat: pt.Lat * factor}
+	}
+	return res
+}
+
+// Contains checks whether the inner polygon lies completely inside the outer polygon, sharing boundary segments or
+// points with it is allowed
+func Contains(outer Polygon, inner Polygon) (bool, error) {
+	if len(outer) == 0 || len(inner) == 0 {
+		return false, fmt.Errorf("empty polygon")
+	}
+	// midpoints are tested against the outer polygon scaled by two so that they have integer coordinates
+	doubled := outer.scaled(2)
+	n, m := len(inner), len(outer)
+	for i := range inner {
+		a, b := inner[i], inner[(i+1)%n]
+		// the edge is cut at every point where it touches the boundary of the outer polygon, each of the pieces
+		// then lies either completely inside or completely outside of it
+		cuts := []Point{a, b}
+		for j := range outer {
+			c, d := outer[j], outer[(j+1)%m]
+			d1, d2 := sign(cross(c, d, a)), sign(cross(c, d, b))
+			d3, d4 := sign(cross(a, b, c)), sign(cross(a, b, d))
+			if d1*d2 < 0 && d3*d4 < 0 {
+				return false, nil
+			}
+			if d3 == 0 && onSegment(a, b, c) {
+				cuts = append(cuts, c)
+			}
+		}
+		dir := Point{Lon: b.Lon - a.Lon, Lat: b.Lat - a.Lat}
+		sort.Slice(cuts, func(x, y int) bool {
+			return (cuts[x].Lon-a.Lon)*dir.Lon+(cuts[x].Lat-a.Lat)*dir.Lat <
+				(cuts[y].Lon-a.Lon)*dir.Lon+(cuts[y].Lat-a.Lat)*dir.Lat
+		})
+		for k := 0; k+1 < len(cuts); k++ {
+			if !doubled.containsPoint(Point{Lon: cuts[k].Lon + cuts[k+1].Lon, Lat: cuts[k].Lat + cuts[k+1].Lat}) {
+				return false, nil
+			}
+		}
+	}
+	return true, nil
+}
+
+// Tiles checks whether the parts cover the whole polygon exactly without overlapping each other, i.e. whether the
+// whole polygon can be cut into the parts along their shared boundaries. This is the case if every part lies inside
+// the whole, no two parts intersect and the areas of the parts add up to the area of the whole. The parts are
+// triangulated once by the caller and tested is called before testing each pair of triangles of two parts, if it isn't
+// nil, so that callers can meter the work done
+func Tiles(whole Polygon, parts []Triangulation, tested func()) (bool, error) {
+	if len(parts) == 0 {
+		return false, fmt.Errorf("no parts")
+	}
+	sum := new(big.Int)
+	for i, part := range parts {
+		contained, err := Contains(whole, part.polygon)
+		if err != nil || !contained {
+			return false, err
+		}
+		for _, other := range parts[:i] {
+			if part.Intersects(other, tested) {
+				return false, nil
+			}
+		}
+		sum.Add(sum, part.polygon.ExactArea())
+	}
+	return sum.Cmp(whole.ExactArea()) == 0, nil
+}
@@ -0,0 +1,191 @@
+package geo
+
+import (
+	"encoding/binary"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	square   = `{"type":"Polygon","coordinates":[[[0,0],[1,0],[1,1],[0,1],[0,0]]]}`
+	adjacent = `POLYGON ((1 0, 2 0, 2 1, 1 1, 1 0))`
+	shifted  = `POLYGON ((0.5 0.5, 2 0.5, 2 2, 0.5 2, 0.5 0.5))`
+	uShape   = `POLYGON ((0 0, 3 0, 3 3, 2 3, 2 1, 1 1, 1 3, 0 3, 0 0))`
+	inU      = `POLYGON ((1.1 1.5, 1.9 1.5, 1.9 2.5, 1.1 2.5, 1.1 1.5))`
+)
+
+func mustParse(t *testing.T, s string) Polygon {
+	p, err := Parse(s)
+	require.NoError(t, err)
+	return p
+}
+
+func TestParseAndValidate(t *testing.T) {
+	cases := map[string]struct {
+		input string
+		valid bool
+	}{
+		"geojson square":      {square, true},
+		"wkt square":          {`POLYGON((0 0, 1 0, 1 1, 0 1, 0 0))`, true},
+		"unclosed ring":       {`POLYGON ((0 0, 1 0, 1 1, 0 1))`, false},
+		"holes":               {`POLYGON ((0 0, 3 0, 3 3, 0 3, 0 0), (1 1, 2 1, 2 2, 1 1))`, false},
+		"not a polygon":       {`{"type":"Point","coordinates":[0,0]}`, false},
+		"bow tie":             {`POLYGON ((0 0, 1 1, 1 0, 0 1, 0 0))`, false},
+		"out of bounds":       {`POLYGON ((179 0, 181 0, 181 1, 179 1, 179 0))`, false},
+		"collinear":           {`POLYGON ((0 0, 1 0, 2 0, 0 0))`, false},
+		"repeated point":      {`POLYGON ((0 0, 1 0, 1 0, 1 1, 0 0))`, false},
+		"spike":               {`POLYGON ((0 0, 2 0, 1 0, 1 1, 0 0))`, false},
+		"touching itself":     {`POLYGON ((0 0, 2 0, 2 2, 1 0.5, 0 2, 1 0, 0 0))`, false},
+		"non-convex is valid": {uShape, true},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			p, err := Parse(tc.input)
+			if err == nil {
+				err = p.Validate()
+			}
+			if tc.valid {
+				require.NoError(t, err)
+			} else {
+				require.Error(t, err)
+			}
+		})
+	}
+}
+
+func TestCanonicalEncoding(t *testing.T) {
+	a, err := mustParse(t, square).Marshal()
+	require.NoError(t, err)
+	// same square, clockwise, starting elsewhere and with an extra collinear point
+	b, err := mustParse(t, `POLYGON ((1 1, 1 0.5, 1 0, 0 0, 0 1, 1 1))`).Marshal()
+	require.NoError(t, err)
+	require.Equal(t, a, b)
+
+	p, err := Unmarshal(a)
+	require.NoError(t, err)
+	require.Equal(t, Polygon{{0, 0}, {1000000, 0}, {1000000, 1000000}, {0, 1000000}}, p)
+
+	_, err = Unmarshal(append(a, 0))
+	require.Error(t, err)
+	// the same square wound clockwise is valid but not canonical
+	clockwise := []byte{4}
+	for _, x := range []int64{0, 0, 0, 1000000, 1000000, 1000000, 1000000, 0} {
+		buf := make([]byte, binary.MaxVarintLen64)
+		clockwise = append(clockwise, buf[:binary.PutVarint(buf, x)]...)
+	}
+	_, err = Unmarshal(clockwise)
+	require.EqualError(t, err, "polygon is not canonically encoded")
+}
+
+func TestArea(t *testing.T) {
+	// one degree square at the equator is roughly 111.3km x 110.6km
+	area := mustParse(t, square).Area()
+	require.True(t, math.Abs(area-1.2308e10) < 1e8, "unexpected area %f", area)
+}
+
+func TestExactArea(t *testing.T) {
+	// the unit square is 10^12 square microdegrees
+	require.Equal(t, "2000000000000", mustParse(t, square).ExactArea().String())
+	require.Equal(t, "14000000000000", mustParse(t, uShape).ExactArea().String())
+}
+
+func TestIntersects(t *testing.T) {
+	cases := map[string]struct {
+		a, b       string
+		intersects bool
+	}{
+		"adjacent":         {square, adjacent, false},
+		"overlapping":      {square, shifted, true},
+		"identical":        {square, square, true},
+		"contained":        {square, `POLYGON ((0.2 0.2, 0.3 0.2, 0.3 0.3, 0.2 0.2))`, true},
+		"inside concavity": {uShape, inU, false},
+		"concave overlap":  {uShape, square, true},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			a, b := mustParse(t, tc.a), mustParse(t, tc.b)
+			res, err := Intersects(a, b)
+			require.NoError(t, err)
+			require.Equal(t, tc.intersects, res)
+			res, err = Intersects(b, a)
+			require.NoError(t, err)
+			require.Equal(t, tc.intersects, res)
+			ta, err := Triangulate(a)
+			require.NoError(t, err)
+			tb, err := Triangulate(b)
+			require.NoError(t, err)
+			require.Equal(t, tc.intersects, ta.Intersects(tb, nil))
+		})
+	}
+
+	// polygons with overlapping bounding boxes but disjoint interiors have all pairs of their triangles tested
+	a, err := Triangulate(mustParse(t, uShape))
+	require.NoError(t, err)
+	b, err := Triangulate(mustParse(t, inU))
+	require.NoError(t, err)
+	var tested int
+	require.False(t, a.Intersects(b, func() { tested++ }))
+	require.Equal(t, len(a.triangles)*len(b.triangles), tested)
+}
+
+func TestContains(t *testing.T) {
+	cases := map[string]struct {
+		outer, inner string
+		contains     bool
+	}{
+		"identical":          {square, square, true},
+		"strictly inside":    {square, `POLYGON ((0.2 0.2, 0.3 0.2, 0.3 0.3, 0.2 0.2))`, true},
+		"sharing edges":      {square, `POLYGON ((0 0, 0.5 0, 0.5 1, 0 1, 0 0))`, true},
+		"adjacent":           {square, adjacent, false},
+		"overlapping":        {square, shifted, false},
+		"larger":             {`POLYGON ((0.2 0.2, 0.3 0.2, 0.3 0.3, 0.2 0.2))`, square, false},
+		"inside concavity":   {uShape, inU, false},
+		"bridging concavity": {uShape, `POLYGON ((0 2, 3 2, 3 3, 0 3, 0 2))`, false},
+		"along concavity":    {uShape, `POLYGON ((0 0, 3 0, 3 1, 0 1, 0 0))`, true},
+		"arm of u":           {uShape, `POLYGON ((2 1, 3 1, 3 3, 2 3, 2 1))`, true},
+		"touching vertices":  {uShape, `POLYGON ((1 1, 2 1, 2 3, 1 3, 1 1))`, false},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			res, err := Contains(mustParse(t, tc.outer), mustParse(t, tc.inner))
+			require.NoError(t, err)
+			require.Equal(t, tc.contains, res)
+		})
+	}
+}
+
+func TestTiles(t *testing.T) {
+	left := `POLYGON ((0 0, 0.5 0, 0.5 1, 0 1, 0 0))`
+	right := `POLYGON ((0.5 0, 1 0, 1 1, 0.5 1, 0.5 0))`
+	lowerRight := `POLYGON ((0.5 0, 1 0, 1 0.5, 0.5 0.5, 0.5 0))`
+	upperHalf := `POLYGON ((0 0.5, 1 0.5, 1 1, 0 1, 0 0.5))`
+	cases := map[string]struct {
+		whole string
+		parts []string
+		tiles bool
+	}{
+		"halves":         {square, []string{left, right}, true},
+		"itself":         {square, []string{square}, true},
+		"gap":            {square, []string{left, lowerRight}, false},
+		"overlap":        {square, []string{left, right, upperHalf}, false},
+		"sticking out":   {square, []string{left, adjacent}, false},
+		"union":          {`POLYGON ((0 0, 2 0, 2 1, 0 1, 0 0))`, []string{square, adjacent}, true},
+		"u in three":     {uShape, []string{`POLYGON ((0 0, 3 0, 3 1, 0 1, 0 0))`, `POLYGON ((0 1, 1 1, 1 3, 0 3, 0 1))`, `POLYGON ((2 1, 3 1, 3 3, 2 3, 2 1))`}, true},
+		"u with its gap": {uShape, []string{`POLYGON ((0 0, 3 0, 3 1, 0 1, 0 0))`, `POLYGON ((0 1, 3 1, 3 3, 0 3, 0 1))`}, false},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			parts := make([]Triangulation, len(tc.parts))
+			for i, part := range tc.parts {
+				var err error
+				parts[i], err = Triangulate(mustParse(t, part))
+				require.NoError(t, err)
+			}
+			res, err := Tiles(mustParse(t, tc.whole), parts, nil)
+			require.NoError(t, err)
+			require.Equal(t, tc.tiles, res)
+		})
+	}
+}
@@ -5,7 +5,6 @@ case basis.
 package orm
 
 import (
-	"bytes"
 	"encoding/binary"
 	"encoding/hex"
 	"fmt"
@@ -70,6 +69,10 @@ type AutoIDBucket interface {
 
 	// Create auto-generates key
 	Create(ctx sdk.Context, value interface{}) ([]byte, error)
+	// Sequence returns the number of IDs generated so far, which is the sequence number of the next generated ID
+	Sequence(ctx sdk.Context) (uint64, error)
+	// SetSequence sets the sequence number of the next generated ID. It is used to restore a bucket from genesis
+	SetSequence(ctx sdk.Context, seq uint64)
 }
 
 // Iterator allows iteration through a sequence of key value pairs
@@ -89,8 +92,7 @@ type bucketBase struct {
 }
 
 func (b bucketBase) getOne(ctx sdk.Context, key []byte, dest interface{}) error {
-	store := prefix.NewStore(ctx.KVStore(b.key), []byte(b.bucketPrefix))
-	bz := store.Get(key)
+	bz := b.rootStore(ctx).Get(key)
 	if len(bz) == 0 {
 		return fmt.Errorf("not found")
 	}
@@ -101,8 +103,11 @@ func (b bucketBase) GetOne(ctx sdk.Context, key []byte, dest interface{}) error
 	return b.getOne(ctx, key, dest)
 }
 
+// rootStore returns the store holding the values of the bucket. Values and index entries live under distinct
+// sub-prefixes of the bucket prefix, so that scanning the values of a bucket never returns index entries or values
+// of another bucket whose prefix starts with the same string
 func (b bucketBase) rootStore(ctx sdk.Context) prefix.Store {
-	return prefix.NewStore(ctx.KVStore(b.key), []byte(b.bucketPrefix))
+	return prefix.NewStore(ctx.KVStore(b.key), []byte(fmt.Sprintf("%s/d/", b.bucketPrefix)))
 }
 
 func (b bucketBase) PrefixScan(ctx sdk.Context, start []byte, end []byte, reverse bool) (Iterator, error) {
@@ -117,23 +122,37 @@ func (b bucketBase) PrefixScan(ctx sdk.Context, start []byte, end []byte, revers
 }
 
 func (b bucketBase) indexStore(ctx sdk.Context, indexName string) prefix.Store {
-	return prefix.NewStore(ctx.KVStore(b.key), []byte(fmt.Sprintf("%s/%s", b.bucketPrefix, indexName)))
+	return prefix.NewStore(ctx.KVStore(b.key), []byte(fmt.Sprintf("%s/i/%s/", b.bucketPrefix, indexName)))
+}
+
+// indexKey returns the key under which the index entry for the given index value and primary key is stored. Both
+// parts are hex encoded which preserves their lexicographic ordering
+func indexKey(indexValue []byte, key []byte) []byte {
+	return []byte(fmt.Sprintf("%x/%x", indexValue, key))
+}
+
+// indexBound converts an index value passed to ByIndexPrefixScan into a bound on the hex encoded index keys
+func indexBound(indexValue []byte) []byte {
+	if indexValue == nil {
+		return nil
+	}
+	return []byte(hex.EncodeToString(indexValue))
 }
 
 func (b bucketBase) ByIndex(ctx sdk.Context, indexName string, key []byte) (Iterator, error) {
-	st := b.indexStore(ctx, indexName)
-	it := st.Iterator(key, nil)
-	return &indexIterator{b, ctx, it, key, key}, nil
+	st := prefix.NewStore(b.indexStore(ctx, indexName), []byte(fmt.Sprintf("%x/", key)))
+	it := st.Iterator(nil, nil)
+	return &indexIterator{b, ctx, it}, nil
 }
 
 func (b bucketBase) ByIndexPrefixScan(ctx sdk.Context, indexName string, start []byte, end []byte, reverse bool) (Iterator, error) {
 	st := b.indexStore(ctx, indexName)
 	if reverse {
-		it := st.ReverseIterator(start, end)
-		return &indexIterator{b, ctx,it, start, end}, nil
+		it := st.ReverseIterator(indexBound(start), indexBound(end))
+		return &indexIterator{b, ctx, it}, nil
 	} else {
-		it := st.Iterator(start, end)
-		return &indexIterator{b, ctx, it, start, end}, nil
+		it := st.Iterator(indexBound(start), indexBound(end))
+		return &indexIterator{b, ctx, it}, nil
 	}
 }
 
@@ -162,11 +181,29 @@ func (b bucketBase) save(ctx sdk.Context, key []byte, value interface{}) error {
 		if err != nil {
 			return err
 		}
-		indexStore := b.indexStore(ctx, idx.Name)
-		indexStore.Set([]byte(fmt.Sprintf("%x/%x", i, key)), []byte{0})
+		b.deleteIndexEntry(ctx, idx.Name, key)
+		b.indexStore(ctx, idx.Name).Set(indexKey(i, key), []byte{0})
+		b.reverseIndexStore(ctx, idx.Name).Set(key, i)
 	}
 	return nil
 }
+
+// reverseIndexStore maps the keys of the bucket to their current index value, so that index entries can be removed
+// when a value is updated or deleted without having to decode the old value
+func (b bucketBase) reverseIndexStore(ctx sdk.Context, indexName string) prefix.Store {
+	return prefix.NewStore(ctx.KVStore(b.key), []byte(fmt.Sprintf("%s/r/%s/", b.bucketPrefix, indexName)))
+}
+
+// deleteIndexEntry removes the index entry of the key for the index if there is one
+func (b bucketBase) deleteIndexEntry(ctx sdk.Context, indexName string, key []byte) {
+	reverseStore := b.reverseIndexStore(ctx, indexName)
+	if !reverseStore.Has(key) {
+		return
+	}
+	b.indexStore(ctx, indexName).Delete(indexKey(reverseStore.Get(key), key))
+	reverseStore.Delete(key)
+}
+
 func (b externalKeyBucket) Save(ctx sdk.Context, key []byte, value interface{}) error {
 	return b.save(ctx, key, value)
 }
@@ -174,7 +211,9 @@ func (b externalKeyBucket) Save(ctx sdk.Context, key []byte, value interface{})
 func (b bucketBase) delete(ctx sdk.Context, key []byte) error {
 	rootStore := b.rootStore(ctx)
 	rootStore.Delete(key)
-	// TODO: delete indexes
+	for _, idx := range b.indexes {
+		b.deleteIndexEntry(ctx, idx.Name, key)
+	}
 	return nil
 }
 
@@ -207,7 +246,12 @@ func (n naturalKeyBucket) Delete(ctx sdk.Context, hasID HasID) error {
 	return n.delete(ctx, hasID.ID())
 }
 
+// NewAutoIDBucket creates an AutoIDBucket. If idGenerator is nil, keys are the 8-byte big endian encoding of the
+// auto-incremented ID
 func NewAutoIDBucket(key sdk.StoreKey, bucketPrefix string, cdc *codec.Codec, indexes []Index, idGenerator func(x uint64) []byte) AutoIDBucket {
+	if idGenerator == nil {
+		idGenerator = sdk.Uint64ToBigEndian
+	}
 	return &autoIDBucket{externalKeyBucket{bucketBase{key, bucketPrefix, cdc, indexes}}, idGenerator}
 }
 
@@ -230,19 +274,30 @@ func readUInt64(bz []byte) (uint64, error) {
 	return x, nil
 }
 
+func (a autoIDBucket) Sequence(ctx sdk.Context) (uint64, error) {
+	bz := a.indexStore(ctx, "$").Get([]byte("$"))
+	if bz == nil {
+		return 0, nil
+	}
+	return readUInt64(bz)
+}
+
+func (a autoIDBucket) SetSequence(ctx sdk.Context, seq uint64) {
+	a.indexStore(ctx, "$").Set([]byte("$"), writeUInt64(seq))
+}
+
 func (a autoIDBucket) Create(ctx sdk.Context, value interface{}) ([]byte, error) {
-	st := a.indexStore(ctx, "$")
-	bz := st.Get([]byte("$"))
-	var nextID uint64 = 0
-	var err error
-	if bz != nil {
-		nextID, err = readUInt64(bz)
-		if err != nil {
-			return nil, err
-		}
+	nextID, err := a.Sequence(ctx)
+	if err != nil {
+		return nil, err
+	}
+	a.SetSequence(ctx, nextID+1)
+	key := a.idGenerator(nextID)
+	err = a.save(ctx, key, value)
+	if err != nil {
+		return nil, err
 	}
-	st.Set([]byte("$"), writeUInt64(nextID))
-	return a.idGenerator(nextID), nil
+	return key, nil
 }
 
 type iterator struct {
@@ -270,31 +325,24 @@ func (i *iterator) Release() {
 type indexIterator struct {
 	bucketBase
 	ctx sdk.Context
-	it    sdk.Iterator
-	start []byte
-	end   []byte
+	it  sdk.Iterator
 }
 
 func (i indexIterator) LoadNext(dest interface{}) (key []byte, err error) {
 	if !i.it.Valid() {
 		return nil, fmt.Errorf("invalid")
 	}
+	// keys are the hex encoded primary key, prefixed with the hex encoded index value and a slash unless the
+	// iterator was restricted to a single index value, see indexKey
 	pieces := strings.Split(string(i.it.Key()), "/")
-	if len(pieces) != 2 {
-		return nil, fmt.Errorf("unexpected index key")
+	if len(pieces) > 2 || len(pieces[len(pieces)-1]) == 0 {
+		return nil, fmt.Errorf("unexpected index key %q", i.it.Key())
 	}
-	indexPrefix, err := hex.DecodeString(pieces[0])
-	if err != nil {
-		return nil, err
-	}
-	// check out of range
-	if !((i.start == nil || bytes.Compare(i.start, indexPrefix) >= 0) && (i.end == nil || bytes.Compare(indexPrefix, i.end) <= 0)) {
-		return nil, fmt.Errorf("done")
-	}
-	key, err = hex.DecodeString(pieces[1])
+	key, err = hex.DecodeString(pieces[len(pieces)-1])
 	if err != nil {
 		return nil, err
 	}
+	i.it.Next()
 	err = i.bucketBase.GetOne(i.ctx, key, dest)
 	if err != nil {
 		return nil, err
@@ -0,0 +1,123 @@
+package ecocredit
+
+import (
+	"fmt"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"time"
+)
+
+// CreditAllowance allows the spender to send up to Units units of the holder's credit on behalf of the holder. An
+// allowance without a credit applies to all credits of the holder. An allowance with a zero expiration never expires
+type CreditAllowance struct {
+	Holder     sdk.AccAddress `json:"holder"`
+	Spender    sdk.AccAddress `json:"spender"`
+	Credit     CreditID       `json:"credit"`
+	Units      sdk.Dec        `json:"units"`
+	Expiration time.Time      `json:"expiration"`
+}
+
+func (a CreditAllowance) ID() []byte {
+	return []byte(fmt.Sprintf("%x/%x/%x", a.Holder, a.Spender, a.Credit))
+}
+
+// IsExpired returns whether the allowance is expired at the given block time
+func (a CreditAllowance) IsExpired(blockTime time.Time) bool {
+	return !a.Expiration.IsZero() && !blockTime.Before(a.Expiration)
+}
+
+// GetCreditAllowance gets the allowance of the spender for the credit of the holder. An empty credit gets the
+// allowance for all credits of the holder
+func (k Keeper) GetCreditAllowance(ctx sdk.Context, holder sdk.AccAddress, spender sdk.AccAddress, credit CreditID) (allowance CreditAllowance, found bool) {
+	allowance = CreditAllowance{Holder: holder, Spender: spender, Credit: credit}
+	err := k.creditAllowanceBucket.GetOne(ctx, &allowance)
+	if err != nil {
+		return allowance, false
+	}
+	return allowance, true
+}
+
+// IterateCreditAllowances iterates over all allowances
+func (k Keeper) IterateCreditAllowances(ctx sdk.Context, callback func(allowance CreditAllowance) (stop bool)) {
+	iterator, err := k.creditAllowanceBucket.PrefixScan(ctx, nil, nil, false)
+	if err != nil {
+		return
+	}
+	defer iterator.Release()
+	for {
+		var allowance CreditAllowance
+		_, err := iterator.LoadNext(&allowance)
+		if err != nil {
+			break
+		}
+		if callback(allowance) {
+			return
+		}
+	}
+}
+
+// ApproveCredit sets the allowance of the spender for the credit of the holder, replacing any previous allowance. An
+// empty credit approves the spender for all credits of the holder
+func (k Keeper) ApproveCredit(ctx sdk.Context, holder sdk.AccAddress, spender sdk.AccAddress, credit CreditID, units sdk.Dec, expiration time.Time) error {
+	allowance := CreditAllowance{Holder: holder, Spender: spender, Credit: credit, Units: units, Expiration: expiration}
+	if allowance.IsExpired(ctx.BlockHeader().Time) {
+		return ErrInvalidAllowance(DefaultCodespace, "expiration must be in the future")
+	}
+	if len(credit) != 0 {
+		if _, found := k.GetCredit(ctx, credit); !found {
+			return ErrInvalidCredit(DefaultCodespace, fmt.Sprintf("credit %s not found", credit))
+		}
+	}
+	if err := k.creditAllowanceBucket.Save(ctx, allowance); err != nil {
+		return err
+	}
+	ctx.EventManager().EmitEvent(sdk.NewEvent(
+		EventTypeApproveCredit,
+		sdk.NewAttribute(AttributeKeyHolder, holder.String()),
+		sdk.NewAttribute(AttributeKeySpender, spender.String()),
+		sdk.NewAttribute(AttributeKeyCredit, credit.String()),
+		sdk.NewAttribute(AttributeKeyUnits, units.String()),
+	))
+	return nil
+}
+
+// RevokeCredit removes the allowance of the spender for the credit of the holder
+func (k Keeper) RevokeCredit(ctx sdk.Context, holder sdk.AccAddress, spender sdk.AccAddress, credit CreditID) error {
+	allowance, found := k.GetCreditAllowance(ctx, holder, spender, credit)
+	if !found {
+		return ErrInvalidAllowance(DefaultCodespace, "no allowance to revoke")
+	}
+	if err := k.creditAllowanceBucket.Delete(ctx, allowance); err != nil {
+		return err
+	}
+	ctx.EventManager().EmitEvent(sdk.NewEvent(
+		EventTypeRevokeCredit,
+		sdk.NewAttribute(AttributeKeyHolder, holder.String()),
+		sdk.NewAttribute(AttributeKeySpender, spender.String()),
+		sdk.NewAttribute(AttributeKeyCredit, credit.String()),
+	))
+	return nil
+}
+
+// SendCreditFrom sends units of a credit of the from account on its behalf by the spender, consuming the allowance of
+// the spender. The allowance for the specific credit is used if there is one, otherwise the allowance for all credits
+func (k Keeper) SendCreditFrom(ctx sdk.Context, spender sdk.AccAddress, credit CreditID, from sdk.AccAddress, to sdk.AccAddress, units sdk.Dec) error {
+	allowance, found := k.GetCreditAllowance(ctx, from, spender, credit)
+	if !found {
+		allowance, found = k.GetCreditAllowance(ctx, from, spender, nil)
+	}
+	if !found || allowance.IsExpired(ctx.BlockHeader().Time) {
+		return ErrInsufficientAllowance(DefaultCodespace, fmt.Sprintf("%s has no allowance for credit %s of %s", spender, credit, from))
+	}
+	allowance.Units = allowance.Units.Sub(units)
+	if allowance.Units.IsNegative() {
+		return ErrInsufficientAllowance(DefaultCodespace, fmt.Sprintf("allowance of %s exceeded", spender))
+	}
+	err := k.SendCredit(ctx, credit, from, to, units)
+	if err != nil {
+		return err
+	}
+	if allowance.Units.IsZero() {
+		return k.creditAllowanceBucket.Delete(ctx, allowance)
+	}
+	return k.creditAllowanceBucket.Save(ctx, allowance)
+}
@@ -0,0 +1,347 @@
+package ecocredit
+
+import (
+	"fmt"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/x/supply"
+	"sort"
+	"time"
+)
+
+// AuctionEscrowAddress holds the units offered by all open auctions in the credit holdings bucket and the coins locked
+// by all their bids in the bank
+var AuctionEscrowAddress = supply.NewModuleAddress("ecocredit-auctions")
+
+// MaxAuctionBids is the maximum number of bids of an auction, which bounds the work of clearing it at the end of a
+// block
+const MaxAuctionBids = 100
+
+type AuctionID []byte
+
+// Auction is a uniform-price batch auction of units of a credit escrowed from the seller. When the block time reaches
+// EndTime the auction is cleared: bids are filled from the highest price down until all units are sold and every
+// winning bidder pays the same clearing price, the price of the lowest winning bid
+type Auction struct {
+	Seller sdk.AccAddress `json:"seller"`
+	Credit CreditID       `json:"credit"`
+	Units  sdk.Dec        `json:"units"`
+	// MinPrice is the lowest accepted price of a single unit, bids must be in its denomination
+	MinPrice sdk.Coin  `json:"min_price"`
+	EndTime  time.Time `json:"end_time"`
+}
+
+// AuctionWithID pairs an auction with its ID
+type AuctionWithID struct {
+	ID      AuctionID `json:"id"`
+	Auction Auction   `json:"auction"`
+}
+
+type AuctionBidID []byte
+
+// AuctionBid bids for up to Units units of an auction at Price per unit. The coins needed to pay for all units at the
+// bid price are locked until the auction is cleared
+type AuctionBid struct {
+	Auction AuctionID      `json:"auction"`
+	Bidder  sdk.AccAddress `json:"bidder"`
+	Units   sdk.Dec        `json:"units"`
+	Price   sdk.Coin       `json:"price"`
+}
+
+// AuctionBidWithID pairs an auction bid with its ID
+type AuctionBidWithID struct {
+	ID  AuctionBidID `json:"id"`
+	Bid AuctionBid   `json:"bid"`
+}
+
+// auctionCost returns the cost of units at the price per unit, rounding up fractions of the smallest coin unit
+func auctionCost(price sdk.Coin, units sdk.Dec) sdk.Coin {
+	return sdk.NewCoin(price.Denom, sdk.NewDecFromInt(price.Amount).Mul(units).Ceil().TruncateInt())
+}
+
+// Locked returns the coins locked by the bid
+func (b AuctionBid) Locked() sdk.Coin {
+	return auctionCost(b.Price, b.Units)
+}
+
+// GetAuction gets an open auction
+func (k Keeper) GetAuction(ctx sdk.Context, id AuctionID) (auction Auction, found bool) {
+	err := k.auctionBucket.GetOne(ctx, id, &auction)
+	if err != nil {
+		return auction, false
+	}
+	return auction, true
+}
+
+// IterateAuctions iterates over all open auctions
+func (k Keeper) IterateAuctions(ctx sdk.Context, callback func(id AuctionID, auction Auction) (stop bool)) {
+	iterator, err := k.auctionBucket.PrefixScan(ctx, nil, nil, false)
+	if err != nil {
+		return
+	}
+	defer iterator.Release()
+	for {
+		var auction Auction
+		id, err := iterator.LoadNext(&auction)
+		if err != nil {
+			break
+		}
+		if callback(id, auction) {
+			return
+		}
+	}
+}
+
+// IterateAuctionBids iterates over the bids of all open auctions
+func (k Keeper) IterateAuctionBids(ctx sdk.Context, callback func(id AuctionBidID, bid AuctionBid) (stop bool)) {
+	iterator, err := k.auctionBidBucket.PrefixScan(ctx, nil, nil, false)
+	if err != nil {
+		return
+	}
+	defer iterator.Release()
+	for {
+		var bid AuctionBid
+		id, err := iterator.LoadNext(&bid)
+		if err != nil {
+			break
+		}
+		if callback(id, bid) {
+			return
+		}
+	}
+}
+
+// IterateBidsOfAuction iterates over the bids of an auction in the order they were placed
+func (k Keeper) IterateBidsOfAuction(ctx sdk.Context, auction AuctionID, callback func(id AuctionBidID, bid AuctionBid) (stop bool)) {
+	iterator, err := k.auctionBidBucket.ByIndex(ctx, IndexByAuction, auction)
+	if iterator != nil {
+		defer iterator.Release()
+	}
+	if err != nil {
+		return
+	}
+	for {
+		var bid AuctionBid
+		id, err := iterator.LoadNext(&bid)
+		if err != nil {
+			break
+		}
+		if callback(id, bid) {
+			return
+		}
+	}
+}
+
+// CreateAuction escrows units of a credit held by the seller and auctions them off at the end time. Units of expired
+// credits can't be auctioned
+func (k Keeper) CreateAuction(ctx sdk.Context, seller sdk.AccAddress, credit CreditID, units sdk.Dec, minPrice sdk.Coin, endTime time.Time) (AuctionID, error) {
+	if !endTime.After(ctx.BlockHeader().Time) {
+		return nil, ErrInvalidAuction(DefaultCodespace, "end time must be in the future")
+	}
+	if err := k.checkCreditNotExpired(ctx, credit); err != nil {
+		return nil, err
+	}
+	err := k.transferCredit(ctx, credit, seller, AuctionEscrowAddress, units)
+	if err != nil {
+		return nil, err
+	}
+	id, err := k.auctionBucket.Create(ctx, Auction{Seller: seller, Credit: credit, Units: units, MinPrice: minPrice, EndTime: endTime})
+	if err != nil {
+		return nil, err
+	}
+	ctx.EventManager().EmitEvent(sdk.NewEvent(
+		EventTypeCreateAuction,
+		sdk.NewAttribute(AttributeKeyAuction, AuctionID(id).String()),
+		sdk.NewAttribute(AttributeKeySeller, seller.String()),
+		sdk.NewAttribute(AttributeKeyCredit, credit.String()),
+		sdk.NewAttribute(AttributeKeyUnits, units.String()),
+		sdk.NewAttribute(AttributeKeyPrice, minPrice.String()),
+		sdk.NewAttribute(AttributeKeyEndTime, endTime.UTC().Format(time.RFC3339)),
+	))
+	return id, nil
+}
+
+// PlaceBid bids for up to units units of an open auction at a price per unit and locks the coins needed to pay for
+// all of them. Bids can't be withdrawn, the coins not needed are refunded when the auction is cleared. An auction
+// takes up to MaxAuctionBids bids
+func (k Keeper) PlaceBid(ctx sdk.Context, bidder sdk.AccAddress, id AuctionID, units sdk.Dec, price sdk.Coin) (AuctionBidID, error) {
+	auction, found := k.GetAuction(ctx, id)
+	if !found {
+		return nil, ErrInvalidAuction(DefaultCodespace, fmt.Sprintf("auction %s not found", id))
+	}
+	if !ctx.BlockHeader().Time.Before(auction.EndTime) {
+		return nil, ErrInvalidAuction(DefaultCodespace, fmt.Sprintf("auction %s has ended", id))
+	}
+	if price.Denom != auction.MinPrice.Denom || price.IsLT(auction.MinPrice) {
+		return nil, ErrInvalidPrice(DefaultCodespace, fmt.Sprintf("bids must be at least %s", auction.MinPrice))
+	}
+	if err := k.checkPrecision(ctx, auction.Credit, units); err != nil {
+		return nil, err
+	}
+	bids := 0
+	k.IterateBidsOfAuction(ctx, id, func(bidID AuctionBidID, bid AuctionBid) (stop bool) {
+		bids++
+		return false
+	})
+	if bids >= MaxAuctionBids {
+		return nil, ErrInvalidAuction(DefaultCodespace, fmt.Sprintf("auction %s already has %d bids", id, bids))
+	}
+	bid := AuctionBid{Auction: id, Bidder: bidder, Units: units, Price: price}
+	if err := k.bankKeeper.SendCoins(ctx, bidder, AuctionEscrowAddress, sdk.NewCoins(bid.Locked())); err != nil {
+		return nil, err
+	}
+	bidID, err := k.auctionBidBucket.Create(ctx, bid)
+	if err != nil {
+		return nil, err
+	}
+	ctx.EventManager().EmitEvent(sdk.NewEvent(
+		EventTypePlaceBid,
+		sdk.NewAttribute(AttributeKeyAuction, id.String()),
+		sdk.NewAttribute(AttributeKeyBid, AuctionBidID(bidID).String()),
+		sdk.NewAttribute(AttributeKeyBidder, bidder.String()),
+		sdk.NewAttribute(AttributeKeyCredit, auction.Credit.String()),
+		sdk.NewAttribute(AttributeKeyUnits, units.String()),
+		sdk.NewAttribute(AttributeKeyPrice, price.String()),
+	))
+	return bidID, nil
+}
+
+// ClearAuctions clears all auctions which ended at or before the block time in the order of their end time. It is
+// called at the end of every block, so an auction which fails to clear doesn't halt the chain: it is logged and
+// cancelled instead, refunding its bids and returning its units to the seller
+func (k Keeper) ClearAuctions(ctx sdk.Context) {
+	end := sdk.FormatTimeBytes(ctx.BlockHeader().Time.Add(time.Nanosecond))
+	iterator, err := k.auctionBucket.ByIndexPrefixScan(ctx, IndexByEndTime, nil, end, false)
+	if err != nil {
+		k.Logger(ctx).Error(fmt.Sprintf("failed to load ended auctions: %s", err))
+		return
+	}
+	// the auctions are collected first as clearing them removes them from the index being iterated
+	var ended []AuctionWithID
+	for {
+		var auction Auction
+		id, err := iterator.LoadNext(&auction)
+		if err != nil {
+			break
+		}
+		ended = append(ended, AuctionWithID{ID: id, Auction: auction})
+	}
+	iterator.Release()
+	for _, auction := range ended {
+		// each auction is cleared in a cache context so that a failure leaves no partial state behind
+		cacheCtx, write := ctx.CacheContext()
+		err := k.clearAuction(cacheCtx, auction.ID, auction.Auction)
+		if err != nil {
+			k.Logger(ctx).Error(fmt.Sprintf("failed to clear auction %s: %s", auction.ID, err))
+			cacheCtx, write = ctx.CacheContext()
+			if err := k.cancelAuction(cacheCtx, auction.ID, auction.Auction, err.Error()); err != nil {
+				k.Logger(ctx).Error(fmt.Sprintf("failed to cancel auction %s: %s", auction.ID, err))
+				continue
+			}
+		}
+		write()
+		// the cache context has its own event manager
+		ctx.EventManager().EmitEvents(cacheCtx.EventManager().Events())
+	}
+}
+
+// clearAuction fills the bids of an auction from the highest price down, earlier bids first among equal prices, until
+// all units are sold. Winning bidders receive their units and pay the clearing price for them, the rest of their
+// locked coins and the locked coins of losing bids are refunded. The seller receives the proceeds and any unsold units.
+// Units are released with transferCredit rather than SendCredit, which refuses to move units out of escrow and units
+// of expired credits: the units of an auction of a credit which expired meanwhile go to their new holder and expire
+// there, see transferCredit
+func (k Keeper) clearAuction(ctx sdk.Context, id AuctionID, auction Auction) error {
+	var bids []AuctionBidWithID
+	k.IterateBidsOfAuction(ctx, id, func(bidID AuctionBidID, bid AuctionBid) (stop bool) {
+		bids = append(bids, AuctionBidWithID{ID: bidID, Bid: bid})
+		return false
+	})
+	ranked := make([]AuctionBidWithID, len(bids))
+	copy(ranked, bids)
+	sort.SliceStable(ranked, func(i, j int) bool {
+		return ranked[i].Bid.Price.Amount.GT(ranked[j].Bid.Price.Amount)
+	})
+	filled := make(map[string]sdk.Dec, len(bids))
+	remaining := auction.Units
+	clearingPrice := auction.MinPrice
+	for _, bid := range ranked {
+		if !remaining.IsPositive() {
+			break
+		}
+		units := sdk.MinDec(bid.Bid.Units, remaining)
+		filled[string(bid.ID)] = units
+		remaining = remaining.Sub(units)
+		clearingPrice = bid.Bid.Price
+	}
+
+	proceeds := sdk.NewCoin(clearingPrice.Denom, sdk.ZeroInt())
+	for _, bid := range bids {
+		refund := bid.Bid.Locked()
+		if units, won := filled[string(bid.ID)]; won {
+			cost := auctionCost(clearingPrice, units)
+			if err := k.transferCredit(ctx, auction.Credit, AuctionEscrowAddress, bid.Bid.Bidder, units); err != nil {
+				return err
+			}
+			proceeds = proceeds.Add(cost)
+			refund = refund.Sub(cost)
+		}
+		if refund.IsPositive() {
+			if err := k.bankKeeper.SendCoins(ctx, AuctionEscrowAddress, bid.Bid.Bidder, sdk.NewCoins(refund)); err != nil {
+				return err
+			}
+		}
+		if err := k.auctionBidBucket.Delete(ctx, bid.ID); err != nil {
+			return err
+		}
+	}
+	if proceeds.IsPositive() {
+		if err := k.bankKeeper.SendCoins(ctx, AuctionEscrowAddress, auction.Seller, sdk.NewCoins(proceeds)); err != nil {
+			return err
+		}
+	}
+	if remaining.IsPositive() {
+		if err := k.transferCredit(ctx, auction.Credit, AuctionEscrowAddress, auction.Seller, remaining); err != nil {
+			return err
+		}
+	}
+	if err := k.auctionBucket.Delete(ctx, id); err != nil {
+		return err
+	}
+	ctx.EventManager().EmitEvent(sdk.NewEvent(
+		EventTypeClearAuction,
+		sdk.NewAttribute(AttributeKeyAuction, id.String()),
+		sdk.NewAttribute(AttributeKeyClearingPrice, clearingPrice.String()),
+		sdk.NewAttribute(AttributeKeyUnitsSold, auction.Units.Sub(remaining).String()),
+	))
+	return nil
+}
+
+// cancelAuction refunds the locked coins of all bids of an auction which couldn't be cleared and returns its units to
+// the seller
+func (k Keeper) cancelAuction(ctx sdk.Context, id AuctionID, auction Auction, reason string) error {
+	var bids []AuctionBidWithID
+	k.IterateBidsOfAuction(ctx, id, func(bidID AuctionBidID, bid AuctionBid) (stop bool) {
+		bids = append(bids, AuctionBidWithID{ID: bidID, Bid: bid})
+		return false
+	})
+	for _, bid := range bids {
+		if err := k.bankKeeper.SendCoins(ctx, AuctionEscrowAddress, bid.Bid.Bidder, sdk.NewCoins(bid.Bid.Locked())); err != nil {
+			return err
+		}
+		if err := k.auctionBidBucket.Delete(ctx, bid.ID); err != nil {
+			return err
+		}
+	}
+	if err := k.transferCredit(ctx, auction.Credit, AuctionEscrowAddress, auction.Seller, auction.Units); err != nil {
+		return err
+	}
+	if err := k.auctionBucket.Delete(ctx, id); err != nil {
+		return err
+	}
+	ctx.EventManager().EmitEvent(sdk.NewEvent(
+		EventTypeCancelAuction,
+		sdk.NewAttribute(AttributeKeyAuction, id.String()),
+		sdk.NewAttribute(AttributeKeyReason, reason),
+	))
+	return nil
+}
@@ -0,0 +1,365 @@
+package ecocredit
+
+import (
+	"bytes"
+	"fmt"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/x/supply"
+	"time"
+)
+
+// BondPoolName is the name of the module account holding the bonds posted by issuers, including the bonds being
+// unbonded. It must be able to burn slashed coins
+const BondPoolName = "ecocredit_bonds"
+
+// MaxSlashReasonLength is the maximum length of the reason for slashing a bond
+const MaxSlashReasonLength = 256
+
+// IssuerBond is the bond an issuer posted for a credit class. Classes with a minimum IssuerBond only let issuers
+// with at least that much bonded issue credits, and the bond can be slashed after a fraudulent issuance
+type IssuerBond struct {
+	CreditClass CreditClassID  `json:"credit_class"`
+	Issuer      sdk.AccAddress `json:"issuer"`
+	Amount      sdk.Coins      `json:"amount"`
+}
+
+func (b IssuerBond) ID() []byte {
+	return []byte(fmt.Sprintf("%x/%x", b.CreditClass, b.Issuer))
+}
+
+type BondUnbondingID []byte
+
+// BondUnbonding is a part of a bond withdrawn by the issuer. It no longer counts towards the bond required to issue
+// credits but can still be slashed until it is returned to the issuer at CompletionTime
+type BondUnbonding struct {
+	CreditClass    CreditClassID  `json:"credit_class"`
+	Issuer         sdk.AccAddress `json:"issuer"`
+	Amount         sdk.Coins      `json:"amount"`
+	CompletionTime time.Time      `json:"completion_time"`
+}
+
+// BondUnbondingWithID pairs a bond unbonding with its ID
+type BondUnbondingWithID struct {
+	ID        BondUnbondingID `json:"id"`
+	Unbonding BondUnbonding   `json:"unbonding"`
+}
+
+// IssuerBondStatus is the result of the bond query
+type IssuerBondStatus struct {
+	IssuerBond `json:"bond"`
+	Unbondings []BondUnbondingWithID `json:"unbondings"`
+	// Sufficient is whether the bond covers the minimum bond of the class, so that the issuer can issue credits
+	Sufficient bool `json:"sufficient"`
+}
+
+// PostBond moves coins of an issuer of the credit class to the bond pool, adding them to the bond of the issuer
+// for the class
+func (k Keeper) PostBond(ctx sdk.Context, issuer sdk.AccAddress, class CreditClassID, amount sdk.Coins) error {
+	metadata, found := k.GetCreditClass(ctx, class)
+	if !found {
+		return ErrInvalidCreditClass(DefaultCodespace, fmt.Sprintf("credit class %s not found", class))
+	}
+	if _, found := k.GetCreditClassVoucher(ctx, class); found {
+		return ErrInvalidCreditClass(DefaultCodespace, fmt.Sprintf("credit class %s is a voucher, no credits can be issued under it", class))
+	}
+	if !metadata.IsIssuer(issuer) {
+		return sdk.ErrUnauthorized(fmt.Sprintf("%s is not an issuer of the credit class", issuer))
+	}
+	if err := k.supplyKeeper.SendCoinsFromAccountToModule(ctx, issuer, BondPoolName, amount); err != nil {
+		return err
+	}
+	bond, found := k.GetIssuerBond(ctx, class, issuer)
+	if !found {
+		bond = IssuerBond{CreditClass: class, Issuer: issuer}
+	}
+	bond.Amount = bond.Amount.Add(amount)
+	if err := k.issuerBondBucket.Save(ctx, bond); err != nil {
+		return err
+	}
+	ctx.EventManager().EmitEvent(sdk.NewEvent(
+		EventTypePostBond,
+		sdk.NewAttribute(AttributeKeyCreditClass, class.String()),
+		sdk.NewAttribute(AttributeKeyIssuer, issuer.String()),
+		sdk.NewAttribute(AttributeKeyAmount, amount.String()),
+	))
+	return nil
+}
+
+// UnbondBond starts withdrawing coins from the bond of the issuer for the credit class. The coins are returned after
+// the bond unbonding period of the class, see CompleteBondUnbondings, and the ID of the unbonding is returned
+func (k Keeper) UnbondBond(ctx sdk.Context, issuer sdk.AccAddress, class CreditClassID, amount sdk.Coins) (BondUnbondingID, error) {
+	metadata, found := k.GetCreditClass(ctx, class)
+	if !found {
+		return nil, ErrInvalidCreditClass(DefaultCodespace, fmt.Sprintf("credit class %s not found", class))
+	}
+	bond, found := k.GetIssuerBond(ctx, class, issuer)
+	if !found || !bond.Amount.IsAllGTE(amount) {
+		return nil, ErrInsufficientBond(DefaultCodespace, fmt.Sprintf("%s has not bonded %s for the credit class", issuer, amount))
+	}
+	bond.Amount = bond.Amount.Sub(amount)
+	if err := k.saveIssuerBond(ctx, bond); err != nil {
+		return nil, err
+	}
+	completion := ctx.BlockHeader().Time.Add(metadata.BondUnbondingPeriod)
+	id, err := k.bondUnbondingBucket.Create(ctx, BondUnbonding{
+		CreditClass:    class,
+		Issuer:         issuer,
+		Amount:         amount,
+		CompletionTime: completion,
+	})
+	if err != nil {
+		return nil, err
+	}
+	ctx.EventManager().EmitEvent(sdk.NewEvent(
+		EventTypeUnbondBond,
+		sdk.NewAttribute(AttributeKeyCreditClass, class.String()),
+		sdk.NewAttribute(AttributeKeyIssuer, issuer.String()),
+		sdk.NewAttribute(AttributeKeyAmount, amount.String()),
+		sdk.NewAttribute(AttributeKeyCompletionTime, completion.Format(time.RFC3339)),
+	))
+	return id, nil
+}
+
+// CompleteBondUnbondings returns the coins of all bond unbondings which completed by the time of the current block
+// to their issuers. It is called at the end of every block, so an unbonding which fails to complete doesn't halt the
+// chain: it is logged and left in place to be retried at the next block
+func (k Keeper) CompleteBondUnbondings(ctx sdk.Context) {
+	end := sdk.FormatTimeBytes(ctx.BlockHeader().Time.Add(time.Nanosecond))
+	iterator, err := k.bondUnbondingBucket.ByIndexPrefixScan(ctx, IndexByEndTime, nil, end, false)
+	if err != nil {
+		k.Logger(ctx).Error(fmt.Sprintf("failed to load completed bond unbondings: %s", err))
+		return
+	}
+	// the unbondings are collected first as completing them removes them from the index being iterated
+	var completed []BondUnbondingWithID
+	for {
+		var unbonding BondUnbonding
+		id, err := iterator.LoadNext(&unbonding)
+		if err != nil {
+			break
+		}
+		completed = append(completed, BondUnbondingWithID{ID: id, Unbonding: unbonding})
+	}
+	iterator.Release()
+	for _, u := range completed {
+		// each unbonding is completed in a cache context so that a failure leaves no partial state behind
+		cacheCtx, write := ctx.CacheContext()
+		if err := k.completeBondUnbonding(cacheCtx, u.ID, u.Unbonding); err != nil {
+			k.Logger(ctx).Error(fmt.Sprintf("failed to complete bond unbonding %s: %s", u.ID, err))
+			continue
+		}
+		write()
+		// the cache context has its own event manager
+		ctx.EventManager().EmitEvents(cacheCtx.EventManager().Events())
+	}
+}
+
+// completeBondUnbonding returns the coins of a bond unbonding to its issuer
+func (k Keeper) completeBondUnbonding(ctx sdk.Context, id BondUnbondingID, unbonding BondUnbonding) error {
+	if !unbonding.Amount.IsZero() {
+		err := k.supplyKeeper.SendCoinsFromModuleToAccount(ctx, BondPoolName, unbonding.Issuer, unbonding.Amount)
+		if err != nil {
+			return err
+		}
+	}
+	if err := k.bondUnbondingBucket.Delete(ctx, id); err != nil {
+		return err
+	}
+	ctx.EventManager().EmitEvent(sdk.NewEvent(
+		EventTypeCompleteBondUnbonding,
+		sdk.NewAttribute(AttributeKeyCreditClass, unbonding.CreditClass.String()),
+		sdk.NewAttribute(AttributeKeyIssuer, unbonding.Issuer.String()),
+		sdk.NewAttribute(AttributeKeyAmount, unbonding.Amount.String()),
+	))
+	return nil
+}
+
+// SlashBond slashes the bond of an issuer for a fraudulent issuance on behalf of the designer of the credit class.
+// The credit proves the fraud and must be a credit of the class issued by the issuer, see slashBond
+func (k Keeper) SlashBond(ctx sdk.Context, designer sdk.AccAddress, class CreditClassID, issuer sdk.AccAddress, credit CreditID, fraction sdk.Dec, reason string) (sdk.Coins, error) {
+	if _, err := k.getCreditClassAsDesigner(ctx, class, designer); err != nil {
+		return nil, err
+	}
+	return k.slashBond(ctx, class, issuer, credit, fraction, reason)
+}
+
+// HandleSlashBondProposal slashes the bond of an issuer after a governance proposal passed
+func HandleSlashBondProposal(ctx sdk.Context, k Keeper, p SlashBondProposal) sdk.Error {
+	_, err := k.slashBond(ctx, p.CreditClass, p.Issuer, p.Credit, p.Fraction, p.Title)
+	if err != nil {
+		return toSDKError(err)
+	}
+	return nil
+}
+
+// slashBond burns the fraction of the bond of the issuer for the credit class and of each of its pending
+// unbondings, rounded down, and returns the burned coins. The credit must be a credit of the class issued by the
+// issuer
+func (k Keeper) slashBond(ctx sdk.Context, class CreditClassID, issuer sdk.AccAddress, credit CreditID, fraction sdk.Dec, reason string) (sdk.Coins, error) {
+	metadata, found := k.GetCredit(ctx, credit)
+	if !found || !bytes.Equal(metadata.CreditClass, class) || !metadata.Issuer.Equals(issuer) {
+		return nil, ErrInvalidBond(DefaultCodespace, fmt.Sprintf("credit %s was not issued by %s in the credit class", credit, issuer))
+	}
+	var slashed sdk.Coins
+	bond, found := k.GetIssuerBond(ctx, class, issuer)
+	if found {
+		cut := slashCoins(bond.Amount, fraction)
+		bond.Amount = bond.Amount.Sub(cut)
+		if err := k.saveIssuerBond(ctx, bond); err != nil {
+			return nil, err
+		}
+		slashed = slashed.Add(cut)
+	}
+	var unbondings []BondUnbondingWithID
+	k.IterateIssuerBondUnbondings(ctx, class, issuer, func(id BondUnbondingID, unbonding BondUnbonding) (stop bool) {
+		unbondings = append(unbondings, BondUnbondingWithID{ID: id, Unbonding: unbonding})
+		return false
+	})
+	for _, u := range unbondings {
+		cut := slashCoins(u.Unbonding.Amount, fraction)
+		u.Unbonding.Amount = u.Unbonding.Amount.Sub(cut)
+		if err := k.bondUnbondingBucket.Save(ctx, u.ID, u.Unbonding); err != nil {
+			return nil, err
+		}
+		slashed = slashed.Add(cut)
+	}
+	if slashed.IsZero() {
+		return nil, ErrInsufficientBond(DefaultCodespace, fmt.Sprintf("%s has no bond to slash for the credit class", issuer))
+	}
+	if err := k.supplyKeeper.BurnCoins(ctx, BondPoolName, slashed); err != nil {
+		return nil, err
+	}
+	ctx.EventManager().EmitEvent(sdk.NewEvent(
+		EventTypeSlashBond,
+		sdk.NewAttribute(AttributeKeyCreditClass, class.String()),
+		sdk.NewAttribute(AttributeKeyIssuer, issuer.String()),
+		sdk.NewAttribute(AttributeKeyCredit, credit.String()),
+		sdk.NewAttribute(AttributeKeyFraction, fraction.String()),
+		sdk.NewAttribute(AttributeKeyAmount, slashed.String()),
+		sdk.NewAttribute(AttributeKeyReason, reason),
+	))
+	return slashed, nil
+}
+
+// slashCoins returns the fraction of the coins, rounded down
+func slashCoins(coins sdk.Coins, fraction sdk.Dec) sdk.Coins {
+	var cut sdk.Coins
+	for _, coin := range coins {
+		amount := coin.Amount.ToDec().Mul(fraction).TruncateInt()
+		if amount.IsPositive() {
+			cut = cut.Add(sdk.NewCoins(sdk.NewCoin(coin.Denom, amount)))
+		}
+	}
+	return cut
+}
+
+// saveIssuerBond saves the bond or deletes it once nothing is bonded anymore
+func (k Keeper) saveIssuerBond(ctx sdk.Context, bond IssuerBond) error {
+	if bond.Amount.IsZero() {
+		return k.issuerBondBucket.Delete(ctx, bond)
+	}
+	return k.issuerBondBucket.Save(ctx, bond)
+}
+
+// checkIssuerBond checks that the issuer has bonded at least the minimum bond of the credit class
+func (k Keeper) checkIssuerBond(ctx sdk.Context, class CreditClassID, metadata CreditClassMetadata, issuer sdk.AccAddress) error {
+	if metadata.IssuerBond.IsZero() {
+		return nil
+	}
+	bond, found := k.GetIssuerBond(ctx, class, issuer)
+	if !found || !bond.Amount.IsAllGTE(metadata.IssuerBond) {
+		return ErrInsufficientBond(DefaultCodespace, fmt.Sprintf("%s must bond at least %s to issue credits of the class", issuer, metadata.IssuerBond))
+	}
+	return nil
+}
+
+// BondPoolAddress returns the address of the module account holding the bonds
+func BondPoolAddress() sdk.AccAddress {
+	return supply.NewModuleAddress(BondPoolName)
+}
+
+// GetIssuerBond gets the bond of an issuer for a credit class
+func (k Keeper) GetIssuerBond(ctx sdk.Context, class CreditClassID, issuer sdk.AccAddress) (bond IssuerBond, found bool) {
+	bond = IssuerBond{CreditClass: class, Issuer: issuer}
+	err := k.issuerBondBucket.GetOne(ctx, &bond)
+	if err != nil {
+		return bond, false
+	}
+	return bond, true
+}
+
+// GetIssuerBondStatus gets the bond of an issuer for a credit class with its pending unbondings and whether it
+// covers the minimum bond of the class
+func (k Keeper) GetIssuerBondStatus(ctx sdk.Context, class CreditClassID, issuer sdk.AccAddress) IssuerBondStatus {
+	bond, _ := k.GetIssuerBond(ctx, class, issuer)
+	status := IssuerBondStatus{IssuerBond: bond, Unbondings: []BondUnbondingWithID{}}
+	k.IterateIssuerBondUnbondings(ctx, class, issuer, func(id BondUnbondingID, unbonding BondUnbonding) (stop bool) {
+		status.Unbondings = append(status.Unbondings, BondUnbondingWithID{ID: id, Unbonding: unbonding})
+		return false
+	})
+	if metadata, found := k.GetCreditClass(ctx, class); found {
+		status.Sufficient = k.checkIssuerBond(ctx, class, metadata, issuer) == nil
+	}
+	return status
+}
+
+// IterateIssuerBonds iterates over the bonds of all issuers for all credit classes
+func (k Keeper) IterateIssuerBonds(ctx sdk.Context, callback func(bond IssuerBond) (stop bool)) {
+	iterator, err := k.issuerBondBucket.PrefixScan(ctx, nil, nil, false)
+	if err != nil {
+		return
+	}
+	defer iterator.Release()
+	for {
+		var bond IssuerBond
+		_, err := iterator.LoadNext(&bond)
+		if err != nil {
+			break
+		}
+		if callback(bond) {
+			return
+		}
+	}
+}
+
+// IterateBondUnbondings iterates over all pending bond unbondings in the order they were started
+func (k Keeper) IterateBondUnbondings(ctx sdk.Context, callback func(id BondUnbondingID, unbonding BondUnbonding) (stop bool)) {
+	iterator, err := k.bondUnbondingBucket.PrefixScan(ctx, nil, nil, false)
+	if err != nil {
+		return
+	}
+	defer iterator.Release()
+	for {
+		var unbonding BondUnbonding
+		id, err := iterator.LoadNext(&unbonding)
+		if err != nil {
+			break
+		}
+		if callback(id, unbonding) {
+			return
+		}
+	}
+}
+
+// IterateIssuerBondUnbondings iterates over the pending unbondings of an issuer for a credit class in the order they
+// were started
+func (k Keeper) IterateIssuerBondUnbondings(ctx sdk.Context, class CreditClassID, issuer sdk.AccAddress, callback func(id BondUnbondingID, unbonding BondUnbonding) (stop bool)) {
+	key := IssuerBond{CreditClass: class, Issuer: issuer}.ID()
+	iterator, err := k.bondUnbondingBucket.ByIndex(ctx, IndexByIssuerBond, key)
+	if iterator != nil {
+		defer iterator.Release()
+	}
+	if err != nil {
+		return
+	}
+	for {
+		var unbonding BondUnbonding
+		id, err := iterator.LoadNext(&unbonding)
+		if err != nil {
+			break
+		}
+		if callback(id, unbonding) {
+			return
+		}
+	}
+}
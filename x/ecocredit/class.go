@@ -0,0 +1,254 @@
+package ecocredit
+
+import (
+	"bytes"
+	"fmt"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"strings"
+)
+
+// CreditClass pairs the metadata of a credit class with its ID
+type CreditClass struct {
+	ID       CreditClassID       `json:"id"`
+	Metadata CreditClassMetadata `json:"metadata"`
+}
+
+// GetCreditClass gets the metadata of a credit class
+func (k Keeper) GetCreditClass(ctx sdk.Context, id CreditClassID) (metadata CreditClassMetadata, found bool) {
+	err := k.creditClassBucket.GetOne(ctx, id, &metadata)
+	if err != nil {
+		return metadata, false
+	}
+	return metadata, true
+}
+
+// IterateCreditClasses iterates over all credit classes in the order they were created
+func (k Keeper) IterateCreditClasses(ctx sdk.Context, callback func(id CreditClassID, metadata CreditClassMetadata) (stop bool)) {
+	iterator, err := k.creditClassBucket.PrefixScan(ctx, nil, nil, false)
+	if err != nil {
+		return
+	}
+	defer iterator.Release()
+	for {
+		var metadata CreditClassMetadata
+		id, err := iterator.LoadNext(&metadata)
+		if err != nil {
+			break
+		}
+		if callback(id, metadata) {
+			return
+		}
+	}
+}
+
+// IsIssuer returns whether the address is one of the authorized issuers of the credit class
+func (m CreditClassMetadata) IsIssuer(addr sdk.AccAddress) bool {
+	return m.issuerIndex(addr) >= 0
+}
+
+func (m CreditClassMetadata) issuerIndex(addr sdk.AccAddress) int {
+	for i, issuer := range m.Issuers {
+		if issuer.Equals(addr) {
+			return i
+		}
+	}
+	return -1
+}
+
+// IsVerifier returns whether the address is one of the registered verifiers of the credit class
+func (m CreditClassMetadata) IsVerifier(addr sdk.AccAddress) bool {
+	return m.verifierIndex(addr) >= 0
+}
+
+func (m CreditClassMetadata) verifierIndex(addr sdk.AccAddress) int {
+	for i, verifier := range m.Verifiers {
+		if verifier.Equals(addr) {
+			return i
+		}
+	}
+	return -1
+}
+
+// precisionScale returns 10 to the power of the precision, the number of indivisible parts of one unit
+func precisionScale(precision uint32) sdk.Int {
+	scale := sdk.OneInt()
+	for i := uint32(0); i < precision; i++ {
+		scale = scale.MulRaw(10)
+	}
+	return scale
+}
+
+// CheckPrecision checks that units don't have more decimal places than the precision of the credit class
+func (m CreditClassMetadata) CheckPrecision(units sdk.Dec) error {
+	if !units.MulInt(precisionScale(m.Precision)).IsInteger() {
+		return ErrInvalidUnits(DefaultCodespace, fmt.Sprintf("units %s have more than %d decimal places", units, m.Precision))
+	}
+	return nil
+}
+
+// CheckAttributes checks that the attributes of an issuance provide a non-empty value for every attribute required
+// by the credit class
+func (m CreditClassMetadata) CheckAttributes(attributes []CreditAttribute) error {
+	values := make(map[string]string, len(attributes))
+	for _, attr := range attributes {
+		values[attr.Key] = attr.Value
+	}
+	for _, key := range m.RequiredAttributes {
+		if len(strings.TrimSpace(values[key])) == 0 {
+			return ErrInvalidCreditAttributes(DefaultCodespace, fmt.Sprintf("missing required attribute %s", key))
+		}
+	}
+	return nil
+}
+
+// getCreditClassAsDesigner loads a credit class and checks that designer is its current designer. Voucher classes
+// can only be administered on their origin chain
+func (k Keeper) getCreditClassAsDesigner(ctx sdk.Context, id CreditClassID, designer sdk.AccAddress) (CreditClassMetadata, error) {
+	metadata, found := k.GetCreditClass(ctx, id)
+	if !found {
+		return metadata, ErrInvalidCreditClass(DefaultCodespace, fmt.Sprintf("credit class %s not found", id))
+	}
+	if _, found := k.GetCreditClassVoucher(ctx, id); found {
+		return metadata, ErrInvalidCreditClass(DefaultCodespace, fmt.Sprintf("credit class %s is a voucher, it can only be administered on its origin chain", id))
+	}
+	if !bytes.Equal(metadata.Designer, designer) {
+		return metadata, sdk.ErrUnauthorized("only the credit class designer can administer the credit class")
+	}
+	return metadata, nil
+}
+
+// AddCreditClassIssuer authorizes a new issuer for the credit class. Only the designer of the class can do this
+func (k Keeper) AddCreditClassIssuer(ctx sdk.Context, id CreditClassID, designer sdk.AccAddress, issuer sdk.AccAddress) error {
+	metadata, err := k.getCreditClassAsDesigner(ctx, id, designer)
+	if err != nil {
+		return err
+	}
+	if metadata.IsIssuer(issuer) {
+		return ErrInvalidIssuers(DefaultCodespace, fmt.Sprintf("%s is already an issuer", issuer))
+	}
+	metadata.Issuers = append(metadata.Issuers, issuer)
+	if err := k.creditClassBucket.Save(ctx, id, metadata); err != nil {
+		return err
+	}
+	ctx.EventManager().EmitEvent(sdk.NewEvent(
+		EventTypeAddCreditClassIssuer,
+		sdk.NewAttribute(AttributeKeyCreditClass, id.String()),
+		sdk.NewAttribute(AttributeKeyIssuer, issuer.String()),
+	))
+	return nil
+}
+
+// RemoveCreditClassIssuer revokes the authorization of an issuer of the credit class. Credits which were already
+// issued by the issuer are not affected. Only the designer of the class can do this and the last issuer can't be
+// removed, the class should be deprecated instead
+func (k Keeper) RemoveCreditClassIssuer(ctx sdk.Context, id CreditClassID, designer sdk.AccAddress, issuer sdk.AccAddress) error {
+	metadata, err := k.getCreditClassAsDesigner(ctx, id, designer)
+	if err != nil {
+		return err
+	}
+	i := metadata.issuerIndex(issuer)
+	if i < 0 {
+		return ErrInvalidIssuers(DefaultCodespace, fmt.Sprintf("%s is not an issuer", issuer))
+	}
+	if len(metadata.Issuers) == 1 {
+		return ErrInvalidIssuers(DefaultCodespace, "credit class must have at least one issuer")
+	}
+	metadata.Issuers = append(metadata.Issuers[:i], metadata.Issuers[i+1:]...)
+	if err := k.creditClassBucket.Save(ctx, id, metadata); err != nil {
+		return err
+	}
+	ctx.EventManager().EmitEvent(sdk.NewEvent(
+		EventTypeRemoveCreditClassIssuer,
+		sdk.NewAttribute(AttributeKeyCreditClass, id.String()),
+		sdk.NewAttribute(AttributeKeyIssuer, issuer.String()),
+	))
+	return nil
+}
+
+// AddCreditClassVerifier registers a new verifier for the credit class. Only the designer of the class can do this
+func (k Keeper) AddCreditClassVerifier(ctx sdk.Context, id CreditClassID, designer sdk.AccAddress, verifier sdk.AccAddress) error {
+	metadata, err := k.getCreditClassAsDesigner(ctx, id, designer)
+	if err != nil {
+		return err
+	}
+	if metadata.IsVerifier(verifier) {
+		return ErrInvalidVerifiers(DefaultCodespace, fmt.Sprintf("%s is already a verifier", verifier))
+	}
+	metadata.Verifiers = append(metadata.Verifiers, verifier)
+	if err := k.creditClassBucket.Save(ctx, id, metadata); err != nil {
+		return err
+	}
+	ctx.EventManager().EmitEvent(sdk.NewEvent(
+		EventTypeAddCreditClassVerifier,
+		sdk.NewAttribute(AttributeKeyCreditClass, id.String()),
+		sdk.NewAttribute(AttributeKeyVerifier, verifier.String()),
+	))
+	return nil
+}
+
+// RemoveCreditClassVerifier unregisters a verifier of the credit class, its attestations no longer count for new
+// issuances. Only the designer of the class can do this and the class must keep at least as many verifiers as the
+// attestations it requires
+func (k Keeper) RemoveCreditClassVerifier(ctx sdk.Context, id CreditClassID, designer sdk.AccAddress, verifier sdk.AccAddress) error {
+	metadata, err := k.getCreditClassAsDesigner(ctx, id, designer)
+	if err != nil {
+		return err
+	}
+	i := metadata.verifierIndex(verifier)
+	if i < 0 {
+		return ErrInvalidVerifiers(DefaultCodespace, fmt.Sprintf("%s is not a verifier", verifier))
+	}
+	if len(metadata.Verifiers) <= int(metadata.RequiredAttestations) {
+		return ErrInvalidVerifiers(DefaultCodespace, "credit class would have fewer verifiers than the attestations it requires")
+	}
+	metadata.Verifiers = append(metadata.Verifiers[:i], metadata.Verifiers[i+1:]...)
+	if err := k.creditClassBucket.Save(ctx, id, metadata); err != nil {
+		return err
+	}
+	ctx.EventManager().EmitEvent(sdk.NewEvent(
+		EventTypeRemoveCreditClassVerifier,
+		sdk.NewAttribute(AttributeKeyCreditClass, id.String()),
+		sdk.NewAttribute(AttributeKeyVerifier, verifier.String()),
+	))
+	return nil
+}
+
+// TransferCreditClassDesigner hands over the designer rights of the credit class to a new designer
+func (k Keeper) TransferCreditClassDesigner(ctx sdk.Context, id CreditClassID, designer sdk.AccAddress, newDesigner sdk.AccAddress) error {
+	metadata, err := k.getCreditClassAsDesigner(ctx, id, designer)
+	if err != nil {
+		return err
+	}
+	metadata.Designer = newDesigner
+	if err := k.creditClassBucket.Save(ctx, id, metadata); err != nil {
+		return err
+	}
+	ctx.EventManager().EmitEvent(sdk.NewEvent(
+		EventTypeTransferCreditClassDesigner,
+		sdk.NewAttribute(AttributeKeyCreditClass, id.String()),
+		sdk.NewAttribute(AttributeKeyDesigner, designer.String()),
+		sdk.NewAttribute(AttributeKeyNewDesigner, newDesigner.String()),
+	))
+	return nil
+}
+
+// DeprecateCreditClass permanently blocks the issuance of new credits of the credit class. Existing credits of the
+// class can still be sent and burned
+func (k Keeper) DeprecateCreditClass(ctx sdk.Context, id CreditClassID, designer sdk.AccAddress) error {
+	metadata, err := k.getCreditClassAsDesigner(ctx, id, designer)
+	if err != nil {
+		return err
+	}
+	if metadata.Deprecated {
+		return ErrCreditClassDeprecated(DefaultCodespace, id)
+	}
+	metadata.Deprecated = true
+	if err := k.creditClassBucket.Save(ctx, id, metadata); err != nil {
+		return err
+	}
+	ctx.EventManager().EmitEvent(sdk.NewEvent(
+		EventTypeDeprecateCreditClass,
+		sdk.NewAttribute(AttributeKeyCreditClass, id.String()),
+	))
+	return nil
+}
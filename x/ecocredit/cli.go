@@ -1,17 +1,63 @@
 package ecocredit
 
 import (
+	"encoding/csv"
+	"encoding/hex"
+	"fmt"
 	"github.com/cosmos/cosmos-sdk/client"
 	"github.com/cosmos/cosmos-sdk/client/context"
 	"github.com/cosmos/cosmos-sdk/codec"
 	sdk "github.com/cosmos/cosmos-sdk/types"
 	"github.com/cosmos/cosmos-sdk/x/auth"
 	"github.com/cosmos/cosmos-sdk/x/auth/client/utils"
+	govtypes "github.com/cosmos/cosmos-sdk/x/gov/types"
+	"github.com/cosmos/gaia/geo"
 	"github.com/spf13/cobra"
+	"io"
+	"io/ioutil"
+	"os"
 	"strings"
 	"time"
 )
 
+// dateLayout is the layout of the start and end dates accepted by the CLI
+const dateLayout = "2006-01-02T15:04:05-0700"
+
+// parseGeoPolygon parses a GeoJSON or WKT polygon and returns its canonical binary encoding
+func parseGeoPolygon(s string) ([]byte, error) {
+	polygon, err := geo.Parse(s)
+	if err != nil {
+		return nil, err
+	}
+	return polygon.Marshal()
+}
+
+// parseCreditAttributes parses credit attributes of the form key=value
+func parseCreditAttributes(attributes []string) ([]CreditAttribute, error) {
+	var attrs []CreditAttribute
+	for _, attr := range attributes {
+		kv := strings.SplitN(attr, "=", 2)
+		if len(kv) != 2 {
+			return nil, fmt.Errorf("attribute %s is not of the form key=value", attr)
+		}
+		attrs = append(attrs, CreditAttribute{Key: kv[0], Value: kv[1]})
+	}
+	return attrs, nil
+}
+
+// parseDatasets decodes the hex encoded SHA-256 hashes of anchored datasets
+func parseDatasets(datasets []string) ([][]byte, error) {
+	var hashes [][]byte
+	for _, dataset := range datasets {
+		hash, err := hex.DecodeString(dataset)
+		if err != nil {
+			return nil, err
+		}
+		hashes = append(hashes, hash)
+	}
+	return hashes, nil
+}
+
 func GetTxCmd(storeKey string, cdc *codec.Codec) *cobra.Command {
 	txCmd := &cobra.Command{
 		Use:   ModuleName,
@@ -21,16 +67,56 @@ func GetTxCmd(storeKey string, cdc *codec.Codec) *cobra.Command {
 	txCmd.AddCommand(client.PostCommands(
 		GetCmdCreateCreditClass(cdc),
 		GetCmdIssueCredit(cdc),
+		GetCmdIssueCreditBatch(cdc),
+		GetCmdMultiSendCredit(cdc),
+		GetCmdApproveCredit(cdc),
+		GetCmdRevokeCredit(cdc),
+		GetCmdSendCreditFrom(cdc),
+		GetCmdBurnCredit(cdc),
+		GetCmdCreateSellOrder(cdc),
+		GetCmdUpdateSellOrder(cdc),
+		GetCmdCancelSellOrder(cdc),
+		GetCmdBuyCredit(cdc),
+		GetCmdCreateAuction(cdc),
+		GetCmdPlaceBid(cdc),
+		GetCmdWrapCredit(cdc),
+		GetCmdUnwrapCredit(cdc),
+		GetCmdTransferCredit(cdc),
+		GetCmdReverseCredit(cdc),
+		GetCmdSplitCredit(cdc),
+		GetCmdMergeCredits(cdc),
+		GetCmdAnchorData(cdc),
+		GetCmdAttestData(cdc),
+		GetCmdPostBond(cdc),
+		GetCmdUnbondBond(cdc),
+		GetCmdSlashBond(cdc),
+		GetCmdSubmitSlashBondProposal(cdc),
+		GetCmdAddCreditClassIssuer(cdc),
+		GetCmdRemoveCreditClassIssuer(cdc),
+		GetCmdAddCreditClassVerifier(cdc),
+		GetCmdRemoveCreditClassVerifier(cdc),
+		GetCmdTransferCreditClassDesigner(cdc),
+		GetCmdDeprecateCreditClass(cdc),
 	)...)
 
 	return txCmd
 }
 
 func GetCmdCreateCreditClass(cdc *codec.Codec) *cobra.Command {
+	var (
+		metadata           CreditClassMetadata
+		methodologyHash    string
+		requiredAttributes string
+		bufferRate         string
+		verifiers          string
+		issuerBond         string
+	)
 	cmd := &cobra.Command{
 		Use:   "create-class [name] [issuers]",
 		Args:  cobra.ExactArgs(2),
 		Short: "create a new credit class",
+		Long: "Create a new credit class. The credit type and unit of measure are required, the methodology hash " +
+			"is the hex encoded SHA-256 hash of the methodology document.",
 		RunE: func(cmd *cobra.Command, args []string) error {
 			txBldr := auth.NewTxBuilderFromCLI().WithTxEncoder(utils.GetTxEncoder(cdc))
 			cliCtx := context.NewCLIContext().WithCodec(cdc)
@@ -45,7 +131,40 @@ func GetCmdCreateCreditClass(cdc *codec.Codec) *cobra.Command {
 				issuers = append(issuers, addr)
 			}
 
-			msg := MsgCreateCreditClass{CreditClassMetadata{Designer: from, Name: args[0], Issuers: issuers}}
+			hash, err := hex.DecodeString(methodologyHash)
+			if err != nil {
+				return err
+			}
+
+			metadata.Designer = from
+			metadata.Name = args[0]
+			metadata.Issuers = issuers
+			metadata.MethodologyHash = hash
+			if len(requiredAttributes) != 0 {
+				metadata.RequiredAttributes = strings.Split(requiredAttributes, ",")
+			}
+			if len(bufferRate) != 0 {
+				metadata.BufferRate, err = sdk.NewDecFromStr(bufferRate)
+				if err != nil {
+					return err
+				}
+			}
+			if len(verifiers) != 0 {
+				for _, bech := range strings.Split(verifiers, ",") {
+					addr, err := sdk.AccAddressFromBech32(bech)
+					if err != nil {
+						return err
+					}
+					metadata.Verifiers = append(metadata.Verifiers, addr)
+				}
+			}
+			if len(issuerBond) != 0 {
+				metadata.IssuerBond, err = sdk.ParseCoins(issuerBond)
+				if err != nil {
+					return err
+				}
+			}
+			msg := MsgCreateCreditClass{metadata}
 			if err := msg.ValidateBasic(); err != nil {
 				return err
 			}
@@ -53,14 +172,28 @@ func GetCmdCreateCreditClass(cdc *codec.Codec) *cobra.Command {
 			return utils.GenerateOrBroadcastMsgs(cliCtx, txBldr, []sdk.Msg{msg})
 		},
 	}
+	cmd.Flags().StringVar(&metadata.CreditType, "credit-type", "", "the kind of ecosystem service, e.g. carbon or biodiversity")
+	cmd.Flags().StringVar(&metadata.UnitOfMeasure, "unit", "", "what one unit of a credit measures, e.g. tCO2e")
+	cmd.Flags().StringVar(&metadata.MethodologyURI, "methodology-uri", "", "the URI of the methodology document")
+	cmd.Flags().StringVar(&methodologyHash, "methodology-hash", "", "the hex encoded SHA-256 hash of the methodology document")
+	cmd.Flags().Uint32Var(&metadata.Precision, "precision", 6, "the maximum number of decimal places of credit units")
+	cmd.Flags().StringVar(&requiredAttributes, "required-attributes", "", "comma separated keys of attributes every issuance must provide")
+	cmd.Flags().StringVar(&bufferRate, "buffer-rate", "", "the fraction of liquid units withheld in the buffer pool at issuance, e.g. 0.2")
+	cmd.Flags().StringVar(&verifiers, "verifiers", "", "comma separated addresses of the verifiers attesting MRV datasets")
+	cmd.Flags().Uint32Var(&metadata.RequiredAttestations, "required-attestations", 0, "the number of verifiers which must attest each dataset of an issuance")
+	cmd.Flags().StringVar(&issuerBond, "issuer-bond", "", "the minimum bond each issuer must post to issue credits, e.g. 1000uatom")
+	cmd.Flags().DurationVar(&metadata.BondUnbondingPeriod, "bond-unbonding-period", 0, "how long withdrawn bonds stay slashable before they are returned, e.g. 504h")
+	cmd.Flags().DurationVar(&metadata.ValidityPeriod, "validity-period", 0, "how long after their end date credits stay valid, e.g. 43800h, credits never expire if unset")
 	return cmd
 }
 
 func GetCmdIssueCredit(cdc *codec.Codec) *cobra.Command {
+	var attributes, datasets []string
 	cmd := &cobra.Command{
 		Use:   "issue [credit-class] [geo-polygon] [start-date] [end-date] [units] [holder]",
 		Args:  cobra.ExactArgs(6),
 		Short: "issue a new ecosystem service credit",
+		Long:  "Issue a new ecosystem service credit. The geo-polygon can be given either as a GeoJSON Polygon or as WKT.",
 		RunE: func(cmd *cobra.Command, args []string) error {
 			txBldr := auth.NewTxBuilderFromCLI().WithTxEncoder(utils.GetTxEncoder(cdc))
 			cliCtx := context.NewCLIContext().WithCodec(cdc)
@@ -71,12 +204,12 @@ func GetCmdIssueCredit(cdc *codec.Codec) *cobra.Command {
 				return err
 			}
 
-			startDate, err := time.Parse("2006-01-02T15:04:05-0700", args[2])
+			startDate, err := time.Parse(dateLayout, args[2])
 			if err != nil {
 				return err
 			}
 
-			endDate, err := time.Parse("2006-01-02T15:04:05-0700", args[3])
+			endDate, err := time.Parse(dateLayout, args[3])
 			if err != nil {
 				return err
 			}
@@ -91,13 +224,30 @@ func GetCmdIssueCredit(cdc *codec.Codec) *cobra.Command {
 				return err
 			}
 
+			polygon, err := parseGeoPolygon(args[1])
+			if err != nil {
+				return err
+			}
+
+			attrs, err := parseCreditAttributes(attributes)
+			if err != nil {
+				return err
+			}
+
+			hashes, err := parseDatasets(datasets)
+			if err != nil {
+				return err
+			}
+
 			msg := MsgIssueCredit{CreditMetadata{
 				Issuer:      from,
 				CreditClass: creditClass,
-				GeoPolygon:  []byte(args[1]),
+				GeoPolygon:  polygon,
 				StartDate:   startDate,
 				EndDate:     endDate,
 				LiquidUnits: units,
+				Attributes:  attrs,
+				Datasets:    hashes,
 			},
 				holder,
 			}
@@ -105,8 +255,1824 @@ func GetCmdIssueCredit(cdc *codec.Codec) *cobra.Command {
 				return err
 			}
 
-			return utils.GenerateOrBroadcastMsgs(cliCtx, txBldr, []sdk.Msg{})
+			return utils.GenerateOrBroadcastMsgs(cliCtx, txBldr, []sdk.Msg{msg})
+		},
+	}
+	cmd.Flags().StringArrayVar(&attributes, "attribute", nil, "an attribute of the issuance of the form key=value, can be repeated")
+	cmd.Flags().StringArrayVar(&datasets, "dataset", nil, "the hex encoded hash of an anchored MRV dataset backing the issuance, can be repeated")
+	return cmd
+}
+
+func GetCmdIssueCreditBatch(cdc *codec.Codec) *cobra.Command {
+	var attributes, datasets []string
+	cmd := &cobra.Command{
+		Use:   "issue-batch [credit-class] [geo-polygon] [start-date] [end-date] [issuances-file]",
+		Args:  cobra.ExactArgs(5),
+		Short: "issue a new ecosystem service credit to many holders",
+		Long: `Issue a new ecosystem service credit distributed over many holders. The issuances file is a JSON array of
+issuances, units in retired_units are issued directly into retired state:
+
+[{"holder": "cosmos1...", "liquid_units": "10", "retired_units": "0"},
+ {"holder": "cosmos1...", "liquid_units": "0", "retired_units": "5", "retirement_info": {"beneficiary": "ACME"}}]`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			txBldr := auth.NewTxBuilderFromCLI().WithTxEncoder(utils.GetTxEncoder(cdc))
+			cliCtx := context.NewCLIContext().WithCodec(cdc)
+			from := cliCtx.GetFromAddress()
+
+			creditClass, err := CreditClassFromBech32(args[0])
+			if err != nil {
+				return err
+			}
+
+			startDate, err := time.Parse(dateLayout, args[2])
+			if err != nil {
+				return err
+			}
+
+			endDate, err := time.Parse(dateLayout, args[3])
+			if err != nil {
+				return err
+			}
+
+			polygon, err := parseGeoPolygon(args[1])
+			if err != nil {
+				return err
+			}
+
+			bz, err := ioutil.ReadFile(args[4])
+			if err != nil {
+				return err
+			}
+			var issuances []CreditIssuance
+			err = cdc.UnmarshalJSON(bz, &issuances)
+			if err != nil {
+				return err
+			}
+
+			attrs, err := parseCreditAttributes(attributes)
+			if err != nil {
+				return err
+			}
+
+			hashes, err := parseDatasets(datasets)
+			if err != nil {
+				return err
+			}
+
+			msg := MsgIssueCreditBatch{CreditMetadata{
+				Issuer:      from,
+				CreditClass: creditClass,
+				GeoPolygon:  polygon,
+				StartDate:   startDate,
+				EndDate:     endDate,
+				Attributes:  attrs,
+				Datasets:    hashes,
+			},
+				issuances,
+			}
+			if err := msg.ValidateBasic(); err != nil {
+				return err
+			}
+
+			return utils.GenerateOrBroadcastMsgs(cliCtx, txBldr, []sdk.Msg{msg})
+		},
+	}
+	cmd.Flags().StringArrayVar(&attributes, "attribute", nil, "an attribute of the issuance of the form key=value, can be repeated")
+	cmd.Flags().StringArrayVar(&datasets, "dataset", nil, "the hex encoded hash of an anchored MRV dataset backing the issuance, can be repeated")
+	return cmd
+}
+
+func GetCmdMultiSendCredit(cdc *codec.Codec) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "multi-send [recipients-file]",
+		Args:  cobra.ExactArgs(1),
+		Short: "send units of credits to many recipients at once, either all or none are sent",
+		Long: `Send units of credits to many recipients at once, either all or none are sent. The recipients file is a
+CSV file with the columns credit (bech32 encoded), recipient (bech32 address) and units, with an optional header row:
+
+credit,recipient,units
+ecocrd1...,cosmos1...,12.5`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			txBldr := auth.NewTxBuilderFromCLI().WithTxEncoder(utils.GetTxEncoder(cdc))
+			cliCtx := context.NewCLIContext().WithCodec(cdc)
+			from := cliCtx.GetFromAddress()
+
+			f, err := os.Open(args[0])
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+			outputs, err := parseCreditOutputs(f)
+			if err != nil {
+				return err
+			}
+
+			msg := MsgMultiSendCredit{From: from, Outputs: outputs}
+			if err := msg.ValidateBasic(); err != nil {
+				return err
+			}
+
+			return utils.GenerateOrBroadcastMsgs(cliCtx, txBldr, []sdk.Msg{msg})
+		},
+	}
+	return cmd
+}
+
+// parseCreditOutputs reads credit outputs from CSV rows of credit, recipient and units, skipping a header row
+func parseCreditOutputs(r io.Reader) ([]CreditOutput, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = 3
+	reader.TrimLeadingSpace = true
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(records) != 0 && records[0][0] == "credit" {
+		records = records[1:]
+	}
+	outputs := make([]CreditOutput, 0, len(records))
+	for i, record := range records {
+		credit, err := CreditFromBech32(record[0])
+		if err != nil {
+			return nil, fmt.Errorf("row %d: invalid credit: %s", i+1, err)
+		}
+		to, err := sdk.AccAddressFromBech32(record[1])
+		if err != nil {
+			return nil, fmt.Errorf("row %d: invalid recipient: %s", i+1, err)
+		}
+		units, err := sdk.NewDecFromStr(record[2])
+		if err != nil {
+			return nil, fmt.Errorf("row %d: invalid units: %s", i+1, err)
+		}
+		outputs = append(outputs, CreditOutput{Credit: credit, To: to, Units: units})
+	}
+	return outputs, nil
+}
+
+// parseOptionalCredit parses a bech32 encoded credit ID, where "all" stands for all credits
+func parseOptionalCredit(s string) (CreditID, error) {
+	if s == "all" {
+		return nil, nil
+	}
+	return CreditFromBech32(s)
+}
+
+func GetCmdApproveCredit(cdc *codec.Codec) *cobra.Command {
+	var expiration string
+	cmd := &cobra.Command{
+		Use:   "approve [spender] [credit] [units]",
+		Args:  cobra.ExactArgs(3),
+		Short: `allow a spender to send units of a credit, or of all credits when credit is "all", on your behalf`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			txBldr := auth.NewTxBuilderFromCLI().WithTxEncoder(utils.GetTxEncoder(cdc))
+			cliCtx := context.NewCLIContext().WithCodec(cdc)
+			from := cliCtx.GetFromAddress()
+
+			spender, err := sdk.AccAddressFromBech32(args[0])
+			if err != nil {
+				return err
+			}
+
+			credit, err := parseOptionalCredit(args[1])
+			if err != nil {
+				return err
+			}
+
+			units, err := sdk.NewDecFromStr(args[2])
+			if err != nil {
+				return err
+			}
+
+			var expires time.Time
+			if len(expiration) != 0 {
+				expires, err = time.Parse(dateLayout, expiration)
+				if err != nil {
+					return err
+				}
+			}
+
+			msg := MsgApproveCredit{Holder: from, Spender: spender, Credit: credit, Units: units, Expiration: expires}
+			if err := msg.ValidateBasic(); err != nil {
+				return err
+			}
+
+			return utils.GenerateOrBroadcastMsgs(cliCtx, txBldr, []sdk.Msg{msg})
+		},
+	}
+	cmd.Flags().StringVar(&expiration, "expiration", "", "the time the allowance expires, it never expires if omitted")
+	return cmd
+}
+
+func GetCmdRevokeCredit(cdc *codec.Codec) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "revoke [spender] [credit]",
+		Args:  cobra.ExactArgs(2),
+		Short: `revoke the allowance of a spender for a credit, or for all credits when credit is "all"`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			txBldr := auth.NewTxBuilderFromCLI().WithTxEncoder(utils.GetTxEncoder(cdc))
+			cliCtx := context.NewCLIContext().WithCodec(cdc)
+			from := cliCtx.GetFromAddress()
+
+			spender, err := sdk.AccAddressFromBech32(args[0])
+			if err != nil {
+				return err
+			}
+
+			credit, err := parseOptionalCredit(args[1])
+			if err != nil {
+				return err
+			}
+
+			msg := MsgRevokeCredit{Holder: from, Spender: spender, Credit: credit}
+			if err := msg.ValidateBasic(); err != nil {
+				return err
+			}
+
+			return utils.GenerateOrBroadcastMsgs(cliCtx, txBldr, []sdk.Msg{msg})
+		},
+	}
+	return cmd
+}
+
+func GetCmdSendCreditFrom(cdc *codec.Codec) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "send-from [holder] [credit] [recipient] [units]",
+		Args:  cobra.ExactArgs(4),
+		Short: "send units of a credit on behalf of a holder who approved you",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			txBldr := auth.NewTxBuilderFromCLI().WithTxEncoder(utils.GetTxEncoder(cdc))
+			cliCtx := context.NewCLIContext().WithCodec(cdc)
+			spender := cliCtx.GetFromAddress()
+
+			holder, err := sdk.AccAddressFromBech32(args[0])
+			if err != nil {
+				return err
+			}
+
+			credit, err := CreditFromBech32(args[1])
+			if err != nil {
+				return err
+			}
+
+			to, err := sdk.AccAddressFromBech32(args[2])
+			if err != nil {
+				return err
+			}
+
+			units, err := sdk.NewDecFromStr(args[3])
+			if err != nil {
+				return err
+			}
+
+			msg := MsgSendCreditFrom{Spender: spender, Credit: credit, From: holder, To: to, Units: units}
+			if err := msg.ValidateBasic(); err != nil {
+				return err
+			}
+
+			return utils.GenerateOrBroadcastMsgs(cliCtx, txBldr, []sdk.Msg{msg})
+		},
+	}
+	return cmd
+}
+
+func GetCmdBurnCredit(cdc *codec.Codec) *cobra.Command {
+	var info RetirementInfo
+	cmd := &cobra.Command{
+		Use:   "burn [credit] [units]",
+		Args:  cobra.ExactArgs(2),
+		Short: "burn (retire) units of a credit and create a retirement certificate",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			txBldr := auth.NewTxBuilderFromCLI().WithTxEncoder(utils.GetTxEncoder(cdc))
+			cliCtx := context.NewCLIContext().WithCodec(cdc)
+			from := cliCtx.GetFromAddress()
+
+			credit, err := CreditFromBech32(args[0])
+			if err != nil {
+				return err
+			}
+
+			units, err := sdk.NewDecFromStr(args[1])
+			if err != nil {
+				return err
+			}
+
+			msg := MsgBurnCredit{Credit: credit, Holder: from, Units: units, RetirementInfo: info}
+			if err := msg.ValidateBasic(); err != nil {
+				return err
+			}
+
+			return utils.GenerateOrBroadcastMsgs(cliCtx, txBldr, []sdk.Msg{msg})
+		},
+	}
+	cmd.Flags().StringVar(&info.Beneficiary, "beneficiary", "", "the entity on whose behalf the units are retired")
+	cmd.Flags().StringVar(&info.Jurisdiction, "jurisdiction", "", "the jurisdiction in which the offset is claimed")
+	cmd.Flags().StringVar(&info.Reason, "reason", "", "the reason the units are retired")
+	return cmd
+}
+
+func GetCmdCreateSellOrder(cdc *codec.Codec) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "sell [credit] [units] [price]",
+		Args:  cobra.ExactArgs(3),
+		Short: "escrow units of a credit and offer them for sale at a price per unit, e.g. 10uatom",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			txBldr := auth.NewTxBuilderFromCLI().WithTxEncoder(utils.GetTxEncoder(cdc))
+			cliCtx := context.NewCLIContext().WithCodec(cdc)
+			seller := cliCtx.GetFromAddress()
+
+			credit, err := CreditFromBech32(args[0])
+			if err != nil {
+				return err
+			}
+
+			units, err := sdk.NewDecFromStr(args[1])
+			if err != nil {
+				return err
+			}
+
+			price, err := sdk.ParseCoins(args[2])
+			if err != nil {
+				return err
+			}
+
+			msg := MsgCreateSellOrder{Seller: seller, Credit: credit, Units: units, Price: price}
+			if err := msg.ValidateBasic(); err != nil {
+				return err
+			}
+
+			return utils.GenerateOrBroadcastMsgs(cliCtx, txBldr, []sdk.Msg{msg})
+		},
+	}
+	return cmd
+}
+
+func GetCmdUpdateSellOrder(cdc *codec.Codec) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "update-sell-order [order] [units] [price]",
+		Args:  cobra.ExactArgs(3),
+		Short: "change the units offered by a sell order and its price per unit",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			txBldr := auth.NewTxBuilderFromCLI().WithTxEncoder(utils.GetTxEncoder(cdc))
+			cliCtx := context.NewCLIContext().WithCodec(cdc)
+			seller := cliCtx.GetFromAddress()
+
+			order, err := SellOrderFromBech32(args[0])
+			if err != nil {
+				return err
+			}
+
+			units, err := sdk.NewDecFromStr(args[1])
+			if err != nil {
+				return err
+			}
+
+			price, err := sdk.ParseCoins(args[2])
+			if err != nil {
+				return err
+			}
+
+			msg := MsgUpdateSellOrder{Seller: seller, Order: order, Units: units, Price: price}
+			if err := msg.ValidateBasic(); err != nil {
+				return err
+			}
+
+			return utils.GenerateOrBroadcastMsgs(cliCtx, txBldr, []sdk.Msg{msg})
+		},
+	}
+	return cmd
+}
+
+func GetCmdCancelSellOrder(cdc *codec.Codec) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "cancel-sell-order [order]",
+		Args:  cobra.ExactArgs(1),
+		Short: "close a sell order and get its remaining units back",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			txBldr := auth.NewTxBuilderFromCLI().WithTxEncoder(utils.GetTxEncoder(cdc))
+			cliCtx := context.NewCLIContext().WithCodec(cdc)
+			seller := cliCtx.GetFromAddress()
+
+			order, err := SellOrderFromBech32(args[0])
+			if err != nil {
+				return err
+			}
+
+			msg := MsgCancelSellOrder{Seller: seller, Order: order}
+			if err := msg.ValidateBasic(); err != nil {
+				return err
+			}
+
+			return utils.GenerateOrBroadcastMsgs(cliCtx, txBldr, []sdk.Msg{msg})
+		},
+	}
+	return cmd
+}
+
+func GetCmdBuyCredit(cdc *codec.Codec) *cobra.Command {
+	var retire bool
+	var info RetirementInfo
+	cmd := &cobra.Command{
+		Use:   "buy [order] [units] [max-price]",
+		Args:  cobra.ExactArgs(3),
+		Short: "buy units of a sell order paying at most max-price per unit",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			txBldr := auth.NewTxBuilderFromCLI().WithTxEncoder(utils.GetTxEncoder(cdc))
+			cliCtx := context.NewCLIContext().WithCodec(cdc)
+			buyer := cliCtx.GetFromAddress()
+
+			order, err := SellOrderFromBech32(args[0])
+			if err != nil {
+				return err
+			}
+
+			units, err := sdk.NewDecFromStr(args[1])
+			if err != nil {
+				return err
+			}
+
+			maxPrice, err := sdk.ParseCoins(args[2])
+			if err != nil {
+				return err
+			}
+
+			msg := MsgBuyCredit{Buyer: buyer, Order: order, Units: units, MaxPrice: maxPrice, Retire: retire, RetirementInfo: info}
+			if err := msg.ValidateBasic(); err != nil {
+				return err
+			}
+
+			return utils.GenerateOrBroadcastMsgs(cliCtx, txBldr, []sdk.Msg{msg})
+		},
+	}
+	cmd.Flags().BoolVar(&retire, "retire", false, "retire the bought units right away")
+	cmd.Flags().StringVar(&info.Beneficiary, "beneficiary", "", "the entity on whose behalf the units are retired")
+	cmd.Flags().StringVar(&info.Jurisdiction, "jurisdiction", "", "the jurisdiction in which the offset is claimed")
+	cmd.Flags().StringVar(&info.Reason, "reason", "", "the reason the units are retired")
+	return cmd
+}
+
+func GetCmdCreateAuction(cdc *codec.Codec) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "auction [credit] [units] [min-price] [end-time]",
+		Args:  cobra.ExactArgs(4),
+		Short: "escrow units of a credit and auction them off at the end time, e.g. 10uatom 2020-01-01T00:00:00+0000",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			txBldr := auth.NewTxBuilderFromCLI().WithTxEncoder(utils.GetTxEncoder(cdc))
+			cliCtx := context.NewCLIContext().WithCodec(cdc)
+			seller := cliCtx.GetFromAddress()
+
+			credit, err := CreditFromBech32(args[0])
+			if err != nil {
+				return err
+			}
+
+			units, err := sdk.NewDecFromStr(args[1])
+			if err != nil {
+				return err
+			}
+
+			minPrice, err := sdk.ParseCoin(args[2])
+			if err != nil {
+				return err
+			}
+
+			endTime, err := time.Parse(dateLayout, args[3])
+			if err != nil {
+				return err
+			}
+
+			msg := MsgCreateAuction{Seller: seller, Credit: credit, Units: units, MinPrice: minPrice, EndTime: endTime}
+			if err := msg.ValidateBasic(); err != nil {
+				return err
+			}
+
+			return utils.GenerateOrBroadcastMsgs(cliCtx, txBldr, []sdk.Msg{msg})
+		},
+	}
+	return cmd
+}
+
+func GetCmdPlaceBid(cdc *codec.Codec) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "bid [auction] [units] [price]",
+		Args:  cobra.ExactArgs(3),
+		Short: "bid for units of an auction at a price per unit, locking the coins to pay for all of them",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			txBldr := auth.NewTxBuilderFromCLI().WithTxEncoder(utils.GetTxEncoder(cdc))
+			cliCtx := context.NewCLIContext().WithCodec(cdc)
+			bidder := cliCtx.GetFromAddress()
+
+			auction, err := AuctionFromBech32(args[0])
+			if err != nil {
+				return err
+			}
+
+			units, err := sdk.NewDecFromStr(args[1])
+			if err != nil {
+				return err
+			}
+
+			price, err := sdk.ParseCoin(args[2])
+			if err != nil {
+				return err
+			}
+
+			msg := MsgPlaceBid{Bidder: bidder, Auction: auction, Units: units, Price: price}
+			if err := msg.ValidateBasic(); err != nil {
+				return err
+			}
+
+			return utils.GenerateOrBroadcastMsgs(cliCtx, txBldr, []sdk.Msg{msg})
+		},
+	}
+	return cmd
+}
+
+func GetCmdWrapCredit(cdc *codec.Codec) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "wrap [credit] [units]",
+		Args:  cobra.ExactArgs(2),
+		Short: "lock units of a credit and receive them as coins of the eco/<credit> denom",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			txBldr := auth.NewTxBuilderFromCLI().WithTxEncoder(utils.GetTxEncoder(cdc))
+			cliCtx := context.NewCLIContext().WithCodec(cdc)
+			holder := cliCtx.GetFromAddress()
+
+			credit, err := CreditFromBech32(args[0])
+			if err != nil {
+				return err
+			}
+
+			units, err := sdk.NewDecFromStr(args[1])
+			if err != nil {
+				return err
+			}
+
+			msg := MsgWrapCredit{Holder: holder, Credit: credit, Units: units}
+			if err := msg.ValidateBasic(); err != nil {
+				return err
+			}
+
+			return utils.GenerateOrBroadcastMsgs(cliCtx, txBldr, []sdk.Msg{msg})
+		},
+	}
+	return cmd
+}
+
+func GetCmdUnwrapCredit(cdc *codec.Codec) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "unwrap [credit] [units]",
+		Args:  cobra.ExactArgs(2),
+		Short: "burn wrapped coins of a credit and get the locked units back",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			txBldr := auth.NewTxBuilderFromCLI().WithTxEncoder(utils.GetTxEncoder(cdc))
+			cliCtx := context.NewCLIContext().WithCodec(cdc)
+			holder := cliCtx.GetFromAddress()
+
+			credit, err := CreditFromBech32(args[0])
+			if err != nil {
+				return err
+			}
+
+			units, err := sdk.NewDecFromStr(args[1])
+			if err != nil {
+				return err
+			}
+
+			msg := MsgUnwrapCredit{Holder: holder, Credit: credit, Units: units}
+			if err := msg.ValidateBasic(); err != nil {
+				return err
+			}
+
+			return utils.GenerateOrBroadcastMsgs(cliCtx, txBldr, []sdk.Msg{msg})
+		},
+	}
+	return cmd
+}
+
+func GetCmdTransferCredit(cdc *codec.Codec) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "ibc-transfer [src-channel] [receiver] [credit] [units]",
+		Args:  cobra.ExactArgs(4),
+		Short: "send units of a credit over an IBC channel of the ecocredit port to another chain",
+		Long: "Send units of a credit over an IBC channel of the ecocredit port to the receiver on the counterparty " +
+			"chain. The units are escrowed until they come back, or burned if they are vouchers which came over the " +
+			"same channel. They are refunded if the packet times out or is rejected by the counterparty chain.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			txBldr := auth.NewTxBuilderFromCLI().WithTxEncoder(utils.GetTxEncoder(cdc))
+			cliCtx := context.NewCLIContext().WithCodec(cdc)
+			sender := cliCtx.GetFromAddress()
+
+			receiver, err := sdk.AccAddressFromBech32(args[1])
+			if err != nil {
+				return err
+			}
+
+			credit, err := CreditFromBech32(args[2])
+			if err != nil {
+				return err
+			}
+
+			units, err := sdk.NewDecFromStr(args[3])
+			if err != nil {
+				return err
+			}
+
+			msg := MsgTransferCredit{SourceChannel: args[0], Credit: credit, Units: units, Sender: sender, Receiver: receiver}
+			if err := msg.ValidateBasic(); err != nil {
+				return err
+			}
+
+			return utils.GenerateOrBroadcastMsgs(cliCtx, txBldr, []sdk.Msg{msg})
+		},
+	}
+	return cmd
+}
+
+func GetCmdPostBond(cdc *codec.Codec) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "post-bond [credit-class] [amount]",
+		Args:  cobra.ExactArgs(2),
+		Short: "add coins to your bond as an issuer of a credit class, e.g. 1000uatom",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			txBldr := auth.NewTxBuilderFromCLI().WithTxEncoder(utils.GetTxEncoder(cdc))
+			cliCtx := context.NewCLIContext().WithCodec(cdc)
+			from := cliCtx.GetFromAddress()
+
+			creditClass, err := CreditClassFromBech32(args[0])
+			if err != nil {
+				return err
+			}
+
+			amount, err := sdk.ParseCoins(args[1])
+			if err != nil {
+				return err
+			}
+
+			msg := MsgPostBond{Issuer: from, CreditClass: creditClass, Amount: amount}
+			if err := msg.ValidateBasic(); err != nil {
+				return err
+			}
+
+			return utils.GenerateOrBroadcastMsgs(cliCtx, txBldr, []sdk.Msg{msg})
+		},
+	}
+	return cmd
+}
+
+func GetCmdUnbondBond(cdc *codec.Codec) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "unbond [credit-class] [amount]",
+		Args:  cobra.ExactArgs(2),
+		Short: "withdraw coins from your bond for a credit class after its bond unbonding period",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			txBldr := auth.NewTxBuilderFromCLI().WithTxEncoder(utils.GetTxEncoder(cdc))
+			cliCtx := context.NewCLIContext().WithCodec(cdc)
+			from := cliCtx.GetFromAddress()
+
+			creditClass, err := CreditClassFromBech32(args[0])
+			if err != nil {
+				return err
+			}
+
+			amount, err := sdk.ParseCoins(args[1])
+			if err != nil {
+				return err
+			}
+
+			msg := MsgUnbondBond{Issuer: from, CreditClass: creditClass, Amount: amount}
+			if err := msg.ValidateBasic(); err != nil {
+				return err
+			}
+
+			return utils.GenerateOrBroadcastMsgs(cliCtx, txBldr, []sdk.Msg{msg})
+		},
+	}
+	return cmd
+}
+
+func GetCmdSlashBond(cdc *codec.Codec) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "slash-bond [credit-class] [issuer] [credit] [fraction] [reason]",
+		Args:  cobra.ExactArgs(5),
+		Short: "slash a fraction of the bond of an issuer for a fraudulent issuance of a credit as the class designer",
+		Long: "Burn a fraction, e.g. 0.5, of the bond of an issuer of the credit class and of its pending unbondings. " +
+			"The credit proving the fraud must have been issued by the issuer in the class.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			txBldr := auth.NewTxBuilderFromCLI().WithTxEncoder(utils.GetTxEncoder(cdc))
+			cliCtx := context.NewCLIContext().WithCodec(cdc)
+			from := cliCtx.GetFromAddress()
+
+			creditClass, issuer, credit, fraction, err := parseSlashArgs(args)
+			if err != nil {
+				return err
+			}
+
+			msg := MsgSlashBond{Designer: from, CreditClass: creditClass, Issuer: issuer, Credit: credit, Fraction: fraction, Reason: args[4]}
+			if err := msg.ValidateBasic(); err != nil {
+				return err
+			}
+
+			return utils.GenerateOrBroadcastMsgs(cliCtx, txBldr, []sdk.Msg{msg})
+		},
+	}
+	return cmd
+}
+
+func GetCmdSubmitSlashBondProposal(cdc *codec.Codec) *cobra.Command {
+	var title, description, deposit string
+	cmd := &cobra.Command{
+		Use:   "submit-slash-bond-proposal [credit-class] [issuer] [credit] [fraction]",
+		Args:  cobra.ExactArgs(4),
+		Short: "submit a governance proposal to slash a fraction of the bond of an issuer for a fraudulent issuance",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			txBldr := auth.NewTxBuilderFromCLI().WithTxEncoder(utils.GetTxEncoder(cdc))
+			cliCtx := context.NewCLIContext().WithCodec(cdc)
+			from := cliCtx.GetFromAddress()
+
+			creditClass, issuer, credit, fraction, err := parseSlashArgs(args)
+			if err != nil {
+				return err
+			}
+
+			amount, err := sdk.ParseCoins(deposit)
+			if err != nil {
+				return err
+			}
+
+			content := SlashBondProposal{Title: title, Description: description, CreditClass: creditClass,
+				Issuer: issuer, Credit: credit, Fraction: fraction}
+			msg := govtypes.NewMsgSubmitProposal(content, amount, from)
+			if err := msg.ValidateBasic(); err != nil {
+				return err
+			}
+
+			return utils.GenerateOrBroadcastMsgs(cliCtx, txBldr, []sdk.Msg{msg})
+		},
+	}
+	cmd.Flags().StringVar(&title, "title", "", "the title of the proposal")
+	cmd.Flags().StringVar(&description, "description", "", "the description of the proposal")
+	cmd.Flags().StringVar(&deposit, "deposit", "", "the initial deposit of the proposal, e.g. 10000uatom")
+	return cmd
+}
+
+// parseSlashArgs parses the credit class, issuer, credit and fraction arguments of the slash commands
+func parseSlashArgs(args []string) (CreditClassID, sdk.AccAddress, CreditID, sdk.Dec, error) {
+	creditClass, err := CreditClassFromBech32(args[0])
+	if err != nil {
+		return nil, nil, nil, sdk.Dec{}, err
+	}
+	issuer, err := sdk.AccAddressFromBech32(args[1])
+	if err != nil {
+		return nil, nil, nil, sdk.Dec{}, err
+	}
+	credit, err := CreditFromBech32(args[2])
+	if err != nil {
+		return nil, nil, nil, sdk.Dec{}, err
+	}
+	fraction, err := sdk.NewDecFromStr(args[3])
+	if err != nil {
+		return nil, nil, nil, sdk.Dec{}, err
+	}
+	return creditClass, issuer, credit, fraction, nil
+}
+
+func GetCmdAddCreditClassIssuer(cdc *codec.Codec) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "add-issuer [credit-class] [issuer]",
+		Args:  cobra.ExactArgs(2),
+		Short: "authorize a new issuer for a credit class, must be sent by the class designer",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			txBldr := auth.NewTxBuilderFromCLI().WithTxEncoder(utils.GetTxEncoder(cdc))
+			cliCtx := context.NewCLIContext().WithCodec(cdc)
+			from := cliCtx.GetFromAddress()
+
+			creditClass, err := CreditClassFromBech32(args[0])
+			if err != nil {
+				return err
+			}
+
+			issuer, err := sdk.AccAddressFromBech32(args[1])
+			if err != nil {
+				return err
+			}
+
+			msg := MsgAddCreditClassIssuer{CreditClass: creditClass, Designer: from, Issuer: issuer}
+			if err := msg.ValidateBasic(); err != nil {
+				return err
+			}
+
+			return utils.GenerateOrBroadcastMsgs(cliCtx, txBldr, []sdk.Msg{msg})
+		},
+	}
+	return cmd
+}
+
+func GetCmdRemoveCreditClassIssuer(cdc *codec.Codec) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "remove-issuer [credit-class] [issuer]",
+		Args:  cobra.ExactArgs(2),
+		Short: "revoke an issuer of a credit class, must be sent by the class designer",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			txBldr := auth.NewTxBuilderFromCLI().WithTxEncoder(utils.GetTxEncoder(cdc))
+			cliCtx := context.NewCLIContext().WithCodec(cdc)
+			from := cliCtx.GetFromAddress()
+
+			creditClass, err := CreditClassFromBech32(args[0])
+			if err != nil {
+				return err
+			}
+
+			issuer, err := sdk.AccAddressFromBech32(args[1])
+			if err != nil {
+				return err
+			}
+
+			msg := MsgRemoveCreditClassIssuer{CreditClass: creditClass, Designer: from, Issuer: issuer}
+			if err := msg.ValidateBasic(); err != nil {
+				return err
+			}
+
+			return utils.GenerateOrBroadcastMsgs(cliCtx, txBldr, []sdk.Msg{msg})
+		},
+	}
+	return cmd
+}
+
+func GetCmdReverseCredit(cdc *codec.Codec) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "reverse [credit] [units] [reason]",
+		Args:  cobra.ExactArgs(3),
+		Short: "cancel reversed units of a credit from the buffer pool of its class and then pro rata from its holders",
+		Long: "Cancel units of a credit whose ecosystem service was reversed, e.g. by a fire. Only the issuer of the " +
+			"credit or the designer of its class can reverse it. The reversal record lists every cancellation.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			txBldr := auth.NewTxBuilderFromCLI().WithTxEncoder(utils.GetTxEncoder(cdc))
+			cliCtx := context.NewCLIContext().WithCodec(cdc)
+			from := cliCtx.GetFromAddress()
+
+			credit, err := CreditFromBech32(args[0])
+			if err != nil {
+				return err
+			}
+
+			units, err := sdk.NewDecFromStr(args[1])
+			if err != nil {
+				return err
+			}
+
+			msg := MsgReverseCredit{Reverser: from, Credit: credit, Units: units, Reason: args[2]}
+			if err := msg.ValidateBasic(); err != nil {
+				return err
+			}
+
+			return utils.GenerateOrBroadcastMsgs(cliCtx, txBldr, []sdk.Msg{msg})
+		},
+	}
+	return cmd
+}
+
+func GetCmdSplitCredit(cdc *codec.Codec) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "split [credit] [units] [part-geo-polygon] [part-geo-polygon]...",
+		Args:  cobra.MinimumNArgs(4),
+		Short: "split units of a credit into child credits for parts of its polygon",
+		Long: "Split units of a credit into child credits, one for each part. The parts must tile the polygon of the " +
+			"credit and can be given either as GeoJSON Polygons or as WKT. The units are apportioned to the parts by " +
+			"area. Once a credit was split, it can only be split into the same parts again.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			txBldr := auth.NewTxBuilderFromCLI().WithTxEncoder(utils.GetTxEncoder(cdc))
+			cliCtx := context.NewCLIContext().WithCodec(cdc)
+			from := cliCtx.GetFromAddress()
+
+			credit, err := CreditFromBech32(args[0])
+			if err != nil {
+				return err
+			}
+
+			units, err := sdk.NewDecFromStr(args[1])
+			if err != nil {
+				return err
+			}
+
+			var parts [][]byte
+			for _, arg := range args[2:] {
+				part, err := parseGeoPolygon(arg)
+				if err != nil {
+					return err
+				}
+				parts = append(parts, part)
+			}
+
+			msg := MsgSplitCredit{Holder: from, Credit: credit, Units: units, Parts: parts}
+			if err := msg.ValidateBasic(); err != nil {
+				return err
+			}
+
+			return utils.GenerateOrBroadcastMsgs(cliCtx, txBldr, []sdk.Msg{msg})
+		},
+	}
+	return cmd
+}
+
+func GetCmdMergeCredits(cdc *codec.Codec) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "merge [geo-polygon] [credit]:[units] [credit]:[units]...",
+		Args:  cobra.MinimumNArgs(3),
+		Short: "merge units of compatible credits into a single credit for the union of their polygons",
+		Long: "Merge units of credits of the same class and vintage with the same issuer, attributes and datasets " +
+			"into a single credit. The polygons of the credits must tile the merged geo-polygon, which can be given " +
+			"either as a GeoJSON Polygon or as WKT.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			txBldr := auth.NewTxBuilderFromCLI().WithTxEncoder(utils.GetTxEncoder(cdc))
+			cliCtx := context.NewCLIContext().WithCodec(cdc)
+			from := cliCtx.GetFromAddress()
+
+			polygon, err := parseGeoPolygon(args[0])
+			if err != nil {
+				return err
+			}
+
+			var credits []CreditUnits
+			for _, arg := range args[1:] {
+				parts := strings.SplitN(arg, ":", 2)
+				if len(parts) != 2 {
+					return fmt.Errorf("invalid credit units %s, expected credit:units", arg)
+				}
+				credit, err := CreditFromBech32(parts[0])
+				if err != nil {
+					return err
+				}
+				units, err := sdk.NewDecFromStr(parts[1])
+				if err != nil {
+					return err
+				}
+				credits = append(credits, CreditUnits{Credit: credit, Units: units})
+			}
+
+			msg := MsgMergeCredits{Holder: from, Credits: credits, GeoPolygon: polygon}
+			if err := msg.ValidateBasic(); err != nil {
+				return err
+			}
+
+			return utils.GenerateOrBroadcastMsgs(cliCtx, txBldr, []sdk.Msg{msg})
+		},
+	}
+	return cmd
+}
+
+func GetCmdAnchorData(cdc *codec.Codec) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "anchor [hash] [uri] [media-type]",
+		Args:  cobra.ExactArgs(3),
+		Short: "anchor the hex encoded SHA-256 hash of an MRV dataset stored at a URI, e.g. with media type text/csv",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			txBldr := auth.NewTxBuilderFromCLI().WithTxEncoder(utils.GetTxEncoder(cdc))
+			cliCtx := context.NewCLIContext().WithCodec(cdc)
+			from := cliCtx.GetFromAddress()
+
+			hash, err := hex.DecodeString(args[0])
+			if err != nil {
+				return err
+			}
+
+			msg := MsgAnchorData{Sender: from, Hash: hash, URI: args[1], MediaType: args[2]}
+			if err := msg.ValidateBasic(); err != nil {
+				return err
+			}
+
+			return utils.GenerateOrBroadcastMsgs(cliCtx, txBldr, []sdk.Msg{msg})
+		},
+	}
+	return cmd
+}
+
+func GetCmdAttestData(cdc *codec.Codec) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "attest [credit-class] [hash]",
+		Args:  cobra.ExactArgs(2),
+		Short: "attest an anchored MRV dataset as a verifier of a credit class",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			txBldr := auth.NewTxBuilderFromCLI().WithTxEncoder(utils.GetTxEncoder(cdc))
+			cliCtx := context.NewCLIContext().WithCodec(cdc)
+			from := cliCtx.GetFromAddress()
+
+			creditClass, err := CreditClassFromBech32(args[0])
+			if err != nil {
+				return err
+			}
+
+			hash, err := hex.DecodeString(args[1])
+			if err != nil {
+				return err
+			}
+
+			msg := MsgAttestData{Verifier: from, CreditClass: creditClass, Hash: hash}
+			if err := msg.ValidateBasic(); err != nil {
+				return err
+			}
+
+			return utils.GenerateOrBroadcastMsgs(cliCtx, txBldr, []sdk.Msg{msg})
+		},
+	}
+	return cmd
+}
+
+func GetCmdAddCreditClassVerifier(cdc *codec.Codec) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "add-verifier [credit-class] [verifier]",
+		Args:  cobra.ExactArgs(2),
+		Short: "register a new verifier for a credit class, must be sent by the class designer",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			txBldr := auth.NewTxBuilderFromCLI().WithTxEncoder(utils.GetTxEncoder(cdc))
+			cliCtx := context.NewCLIContext().WithCodec(cdc)
+			from := cliCtx.GetFromAddress()
+
+			creditClass, err := CreditClassFromBech32(args[0])
+			if err != nil {
+				return err
+			}
+
+			verifier, err := sdk.AccAddressFromBech32(args[1])
+			if err != nil {
+				return err
+			}
+
+			msg := MsgAddCreditClassVerifier{CreditClass: creditClass, Designer: from, Verifier: verifier}
+			if err := msg.ValidateBasic(); err != nil {
+				return err
+			}
+
+			return utils.GenerateOrBroadcastMsgs(cliCtx, txBldr, []sdk.Msg{msg})
+		},
+	}
+	return cmd
+}
+
+func GetCmdRemoveCreditClassVerifier(cdc *codec.Codec) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "remove-verifier [credit-class] [verifier]",
+		Args:  cobra.ExactArgs(2),
+		Short: "unregister a verifier of a credit class, must be sent by the class designer",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			txBldr := auth.NewTxBuilderFromCLI().WithTxEncoder(utils.GetTxEncoder(cdc))
+			cliCtx := context.NewCLIContext().WithCodec(cdc)
+			from := cliCtx.GetFromAddress()
+
+			creditClass, err := CreditClassFromBech32(args[0])
+			if err != nil {
+				return err
+			}
+
+			verifier, err := sdk.AccAddressFromBech32(args[1])
+			if err != nil {
+				return err
+			}
+
+			msg := MsgRemoveCreditClassVerifier{CreditClass: creditClass, Designer: from, Verifier: verifier}
+			if err := msg.ValidateBasic(); err != nil {
+				return err
+			}
+
+			return utils.GenerateOrBroadcastMsgs(cliCtx, txBldr, []sdk.Msg{msg})
+		},
+	}
+	return cmd
+}
+
+func GetCmdTransferCreditClassDesigner(cdc *codec.Codec) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "transfer-designer [credit-class] [new-designer]",
+		Args:  cobra.ExactArgs(2),
+		Short: "transfer the designer rights of a credit class, must be sent by the class designer",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			txBldr := auth.NewTxBuilderFromCLI().WithTxEncoder(utils.GetTxEncoder(cdc))
+			cliCtx := context.NewCLIContext().WithCodec(cdc)
+			from := cliCtx.GetFromAddress()
+
+			creditClass, err := CreditClassFromBech32(args[0])
+			if err != nil {
+				return err
+			}
+
+			newDesigner, err := sdk.AccAddressFromBech32(args[1])
+			if err != nil {
+				return err
+			}
+
+			msg := MsgTransferCreditClassDesigner{CreditClass: creditClass, Designer: from, NewDesigner: newDesigner}
+			if err := msg.ValidateBasic(); err != nil {
+				return err
+			}
+
+			return utils.GenerateOrBroadcastMsgs(cliCtx, txBldr, []sdk.Msg{msg})
+		},
+	}
+	return cmd
+}
+
+func GetCmdDeprecateCreditClass(cdc *codec.Codec) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "deprecate-class [credit-class]",
+		Args:  cobra.ExactArgs(1),
+		Short: "block new issuance of a credit class, must be sent by the class designer",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			txBldr := auth.NewTxBuilderFromCLI().WithTxEncoder(utils.GetTxEncoder(cdc))
+			cliCtx := context.NewCLIContext().WithCodec(cdc)
+			from := cliCtx.GetFromAddress()
+
+			creditClass, err := CreditClassFromBech32(args[0])
+			if err != nil {
+				return err
+			}
+
+			msg := MsgDeprecateCreditClass{CreditClass: creditClass, Designer: from}
+			if err := msg.ValidateBasic(); err != nil {
+				return err
+			}
+
+			return utils.GenerateOrBroadcastMsgs(cliCtx, txBldr, []sdk.Msg{msg})
+		},
+	}
+	return cmd
+}
+
+func GetQueryCmd(queryRoute string, cdc *codec.Codec) *cobra.Command {
+	queryCmd := &cobra.Command{
+		Use:                        ModuleName,
+		Short:                      "Querying commands for the ecocredit module",
+		DisableFlagParsing:         true,
+		SuggestionsMinimumDistance: 2,
+		RunE:                       client.ValidateCmd,
+	}
+
+	queryCmd.AddCommand(client.GetCommands(
+		GetCmdQueryCreditClass(queryRoute, cdc),
+		GetCmdQueryConflicts(queryRoute, cdc),
+		GetCmdQuerySupply(queryRoute, cdc),
+		GetCmdQueryPortfolio(queryRoute, cdc),
+		GetCmdQueryHolders(queryRoute, cdc),
+		GetCmdQueryAllowance(queryRoute, cdc),
+		GetCmdQueryRetirement(queryRoute, cdc),
+		GetCmdQueryRetirementsByHolder(queryRoute, cdc),
+		GetCmdQueryRetirementsByCredit(queryRoute, cdc),
+		GetCmdQuerySellOrder(queryRoute, cdc),
+		GetCmdQuerySellOrdersBySeller(queryRoute, cdc),
+		GetCmdQuerySellOrdersByCredit(queryRoute, cdc),
+		GetCmdQueryAuction(queryRoute, cdc),
+		GetCmdQueryAuctions(queryRoute, cdc),
+		GetCmdQueryCreditVoucher(queryRoute, cdc),
+		GetCmdQueryReversal(queryRoute, cdc),
+		GetCmdQueryReversalsByCredit(queryRoute, cdc),
+		GetCmdQueryBufferPool(queryRoute, cdc),
+		GetCmdQueryAnchoredData(queryRoute, cdc),
+		GetCmdQueryBond(queryRoute, cdc),
+		GetCmdQueryExpiry(queryRoute, cdc),
+		GetCmdQueryConversion(queryRoute, cdc),
+		GetCmdQueryLineage(queryRoute, cdc),
+		GetCmdQueryReport(queryRoute, cdc),
+	)...)
+
+	return queryCmd
+}
+
+func GetCmdQueryCreditClass(queryRoute string, cdc *codec.Codec) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "class [credit-class]",
+		Args:  cobra.ExactArgs(1),
+		Short: "show a credit class and its schema",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cliCtx := context.NewCLIContext().WithCodec(cdc)
+
+			creditClass, err := CreditClassFromBech32(args[0])
+			if err != nil {
+				return err
+			}
+
+			var metadata CreditClassMetadata
+			err = queryJSON(cliCtx, fmt.Sprintf("custom/%s/%s", queryRoute, QueryCreditClass), QueryCreditClassParams{CreditClass: creditClass}, &metadata)
+			if err != nil {
+				return err
+			}
+			return cliCtx.PrintOutput(metadata)
+		},
+	}
+	return cmd
+}
+
+func GetCmdQueryConflicts(queryRoute string, cdc *codec.Codec) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "conflicts [credit-class] [geo-polygon] [start-date] [end-date]",
+		Args:  cobra.ExactArgs(4),
+		Short: "list the existing credits a proposed issuance would overlap with",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cliCtx := context.NewCLIContext().WithCodec(cdc)
+
+			creditClass, err := CreditClassFromBech32(args[0])
+			if err != nil {
+				return err
+			}
+
+			startDate, err := time.Parse(dateLayout, args[2])
+			if err != nil {
+				return err
+			}
+
+			endDate, err := time.Parse(dateLayout, args[3])
+			if err != nil {
+				return err
+			}
+
+			polygon, err := parseGeoPolygon(args[1])
+			if err != nil {
+				return err
+			}
+
+			var conflicts []Credit
+			err = queryJSON(cliCtx, fmt.Sprintf("custom/%s/%s", queryRoute, QueryConflicts), CreditMetadata{
+				CreditClass: creditClass,
+				GeoPolygon:  polygon,
+				StartDate:   startDate,
+				EndDate:     endDate,
+			}, &conflicts)
+			if err != nil {
+				return err
+			}
+			return cliCtx.PrintOutput(conflicts)
+		},
+	}
+	return cmd
+}
+
+func GetCmdQuerySupply(queryRoute string, cdc *codec.Codec) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "supply [credit]",
+		Args:  cobra.ExactArgs(1),
+		Short: "show the issued, liquid and retired units of a credit",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cliCtx := context.NewCLIContext().WithCodec(cdc)
+
+			credit, err := CreditFromBech32(args[0])
+			if err != nil {
+				return err
+			}
+
+			var supply CreditSupply
+			err = queryJSON(cliCtx, fmt.Sprintf("custom/%s/%s", queryRoute, QuerySupply), QueryCreditParams{Credit: credit}, &supply)
+			if err != nil {
+				return err
+			}
+			return cliCtx.PrintOutput(supply)
+		},
+	}
+	return cmd
+}
+
+func GetCmdQueryPortfolio(queryRoute string, cdc *codec.Codec) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "portfolio [holder]",
+		Args:  cobra.ExactArgs(1),
+		Short: "list the holdings of all credits held by an account",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cliCtx := context.NewCLIContext().WithCodec(cdc)
+
+			holder, err := sdk.AccAddressFromBech32(args[0])
+			if err != nil {
+				return err
+			}
+
+			var holdings []CreditHolding
+			err = queryJSON(cliCtx, fmt.Sprintf("custom/%s/%s", queryRoute, QueryHoldingsByHolder), QueryHolderParams{Holder: holder}, &holdings)
+			if err != nil {
+				return err
+			}
+			return cliCtx.PrintOutput(holdings)
+		},
+	}
+	return cmd
+}
+
+func GetCmdQueryHolders(queryRoute string, cdc *codec.Codec) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "holders [credit]",
+		Args:  cobra.ExactArgs(1),
+		Short: "list the holdings of a credit by all its holders",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cliCtx := context.NewCLIContext().WithCodec(cdc)
+
+			credit, err := CreditFromBech32(args[0])
+			if err != nil {
+				return err
+			}
+
+			var holdings []CreditHolding
+			err = queryJSON(cliCtx, fmt.Sprintf("custom/%s/%s", queryRoute, QueryHoldersOfCredit), QueryCreditParams{Credit: credit}, &holdings)
+			if err != nil {
+				return err
+			}
+			return cliCtx.PrintOutput(holdings)
+		},
+	}
+	return cmd
+}
+
+func GetCmdQueryAllowance(queryRoute string, cdc *codec.Codec) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "allowance [holder] [spender] [credit]",
+		Args:  cobra.ExactArgs(3),
+		Short: `show the allowance of a spender for a credit, or for all credits when credit is "all"`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cliCtx := context.NewCLIContext().WithCodec(cdc)
+
+			holder, err := sdk.AccAddressFromBech32(args[0])
+			if err != nil {
+				return err
+			}
+
+			spender, err := sdk.AccAddressFromBech32(args[1])
+			if err != nil {
+				return err
+			}
+
+			credit, err := parseOptionalCredit(args[2])
+			if err != nil {
+				return err
+			}
+
+			var allowance CreditAllowance
+			err = queryJSON(cliCtx, fmt.Sprintf("custom/%s/%s", queryRoute, QueryAllowance),
+				QueryAllowanceParams{Holder: holder, Spender: spender, Credit: credit}, &allowance)
+			if err != nil {
+				return err
+			}
+			return cliCtx.PrintOutput(allowance)
+		},
+	}
+	return cmd
+}
+
+func GetCmdQueryRetirement(queryRoute string, cdc *codec.Codec) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "retirement [id]",
+		Args:  cobra.ExactArgs(1),
+		Short: "show a retirement certificate",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cliCtx := context.NewCLIContext().WithCodec(cdc)
+
+			id, err := RetirementFromBech32(args[0])
+			if err != nil {
+				return err
+			}
+
+			var certificate RetirementCertificate
+			err = queryJSON(cliCtx, fmt.Sprintf("custom/%s/%s", queryRoute, QueryRetirement), QueryRetirementParams{ID: id}, &certificate)
+			if err != nil {
+				return err
+			}
+			return cliCtx.PrintOutput(certificate)
+		},
+	}
+	return cmd
+}
+
+func GetCmdQueryRetirementsByHolder(queryRoute string, cdc *codec.Codec) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "retirements-by-holder [holder]",
+		Args:  cobra.ExactArgs(1),
+		Short: "list the retirement certificates of an account",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cliCtx := context.NewCLIContext().WithCodec(cdc)
+
+			holder, err := sdk.AccAddressFromBech32(args[0])
+			if err != nil {
+				return err
+			}
+
+			var certificates []RetirementCertificate
+			err = queryJSON(cliCtx, fmt.Sprintf("custom/%s/%s", queryRoute, QueryRetirementsByHolder), QueryHolderParams{Holder: holder}, &certificates)
+			if err != nil {
+				return err
+			}
+			return cliCtx.PrintOutput(certificates)
+		},
+	}
+	return cmd
+}
+
+func GetCmdQueryRetirementsByCredit(queryRoute string, cdc *codec.Codec) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "retirements-by-credit [credit]",
+		Args:  cobra.ExactArgs(1),
+		Short: "list the retirement certificates of a credit",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cliCtx := context.NewCLIContext().WithCodec(cdc)
+
+			credit, err := CreditFromBech32(args[0])
+			if err != nil {
+				return err
+			}
+
+			var certificates []RetirementCertificate
+			err = queryJSON(cliCtx, fmt.Sprintf("custom/%s/%s", queryRoute, QueryRetirementsByCredit), QueryCreditParams{Credit: credit}, &certificates)
+			if err != nil {
+				return err
+			}
+			return cliCtx.PrintOutput(certificates)
+		},
+	}
+	return cmd
+}
+
+func GetCmdQuerySellOrder(queryRoute string, cdc *codec.Codec) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "sell-order [id]",
+		Args:  cobra.ExactArgs(1),
+		Short: "show an open sell order",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cliCtx := context.NewCLIContext().WithCodec(cdc)
+
+			id, err := SellOrderFromBech32(args[0])
+			if err != nil {
+				return err
+			}
+
+			var order SellOrderWithID
+			err = queryJSON(cliCtx, fmt.Sprintf("custom/%s/%s", queryRoute, QuerySellOrder), QuerySellOrderParams{ID: id}, &order)
+			if err != nil {
+				return err
+			}
+			return cliCtx.PrintOutput(order)
+		},
+	}
+	return cmd
+}
+
+func GetCmdQuerySellOrdersBySeller(queryRoute string, cdc *codec.Codec) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "sell-orders-by-seller [seller]",
+		Args:  cobra.ExactArgs(1),
+		Short: "list the open sell orders of an account",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cliCtx := context.NewCLIContext().WithCodec(cdc)
+
+			seller, err := sdk.AccAddressFromBech32(args[0])
+			if err != nil {
+				return err
+			}
+
+			var orders []SellOrderWithID
+			err = queryJSON(cliCtx, fmt.Sprintf("custom/%s/%s", queryRoute, QuerySellOrdersBySeller), QueryHolderParams{Holder: seller}, &orders)
+			if err != nil {
+				return err
+			}
+			return cliCtx.PrintOutput(orders)
+		},
+	}
+	return cmd
+}
+
+func GetCmdQuerySellOrdersByCredit(queryRoute string, cdc *codec.Codec) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "sell-orders-by-credit [credit]",
+		Args:  cobra.ExactArgs(1),
+		Short: "list the open sell orders of a credit",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cliCtx := context.NewCLIContext().WithCodec(cdc)
+
+			credit, err := CreditFromBech32(args[0])
+			if err != nil {
+				return err
+			}
+
+			var orders []SellOrderWithID
+			err = queryJSON(cliCtx, fmt.Sprintf("custom/%s/%s", queryRoute, QuerySellOrdersByCredit), QueryCreditParams{Credit: credit}, &orders)
+			if err != nil {
+				return err
+			}
+			return cliCtx.PrintOutput(orders)
+		},
+	}
+	return cmd
+}
+
+func GetCmdQueryAuction(queryRoute string, cdc *codec.Codec) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "auction [id]",
+		Args:  cobra.ExactArgs(1),
+		Short: "show an open auction and its bids",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cliCtx := context.NewCLIContext().WithCodec(cdc)
+
+			id, err := AuctionFromBech32(args[0])
+			if err != nil {
+				return err
+			}
+
+			var auction AuctionWithBids
+			err = queryJSON(cliCtx, fmt.Sprintf("custom/%s/%s", queryRoute, QueryAuction), QueryAuctionParams{ID: id}, &auction)
+			if err != nil {
+				return err
+			}
+			return cliCtx.PrintOutput(auction)
+		},
+	}
+	return cmd
+}
+
+func GetCmdQueryAuctions(queryRoute string, cdc *codec.Codec) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "auctions",
+		Args:  cobra.NoArgs,
+		Short: "list all open auctions",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cliCtx := context.NewCLIContext().WithCodec(cdc)
+
+			res, _, err := cliCtx.QueryWithData(fmt.Sprintf("custom/%s/%s", queryRoute, QueryAuctions), nil)
+			if err != nil {
+				return err
+			}
+			var auctions []AuctionWithID
+			if err := cdc.UnmarshalJSON(res, &auctions); err != nil {
+				return err
+			}
+			return cliCtx.PrintOutput(auctions)
+		},
+	}
+	return cmd
+}
+
+func GetCmdQueryCreditVoucher(queryRoute string, cdc *codec.Codec) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "voucher [credit]",
+		Args:  cobra.ExactArgs(1),
+		Short: "show the origin and provenance path of a credit received over IBC",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cliCtx := context.NewCLIContext().WithCodec(cdc)
+
+			credit, err := CreditFromBech32(args[0])
+			if err != nil {
+				return err
+			}
+
+			var voucher CreditVoucher
+			err = queryJSON(cliCtx, fmt.Sprintf("custom/%s/%s", queryRoute, QueryCreditVoucher), QueryCreditParams{Credit: credit}, &voucher)
+			if err != nil {
+				return err
+			}
+			return cliCtx.PrintOutput(voucher)
+		},
+	}
+	return cmd
+}
+
+// queryJSON runs a custom query with the JSON encoded params and decodes the JSON result into res
+func queryJSON(cliCtx context.CLIContext, path string, params interface{}, res interface{}) error {
+	bz, err := cliCtx.Codec.MarshalJSON(params)
+	if err != nil {
+		return err
+	}
+	bz, _, err = cliCtx.QueryWithData(path, bz)
+	if err != nil {
+		return err
+	}
+	return cliCtx.Codec.UnmarshalJSON(bz, res)
+}
+
+func GetCmdQueryReversal(queryRoute string, cdc *codec.Codec) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "reversal [id]",
+		Args:  cobra.ExactArgs(1),
+		Short: "show a reversal and its cancellations",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cliCtx := context.NewCLIContext().WithCodec(cdc)
+
+			id, err := ReversalFromBech32(args[0])
+			if err != nil {
+				return err
+			}
+
+			var record ReversalRecord
+			err = queryJSON(cliCtx, fmt.Sprintf("custom/%s/%s", queryRoute, QueryReversal), QueryReversalParams{ID: id}, &record)
+			if err != nil {
+				return err
+			}
+			return cliCtx.PrintOutput(record)
+		},
+	}
+	return cmd
+}
+
+func GetCmdQueryReversalsByCredit(queryRoute string, cdc *codec.Codec) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "reversals-by-credit [credit]",
+		Args:  cobra.ExactArgs(1),
+		Short: "list the reversals of a credit",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cliCtx := context.NewCLIContext().WithCodec(cdc)
+
+			credit, err := CreditFromBech32(args[0])
+			if err != nil {
+				return err
+			}
+
+			var records []ReversalRecord
+			err = queryJSON(cliCtx, fmt.Sprintf("custom/%s/%s", queryRoute, QueryReversalsByCredit), QueryCreditParams{Credit: credit}, &records)
+			if err != nil {
+				return err
+			}
+			return cliCtx.PrintOutput(records)
+		},
+	}
+	return cmd
+}
+
+func GetCmdQueryBufferPool(queryRoute string, cdc *codec.Codec) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "buffer-pool [credit-class]",
+		Args:  cobra.ExactArgs(1),
+		Short: "list the units withheld in the buffer pool of a credit class",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cliCtx := context.NewCLIContext().WithCodec(cdc)
+
+			creditClass, err := CreditClassFromBech32(args[0])
+			if err != nil {
+				return err
+			}
+
+			var holdings []CreditHolding
+			err = queryJSON(cliCtx, fmt.Sprintf("custom/%s/%s", queryRoute, QueryBufferPool), QueryCreditClassParams{CreditClass: creditClass}, &holdings)
+			if err != nil {
+				return err
+			}
+			return cliCtx.PrintOutput(holdings)
+		},
+	}
+	return cmd
+}
+
+func GetCmdQueryAnchoredData(queryRoute string, cdc *codec.Codec) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "data [hash]",
+		Args:  cobra.ExactArgs(1),
+		Short: "show an anchored MRV dataset and its attestations",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cliCtx := context.NewCLIContext().WithCodec(cdc)
+
+			hash, err := hex.DecodeString(args[0])
+			if err != nil {
+				return err
+			}
+
+			var data AnchoredDataWithAttestations
+			err = queryJSON(cliCtx, fmt.Sprintf("custom/%s/%s", queryRoute, QueryAnchoredData), QueryAnchoredDataParams{Hash: hash}, &data)
+			if err != nil {
+				return err
+			}
+			return cliCtx.PrintOutput(data)
+		},
+	}
+	return cmd
+}
+
+func GetCmdQueryBond(queryRoute string, cdc *codec.Codec) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "bond [credit-class] [issuer]",
+		Args:  cobra.ExactArgs(2),
+		Short: "show the bond of an issuer for a credit class, its pending unbondings and whether it allows issuing",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cliCtx := context.NewCLIContext().WithCodec(cdc)
+
+			creditClass, err := CreditClassFromBech32(args[0])
+			if err != nil {
+				return err
+			}
+
+			issuer, err := sdk.AccAddressFromBech32(args[1])
+			if err != nil {
+				return err
+			}
+
+			var status IssuerBondStatus
+			err = queryJSON(cliCtx, fmt.Sprintf("custom/%s/%s", queryRoute, QueryBond), QueryBondParams{CreditClass: creditClass, Issuer: issuer}, &status)
+			if err != nil {
+				return err
+			}
+			return cliCtx.PrintOutput(status)
+		},
+	}
+	return cmd
+}
+
+func GetCmdQueryExpiry(queryRoute string, cdc *codec.Codec) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "expiry [credit]",
+		Args:  cobra.ExactArgs(1),
+		Short: "show when a credit expires, whether it expired and its expired units by holder",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cliCtx := context.NewCLIContext().WithCodec(cdc)
+
+			credit, err := CreditFromBech32(args[0])
+			if err != nil {
+				return err
+			}
+
+			var status CreditExpiryStatus
+			err = queryJSON(cliCtx, fmt.Sprintf("custom/%s/%s", queryRoute, QueryExpiry), QueryCreditParams{Credit: credit}, &status)
+			if err != nil {
+				return err
+			}
+			return cliCtx.PrintOutput(status)
+		},
+	}
+	return cmd
+}
+
+func GetCmdQueryConversion(queryRoute string, cdc *codec.Codec) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "conversion [id]",
+		Args:  cobra.ExactArgs(1),
+		Short: "show the credits and units converted by a split or merge",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cliCtx := context.NewCLIContext().WithCodec(cdc)
+
+			id, err := ConversionFromBech32(args[0])
+			if err != nil {
+				return err
+			}
+
+			var record ConversionRecord
+			err = queryJSON(cliCtx, fmt.Sprintf("custom/%s/%s", queryRoute, QueryConversion), QueryConversionParams{ID: id}, &record)
+			if err != nil {
+				return err
+			}
+			return cliCtx.PrintOutput(record)
+		},
+	}
+	return cmd
+}
+
+func GetCmdQueryLineage(queryRoute string, cdc *codec.Codec) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "lineage [credit]",
+		Args:  cobra.ExactArgs(1),
+		Short: "show the credits a credit was split or merged from and the credits derived from it",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cliCtx := context.NewCLIContext().WithCodec(cdc)
+
+			credit, err := CreditFromBech32(args[0])
+			if err != nil {
+				return err
+			}
+
+			var lineage CreditLineage
+			err = queryJSON(cliCtx, fmt.Sprintf("custom/%s/%s", queryRoute, QueryLineage), QueryCreditParams{Credit: credit}, &lineage)
+			if err != nil {
+				return err
+			}
+			return cliCtx.PrintOutput(lineage)
+		},
+	}
+	return cmd
+}
+
+func GetCmdQueryReport(queryRoute string, cdc *codec.Codec) *cobra.Command {
+	var from, to string
+	var asCSV bool
+	cmd := &cobra.Command{
+		Use:   "report [credit-class]",
+		Args:  cobra.ExactArgs(1),
+		Short: "list the issuances with their serial ranges, transfers and retirements of the credits of a class",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cliCtx := context.NewCLIContext().WithCodec(cdc)
+
+			creditClass, err := CreditClassFromBech32(args[0])
+			if err != nil {
+				return err
+			}
+			params := QueryReportParams{CreditClass: creditClass}
+			if from != "" {
+				if params.From, err = time.Parse(dateLayout, from); err != nil {
+					return err
+				}
+			}
+			if to != "" {
+				if params.To, err = time.Parse(dateLayout, to); err != nil {
+					return err
+				}
+			}
+
+			var report Report
+			err = queryJSON(cliCtx, fmt.Sprintf("custom/%s/%s", queryRoute, QueryReport), params, &report)
+			if err != nil {
+				return err
+			}
+			if asCSV {
+				return report.WriteCSV(cmd.OutOrStdout())
+			}
+			return cliCtx.PrintOutput(report)
 		},
 	}
+	cmd.Flags().StringVar(&from, "from", "", "only list entries at or after this time")
+	cmd.Flags().StringVar(&to, "to", "", "only list entries before this time")
+	cmd.Flags().BoolVar(&asCSV, "csv", false, "print the report as CSV instead of JSON")
 	return cmd
 }
@@ -0,0 +1,74 @@
+package ecocredit
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+func TestParseCreditOutputs(t *testing.T) {
+	credit1, credit2 := CreditID{0, 0, 0, 0, 0, 0, 0, 1}, CreditID{0, 0, 0, 0, 0, 0, 0, 2}
+	outputs := []CreditOutput{
+		{Credit: credit1, To: addr1, Units: sdk.NewDec(10)},
+		{Credit: credit2, To: addr2, Units: sdk.NewDecWithPrec(25, 1)},
+	}
+	rows := fmt.Sprintf("%s,%s,10\n%s, %s, 2.5\n", credit1, addr1, credit2, addr2)
+	cases := map[string]struct {
+		csv     string
+		outputs []CreditOutput
+		err     string
+	}{
+		"with header": {
+			csv:     "credit,recipient,units\n" + rows,
+			outputs: outputs,
+		},
+		"without header": {
+			csv:     rows,
+			outputs: outputs,
+		},
+		"only header": {
+			csv:     "credit,recipient,units\n",
+			outputs: []CreditOutput{},
+		},
+		"empty file": {
+			csv:     "",
+			outputs: []CreditOutput{},
+		},
+		"missing column": {
+			csv: fmt.Sprintf("%s,%s\n", credit1, addr1),
+			err: "wrong number of fields",
+		},
+		"bad credit": {
+			csv: fmt.Sprintf("%s,%s,10\n%s,%s,1\n", credit1, addr1, addr2, addr1),
+			err: "row 2: invalid credit",
+		},
+		"bad recipient": {
+			csv: fmt.Sprintf("credit,recipient,units\n%s,%s,10\n", credit1, credit2),
+			err: "row 1: invalid recipient",
+		},
+		"bad units": {
+			csv: fmt.Sprintf("%s,%s,1.2.3\n", credit1, addr1),
+			err: "row 1: invalid units",
+		},
+		"empty units": {
+			csv: fmt.Sprintf("%s,%s,\n", credit1, addr1),
+			err: "row 1: invalid units",
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			outputs, err := parseCreditOutputs(strings.NewReader(tc.csv))
+			if tc.err != "" {
+				require.Error(t, err)
+				require.Contains(t, err.Error(), tc.err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.outputs, outputs)
+		})
+	}
+}
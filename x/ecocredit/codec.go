@@ -8,11 +8,62 @@ import (
 func RegisterCodec(cdc *codec.Codec) {
 	cdc.RegisterConcrete(MsgCreateCreditClass{}, "ecocredit/MsgCreateCreditClass", nil)
 	cdc.RegisterConcrete(MsgIssueCredit{}, "ecocredit/MsgIssueCredit", nil)
+	cdc.RegisterConcrete(MsgIssueCreditBatch{}, "ecocredit/MsgIssueCreditBatch", nil)
 	cdc.RegisterConcrete(MsgSendCredit{}, "ecocredit/MsgSendCredit", nil)
+	cdc.RegisterConcrete(MsgMultiSendCredit{}, "ecocredit/MsgMultiSendCredit", nil)
+	cdc.RegisterConcrete(MsgApproveCredit{}, "ecocredit/MsgApproveCredit", nil)
+	cdc.RegisterConcrete(MsgRevokeCredit{}, "ecocredit/MsgRevokeCredit", nil)
+	cdc.RegisterConcrete(MsgSendCreditFrom{}, "ecocredit/MsgSendCreditFrom", nil)
 	cdc.RegisterConcrete(MsgBurnCredit{}, "ecocredit/MsgBurnCredit", nil)
+	cdc.RegisterConcrete(MsgCreateSellOrder{}, "ecocredit/MsgCreateSellOrder", nil)
+	cdc.RegisterConcrete(MsgUpdateSellOrder{}, "ecocredit/MsgUpdateSellOrder", nil)
+	cdc.RegisterConcrete(MsgCancelSellOrder{}, "ecocredit/MsgCancelSellOrder", nil)
+	cdc.RegisterConcrete(MsgBuyCredit{}, "ecocredit/MsgBuyCredit", nil)
+	cdc.RegisterConcrete(MsgCreateAuction{}, "ecocredit/MsgCreateAuction", nil)
+	cdc.RegisterConcrete(MsgPlaceBid{}, "ecocredit/MsgPlaceBid", nil)
+	cdc.RegisterConcrete(MsgWrapCredit{}, "ecocredit/MsgWrapCredit", nil)
+	cdc.RegisterConcrete(MsgUnwrapCredit{}, "ecocredit/MsgUnwrapCredit", nil)
+	cdc.RegisterConcrete(MsgTransferCredit{}, "ecocredit/MsgTransferCredit", nil)
+	cdc.RegisterConcrete(MsgRecvCreditPacket{}, "ecocredit/MsgRecvCreditPacket", nil)
+	cdc.RegisterConcrete(MsgAcknowledgeCreditPacket{}, "ecocredit/MsgAcknowledgeCreditPacket", nil)
+	cdc.RegisterConcrete(MsgTimeoutCreditPacket{}, "ecocredit/MsgTimeoutCreditPacket", nil)
+	cdc.RegisterConcrete(MsgReverseCredit{}, "ecocredit/MsgReverseCredit", nil)
+	cdc.RegisterConcrete(MsgSplitCredit{}, "ecocredit/MsgSplitCredit", nil)
+	cdc.RegisterConcrete(MsgMergeCredits{}, "ecocredit/MsgMergeCredits", nil)
+	cdc.RegisterConcrete(MsgAnchorData{}, "ecocredit/MsgAnchorData", nil)
+	cdc.RegisterConcrete(MsgAttestData{}, "ecocredit/MsgAttestData", nil)
+	cdc.RegisterConcrete(MsgPostBond{}, "ecocredit/MsgPostBond", nil)
+	cdc.RegisterConcrete(MsgUnbondBond{}, "ecocredit/MsgUnbondBond", nil)
+	cdc.RegisterConcrete(MsgSlashBond{}, "ecocredit/MsgSlashBond", nil)
+	cdc.RegisterConcrete(MsgAddCreditClassIssuer{}, "ecocredit/MsgAddCreditClassIssuer", nil)
+	cdc.RegisterConcrete(MsgRemoveCreditClassIssuer{}, "ecocredit/MsgRemoveCreditClassIssuer", nil)
+	cdc.RegisterConcrete(MsgAddCreditClassVerifier{}, "ecocredit/MsgAddCreditClassVerifier", nil)
+	cdc.RegisterConcrete(MsgRemoveCreditClassVerifier{}, "ecocredit/MsgRemoveCreditClassVerifier", nil)
+	cdc.RegisterConcrete(MsgTransferCreditClassDesigner{}, "ecocredit/MsgTransferCreditClassDesigner", nil)
+	cdc.RegisterConcrete(MsgDeprecateCreditClass{}, "ecocredit/MsgDeprecateCreditClass", nil)
 	cdc.RegisterConcrete(CreditClassMetadata{}, "ecocredit/CreditClassMetadata", nil)
 	cdc.RegisterConcrete(CreditMetadata{}, "ecocredit/CreditMetadata", nil)
 	cdc.RegisterConcrete(CreditHolding{}, "ecocredit/CreditHolding", nil)
+	cdc.RegisterConcrete(CreditSupply{}, "ecocredit/CreditSupply", nil)
+	cdc.RegisterConcrete(CreditAllowance{}, "ecocredit/CreditAllowance", nil)
+	cdc.RegisterConcrete(Retirement{}, "ecocredit/Retirement", nil)
+	cdc.RegisterConcrete(SellOrder{}, "ecocredit/SellOrder", nil)
+	cdc.RegisterConcrete(Auction{}, "ecocredit/Auction", nil)
+	cdc.RegisterConcrete(AuctionBid{}, "ecocredit/AuctionBid", nil)
+	cdc.RegisterConcrete(CreditVoucher{}, "ecocredit/CreditVoucher", nil)
+	cdc.RegisterConcrete(CreditClassVoucher{}, "ecocredit/CreditClassVoucher", nil)
+	cdc.RegisterConcrete(CreditEscrow{}, "ecocredit/CreditEscrow", nil)
+	cdc.RegisterConcrete(Reversal{}, "ecocredit/Reversal", nil)
+	cdc.RegisterConcrete(AnchoredData{}, "ecocredit/AnchoredData", nil)
+	cdc.RegisterConcrete(Attestation{}, "ecocredit/Attestation", nil)
+	cdc.RegisterConcrete(IssuerBond{}, "ecocredit/IssuerBond", nil)
+	cdc.RegisterConcrete(BondUnbonding{}, "ecocredit/BondUnbonding", nil)
+	cdc.RegisterConcrete(CreditExpiry{}, "ecocredit/CreditExpiry", nil)
+	cdc.RegisterConcrete(ExpiredHolding{}, "ecocredit/ExpiredHolding", nil)
+	cdc.RegisterConcrete(Conversion{}, "ecocredit/Conversion", nil)
+	cdc.RegisterConcrete(CreditLineage{}, "ecocredit/CreditLineage", nil)
+	cdc.RegisterConcrete(Issuance{}, "ecocredit/Issuance", nil)
+	cdc.RegisterConcrete(CreditTransfer{}, "ecocredit/CreditTransfer", nil)
 }
 
 // ModuleCdc generic sealed codec to be used throughout module
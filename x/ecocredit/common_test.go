@@ -0,0 +1,91 @@
+package ecocredit
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	abci "github.com/tendermint/tendermint/abci/types"
+	"github.com/tendermint/tendermint/libs/log"
+	dbm "github.com/tendermint/tm-db"
+
+	"github.com/cosmos/cosmos-sdk/codec"
+	"github.com/cosmos/cosmos-sdk/store"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/x/auth"
+	"github.com/cosmos/cosmos-sdk/x/bank"
+	"github.com/cosmos/cosmos-sdk/x/params"
+	"github.com/cosmos/cosmos-sdk/x/supply"
+)
+
+// createTestInput returns a context backed by an in-memory store and a keeper using it. The bank keeper of the
+// keeper has sending enabled so that tests can fund accounts through it, and the supply keeper starts without supply
+func createTestInput(t *testing.T) (sdk.Context, Keeper) {
+	return createTestInputWithChannels(t, newTestChannelKeeper())
+}
+
+// createTestInputWithChannels is createTestInput with the channel keeper used for IBC
+func createTestInputWithChannels(t *testing.T, channels ChannelKeeper) (sdk.Context, Keeper) {
+	key := sdk.NewKVStoreKey(StoreKey)
+	authKey := sdk.NewKVStoreKey(auth.StoreKey)
+	supplyKey := sdk.NewKVStoreKey(supply.StoreKey)
+	paramsKey := sdk.NewKVStoreKey(params.StoreKey)
+	paramsTKey := sdk.NewTransientStoreKey(params.TStoreKey)
+	db := dbm.NewMemDB()
+	ms := store.NewCommitMultiStore(db)
+	ms.MountStoreWithDB(key, sdk.StoreTypeIAVL, db)
+	ms.MountStoreWithDB(authKey, sdk.StoreTypeIAVL, db)
+	ms.MountStoreWithDB(supplyKey, sdk.StoreTypeIAVL, db)
+	ms.MountStoreWithDB(paramsKey, sdk.StoreTypeIAVL, db)
+	ms.MountStoreWithDB(paramsTKey, sdk.StoreTypeTransient, db)
+	require.NoError(t, ms.LoadLatestVersion())
+	ctx := sdk.NewContext(ms, abci.Header{Time: startDate}, false, log.NewNopLogger())
+
+	cdc := codec.New()
+	auth.RegisterCodec(cdc)
+	supply.RegisterCodec(cdc)
+	RegisterCodec(cdc)
+	codec.RegisterCrypto(cdc)
+	paramsKeeper := params.NewKeeper(cdc, paramsKey, paramsTKey, params.DefaultCodespace)
+	accountKeeper := auth.NewAccountKeeper(cdc, authKey, paramsKeeper.Subspace(auth.DefaultParamspace), auth.ProtoBaseAccount)
+	bankKeeper := bank.NewBaseKeeper(accountKeeper, paramsKeeper.Subspace(bank.DefaultParamspace), bank.DefaultCodespace, nil)
+	bankKeeper.SetSendEnabled(ctx, true)
+	supplyKeeper := supply.NewKeeper(cdc, supplyKey, accountKeeper, bankKeeper, map[string][]string{
+		ModuleName:   {supply.Minter, supply.Burner},
+		BondPoolName: {supply.Burner},
+	})
+	supplyKeeper.SetSupply(ctx, supply.NewSupply(sdk.NewCoins()))
+	return ctx, NewKeeper(cdc, key, bankKeeper, supplyKeeper, channels, sdk.NewKVStoreKey(PortID))
+}
+
+// testCreditClass returns valid metadata of a credit class designed by addr1 with addr1 as its only issuer
+func testCreditClass() CreditClassMetadata {
+	return CreditClassMetadata{Designer: addr1, Name: "carbon", Issuers: []sdk.AccAddress{addr1},
+		CreditType: "carbon", UnitOfMeasure: "tCO2e", Precision: 6, BufferRate: sdk.ZeroDec()}
+}
+
+// testCredit returns valid metadata of a credit of the class issued by addr1
+func testCredit(class CreditClassID) CreditMetadata {
+	return CreditMetadata{
+		Issuer:      addr1,
+		CreditClass: class,
+		GeoPolygon:  mustGeoPolygon("POLYGON ((0 0, 1 0, 1 1, 0 1, 0 0))"),
+		StartDate:   startDate,
+		EndDate:     endDate,
+		LiquidUnits: sdk.NewDec(100),
+		BurnedUnits: sdk.ZeroDec(),
+	}
+}
+
+// mustGeoCircle returns a regular polygon with the number of vertices approximating a circle around (center, center)
+func mustGeoCircle(center float64, radius float64, vertices int) []byte {
+	points := make([]string, vertices)
+	for i := range points {
+		angle := 2 * math.Pi * float64(i) / float64(vertices)
+		points[i] = fmt.Sprintf("%f %f", center+radius*math.Cos(angle), center+radius*math.Sin(angle))
+	}
+	return mustGeoPolygon(fmt.Sprintf("POLYGON ((%s, %s))", strings.Join(points, ", "), points[0]))
+}
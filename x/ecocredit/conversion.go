@@ -0,0 +1,444 @@
+package ecocredit
+
+import (
+	"bytes"
+	"fmt"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/gaia/geo"
+	"math/big"
+	"sort"
+	"time"
+)
+
+// MaxConversionParts is the maximum number of parts of a split and of credits of a merge
+const MaxConversionParts = 32
+
+type ConversionID []byte
+
+// Conversion records liquid units of a holder converted from source credits into derived credits by a split or a
+// merge. The source units move from the liquid to the converted supply of their credits and the same number of units
+// is issued to the holder as units of the derived credits. Conversions are never modified once created
+type Conversion struct {
+	Holder    sdk.AccAddress `json:"holder"`
+	Sources   []CreditUnits  `json:"sources"`
+	Results   []CreditUnits  `json:"results"`
+	Timestamp time.Time      `json:"timestamp"`
+}
+
+// ConversionRecord pairs a conversion with its ID
+type ConversionRecord struct {
+	ID         ConversionID `json:"id"`
+	Conversion Conversion   `json:"conversion"`
+}
+
+// CreditLineage links a credit to the credits it was derived from by a split or a merge and to the credits derived
+// from it. A credit is either split into several children, each of which has it as its only parent, or merged with
+// other credits into a single child with several parents
+type CreditLineage struct {
+	Credit   CreditID   `json:"credit"`
+	Parents  []CreditID `json:"parents"`
+	Children []CreditID `json:"children"`
+}
+
+func (l CreditLineage) ID() []byte {
+	return l.Credit
+}
+
+// SplitCredit converts liquid units of a credit held by the holder into units of child credits whose polygons tile
+// the polygon of the credit. The units are apportioned to the parts by their exact planar area, rounded down to the
+// precision of the class, and the indivisible parts left over go one each to the parts in order, every part must
+// receive at least one indivisible part. The children copy the metadata of the credit apart from the polygon. The
+// first split of a credit creates its children, later splits must use the same parts and add to the existing
+// children, so that derived credits never overlap other credits than those they share their lineage with. The
+// conversion is recorded and its ID is returned
+func (k Keeper) SplitCredit(ctx sdk.Context, holder sdk.AccAddress, credit CreditID, units sdk.Dec, parts [][]byte) (ConversionID, error) {
+	metadata, class, err := k.getConvertibleCredit(ctx, credit)
+	if err != nil {
+		return nil, err
+	}
+	if err := class.CheckPrecision(units); err != nil {
+		return nil, err
+	}
+	whole, err := geo.Unmarshal(metadata.GeoPolygon)
+	if err != nil {
+		return nil, ErrInvalidGeoPolygon(DefaultCodespace, err)
+	}
+	polygons := make([]geo.Polygon, len(parts))
+	for i, part := range parts {
+		if polygons[i], err = geo.Unmarshal(part); err != nil {
+			return nil, ErrInvalidGeoPolygon(DefaultCodespace, err)
+		}
+	}
+	tiles, err := polygonsTile(ctx, whole, polygons)
+	if err != nil {
+		return nil, err
+	}
+	if len(parts) < 2 || !tiles {
+		return nil, ErrInvalidTiling(DefaultCodespace, "the parts must tile the polygon of the credit")
+	}
+	shares, err := apportionByArea(whole, polygons, class.Precision, units)
+	if err != nil {
+		return nil, err
+	}
+
+	children, err := k.existingChildren(ctx, credit, func(lineage CreditLineage, child CreditMetadata) bool {
+		return len(lineage.Parents) == 1
+	})
+	if err != nil {
+		return nil, err
+	}
+	results := make([]CreditUnits, len(parts))
+	for i, part := range parts {
+		if children == nil {
+			continue
+		}
+		for _, child := range children {
+			if bytes.Equal(child.Metadata.GeoPolygon, part) {
+				results[i].Credit = child.ID
+			}
+		}
+		if results[i].Credit == nil || len(children) != len(parts) {
+			return nil, ErrInvalidTiling(DefaultCodespace, fmt.Sprintf("credit %s was already split into other parts", credit))
+		}
+	}
+	for i, part := range parts {
+		if results[i].Credit == nil {
+			child := metadata
+			child.GeoPolygon = part
+			if results[i].Credit, err = k.createDerivedCredit(ctx, child, class, []CreditID{credit}); err != nil {
+				return nil, err
+			}
+		}
+		results[i].Units = shares[i]
+	}
+	return k.convertCredits(ctx, holder, []CreditUnits{{Credit: credit, Units: units}}, results, EventTypeSplitCredit)
+}
+
+// MergeCredits converts liquid units of several compatible credits held by the holder into units of a single merged
+// credit whose polygon is tiled by the polygons of the credits. Credits are compatible if they are of the same class
+// and vintage, i.e. have the same start and end dates, and have the same issuer, attributes and datasets. The merged
+// credit copies their metadata apart from the polygon and receives the sum of the units. The first merge of the
+// credits creates the merged credit, later merges of the same credits add to it, and a credit can't be merged with
+// other credits or split once it was merged. The conversion is recorded and its ID is returned
+func (k Keeper) MergeCredits(ctx sdk.Context, holder sdk.AccAddress, sources []CreditUnits, geoPolygon []byte) (ConversionID, error) {
+	if len(sources) < 2 {
+		return nil, ErrIncompatibleCredits(DefaultCodespace, "at least two credits must be merged")
+	}
+	whole, err := geo.Unmarshal(geoPolygon)
+	if err != nil {
+		return nil, ErrInvalidGeoPolygon(DefaultCodespace, err)
+	}
+	var merged CreditMetadata
+	var class CreditClassMetadata
+	parents := make([]CreditID, len(sources))
+	polygons := make([]geo.Polygon, len(sources))
+	total := sdk.ZeroDec()
+	for i, source := range sources {
+		metadata, sourceClass, err := k.getConvertibleCredit(ctx, source.Credit)
+		if err != nil {
+			return nil, err
+		}
+		if i == 0 {
+			merged, class = metadata, sourceClass
+		} else if !compatibleCredits(merged, metadata) {
+			return nil, ErrIncompatibleCredits(DefaultCodespace, fmt.Sprintf("credit %s is not compatible with credit %s", source.Credit, sources[0].Credit))
+		}
+		if err := class.CheckPrecision(source.Units); err != nil {
+			return nil, err
+		}
+		if polygons[i], err = geo.Unmarshal(metadata.GeoPolygon); err != nil {
+			return nil, ErrInvalidGeoPolygon(DefaultCodespace, err)
+		}
+		parents[i] = source.Credit
+		total = total.Add(source.Units)
+	}
+	tiles, err := polygonsTile(ctx, whole, polygons)
+	if err != nil {
+		return nil, err
+	}
+	if !tiles {
+		return nil, ErrInvalidTiling(DefaultCodespace, "the polygons of the credits must tile the merged polygon")
+	}
+	sort.Slice(parents, func(i, j int) bool {
+		return bytes.Compare(parents[i], parents[j]) < 0
+	})
+
+	var result CreditID
+	for i, source := range sources {
+		children, err := k.existingChildren(ctx, source.Credit, func(lineage CreditLineage, child CreditMetadata) bool {
+			return sameCredits(lineage.Parents, parents) && bytes.Equal(child.GeoPolygon, geoPolygon)
+		})
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case i > 0 && (children == nil) != (result == nil):
+			return nil, ErrInvalidTiling(DefaultCodespace, fmt.Sprintf("credit %s was already split or merged with other credits", source.Credit))
+		case children != nil:
+			result = children[0].ID
+		}
+	}
+	if result == nil {
+		merged.GeoPolygon = geoPolygon
+		if result, err = k.createDerivedCredit(ctx, merged, class, parents); err != nil {
+			return nil, err
+		}
+	}
+	return k.convertCredits(ctx, holder, sources, []CreditUnits{{Credit: result, Units: total}}, EventTypeMergeCredits)
+}
+
+// polygonsTile checks whether the polygons tile the whole polygon, see geo.Tiles. A conversion compares up to
+// MaxConversionParts polygons of up to geo.MaxVertices vertices each, so every polygon is triangulated once and gas is
+// consumed for the triangulations and for every pair of triangles tested
+func polygonsTile(ctx sdk.Context, whole geo.Polygon, polygons []geo.Polygon) (bool, error) {
+	parts := make([]geo.Triangulation, len(polygons))
+	for i, polygon := range polygons {
+		var err error
+		if parts[i], err = triangulate(ctx, polygon); err != nil {
+			return false, err
+		}
+	}
+	tiles, err := geo.Tiles(whole, parts, meterTrianglePairs(ctx))
+	if err != nil {
+		return false, ErrInvalidGeoPolygon(DefaultCodespace, err)
+	}
+	return tiles, nil
+}
+
+// getConvertibleCredit gets a credit which can be split or merged together with its class. Vouchers can only be
+// split and merged on the origin chain of their credit and units of expired credits can't be converted
+func (k Keeper) getConvertibleCredit(ctx sdk.Context, credit CreditID) (CreditMetadata, CreditClassMetadata, error) {
+	metadata, found := k.GetCredit(ctx, credit)
+	if !found {
+		return metadata, CreditClassMetadata{}, ErrInvalidCredit(DefaultCodespace, fmt.Sprintf("credit %s not found", credit))
+	}
+	if _, found := k.GetCreditVoucher(ctx, credit); found {
+		return metadata, CreditClassMetadata{}, ErrIncompatibleCredits(DefaultCodespace, "vouchers can only be split and merged on the origin chain of the credit")
+	}
+	class, found := k.GetCreditClass(ctx, metadata.CreditClass)
+	if !found {
+		return metadata, class, ErrInvalidCreditClass(DefaultCodespace, fmt.Sprintf("credit class %s not found", metadata.CreditClass))
+	}
+	if err := k.checkCreditNotExpired(ctx, credit); err != nil {
+		return metadata, class, err
+	}
+	return metadata, class, nil
+}
+
+// existingChildren returns the credits derived from the credit before, or nil if there are none. It fails if any of
+// them doesn't match, i.e. if the credit was derived differently before
+func (k Keeper) existingChildren(ctx sdk.Context, credit CreditID, match func(lineage CreditLineage, child CreditMetadata) bool) ([]Credit, error) {
+	lineage, found := k.GetCreditLineage(ctx, credit)
+	if !found || len(lineage.Children) == 0 {
+		return nil, nil
+	}
+	children := make([]Credit, len(lineage.Children))
+	for i, id := range lineage.Children {
+		child, _ := k.GetCredit(ctx, id)
+		childLineage, _ := k.GetCreditLineage(ctx, id)
+		if !match(childLineage, child) {
+			return nil, ErrInvalidTiling(DefaultCodespace, fmt.Sprintf("credit %s was already split or merged differently", credit))
+		}
+		children[i] = Credit{ID: id, Metadata: child}
+	}
+	return children, nil
+}
+
+// createDerivedCredit creates a credit derived from its parents and links them in their lineage. Its units are added
+// by convertCredits. Derived credits aren't checked for overlaps as their polygon lies within the polygons of their
+// parents, they aren't subject to the issuance rules of the class and no units are withheld in the buffer pool
+func (k Keeper) createDerivedCredit(ctx sdk.Context, metadata CreditMetadata, class CreditClassMetadata, parents []CreditID) (CreditID, error) {
+	metadata.LiquidUnits, metadata.BurnedUnits = sdk.ZeroDec(), sdk.ZeroDec()
+	id, err := k.creditBucket.Create(ctx, metadata)
+	if err != nil {
+		return nil, err
+	}
+	if err := k.scheduleCreditExpiry(ctx, id, class, metadata); err != nil {
+		return nil, err
+	}
+	for _, parent := range parents {
+		lineage, found := k.GetCreditLineage(ctx, parent)
+		if !found {
+			lineage = CreditLineage{Credit: parent}
+		}
+		lineage.Children = append(lineage.Children, id)
+		if err := k.creditLineageBucket.Save(ctx, lineage); err != nil {
+			return nil, err
+		}
+	}
+	return id, k.creditLineageBucket.Save(ctx, CreditLineage{Credit: id, Parents: parents})
+}
+
+// convertCredits moves the source units of the holder to the converted supply of their credits and issues the result
+// units to the holder. The issued units of the derived credits are increased accordingly
+func (k Keeper) convertCredits(ctx sdk.Context, holder sdk.AccAddress, sources []CreditUnits, results []CreditUnits, eventType string) (ConversionID, error) {
+	for _, source := range sources {
+		holding, found := k.GetCreditHolding(ctx, source.Credit, holder)
+		if !found || holding.LiquidUnits.LT(source.Units) {
+			return nil, ErrInvalidUnits(DefaultCodespace, fmt.Sprintf("%s doesn't hold %s liquid units of credit %s", holder, source.Units, source.Credit))
+		}
+		holding.LiquidUnits = holding.LiquidUnits.Sub(source.Units)
+		if err := k.creditHoldingsBucket.Save(ctx, holding); err != nil {
+			return nil, err
+		}
+		if err := k.convertCreditSupply(ctx, source.Credit, source.Units); err != nil {
+			return nil, err
+		}
+	}
+	for _, result := range results {
+		metadata, found := k.GetCredit(ctx, result.Credit)
+		if !found {
+			return nil, ErrInvalidCredit(DefaultCodespace, fmt.Sprintf("credit %s not found", result.Credit))
+		}
+		metadata.LiquidUnits = metadata.LiquidUnits.Add(result.Units)
+		if err := k.creditBucket.Save(ctx, result.Credit, metadata); err != nil {
+			return nil, err
+		}
+		holding, found := k.GetCreditHolding(ctx, result.Credit, holder)
+		if !found {
+			holding = CreditHolding{Credit: result.Credit, Holder: holder, LiquidUnits: sdk.ZeroDec(), BurnedUnits: sdk.ZeroDec()}
+		}
+		holding.LiquidUnits = holding.LiquidUnits.Add(result.Units)
+		if err := k.creditHoldingsBucket.Save(ctx, holding); err != nil {
+			return nil, err
+		}
+		if err := k.addCreditSupply(ctx, result.Credit, result.Units, sdk.ZeroDec()); err != nil {
+			return nil, err
+		}
+	}
+	id, err := k.conversionBucket.Create(ctx, Conversion{
+		Holder:    holder,
+		Sources:   sources,
+		Results:   results,
+		Timestamp: ctx.BlockHeader().Time,
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, source := range sources {
+		ctx.EventManager().EmitEvent(sdk.NewEvent(
+			eventType,
+			sdk.NewAttribute(AttributeKeyConversion, ConversionID(id).String()),
+			sdk.NewAttribute(AttributeKeyCredit, source.Credit.String()),
+			sdk.NewAttribute(AttributeKeyHolder, holder.String()),
+			sdk.NewAttribute(AttributeKeyUnits, source.Units.String()),
+		))
+	}
+	for _, result := range results {
+		ctx.EventManager().EmitEvent(sdk.NewEvent(
+			EventTypeDeriveCredit,
+			sdk.NewAttribute(AttributeKeyConversion, ConversionID(id).String()),
+			sdk.NewAttribute(AttributeKeyCredit, result.Credit.String()),
+			sdk.NewAttribute(AttributeKeyHolder, holder.String()),
+			sdk.NewAttribute(AttributeKeyUnits, result.Units.String()),
+		))
+	}
+	return id, nil
+}
+
+// apportionByArea splits units over the parts of the whole polygon pro rata by their exact area. Shares are rounded
+// down to the precision and the indivisible parts left over go one each to the parts in order
+func apportionByArea(whole geo.Polygon, parts []geo.Polygon, precision uint32, units sdk.Dec) ([]sdk.Dec, error) {
+	scaled := units.MulInt(precisionScale(precision)).TruncateInt().BigInt()
+	area := whole.ExactArea()
+	shares := make([]*big.Int, len(parts))
+	leftover := new(big.Int).Set(scaled)
+	for i, part := range parts {
+		shares[i] = new(big.Int).Mul(scaled, part.ExactArea())
+		shares[i].Quo(shares[i], area)
+		leftover.Sub(leftover, shares[i])
+	}
+	res := make([]sdk.Dec, len(parts))
+	for i := range parts {
+		if leftover.Sign() > 0 {
+			shares[i].Add(shares[i], big.NewInt(1))
+			leftover.Sub(leftover, big.NewInt(1))
+		}
+		if shares[i].Sign() == 0 {
+			return nil, ErrInvalidUnits(DefaultCodespace, "too few units to split over all parts")
+		}
+		res[i] = sdk.NewDecFromIntWithPrec(sdk.NewIntFromBigInt(shares[i]), int64(precision))
+	}
+	return res, nil
+}
+
+// compatibleCredits checks whether two credits can be merged, i.e. whether their metadata is the same apart from
+// their polygons and units
+func compatibleCredits(a CreditMetadata, b CreditMetadata) bool {
+	normalize := func(m CreditMetadata) []byte {
+		m.GeoPolygon, m.LiquidUnits, m.BurnedUnits = nil, sdk.ZeroDec(), sdk.ZeroDec()
+		return ModuleCdc.MustMarshalBinaryBare(m)
+	}
+	return bytes.Equal(normalize(a), normalize(b))
+}
+
+// sameCredits checks whether two sorted lists of credits are the same
+func sameCredits(a []CreditID, b []CreditID) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !bytes.Equal(a[i], b[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// GetConversion gets a conversion by its ID
+func (k Keeper) GetConversion(ctx sdk.Context, id ConversionID) (conversion Conversion, found bool) {
+	err := k.conversionBucket.GetOne(ctx, id, &conversion)
+	if err != nil {
+		return conversion, false
+	}
+	return conversion, true
+}
+
+// IterateConversions iterates over all conversions in the order they were created
+func (k Keeper) IterateConversions(ctx sdk.Context, callback func(id ConversionID, conversion Conversion) (stop bool)) {
+	iterator, err := k.conversionBucket.PrefixScan(ctx, nil, nil, false)
+	if err != nil {
+		return
+	}
+	defer iterator.Release()
+	for {
+		var conversion Conversion
+		id, err := iterator.LoadNext(&conversion)
+		if err != nil {
+			break
+		}
+		if callback(id, conversion) {
+			return
+		}
+	}
+}
+
+// GetCreditLineage gets the parents and children of a credit, it is only found for credits which were split, merged
+// or derived
+func (k Keeper) GetCreditLineage(ctx sdk.Context, credit CreditID) (lineage CreditLineage, found bool) {
+	lineage = CreditLineage{Credit: credit}
+	err := k.creditLineageBucket.GetOne(ctx, &lineage)
+	if err != nil {
+		return lineage, false
+	}
+	return lineage, true
+}
+
+// IterateCreditLineages iterates over the lineages of all credits which were split, merged or derived
+func (k Keeper) IterateCreditLineages(ctx sdk.Context, callback func(lineage CreditLineage) (stop bool)) {
+	iterator, err := k.creditLineageBucket.PrefixScan(ctx, nil, nil, false)
+	if err != nil {
+		return
+	}
+	defer iterator.Release()
+	for {
+		var lineage CreditLineage
+		_, err := iterator.LoadNext(&lineage)
+		if err != nil {
+			break
+		}
+		if callback(lineage) {
+			return
+		}
+	}
+}
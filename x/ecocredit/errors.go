@@ -0,0 +1,201 @@
+package ecocredit
+
+import (
+	"fmt"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+const (
+	DefaultCodespace sdk.CodespaceType = ModuleName
+
+	CodeCreditOverlap            sdk.CodeType = 101
+	CodeInvalidGeoPolygon        sdk.CodeType = 102
+	CodeInvalidCreditClassName   sdk.CodeType = 103
+	CodeInvalidIssuers           sdk.CodeType = 104
+	CodeInvalidCreditClass       sdk.CodeType = 105
+	CodeInvalidDateRange         sdk.CodeType = 106
+	CodeInvalidUnits             sdk.CodeType = 107
+	CodeInvalidCredit            sdk.CodeType = 108
+	CodeInvalidRetirementInfo    sdk.CodeType = 109
+	CodeCreditClassDeprecated    sdk.CodeType = 110
+	CodeInvalidCreditClassSchema sdk.CodeType = 111
+	CodeInvalidCreditAttributes  sdk.CodeType = 112
+	CodeInvalidIssuances         sdk.CodeType = 113
+	CodeInvalidOutputs           sdk.CodeType = 114
+	CodeInvalidAllowance         sdk.CodeType = 115
+	CodeInsufficientAllowance    sdk.CodeType = 116
+	CodeInvalidSellOrder         sdk.CodeType = 117
+	CodeInvalidPrice             sdk.CodeType = 118
+	CodePriceExceeded            sdk.CodeType = 119
+	CodeInvalidAuction           sdk.CodeType = 120
+	CodeInvalidCreditPacket      sdk.CodeType = 121
+	CodeInvalidReversal          sdk.CodeType = 122
+	CodeInvalidData              sdk.CodeType = 123
+	CodeInvalidVerifiers         sdk.CodeType = 124
+	CodeMissingAttestations      sdk.CodeType = 125
+	CodeInvalidBond              sdk.CodeType = 126
+	CodeInsufficientBond         sdk.CodeType = 127
+	CodeCreditExpired            sdk.CodeType = 128
+	CodeInvalidTiling            sdk.CodeType = 129
+	CodeIncompatibleCredits      sdk.CodeType = 130
+)
+
+// ErrCreditOverlap is returned when a credit's polygon and dates overlap with an existing credit of the same class
+func ErrCreditOverlap(codespace sdk.CodespaceType, existing CreditID) sdk.Error {
+	return sdk.NewError(codespace, CodeCreditOverlap, fmt.Sprintf("credit overlaps with existing credit %s of the same class", existing))
+}
+
+// ErrInvalidGeoPolygon is returned when a geo-polygon can't be parsed or is not a valid polygon
+func ErrInvalidGeoPolygon(codespace sdk.CodespaceType, err error) sdk.Error {
+	return sdk.NewError(codespace, CodeInvalidGeoPolygon, fmt.Sprintf("invalid geo-polygon: %s", err))
+}
+
+// ErrInvalidCreditClassName is returned when a credit class has an empty name
+func ErrInvalidCreditClassName(codespace sdk.CodespaceType) sdk.Error {
+	return sdk.NewError(codespace, CodeInvalidCreditClassName, "credit class name can't be empty")
+}
+
+// ErrInvalidIssuers is returned when the list of issuers of a credit class is empty, contains an empty address or
+// contains duplicates
+func ErrInvalidIssuers(codespace sdk.CodespaceType, msg string) sdk.Error {
+	return sdk.NewError(codespace, CodeInvalidIssuers, msg)
+}
+
+// ErrInvalidCreditClass is returned when a credit class ID is empty or doesn't refer to an existing credit class
+func ErrInvalidCreditClass(codespace sdk.CodespaceType, msg string) sdk.Error {
+	return sdk.NewError(codespace, CodeInvalidCreditClass, msg)
+}
+
+// ErrInvalidDateRange is returned when the end date of a credit is not after its start date
+func ErrInvalidDateRange(codespace sdk.CodespaceType) sdk.Error {
+	return sdk.NewError(codespace, CodeInvalidDateRange, "end date must be after start date")
+}
+
+// ErrInvalidUnits is returned when a number of credit units is missing, negative or zero where it must be positive
+func ErrInvalidUnits(codespace sdk.CodespaceType, msg string) sdk.Error {
+	return sdk.NewError(codespace, CodeInvalidUnits, msg)
+}
+
+// ErrInvalidCredit is returned when a credit ID is empty or doesn't refer to an existing credit
+func ErrInvalidCredit(codespace sdk.CodespaceType, msg string) sdk.Error {
+	return sdk.NewError(codespace, CodeInvalidCredit, msg)
+}
+
+// ErrInvalidRetirementInfo is returned when a retirement beneficiary, jurisdiction or reason is too long
+func ErrInvalidRetirementInfo(codespace sdk.CodespaceType) sdk.Error {
+	return sdk.NewError(codespace, CodeInvalidRetirementInfo,
+		fmt.Sprintf("retirement beneficiary, jurisdiction and reason must be at most %d bytes", MaxRetirementInfoLength))
+}
+
+// ErrCreditClassDeprecated is returned when issuing credits of, or deprecating, an already deprecated credit class
+func ErrCreditClassDeprecated(codespace sdk.CodespaceType, id CreditClassID) sdk.Error {
+	return sdk.NewError(codespace, CodeCreditClassDeprecated, fmt.Sprintf("credit class %s is deprecated", id))
+}
+
+// ErrInvalidCreditClassSchema is returned when a credit class lacks a credit type or unit of measure, has an invalid
+// methodology reference or precision, or has empty or duplicate required attributes
+func ErrInvalidCreditClassSchema(codespace sdk.CodespaceType, msg string) sdk.Error {
+	return sdk.NewError(codespace, CodeInvalidCreditClassSchema, msg)
+}
+
+// ErrInvalidCreditAttributes is returned when the attributes of an issuance have empty or duplicate keys or lack an
+// attribute required by the credit class
+func ErrInvalidCreditAttributes(codespace sdk.CodespaceType, msg string) sdk.Error {
+	return sdk.NewError(codespace, CodeInvalidCreditAttributes, msg)
+}
+
+// ErrInvalidIssuances is returned when a batch issuance has no issuances or issues to the same holder twice
+func ErrInvalidIssuances(codespace sdk.CodespaceType, msg string) sdk.Error {
+	return sdk.NewError(codespace, CodeInvalidIssuances, msg)
+}
+
+// ErrInvalidOutputs is returned when a multi-send has no outputs
+func ErrInvalidOutputs(codespace sdk.CodespaceType, msg string) sdk.Error {
+	return sdk.NewError(codespace, CodeInvalidOutputs, msg)
+}
+
+// ErrInvalidAllowance is returned when approving an already expired allowance or revoking a missing allowance
+func ErrInvalidAllowance(codespace sdk.CodespaceType, msg string) sdk.Error {
+	return sdk.NewError(codespace, CodeInvalidAllowance, msg)
+}
+
+// ErrInsufficientAllowance is returned when a spender sends credits on behalf of a holder without an unexpired
+// allowance covering the units sent
+func ErrInsufficientAllowance(codespace sdk.CodespaceType, msg string) sdk.Error {
+	return sdk.NewError(codespace, CodeInsufficientAllowance, msg)
+}
+
+// ErrInvalidSellOrder is returned when a sell order doesn't exist
+func ErrInvalidSellOrder(codespace sdk.CodespaceType, msg string) sdk.Error {
+	return sdk.NewError(codespace, CodeInvalidSellOrder, msg)
+}
+
+// ErrInvalidPrice is returned when the price of a sell order or the maximum price of a buyer is empty or invalid
+func ErrInvalidPrice(codespace sdk.CodespaceType, msg string) sdk.Error {
+	return sdk.NewError(codespace, CodeInvalidPrice, msg)
+}
+
+// ErrPriceExceeded is returned when the price of a sell order exceeds the maximum price the buyer is willing to pay
+func ErrPriceExceeded(codespace sdk.CodespaceType, price sdk.Coins, maxPrice sdk.Coins) sdk.Error {
+	return sdk.NewError(codespace, CodePriceExceeded, fmt.Sprintf("price %s exceeds maximum price %s", price, maxPrice))
+}
+
+// ErrInvalidAuction is returned when an auction doesn't exist, has already ended or would end in the past
+func ErrInvalidAuction(codespace sdk.CodespaceType, msg string) sdk.Error {
+	return sdk.NewError(codespace, CodeInvalidAuction, msg)
+}
+
+// ErrInvalidCreditPacket is returned when an IBC packet can't be handled as a credit packet
+func ErrInvalidCreditPacket(codespace sdk.CodespaceType, msg string) sdk.Error {
+	return sdk.NewError(codespace, CodeInvalidCreditPacket, msg)
+}
+
+// ErrInvalidReversal is returned when a reversal doesn't exist or can't cancel the requested units
+func ErrInvalidReversal(codespace sdk.CodespaceType, msg string) sdk.Error {
+	return sdk.NewError(codespace, CodeInvalidReversal, msg)
+}
+
+// ErrInvalidData is returned when an MRV dataset is invalid, already anchored or not anchored
+func ErrInvalidData(codespace sdk.CodespaceType, msg string) sdk.Error {
+	return sdk.NewError(codespace, CodeInvalidData, msg)
+}
+
+// ErrInvalidVerifiers is returned when the verifiers of a credit class are invalid or an address isn't one of them
+func ErrInvalidVerifiers(codespace sdk.CodespaceType, msg string) sdk.Error {
+	return sdk.NewError(codespace, CodeInvalidVerifiers, msg)
+}
+
+// ErrMissingAttestations is returned when an issuance doesn't reference datasets attested by as many verifiers as
+// its credit class requires
+func ErrMissingAttestations(codespace sdk.CodespaceType, msg string) sdk.Error {
+	return sdk.NewError(codespace, CodeMissingAttestations, msg)
+}
+
+// ErrInvalidBond is returned when a bond amount, slash fraction or slashed issuance is invalid
+func ErrInvalidBond(codespace sdk.CodespaceType, msg string) sdk.Error {
+	return sdk.NewError(codespace, CodeInvalidBond, msg)
+}
+
+// ErrInsufficientBond is returned when an issuer hasn't bonded enough to issue credits of a class, to unbond or to
+// be slashed
+func ErrInsufficientBond(codespace sdk.CodespaceType, msg string) sdk.Error {
+	return sdk.NewError(codespace, CodeInsufficientBond, msg)
+}
+
+// ErrCreditExpired is returned when sending, selling, wrapping or retiring units of a credit whose validity period
+// passed
+func ErrCreditExpired(codespace sdk.CodespaceType, id CreditID) sdk.Error {
+	return sdk.NewError(codespace, CodeCreditExpired, fmt.Sprintf("credit %s expired", id))
+}
+
+// ErrInvalidTiling is returned when the parts of a split or the credits of a merge don't tile the split or merged
+// polygon, or when a credit was already split or merged differently
+func ErrInvalidTiling(codespace sdk.CodespaceType, msg string) sdk.Error {
+	return sdk.NewError(codespace, CodeInvalidTiling, msg)
+}
+
+// ErrIncompatibleCredits is returned when merging fewer than two credits or credits of different classes, vintages,
+// issuers, attributes or datasets, or when splitting or merging vouchers
+func ErrIncompatibleCredits(codespace sdk.CodespaceType, msg string) sdk.Error {
+	return sdk.NewError(codespace, CodeIncompatibleCredits, msg)
+}
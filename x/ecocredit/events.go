@@ -0,0 +1,152 @@
+package ecocredit
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+)
+
+/*
+Events
+
+Every message of the module results in the events below in addition to a message event with the module and sender
+attributes, and the end blocker emits the events of cleared and cancelled auctions, expired credits and completed bond
+unbondings.
+All IDs are bech32 encoded with the prefixes in id.go, see e.g. CreditClassID.String and CreditID.String, addresses
+are bech32 account addresses and data hashes are hex encoded. Units are decimals, prices and amounts are coins.
+
+	create-credit-class             credit_class, designer
+	issue-credit                    credit_class, credit, issuer, to, units, retired_units, polygon_hash
+	send-credit                     credit, from, to, units
+	retire-credit                   retirement, credit, holder, units, beneficiary
+	approve-credit                  holder, spender, credit, units
+	revoke-credit                   holder, spender, credit
+	create-sell-order               sell_order, seller, credit, units, price
+	update-sell-order               sell_order, seller, credit, units, price
+	cancel-sell-order               sell_order, seller, credit
+	buy-credit                      sell_order, buyer, seller, credit, units, price
+	create-auction                  auction, seller, credit, units, price, end_time
+	place-bid                       auction, bid, bidder, credit, units, price
+	clear-auction                   auction, clearing_price, units_sold
+	cancel-auction                  auction, reason
+	wrap-credit                     credit, holder, units, amount
+	unwrap-credit                   credit, holder, units, amount
+	send-credit-packet              credit, units, receiver
+	recv-credit-packet              success, credit, units, receiver
+	refund-credit-packet            credit, units
+	reverse-credit                  reversal, credit, units, reason
+	cancel-credit                   reversal, credit, holder, units
+	expire-credit                   credit, holder, units
+	split-credit                    conversion, credit, holder, units
+	merge-credits                   conversion, credit, holder, units
+	derive-credit                   conversion, credit, holder, units
+	anchor-data                     data_hash, uri, media_type
+	attest-data                     data_hash, credit_class, verifier
+	post-bond                       credit_class, issuer, amount
+	unbond-bond                     credit_class, issuer, amount, completion_time
+	complete-bond-unbonding         credit_class, issuer, amount
+	slash-bond                      credit_class, issuer, credit, fraction, amount, reason
+	add-credit-class-issuer         credit_class, issuer
+	remove-credit-class-issuer      credit_class, issuer
+	add-credit-class-verifier       credit_class, verifier
+	remove-credit-class-verifier    credit_class, verifier
+	transfer-credit-class-designer  credit_class, designer, new_designer
+	deprecate-credit-class          credit_class
+
+send-credit is emitted for every move of liquid units between holdings, including the escrow holdings of sell orders,
+auctions, wrapped coins and IBC transfers, with the escrow address as from or to. issue-credit carries the units
+a holder receives, the units withheld for the buffer pool of the class are issued to the buffer pool address in an
+issue-credit event of their own. polygon_hash is the hex encoded SHA-256 hash of the geo polygon of the credit in
+its canonical varint encoding, as returned by geo.Polygon.Marshal. Subscribers of the Tendermint websocket select
+events with queries such as
+
+	tm.event = 'Tx' AND issue-credit.credit_class = 'ecocls1...'
+	tm.event = 'Tx' AND send-credit.to = 'cosmos1...'
+	tm.event = 'Tx' AND retire-credit.credit = 'ecocrd1...'
+*/
+
+// ecocredit module event types and attribute keys
+const (
+	EventTypeCreateCreditClass           = "create-credit-class"
+	EventTypeIssueCredit                 = "issue-credit"
+	EventTypeSendCredit                  = "send-credit"
+	EventTypeRetireCredit                = "retire-credit"
+	EventTypeApproveCredit               = "approve-credit"
+	EventTypeRevokeCredit                = "revoke-credit"
+	EventTypeCreateSellOrder             = "create-sell-order"
+	EventTypeUpdateSellOrder             = "update-sell-order"
+	EventTypeCancelSellOrder             = "cancel-sell-order"
+	EventTypeBuyCredit                   = "buy-credit"
+	EventTypeCreateAuction               = "create-auction"
+	EventTypePlaceBid                    = "place-bid"
+	EventTypeWrapCredit                  = "wrap-credit"
+	EventTypeUnwrapCredit                = "unwrap-credit"
+	EventTypeAddCreditClassIssuer        = "add-credit-class-issuer"
+	EventTypeRemoveCreditClassIssuer     = "remove-credit-class-issuer"
+	EventTypeTransferCreditClassDesigner = "transfer-credit-class-designer"
+	EventTypeDeprecateCreditClass        = "deprecate-credit-class"
+	EventTypeClearAuction                = "clear-auction"
+	EventTypeCancelAuction               = "cancel-auction"
+	EventTypeSendCreditPacket            = "send-credit-packet"
+	EventTypeReceiveCreditPacket         = "recv-credit-packet"
+	EventTypeRefundCreditPacket          = "refund-credit-packet"
+	EventTypeReverseCredit               = "reverse-credit"
+	EventTypeCancelCredit                = "cancel-credit"
+	EventTypeAddCreditClassVerifier      = "add-credit-class-verifier"
+	EventTypeRemoveCreditClassVerifier   = "remove-credit-class-verifier"
+	EventTypeAnchorData                  = "anchor-data"
+	EventTypeAttestData                  = "attest-data"
+	EventTypePostBond                    = "post-bond"
+	EventTypeUnbondBond                  = "unbond-bond"
+	EventTypeCompleteBondUnbonding       = "complete-bond-unbonding"
+	EventTypeSlashBond                   = "slash-bond"
+	EventTypeExpireCredit                = "expire-credit"
+	EventTypeSplitCredit                 = "split-credit"
+	EventTypeMergeCredits                = "merge-credits"
+	EventTypeDeriveCredit                = "derive-credit"
+
+	AttributeKeyCreditClass    = "credit_class"
+	AttributeKeyDesigner       = "designer"
+	AttributeKeyNewDesigner    = "new_designer"
+	AttributeKeyIssuer         = "issuer"
+	AttributeKeyAuction        = "auction"
+	AttributeKeyClearingPrice  = "clearing_price"
+	AttributeKeyUnitsSold      = "units_sold"
+	AttributeKeyCredit         = "credit"
+	AttributeKeyUnits          = "units"
+	AttributeKeyReceiver       = "receiver"
+	AttributeKeySuccess        = "success"
+	AttributeKeyReversal       = "reversal"
+	AttributeKeyHolder         = "holder"
+	AttributeKeyReason         = "reason"
+	AttributeKeyVerifier       = "verifier"
+	AttributeKeyDataHash       = "data_hash"
+	AttributeKeyURI            = "uri"
+	AttributeKeyMediaType      = "media_type"
+	AttributeKeyAmount         = "amount"
+	AttributeKeyFraction       = "fraction"
+	AttributeKeyCompletionTime = "completion_time"
+	AttributeKeyConversion     = "conversion"
+	AttributeKeyFrom           = "from"
+	AttributeKeyTo             = "to"
+	AttributeKeyRetiredUnits   = "retired_units"
+	AttributeKeyPolygonHash    = "polygon_hash"
+	AttributeKeyRetirement     = "retirement"
+	AttributeKeyBeneficiary    = "beneficiary"
+	AttributeKeySpender        = "spender"
+	AttributeKeySellOrder      = "sell_order"
+	AttributeKeySeller         = "seller"
+	AttributeKeyBuyer          = "buyer"
+	AttributeKeyPrice          = "price"
+	AttributeKeyBid            = "bid"
+	AttributeKeyBidder         = "bidder"
+	AttributeKeyEndTime        = "end_time"
+
+	AttributeValueCategory = ModuleName
+)
+
+// polygonHash returns the hex encoded SHA-256 hash of the canonical encoding of a geo polygon, which identifies the
+// area of a credit in events without repeating the polygon
+func polygonHash(geoPolygon []byte) string {
+	hash := sha256.Sum256(geoPolygon)
+	return hex.EncodeToString(hash[:])
+}
@@ -0,0 +1,256 @@
+package ecocredit
+
+import (
+	"fmt"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"time"
+)
+
+// CreditExpiry schedules the expiry of a credit of a class with a validity period, see SweepExpiredCredits. It is
+// removed once the credit expired
+type CreditExpiry struct {
+	Credit     CreditID  `json:"credit"`
+	ExpiryTime time.Time `json:"expiry_time"`
+}
+
+func (e CreditExpiry) ID() []byte {
+	return e.Credit
+}
+
+// ExpiredHolding records the units of a credit which expired while the holder held them as liquid units. Expired
+// units can't be sent, sold, wrapped or retired anymore
+type ExpiredHolding struct {
+	Credit CreditID       `json:"credit"`
+	Holder sdk.AccAddress `json:"holder"`
+	Units  sdk.Dec        `json:"units"`
+}
+
+func (h ExpiredHolding) ID() []byte {
+	return []byte(fmt.Sprintf("%x/%x", h.Credit, h.Holder))
+}
+
+// CreditExpiryStatus is the result of the expiry query
+type CreditExpiryStatus struct {
+	Credit CreditID `json:"credit"`
+	// Expires is whether the class of the credit limits its validity, ExpiryTime is only set if it does
+	Expires    bool      `json:"expires"`
+	ExpiryTime time.Time `json:"expiry_time"`
+	Expired    bool      `json:"expired"`
+	// Holdings are the units of the credit which expired, by holder
+	Holdings []ExpiredHolding `json:"holdings"`
+}
+
+// ExpiryTime returns when credits of the class with the end date expire, which is never for classes without a
+// validity period
+func (m CreditClassMetadata) ExpiryTime(endDate time.Time) (time.Time, bool) {
+	if m.ValidityPeriod == 0 {
+		return time.Time{}, false
+	}
+	return endDate.Add(m.ValidityPeriod), true
+}
+
+// scheduleCreditExpiry schedules the expiry of a new credit if its class has a validity period
+func (k Keeper) scheduleCreditExpiry(ctx sdk.Context, credit CreditID, class CreditClassMetadata, metadata CreditMetadata) error {
+	expiry, expires := class.ExpiryTime(metadata.EndDate)
+	if !expires {
+		return nil
+	}
+	return k.creditExpiryBucket.Save(ctx, CreditExpiry{Credit: credit, ExpiryTime: expiry})
+}
+
+// isCreditExpired returns whether the validity period of the credit passed by the time of the current block
+func (k Keeper) isCreditExpired(ctx sdk.Context, credit CreditID) bool {
+	metadata, found := k.GetCredit(ctx, credit)
+	if !found {
+		return false
+	}
+	class, found := k.GetCreditClass(ctx, metadata.CreditClass)
+	if !found {
+		return false
+	}
+	expiry, expires := class.ExpiryTime(metadata.EndDate)
+	return expires && !ctx.BlockHeader().Time.Before(expiry)
+}
+
+// checkCreditNotExpired fails for credits whose validity period passed
+func (k Keeper) checkCreditNotExpired(ctx sdk.Context, credit CreditID) error {
+	if k.isCreditExpired(ctx, credit) {
+		return ErrCreditExpired(DefaultCodespace, credit)
+	}
+	return nil
+}
+
+// SweepExpiredCredits expires the liquid units of all credits whose validity period passed by the time of the
+// current block. Units held in escrow for sell orders, auctions and wrapped coins and the buffer pool are left in
+// place as they back open offers and coins, they expire as soon as they are released to a holder, see
+// transferCredit. Units escrowed for other chains expire with the credit, as their vouchers expire on the other chain.
+// It is called at the end of every block, so a credit which fails to be swept doesn't halt the chain: it is logged
+// and skipped, and retried at the next block
+func (k Keeper) SweepExpiredCredits(ctx sdk.Context) {
+	end := sdk.FormatTimeBytes(ctx.BlockHeader().Time.Add(time.Nanosecond))
+	iterator, err := k.creditExpiryBucket.ByIndexPrefixScan(ctx, IndexByEndTime, nil, end, false)
+	if err != nil {
+		k.Logger(ctx).Error(fmt.Sprintf("failed to load credit expiries: %s", err))
+		return
+	}
+	// the expiries are collected first as sweeping them removes them from the index being iterated
+	var expiries []CreditExpiry
+	for {
+		var expiry CreditExpiry
+		_, err := iterator.LoadNext(&expiry)
+		if err != nil {
+			break
+		}
+		expiries = append(expiries, expiry)
+	}
+	iterator.Release()
+	for _, expiry := range expiries {
+		// each credit is swept in a cache context so that a failure leaves no partial state behind
+		cacheCtx, write := ctx.CacheContext()
+		if err := k.sweepExpiredCredit(cacheCtx, expiry); err != nil {
+			k.Logger(ctx).Error(fmt.Sprintf("failed to sweep expired credit %s: %s", expiry.Credit, err))
+			continue
+		}
+		write()
+		// the cache context has its own event manager
+		ctx.EventManager().EmitEvents(cacheCtx.EventManager().Events())
+	}
+}
+
+// sweepExpiredCredit expires the liquid units of all holders of an expired credit outside of escrow and removes its
+// scheduled expiry
+func (k Keeper) sweepExpiredCredit(ctx sdk.Context, expiry CreditExpiry) error {
+	var holdings []CreditHolding
+	k.IterateHoldersOfCredit(ctx, expiry.Credit, func(holding CreditHolding) (stop bool) {
+		if !isEscrowAddress(holding.Holder) && holding.LiquidUnits.IsPositive() {
+			holdings = append(holdings, holding)
+		}
+		return false
+	})
+	for _, holding := range holdings {
+		if err := k.expireHolding(ctx, holding); err != nil {
+			return err
+		}
+	}
+	return k.creditExpiryBucket.Delete(ctx, expiry)
+}
+
+// expireHolding moves all liquid units of the holding to the expired holding of the holder and to the expired supply
+// of the credit
+func (k Keeper) expireHolding(ctx sdk.Context, holding CreditHolding) error {
+	units := holding.LiquidUnits
+	if !units.IsPositive() {
+		return nil
+	}
+	holding.LiquidUnits = sdk.ZeroDec()
+	if err := k.creditHoldingsBucket.Save(ctx, holding); err != nil {
+		return err
+	}
+	expired, found := k.GetExpiredHolding(ctx, holding.Credit, holding.Holder)
+	if !found {
+		expired = ExpiredHolding{Credit: holding.Credit, Holder: holding.Holder, Units: sdk.ZeroDec()}
+	}
+	expired.Units = expired.Units.Add(units)
+	if err := k.expiredHoldingBucket.Save(ctx, expired); err != nil {
+		return err
+	}
+	if err := k.expireCreditSupply(ctx, holding.Credit, units); err != nil {
+		return err
+	}
+	ctx.EventManager().EmitEvent(sdk.NewEvent(
+		EventTypeExpireCredit,
+		sdk.NewAttribute(AttributeKeyCredit, holding.Credit.String()),
+		sdk.NewAttribute(AttributeKeyHolder, holding.Holder.String()),
+		sdk.NewAttribute(AttributeKeyUnits, units.String()),
+	))
+	return nil
+}
+
+// GetExpiredHolding gets the expired units of a credit of a holder
+func (k Keeper) GetExpiredHolding(ctx sdk.Context, credit CreditID, holder sdk.AccAddress) (holding ExpiredHolding, found bool) {
+	holding = ExpiredHolding{Credit: credit, Holder: holder}
+	err := k.expiredHoldingBucket.GetOne(ctx, &holding)
+	if err != nil {
+		return holding, false
+	}
+	return holding, true
+}
+
+// GetCreditExpiryStatus gets when the credit expires, whether it expired and its expired units
+func (k Keeper) GetCreditExpiryStatus(ctx sdk.Context, credit CreditID) (status CreditExpiryStatus, found bool) {
+	metadata, found := k.GetCredit(ctx, credit)
+	if !found {
+		return status, false
+	}
+	class, found := k.GetCreditClass(ctx, metadata.CreditClass)
+	if !found {
+		return status, false
+	}
+	status = CreditExpiryStatus{Credit: credit, Holdings: []ExpiredHolding{}}
+	status.ExpiryTime, status.Expires = class.ExpiryTime(metadata.EndDate)
+	status.Expired = status.Expires && !ctx.BlockHeader().Time.Before(status.ExpiryTime)
+	k.IterateExpiredHoldingsOfCredit(ctx, credit, func(holding ExpiredHolding) (stop bool) {
+		status.Holdings = append(status.Holdings, holding)
+		return false
+	})
+	return status, true
+}
+
+// IterateExpiredHoldings iterates over the expired units of all credits of all holders
+func (k Keeper) IterateExpiredHoldings(ctx sdk.Context, callback func(holding ExpiredHolding) (stop bool)) {
+	iterator, err := k.expiredHoldingBucket.PrefixScan(ctx, nil, nil, false)
+	if err != nil {
+		return
+	}
+	defer iterator.Release()
+	for {
+		var holding ExpiredHolding
+		_, err := iterator.LoadNext(&holding)
+		if err != nil {
+			break
+		}
+		if callback(holding) {
+			return
+		}
+	}
+}
+
+// IterateExpiredHoldingsOfCredit iterates over the expired units of the credit by holder
+func (k Keeper) IterateExpiredHoldingsOfCredit(ctx sdk.Context, credit CreditID, callback func(holding ExpiredHolding) (stop bool)) {
+	iterator, err := k.expiredHoldingBucket.ByIndex(ctx, IndexByCredit, credit)
+	if iterator != nil {
+		defer iterator.Release()
+	}
+	if err != nil {
+		return
+	}
+	for {
+		var holding ExpiredHolding
+		_, err := iterator.LoadNext(&holding)
+		if err != nil {
+			break
+		}
+		if callback(holding) {
+			return
+		}
+	}
+}
+
+// IterateCreditExpiries iterates over the scheduled expiries of the credits which didn't expire yet
+func (k Keeper) IterateCreditExpiries(ctx sdk.Context, callback func(expiry CreditExpiry) (stop bool)) {
+	iterator, err := k.creditExpiryBucket.PrefixScan(ctx, nil, nil, false)
+	if err != nil {
+		return
+	}
+	defer iterator.Release()
+	for {
+		var expiry CreditExpiry
+		_, err := iterator.LoadNext(&expiry)
+		if err != nil {
+			break
+		}
+		if callback(expiry) {
+			return
+		}
+	}
+}
@@ -0,0 +1,960 @@
+package ecocredit
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+// GenesisState is the state of the ecocredit module exported to and imported from genesis. The sequences are the
+// sequence numbers of the next generated IDs of each kind
+type GenesisState struct {
+	CreditClasses         []CreditClass           `json:"credit_classes"`
+	Credits               []Credit                `json:"credits"`
+	Holdings              []CreditHolding         `json:"holdings"`
+	Retirements           []RetirementCertificate `json:"retirements"`
+	Allowances            []CreditAllowance       `json:"allowances"`
+	SellOrders            []SellOrderWithID       `json:"sell_orders"`
+	Auctions              []AuctionWithID         `json:"auctions"`
+	AuctionBids           []AuctionBidWithID      `json:"auction_bids"`
+	CreditVouchers        []CreditVoucher         `json:"credit_vouchers"`
+	CreditClassVouchers   []CreditClassVoucher    `json:"credit_class_vouchers"`
+	CreditEscrows         []CreditEscrow          `json:"credit_escrows"`
+	Reversals             []ReversalRecord        `json:"reversals"`
+	AnchoredData          []AnchoredData          `json:"anchored_data"`
+	Attestations          []Attestation           `json:"attestations"`
+	Bonds                 []IssuerBond            `json:"bonds"`
+	BondUnbondings        []BondUnbondingWithID   `json:"bond_unbondings"`
+	CreditExpiries        []CreditExpiry          `json:"credit_expiries"`
+	ExpiredHoldings       []ExpiredHolding        `json:"expired_holdings"`
+	Conversions           []ConversionRecord      `json:"conversions"`
+	CreditLineages        []CreditLineage         `json:"credit_lineages"`
+	Issuances             []Issuance              `json:"issuances"`
+	Transfers             []TransferRecord        `json:"transfers"`
+	CreditClassSequence   uint64                  `json:"credit_class_sequence"`
+	CreditSequence        uint64                  `json:"credit_sequence"`
+	RetirementSequence    uint64                  `json:"retirement_sequence"`
+	SellOrderSequence     uint64                  `json:"sell_order_sequence"`
+	AuctionSequence       uint64                  `json:"auction_sequence"`
+	AuctionBidSequence    uint64                  `json:"auction_bid_sequence"`
+	ReversalSequence      uint64                  `json:"reversal_sequence"`
+	BondUnbondingSequence uint64                  `json:"bond_unbonding_sequence"`
+	ConversionSequence    uint64                  `json:"conversion_sequence"`
+	TransferSequence      uint64                  `json:"transfer_sequence"`
+}
+
+// DefaultGenesisState returns a genesis state without any credit classes or credits
+func DefaultGenesisState() GenesisState {
+	return GenesisState{
+		CreditClasses:       []CreditClass{},
+		Credits:             []Credit{},
+		Holdings:            []CreditHolding{},
+		Retirements:         []RetirementCertificate{},
+		Allowances:          []CreditAllowance{},
+		SellOrders:          []SellOrderWithID{},
+		Auctions:            []AuctionWithID{},
+		AuctionBids:         []AuctionBidWithID{},
+		CreditVouchers:      []CreditVoucher{},
+		CreditClassVouchers: []CreditClassVoucher{},
+		CreditEscrows:       []CreditEscrow{},
+		Reversals:           []ReversalRecord{},
+		AnchoredData:        []AnchoredData{},
+		Attestations:        []Attestation{},
+		Bonds:               []IssuerBond{},
+		BondUnbondings:      []BondUnbondingWithID{},
+		CreditExpiries:      []CreditExpiry{},
+		ExpiredHoldings:     []ExpiredHolding{},
+		Conversions:         []ConversionRecord{},
+		CreditLineages:      []CreditLineage{},
+		Issuances:           []Issuance{},
+		Transfers:           []TransferRecord{},
+	}
+}
+
+// ValidateGenesis checks that every entry of the genesis state is valid, that IDs are unique and were generated
+// before the corresponding sequence, that credits, holdings and retirements refer to existing classes and credits, and
+// that the holdings of each credit, its expired holdings and the units cancelled by its reversals and converted by
+// splits and merges add up to exactly the units issued and the units in escrow to the units offered by sell orders
+// and auctions. The holdings of vouchers of credits of other chains add up to the units received over IBC instead,
+// which are not part of the genesis state
+func ValidateGenesis(data GenesisState) error {
+	classes := make(map[string]bool, len(data.CreditClasses))
+	for _, class := range data.CreditClasses {
+		if err := validateGenesisID(class.ID, data.CreditClassSequence); err != nil {
+			return fmt.Errorf("credit class %s: %s", class.ID, err)
+		}
+		if classes[string(class.ID)] {
+			return fmt.Errorf("duplicate credit class %s", class.ID)
+		}
+		classes[string(class.ID)] = true
+		if err := class.Metadata.validate(); err != nil {
+			return err
+		}
+	}
+
+	issued := make(map[string]sdk.Dec, len(data.Credits))
+	for _, credit := range data.Credits {
+		if err := validateGenesisID(credit.ID, data.CreditSequence); err != nil {
+			return fmt.Errorf("credit %s: %s", credit.ID, err)
+		}
+		if _, found := issued[string(credit.ID)]; found {
+			return fmt.Errorf("duplicate credit %s", credit.ID)
+		}
+		if err := credit.Metadata.validate(); err != nil {
+			return err
+		}
+		if credit.Metadata.BurnedUnits.IsNil() {
+			return fmt.Errorf("credit %s: burned units can't be empty", credit.ID)
+		}
+		if !classes[string(credit.Metadata.CreditClass)] {
+			return fmt.Errorf("credit %s: credit class %s not found", credit.ID, credit.Metadata.CreditClass)
+		}
+		issued[string(credit.ID)] = credit.Metadata.LiquidUnits.Add(credit.Metadata.BurnedUnits)
+	}
+
+	vouchers, err := validateGenesisVouchers(data, classes, issued)
+	if err != nil {
+		return err
+	}
+	reversed, err := validateGenesisReversals(data, issued, vouchers)
+	if err != nil {
+		return err
+	}
+	if err := validateGenesisData(data, classes, vouchers); err != nil {
+		return err
+	}
+	if err := validateGenesisBonds(data, classes); err != nil {
+		return err
+	}
+	expired, err := validateGenesisExpiries(data, issued)
+	if err != nil {
+		return err
+	}
+	converted, err := validateGenesisConversions(data, issued, vouchers)
+	if err != nil {
+		return err
+	}
+	if err := validateGenesisRecords(data, issued); err != nil {
+		return err
+	}
+
+	held := make(map[string]sdk.Dec, len(data.Credits))
+	burned := make(map[string]sdk.Dec, len(data.Holdings))
+	escrowed := make(map[string]sdk.Dec)
+	auctioned := make(map[string]sdk.Dec)
+	for _, holding := range data.Holdings {
+		if _, found := issued[string(holding.Credit)]; !found {
+			return fmt.Errorf("holding of unknown credit %s", holding.Credit)
+		}
+		if holding.Holder.Empty() {
+			return fmt.Errorf("holding of credit %s: holder can't be empty", holding.Credit)
+		}
+		if holding.LiquidUnits.IsNil() || holding.LiquidUnits.IsNegative() ||
+			holding.BurnedUnits.IsNil() || holding.BurnedUnits.IsNegative() {
+			return fmt.Errorf("holding of credit %s by %s: units must be non-negative", holding.Credit, holding.Holder)
+		}
+		if _, found := burned[string(holding.ID())]; found {
+			return fmt.Errorf("duplicate holding of credit %s by %s", holding.Credit, holding.Holder)
+		}
+		burned[string(holding.ID())] = holding.BurnedUnits
+		total, found := held[string(holding.Credit)]
+		if !found {
+			total = sdk.ZeroDec()
+		}
+		held[string(holding.Credit)] = total.Add(holding.LiquidUnits).Add(holding.BurnedUnits)
+		if holding.Holder.Equals(SellOrderEscrowAddress) {
+			escrowed[string(holding.Credit)] = holding.LiquidUnits
+		}
+		if holding.Holder.Equals(AuctionEscrowAddress) {
+			auctioned[string(holding.Credit)] = holding.LiquidUnits
+		}
+	}
+	for _, credit := range data.Credits {
+		total, found := held[string(credit.ID)]
+		if !found {
+			total = sdk.ZeroDec()
+		}
+		if units, found := reversed[string(credit.ID)]; found {
+			total = total.Add(units)
+		}
+		if units, found := expired[string(credit.ID)]; found {
+			total = total.Add(units)
+		}
+		if units, found := converted[string(credit.ID)]; found {
+			total = total.Add(units)
+		}
+		if !total.Equal(issued[string(credit.ID)]) && !vouchers[string(credit.ID)] {
+			return fmt.Errorf("credit %s: holdings add up to %s units but %s were issued", credit.ID, total, issued[string(credit.ID)])
+		}
+	}
+
+	retirements := make(map[string]bool, len(data.Retirements))
+	for _, certificate := range data.Retirements {
+		retirement := certificate.Retirement
+		if err := validateGenesisID(certificate.ID, data.RetirementSequence); err != nil {
+			return fmt.Errorf("retirement %s: %s", certificate.ID, err)
+		}
+		if retirements[string(certificate.ID)] {
+			return fmt.Errorf("duplicate retirement %s", certificate.ID)
+		}
+		retirements[string(certificate.ID)] = true
+		if retirement.Units.IsNil() || !retirement.Units.IsPositive() {
+			return fmt.Errorf("retirement %s: units must be positive", certificate.ID)
+		}
+		// retired units can never exceed the units burned by the holder, the difference being units burned at issuance
+		holding := CreditHolding{Credit: retirement.Credit, Holder: retirement.Holder}
+		remaining, found := burned[string(holding.ID())]
+		if !found {
+			return fmt.Errorf("retirement %s: no holding of credit %s by %s", certificate.ID, retirement.Credit, retirement.Holder)
+		}
+		remaining = remaining.Sub(retirement.Units)
+		if remaining.IsNegative() {
+			return fmt.Errorf("retirement %s: more units retired than burned by %s", certificate.ID, retirement.Holder)
+		}
+		burned[string(holding.ID())] = remaining
+	}
+
+	allowances := make(map[string]bool, len(data.Allowances))
+	for _, allowance := range data.Allowances {
+		if allowance.Holder.Empty() || allowance.Spender.Empty() {
+			return fmt.Errorf("allowance holder and spender can't be empty")
+		}
+		if allowances[string(allowance.ID())] {
+			return fmt.Errorf("duplicate allowance of %s for %s", allowance.Spender, allowance.Holder)
+		}
+		allowances[string(allowance.ID())] = true
+		if allowance.Units.IsNil() || !allowance.Units.IsPositive() {
+			return fmt.Errorf("allowance of %s for %s: units must be positive", allowance.Spender, allowance.Holder)
+		}
+		if _, found := issued[string(allowance.Credit)]; len(allowance.Credit) != 0 && !found {
+			return fmt.Errorf("allowance of %s for %s: unknown credit %s", allowance.Spender, allowance.Holder, allowance.Credit)
+		}
+	}
+
+	orders := make(map[string]bool, len(data.SellOrders))
+	for _, o := range data.SellOrders {
+		if err := validateGenesisID(o.ID, data.SellOrderSequence); err != nil {
+			return fmt.Errorf("sell order %s: %s", o.ID, err)
+		}
+		if orders[string(o.ID)] {
+			return fmt.Errorf("duplicate sell order %s", o.ID)
+		}
+		orders[string(o.ID)] = true
+		order := o.SellOrder
+		if order.Seller.Empty() {
+			return fmt.Errorf("sell order %s: seller can't be empty", o.ID)
+		}
+		if order.Units.IsNil() || !order.Units.IsPositive() {
+			return fmt.Errorf("sell order %s: units must be positive", o.ID)
+		}
+		if err := validatePrice(order.Price); err != nil {
+			return fmt.Errorf("sell order %s: %s", o.ID, err.Result().Log)
+		}
+		remaining, found := escrowed[string(order.Credit)]
+		if !found {
+			return fmt.Errorf("sell order %s: no units of credit %s in escrow", o.ID, order.Credit)
+		}
+		escrowed[string(order.Credit)] = remaining.Sub(order.Units)
+	}
+	for credit, remaining := range escrowed {
+		if !remaining.IsZero() {
+			return fmt.Errorf("credit %s: units in escrow don't match the units offered by sell orders", CreditID(credit))
+		}
+	}
+	return validateGenesisAuctions(data, auctioned)
+}
+
+// validateGenesisAuctions checks the auctions and their bids, and that the units of the auctions of each credit add
+// up to the units of the credit held in escrow for auctions
+func validateGenesisAuctions(data GenesisState, escrowed map[string]sdk.Dec) error {
+	auctions := make(map[string]Auction, len(data.Auctions))
+	for _, a := range data.Auctions {
+		if err := validateGenesisID(a.ID, data.AuctionSequence); err != nil {
+			return fmt.Errorf("auction %s: %s", a.ID, err)
+		}
+		if _, found := auctions[string(a.ID)]; found {
+			return fmt.Errorf("duplicate auction %s", a.ID)
+		}
+		auction := a.Auction
+		auctions[string(a.ID)] = auction
+		if auction.Seller.Empty() {
+			return fmt.Errorf("auction %s: seller can't be empty", a.ID)
+		}
+		if auction.Units.IsNil() || !auction.Units.IsPositive() {
+			return fmt.Errorf("auction %s: units must be positive", a.ID)
+		}
+		if err := validateCoinPrice(auction.MinPrice); err != nil {
+			return fmt.Errorf("auction %s: %s", a.ID, err.Result().Log)
+		}
+		remaining, found := escrowed[string(auction.Credit)]
+		if !found {
+			return fmt.Errorf("auction %s: no units of credit %s in escrow", a.ID, auction.Credit)
+		}
+		escrowed[string(auction.Credit)] = remaining.Sub(auction.Units)
+	}
+	for credit, remaining := range escrowed {
+		if !remaining.IsZero() {
+			return fmt.Errorf("credit %s: units in escrow don't match the units offered by auctions", CreditID(credit))
+		}
+	}
+
+	bids := make(map[string]bool, len(data.AuctionBids))
+	bidsOfAuction := make(map[string]int, len(data.Auctions))
+	for _, b := range data.AuctionBids {
+		if err := validateGenesisID(b.ID, data.AuctionBidSequence); err != nil {
+			return fmt.Errorf("auction bid %s: %s", b.ID, err)
+		}
+		if bids[string(b.ID)] {
+			return fmt.Errorf("duplicate auction bid %s", b.ID)
+		}
+		bids[string(b.ID)] = true
+		bid := b.Bid
+		auction, found := auctions[string(bid.Auction)]
+		if !found {
+			return fmt.Errorf("auction bid %s: auction %s not found", b.ID, bid.Auction)
+		}
+		if bid.Bidder.Empty() {
+			return fmt.Errorf("auction bid %s: bidder can't be empty", b.ID)
+		}
+		if bid.Units.IsNil() || !bid.Units.IsPositive() {
+			return fmt.Errorf("auction bid %s: units must be positive", b.ID)
+		}
+		if err := validateCoinPrice(bid.Price); err != nil {
+			return fmt.Errorf("auction bid %s: %s", b.ID, err.Result().Log)
+		}
+		if bid.Price.Denom != auction.MinPrice.Denom || bid.Price.IsLT(auction.MinPrice) {
+			return fmt.Errorf("auction bid %s: price is below the minimum price %s", b.ID, auction.MinPrice)
+		}
+		bidsOfAuction[string(bid.Auction)]++
+		if bidsOfAuction[string(bid.Auction)] > MaxAuctionBids {
+			return fmt.Errorf("auction %s: more than %d bids", bid.Auction, MaxAuctionBids)
+		}
+	}
+	return nil
+}
+
+// validateGenesisVouchers checks that the vouchers refer to existing classes and credits with a provenance path and
+// that the credit escrows are the escrow addresses of their channels, and returns the set of voucher credits
+func validateGenesisVouchers(data GenesisState, classes map[string]bool, issued map[string]sdk.Dec) (map[string]bool, error) {
+	classVouchers := make(map[string]bool, len(data.CreditClassVouchers))
+	for _, voucher := range data.CreditClassVouchers {
+		if !classes[string(voucher.CreditClass)] {
+			return nil, fmt.Errorf("credit class voucher: credit class %s not found", voucher.CreditClass)
+		}
+		if classVouchers[string(voucher.CreditClass)] {
+			return nil, fmt.Errorf("duplicate credit class voucher %s", voucher.CreditClass)
+		}
+		classVouchers[string(voucher.CreditClass)] = true
+		if voucher.Path == "" || len(voucher.Origin) == 0 {
+			return nil, fmt.Errorf("credit class voucher %s: path and origin can't be empty", voucher.CreditClass)
+		}
+	}
+	vouchers := make(map[string]bool, len(data.CreditVouchers))
+	for _, voucher := range data.CreditVouchers {
+		if _, found := issued[string(voucher.Credit)]; !found {
+			return nil, fmt.Errorf("credit voucher: credit %s not found", voucher.Credit)
+		}
+		if vouchers[string(voucher.Credit)] {
+			return nil, fmt.Errorf("duplicate credit voucher %s", voucher.Credit)
+		}
+		vouchers[string(voucher.Credit)] = true
+		if voucher.Path == "" || len(voucher.Origin) == 0 {
+			return nil, fmt.Errorf("credit voucher %s: path and origin can't be empty", voucher.Credit)
+		}
+	}
+	escrows := make(map[string]bool, len(data.CreditEscrows))
+	for _, escrow := range data.CreditEscrows {
+		if !escrow.Address.Equals(CreditEscrowAddress(escrow.Channel)) {
+			return nil, fmt.Errorf("credit escrow %s: not the escrow address of channel %q", escrow.Address, escrow.Channel)
+		}
+		if escrows[escrow.Channel] {
+			return nil, fmt.Errorf("duplicate credit escrow of channel %q", escrow.Channel)
+		}
+		escrows[escrow.Channel] = true
+	}
+	return vouchers, nil
+}
+
+// validateGenesisReversals checks that the reversals are of credits which aren't vouchers and that their
+// cancellations add up to the units reversed, and returns the units cancelled from each credit
+func validateGenesisReversals(data GenesisState, issued map[string]sdk.Dec, vouchers map[string]bool) (map[string]sdk.Dec, error) {
+	reversals := make(map[string]bool, len(data.Reversals))
+	reversed := make(map[string]sdk.Dec)
+	for _, record := range data.Reversals {
+		reversal := record.Reversal
+		if err := validateGenesisID(record.ID, data.ReversalSequence); err != nil {
+			return nil, fmt.Errorf("reversal %s: %s", record.ID, err)
+		}
+		if reversals[string(record.ID)] {
+			return nil, fmt.Errorf("duplicate reversal %s", record.ID)
+		}
+		reversals[string(record.ID)] = true
+		if _, found := issued[string(reversal.Credit)]; !found || vouchers[string(reversal.Credit)] {
+			return nil, fmt.Errorf("reversal %s: credit %s not found", record.ID, reversal.Credit)
+		}
+		if reversal.Reverser.Empty() {
+			return nil, fmt.Errorf("reversal %s: reverser can't be empty", record.ID)
+		}
+		if reversal.Units.IsNil() || !reversal.Units.IsPositive() {
+			return nil, fmt.Errorf("reversal %s: units must be positive", record.ID)
+		}
+		total := sdk.ZeroDec()
+		for _, cancellation := range reversal.Cancellations {
+			if _, found := issued[string(cancellation.Credit)]; !found || vouchers[string(cancellation.Credit)] {
+				return nil, fmt.Errorf("reversal %s: credit %s not found", record.ID, cancellation.Credit)
+			}
+			if cancellation.Holder.Empty() || cancellation.Units.IsNil() || !cancellation.Units.IsPositive() {
+				return nil, fmt.Errorf("reversal %s: cancellations must have a holder and positive units", record.ID)
+			}
+			total = total.Add(cancellation.Units)
+			units, found := reversed[string(cancellation.Credit)]
+			if !found {
+				units = sdk.ZeroDec()
+			}
+			reversed[string(cancellation.Credit)] = units.Add(cancellation.Units)
+		}
+		if !total.Equal(reversal.Units) {
+			return nil, fmt.Errorf("reversal %s: cancellations add up to %s units but %s were reversed", record.ID, total, reversal.Units)
+		}
+	}
+	return reversed, nil
+}
+
+// validateGenesisData checks the anchored datasets and their attestations, and that the datasets referenced by
+// credits are anchored. Vouchers reference the datasets anchored on the origin chain of their credit instead
+func validateGenesisData(data GenesisState, classes map[string]bool, vouchers map[string]bool) error {
+	anchored := make(map[string]bool, len(data.AnchoredData))
+	for _, d := range data.AnchoredData {
+		if len(d.Hash) != sha256.Size {
+			return fmt.Errorf("anchored data %x: hash must be a SHA-256 hash", d.Hash)
+		}
+		if anchored[string(d.Hash)] {
+			return fmt.Errorf("duplicate anchored data %x", d.Hash)
+		}
+		anchored[string(d.Hash)] = true
+		if d.Sender.Empty() || len(d.URI) == 0 {
+			return fmt.Errorf("anchored data %x: sender and URI can't be empty", d.Hash)
+		}
+	}
+	attestations := make(map[string]bool, len(data.Attestations))
+	for _, attestation := range data.Attestations {
+		if !anchored[string(attestation.Hash)] {
+			return fmt.Errorf("attestation of data %x which isn't anchored", attestation.Hash)
+		}
+		if !classes[string(attestation.CreditClass)] {
+			return fmt.Errorf("attestation of data %x: credit class %s not found", attestation.Hash, attestation.CreditClass)
+		}
+		if attestation.Verifier.Empty() {
+			return fmt.Errorf("attestation of data %x: verifier can't be empty", attestation.Hash)
+		}
+		if attestations[string(attestation.ID())] {
+			return fmt.Errorf("duplicate attestation of data %x by %s", attestation.Hash, attestation.Verifier)
+		}
+		attestations[string(attestation.ID())] = true
+	}
+	for _, credit := range data.Credits {
+		if vouchers[string(credit.ID)] {
+			continue
+		}
+		for _, hash := range credit.Metadata.Datasets {
+			if !anchored[string(hash)] {
+				return fmt.Errorf("credit %s: dataset %x isn't anchored", credit.ID, hash)
+			}
+		}
+	}
+	return nil
+}
+
+// validateGenesisBonds checks that the bonds and bond unbondings are of existing credit classes and that each issuer
+// has at most one bond per class. The coins of the bonds are part of the genesis state of the bank
+func validateGenesisBonds(data GenesisState, classes map[string]bool) error {
+	bonds := make(map[string]bool, len(data.Bonds))
+	for _, bond := range data.Bonds {
+		if !classes[string(bond.CreditClass)] {
+			return fmt.Errorf("bond of %s: credit class %s not found", bond.Issuer, bond.CreditClass)
+		}
+		if bond.Issuer.Empty() {
+			return fmt.Errorf("bond for credit class %s: issuer can't be empty", bond.CreditClass)
+		}
+		if !bond.Amount.IsValid() || bond.Amount.IsZero() {
+			return fmt.Errorf("bond of %s for credit class %s: invalid amount %s", bond.Issuer, bond.CreditClass, bond.Amount)
+		}
+		if bonds[string(bond.ID())] {
+			return fmt.Errorf("duplicate bond of %s for credit class %s", bond.Issuer, bond.CreditClass)
+		}
+		bonds[string(bond.ID())] = true
+	}
+	unbondings := make(map[string]bool, len(data.BondUnbondings))
+	for _, u := range data.BondUnbondings {
+		if err := validateGenesisID(u.ID, data.BondUnbondingSequence); err != nil {
+			return fmt.Errorf("bond unbonding %s: %s", u.ID, err)
+		}
+		if unbondings[string(u.ID)] {
+			return fmt.Errorf("duplicate bond unbonding %s", u.ID)
+		}
+		unbondings[string(u.ID)] = true
+		if !classes[string(u.Unbonding.CreditClass)] {
+			return fmt.Errorf("bond unbonding %s: credit class %s not found", u.ID, u.Unbonding.CreditClass)
+		}
+		if u.Unbonding.Issuer.Empty() {
+			return fmt.Errorf("bond unbonding %s: issuer can't be empty", u.ID)
+		}
+		if !u.Unbonding.Amount.IsValid() {
+			return fmt.Errorf("bond unbonding %s: invalid amount %s", u.ID, u.Unbonding.Amount)
+		}
+	}
+	return nil
+}
+
+// validateGenesisExpiries checks that the scheduled expiries and the expired holdings are of existing credits and
+// returns the expired units of each credit
+func validateGenesisExpiries(data GenesisState, issued map[string]sdk.Dec) (map[string]sdk.Dec, error) {
+	expiries := make(map[string]bool, len(data.CreditExpiries))
+	for _, expiry := range data.CreditExpiries {
+		if _, found := issued[string(expiry.Credit)]; !found {
+			return nil, fmt.Errorf("expiry of unknown credit %s", expiry.Credit)
+		}
+		if expiries[string(expiry.Credit)] {
+			return nil, fmt.Errorf("duplicate expiry of credit %s", expiry.Credit)
+		}
+		expiries[string(expiry.Credit)] = true
+	}
+	holdings := make(map[string]bool, len(data.ExpiredHoldings))
+	expired := make(map[string]sdk.Dec)
+	for _, holding := range data.ExpiredHoldings {
+		if _, found := issued[string(holding.Credit)]; !found {
+			return nil, fmt.Errorf("expired holding of unknown credit %s", holding.Credit)
+		}
+		if holding.Holder.Empty() {
+			return nil, fmt.Errorf("expired holding of credit %s: holder can't be empty", holding.Credit)
+		}
+		if holding.Units.IsNil() || !holding.Units.IsPositive() {
+			return nil, fmt.Errorf("expired holding of credit %s by %s: units must be positive", holding.Credit, holding.Holder)
+		}
+		if holdings[string(holding.ID())] {
+			return nil, fmt.Errorf("duplicate expired holding of credit %s by %s", holding.Credit, holding.Holder)
+		}
+		holdings[string(holding.ID())] = true
+		total, found := expired[string(holding.Credit)]
+		if !found {
+			total = sdk.ZeroDec()
+		}
+		expired[string(holding.Credit)] = total.Add(holding.Units)
+	}
+	return expired, nil
+}
+
+// validateGenesisConversions checks that the conversions are of credits which aren't vouchers and that their results
+// add up to their sources, that the lineages link existing credits in both directions, and returns the units
+// converted from each credit
+func validateGenesisConversions(data GenesisState, issued map[string]sdk.Dec, vouchers map[string]bool) (map[string]sdk.Dec, error) {
+	conversions := make(map[string]bool, len(data.Conversions))
+	converted := make(map[string]sdk.Dec)
+	for _, record := range data.Conversions {
+		conversion := record.Conversion
+		if err := validateGenesisID(record.ID, data.ConversionSequence); err != nil {
+			return nil, fmt.Errorf("conversion %s: %s", record.ID, err)
+		}
+		if conversions[string(record.ID)] {
+			return nil, fmt.Errorf("duplicate conversion %s", record.ID)
+		}
+		conversions[string(record.ID)] = true
+		if conversion.Holder.Empty() {
+			return nil, fmt.Errorf("conversion %s: holder can't be empty", record.ID)
+		}
+		sum := func(units []CreditUnits) (sdk.Dec, error) {
+			total := sdk.ZeroDec()
+			for _, u := range units {
+				if _, found := issued[string(u.Credit)]; !found || vouchers[string(u.Credit)] {
+					return total, fmt.Errorf("conversion %s: credit %s not found", record.ID, u.Credit)
+				}
+				if u.Units.IsNil() || !u.Units.IsPositive() {
+					return total, fmt.Errorf("conversion %s: units must be positive", record.ID)
+				}
+				total = total.Add(u.Units)
+			}
+			return total, nil
+		}
+		sources, err := sum(conversion.Sources)
+		if err != nil {
+			return nil, err
+		}
+		results, err := sum(conversion.Results)
+		if err != nil {
+			return nil, err
+		}
+		if !sources.Equal(results) {
+			return nil, fmt.Errorf("conversion %s: %s units were converted into %s units", record.ID, sources, results)
+		}
+		for _, source := range conversion.Sources {
+			units, found := converted[string(source.Credit)]
+			if !found {
+				units = sdk.ZeroDec()
+			}
+			converted[string(source.Credit)] = units.Add(source.Units)
+		}
+	}
+
+	lineages := make(map[string]CreditLineage, len(data.CreditLineages))
+	for _, lineage := range data.CreditLineages {
+		if _, found := issued[string(lineage.Credit)]; !found {
+			return nil, fmt.Errorf("lineage of unknown credit %s", lineage.Credit)
+		}
+		if _, found := lineages[string(lineage.Credit)]; found {
+			return nil, fmt.Errorf("duplicate lineage of credit %s", lineage.Credit)
+		}
+		lineages[string(lineage.Credit)] = lineage
+	}
+	linked := func(credits []CreditID, credit CreditID) bool {
+		for _, c := range credits {
+			if string(c) == string(credit) {
+				return true
+			}
+		}
+		return false
+	}
+	for _, lineage := range data.CreditLineages {
+		for _, parent := range lineage.Parents {
+			if !linked(lineages[string(parent)].Children, lineage.Credit) {
+				return nil, fmt.Errorf("lineage of credit %s: parent %s doesn't list it as a child", lineage.Credit, parent)
+			}
+		}
+		for _, child := range lineage.Children {
+			if !linked(lineages[string(child)].Parents, lineage.Credit) {
+				return nil, fmt.Errorf("lineage of credit %s: child %s doesn't list it as a parent", lineage.Credit, child)
+			}
+		}
+	}
+	return converted, nil
+}
+
+// validateGenesisRecords checks that the issuance and transfer records are of existing credits
+func validateGenesisRecords(data GenesisState, issued map[string]sdk.Dec) error {
+	issuances := make(map[string]bool, len(data.Issuances))
+	for _, issuance := range data.Issuances {
+		if _, found := issued[string(issuance.Credit)]; !found {
+			return fmt.Errorf("issuance of unknown credit %s", issuance.Credit)
+		}
+		if issuances[string(issuance.Credit)] {
+			return fmt.Errorf("duplicate issuance of credit %s", issuance.Credit)
+		}
+		issuances[string(issuance.Credit)] = true
+		for _, i := range issuance.Issuances {
+			if i.Holder.Empty() {
+				return fmt.Errorf("issuance of credit %s: holder can't be empty", issuance.Credit)
+			}
+		}
+	}
+	transfers := make(map[string]bool, len(data.Transfers))
+	for _, record := range data.Transfers {
+		transfer := record.Transfer
+		if err := validateGenesisID(record.ID, data.TransferSequence); err != nil {
+			return fmt.Errorf("transfer %s: %s", record.ID, err)
+		}
+		if transfers[string(record.ID)] {
+			return fmt.Errorf("duplicate transfer %s", record.ID)
+		}
+		transfers[string(record.ID)] = true
+		if _, found := issued[string(transfer.Credit)]; !found {
+			return fmt.Errorf("transfer %s: credit %s not found", record.ID, transfer.Credit)
+		}
+		if transfer.From.Empty() || transfer.To.Empty() {
+			return fmt.Errorf("transfer %s: from and to can't be empty", record.ID)
+		}
+		if transfer.Units.IsNil() || transfer.Units.IsNegative() {
+			return fmt.Errorf("transfer %s: units must be non-negative", record.ID)
+		}
+	}
+	return nil
+}
+
+// validateGenesisID checks that an ID is an auto-generated ID lower than the sequence of its bucket, so that no new
+// ID can collide with it after genesis
+func validateGenesisID(id []byte, sequence uint64) error {
+	if len(id) != 8 {
+		return fmt.Errorf("invalid ID")
+	}
+	if binary.BigEndian.Uint64(id) >= sequence {
+		return fmt.Errorf("ID is not lower than the sequence %d", sequence)
+	}
+	return nil
+}
+
+// InitGenesis stores the credit classes, credits, holdings, retirements, allowances, sell orders, auctions, vouchers,
+// reversals, anchored data, attestations, bonds, expiries, conversions, lineages, issuance and transfer records and
+// sequences of the genesis state. The coins locked by auction bids are part of the genesis state of the bank
+func InitGenesis(ctx sdk.Context, k Keeper, data GenesisState) {
+	for _, class := range data.CreditClasses {
+		if err := k.creditClassBucket.Save(ctx, class.ID, class.Metadata); err != nil {
+			panic(err)
+		}
+	}
+	for _, credit := range data.Credits {
+		if err := k.creditBucket.Save(ctx, credit.ID, credit.Metadata); err != nil {
+			panic(err)
+		}
+	}
+	// the supply of each credit is not part of the genesis state as it is fully determined by the holdings, the
+	// cancellations of the reversals, the expired holdings and the sources of the conversions
+	for _, holding := range data.Holdings {
+		if err := k.creditHoldingsBucket.Save(ctx, holding); err != nil {
+			panic(err)
+		}
+		if err := k.addCreditSupply(ctx, holding.Credit, holding.LiquidUnits, holding.BurnedUnits); err != nil {
+			panic(err)
+		}
+	}
+	for _, record := range data.Reversals {
+		if err := k.reversalBucket.Save(ctx, record.ID, record.Reversal); err != nil {
+			panic(err)
+		}
+		for _, cancellation := range record.Reversal.Cancellations {
+			if err := k.addCreditSupply(ctx, cancellation.Credit, cancellation.Units, sdk.ZeroDec()); err != nil {
+				panic(err)
+			}
+			if err := k.reverseCreditSupply(ctx, cancellation.Credit, cancellation.Units); err != nil {
+				panic(err)
+			}
+		}
+	}
+	for _, certificate := range data.Retirements {
+		if err := k.retirementBucket.Save(ctx, certificate.ID, certificate.Retirement); err != nil {
+			panic(err)
+		}
+	}
+	for _, allowance := range data.Allowances {
+		if err := k.creditAllowanceBucket.Save(ctx, allowance); err != nil {
+			panic(err)
+		}
+	}
+	for _, order := range data.SellOrders {
+		if err := k.sellOrderBucket.Save(ctx, order.ID, order.SellOrder); err != nil {
+			panic(err)
+		}
+	}
+	for _, auction := range data.Auctions {
+		if err := k.auctionBucket.Save(ctx, auction.ID, auction.Auction); err != nil {
+			panic(err)
+		}
+	}
+	for _, bid := range data.AuctionBids {
+		if err := k.auctionBidBucket.Save(ctx, bid.ID, bid.Bid); err != nil {
+			panic(err)
+		}
+	}
+	for _, voucher := range data.CreditVouchers {
+		if err := k.creditVoucherBucket.Save(ctx, voucher); err != nil {
+			panic(err)
+		}
+	}
+	for _, voucher := range data.CreditClassVouchers {
+		if err := k.creditClassVoucherBucket.Save(ctx, voucher); err != nil {
+			panic(err)
+		}
+	}
+	for _, escrow := range data.CreditEscrows {
+		if err := k.creditEscrowBucket.Save(ctx, escrow); err != nil {
+			panic(err)
+		}
+	}
+	for _, d := range data.AnchoredData {
+		if err := k.anchoredDataBucket.Save(ctx, d); err != nil {
+			panic(err)
+		}
+	}
+	for _, attestation := range data.Attestations {
+		if err := k.attestationBucket.Save(ctx, attestation); err != nil {
+			panic(err)
+		}
+	}
+	for _, expired := range data.ExpiredHoldings {
+		if err := k.expiredHoldingBucket.Save(ctx, expired); err != nil {
+			panic(err)
+		}
+		if err := k.addCreditSupply(ctx, expired.Credit, expired.Units, sdk.ZeroDec()); err != nil {
+			panic(err)
+		}
+		if err := k.expireCreditSupply(ctx, expired.Credit, expired.Units); err != nil {
+			panic(err)
+		}
+	}
+	for _, record := range data.Conversions {
+		if err := k.conversionBucket.Save(ctx, record.ID, record.Conversion); err != nil {
+			panic(err)
+		}
+		for _, source := range record.Conversion.Sources {
+			if err := k.addCreditSupply(ctx, source.Credit, source.Units, sdk.ZeroDec()); err != nil {
+				panic(err)
+			}
+			if err := k.convertCreditSupply(ctx, source.Credit, source.Units); err != nil {
+				panic(err)
+			}
+		}
+	}
+	for _, lineage := range data.CreditLineages {
+		if err := k.creditLineageBucket.Save(ctx, lineage); err != nil {
+			panic(err)
+		}
+	}
+	for _, issuance := range data.Issuances {
+		if err := k.issuanceBucket.Save(ctx, issuance); err != nil {
+			panic(err)
+		}
+	}
+	for _, record := range data.Transfers {
+		if err := k.transferBucket.Save(ctx, record.ID, record.Transfer); err != nil {
+			panic(err)
+		}
+	}
+	for _, expiry := range data.CreditExpiries {
+		if err := k.creditExpiryBucket.Save(ctx, expiry); err != nil {
+			panic(err)
+		}
+	}
+	for _, bond := range data.Bonds {
+		if err := k.issuerBondBucket.Save(ctx, bond); err != nil {
+			panic(err)
+		}
+	}
+	for _, u := range data.BondUnbondings {
+		if err := k.bondUnbondingBucket.Save(ctx, u.ID, u.Unbonding); err != nil {
+			panic(err)
+		}
+	}
+	k.creditClassBucket.SetSequence(ctx, data.CreditClassSequence)
+	k.creditBucket.SetSequence(ctx, data.CreditSequence)
+	k.retirementBucket.SetSequence(ctx, data.RetirementSequence)
+	k.sellOrderBucket.SetSequence(ctx, data.SellOrderSequence)
+	k.auctionBucket.SetSequence(ctx, data.AuctionSequence)
+	k.auctionBidBucket.SetSequence(ctx, data.AuctionBidSequence)
+	k.reversalBucket.SetSequence(ctx, data.ReversalSequence)
+	k.bondUnbondingBucket.SetSequence(ctx, data.BondUnbondingSequence)
+	k.conversionBucket.SetSequence(ctx, data.ConversionSequence)
+	k.transferBucket.SetSequence(ctx, data.TransferSequence)
+}
+
+// ExportGenesis exports the whole state of the module
+func ExportGenesis(ctx sdk.Context, k Keeper) GenesisState {
+	data := DefaultGenesisState()
+	k.IterateCreditClasses(ctx, func(id CreditClassID, metadata CreditClassMetadata) (stop bool) {
+		data.CreditClasses = append(data.CreditClasses, CreditClass{ID: id, Metadata: metadata})
+		return false
+	})
+	k.IterateCredits(ctx, func(id CreditID, metadata CreditMetadata) (stop bool) {
+		data.Credits = append(data.Credits, Credit{ID: id, Metadata: metadata})
+		return false
+	})
+	k.IterateCreditHoldings(ctx, func(holding CreditHolding) (stop bool) {
+		data.Holdings = append(data.Holdings, holding)
+		return false
+	})
+	k.IterateRetirements(ctx, func(id RetirementID, retirement Retirement) (stop bool) {
+		data.Retirements = append(data.Retirements, RetirementCertificate{ID: id, Retirement: retirement})
+		return false
+	})
+	k.IterateCreditAllowances(ctx, func(allowance CreditAllowance) (stop bool) {
+		data.Allowances = append(data.Allowances, allowance)
+		return false
+	})
+	k.IterateSellOrders(ctx, func(id SellOrderID, order SellOrder) (stop bool) {
+		data.SellOrders = append(data.SellOrders, SellOrderWithID{ID: id, SellOrder: order})
+		return false
+	})
+	k.IterateAuctions(ctx, func(id AuctionID, auction Auction) (stop bool) {
+		data.Auctions = append(data.Auctions, AuctionWithID{ID: id, Auction: auction})
+		return false
+	})
+	k.IterateAuctionBids(ctx, func(id AuctionBidID, bid AuctionBid) (stop bool) {
+		data.AuctionBids = append(data.AuctionBids, AuctionBidWithID{ID: id, Bid: bid})
+		return false
+	})
+	k.IterateCreditVouchers(ctx, func(voucher CreditVoucher) (stop bool) {
+		data.CreditVouchers = append(data.CreditVouchers, voucher)
+		return false
+	})
+	k.IterateCreditClassVouchers(ctx, func(voucher CreditClassVoucher) (stop bool) {
+		data.CreditClassVouchers = append(data.CreditClassVouchers, voucher)
+		return false
+	})
+	k.IterateCreditEscrows(ctx, func(escrow CreditEscrow) (stop bool) {
+		data.CreditEscrows = append(data.CreditEscrows, escrow)
+		return false
+	})
+	k.IterateReversals(ctx, func(id ReversalID, reversal Reversal) (stop bool) {
+		data.Reversals = append(data.Reversals, ReversalRecord{ID: id, Reversal: reversal})
+		return false
+	})
+	k.IterateAnchoredData(ctx, func(d AnchoredData) (stop bool) {
+		data.AnchoredData = append(data.AnchoredData, d)
+		return false
+	})
+	k.IterateAllAttestations(ctx, func(attestation Attestation) (stop bool) {
+		data.Attestations = append(data.Attestations, attestation)
+		return false
+	})
+	k.IterateCreditExpiries(ctx, func(expiry CreditExpiry) (stop bool) {
+		data.CreditExpiries = append(data.CreditExpiries, expiry)
+		return false
+	})
+	k.IterateExpiredHoldings(ctx, func(holding ExpiredHolding) (stop bool) {
+		data.ExpiredHoldings = append(data.ExpiredHoldings, holding)
+		return false
+	})
+	k.IterateConversions(ctx, func(id ConversionID, conversion Conversion) (stop bool) {
+		data.Conversions = append(data.Conversions, ConversionRecord{ID: id, Conversion: conversion})
+		return false
+	})
+	k.IterateCreditLineages(ctx, func(lineage CreditLineage) (stop bool) {
+		data.CreditLineages = append(data.CreditLineages, lineage)
+		return false
+	})
+	k.IterateIssuances(ctx, func(issuance Issuance) (stop bool) {
+		data.Issuances = append(data.Issuances, issuance)
+		return false
+	})
+	k.IterateTransfers(ctx, func(id TransferID, transfer CreditTransfer) (stop bool) {
+		data.Transfers = append(data.Transfers, TransferRecord{ID: id, Transfer: transfer})
+		return false
+	})
+	k.IterateIssuerBonds(ctx, func(bond IssuerBond) (stop bool) {
+		data.Bonds = append(data.Bonds, bond)
+		return false
+	})
+	k.IterateBondUnbondings(ctx, func(id BondUnbondingID, unbonding BondUnbonding) (stop bool) {
+		data.BondUnbondings = append(data.BondUnbondings, BondUnbondingWithID{ID: id, Unbonding: unbonding})
+		return false
+	})
+	var err error
+	if data.CreditClassSequence, err = k.creditClassBucket.Sequence(ctx); err != nil {
+		panic(err)
+	}
+	if data.CreditSequence, err = k.creditBucket.Sequence(ctx); err != nil {
+		panic(err)
+	}
+	if data.RetirementSequence, err = k.retirementBucket.Sequence(ctx); err != nil {
+		panic(err)
+	}
+	if data.SellOrderSequence, err = k.sellOrderBucket.Sequence(ctx); err != nil {
+		panic(err)
+	}
+	if data.AuctionSequence, err = k.auctionBucket.Sequence(ctx); err != nil {
+		panic(err)
+	}
+	if data.AuctionBidSequence, err = k.auctionBidBucket.Sequence(ctx); err != nil {
+		panic(err)
+	}
+	if data.ReversalSequence, err = k.reversalBucket.Sequence(ctx); err != nil {
+		panic(err)
+	}
+	if data.BondUnbondingSequence, err = k.bondUnbondingBucket.Sequence(ctx); err != nil {
+		panic(err)
+	}
+	if data.ConversionSequence, err = k.conversionBucket.Sequence(ctx); err != nil {
+		panic(err)
+	}
+	if data.TransferSequence, err = k.transferBucket.Sequence(ctx); err != nil {
+		panic(err)
+	}
+	return data
+}
@@ -0,0 +1,117 @@
+package ecocredit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+func TestGenesisExportImport(t *testing.T) {
+	ctx, k := createTestInput(t)
+	class, err := k.CreateCreditClass(ctx, testCreditClass())
+	require.NoError(t, err)
+	credit, err := k.IssueCredit(ctx, testCredit(class), addr1)
+	require.NoError(t, err)
+	require.NoError(t, k.SendCredit(ctx, credit, addr1, addr2, sdk.NewDec(40)))
+	_, err = k.BurnCredit(ctx, credit, addr2, sdk.NewDec(10), RetirementInfo{Beneficiary: "ACME"})
+	require.NoError(t, err)
+	require.NoError(t, k.ApproveCredit(ctx, addr1, addr2, credit, sdk.NewDec(5), endDate))
+	require.NoError(t, k.ApproveCredit(ctx, addr2, addr1, nil, sdk.NewDec(5), time.Time{}))
+	_, err = k.CreateSellOrder(ctx, addr2, credit, sdk.NewDec(3), sdk.NewCoins(sdk.NewInt64Coin("uatom", 10)))
+	require.NoError(t, err)
+	auction, err := k.CreateAuction(ctx, addr2, credit, sdk.NewDec(3), sdk.NewInt64Coin("uatom", 10), endDate)
+	require.NoError(t, err)
+	_, err = k.bankKeeper.AddCoins(ctx, addr1, sdk.NewCoins(sdk.NewInt64Coin("uatom", 100)))
+	require.NoError(t, err)
+	_, err = k.PlaceBid(ctx, addr1, auction, sdk.NewDec(1), sdk.NewInt64Coin("uatom", 10))
+	require.NoError(t, err)
+
+	exported := ExportGenesis(ctx, k)
+	require.NoError(t, ValidateGenesis(exported))
+	require.Len(t, exported.CreditClasses, 1)
+	require.Len(t, exported.Credits, 1)
+	require.Len(t, exported.Holdings, 4)
+	require.Len(t, exported.Retirements, 1)
+	require.Len(t, exported.Allowances, 2)
+	require.Len(t, exported.SellOrders, 1)
+	require.Len(t, exported.Auctions, 1)
+	require.Len(t, exported.AuctionBids, 1)
+
+	ctx2, k2 := createTestInput(t)
+	InitGenesis(ctx2, k2, exported)
+	require.Equal(t, exported, ExportGenesis(ctx2, k2))
+
+	// IDs generated after import must not collide with imported ones
+	class2, err := k2.CreateCreditClass(ctx2, testCreditClass())
+	require.NoError(t, err)
+	require.NotEqual(t, class, class2)
+}
+
+func TestValidateGenesis(t *testing.T) {
+	ctx, k := createTestInput(t)
+	class, err := k.CreateCreditClass(ctx, testCreditClass())
+	require.NoError(t, err)
+	credit, err := k.IssueCredit(ctx, testCredit(class), addr1)
+	require.NoError(t, err)
+	require.NoError(t, k.SendCredit(ctx, credit, addr1, addr2, sdk.NewDec(40)))
+	_, err = k.BurnCredit(ctx, credit, addr2, sdk.NewDec(10), RetirementInfo{})
+	require.NoError(t, err)
+
+	cases := map[string]struct {
+		modify func(data *GenesisState)
+		valid  bool
+	}{
+		"exported":        {func(data *GenesisState) {}, true},
+		"default":         {func(data *GenesisState) { *data = DefaultGenesisState() }, true},
+		"class sequence":  {func(data *GenesisState) { data.CreditClassSequence = 0 }, false},
+		"credit sequence": {func(data *GenesisState) { data.CreditSequence = 0 }, false},
+		"duplicate class": {func(data *GenesisState) {
+			data.CreditClasses = append(data.CreditClasses, data.CreditClasses[0])
+		}, false},
+		"invalid class": {func(data *GenesisState) { data.CreditClasses[0].Metadata.Issuers = nil }, false},
+		"unknown class": {func(data *GenesisState) { data.CreditClasses = nil }, false},
+		"invalid credit": {func(data *GenesisState) {
+			data.Credits[0].Metadata.EndDate = data.Credits[0].Metadata.StartDate
+		}, false},
+		"unknown credit": {func(data *GenesisState) {
+			data.Credits = nil
+		}, false},
+		"units not conserved": {func(data *GenesisState) {
+			data.Holdings[0].LiquidUnits = data.Holdings[0].LiquidUnits.Add(sdk.NewDec(1))
+		}, false},
+		"negative holding": {func(data *GenesisState) {
+			data.Holdings[0].LiquidUnits = data.Holdings[0].LiquidUnits.Add(sdk.NewDec(70))
+			data.Holdings[1].LiquidUnits = sdk.NewDec(-40)
+		}, false},
+		"duplicate holding": {func(data *GenesisState) {
+			data.Holdings = append(data.Holdings, data.Holdings[0])
+		}, false},
+		"allowance of unknown credit": {func(data *GenesisState) {
+			data.Allowances = []CreditAllowance{{Holder: addr1, Spender: addr2, Credit: CreditID{9}, Units: sdk.NewDec(1)}}
+		}, false},
+		"allowance without units": {func(data *GenesisState) {
+			data.Allowances = []CreditAllowance{{Holder: addr1, Spender: addr2, Units: sdk.ZeroDec()}}
+		}, false},
+		"retirement exceeding burned units": {func(data *GenesisState) {
+			data.Retirements[0].Retirement.Units = sdk.NewDec(11)
+		}, false},
+		"retirement without holding": {func(data *GenesisState) {
+			data.Retirements[0].Retirement.Holder = addr1.Bytes()[:10]
+		}, false},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			data := ExportGenesis(ctx, k)
+			tc.modify(&data)
+			err := ValidateGenesis(data)
+			if tc.valid {
+				require.NoError(t, err)
+			} else {
+				require.Error(t, err)
+			}
+		})
+	}
+}
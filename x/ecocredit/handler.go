@@ -5,23 +5,205 @@ import (
 	sdk "github.com/cosmos/cosmos-sdk/types"
 )
 
+// NewHandler returns the handler of the module's messages. The events emitted by the keeper while handling a message
+// are returned with its result together with a message event identifying the module and the sender, see events.go
 func NewHandler(k Keeper) sdk.Handler {
 	return func(ctx sdk.Context, msg sdk.Msg) sdk.Result {
 		ctx = ctx.WithEventManager(sdk.NewEventManager())
-		switch msg := msg.(type) {
-		case MsgCreateCreditClass:
-			_, err := k.CreateCreditClass(ctx, msg.CreditClassMetadata)
+		res := handleMsg(ctx, k, msg)
+		if !res.IsOK() {
+			return res
+		}
+		ctx.EventManager().EmitEvent(messageEvent(msg.GetSigners()[0]))
+		res.Events = ctx.EventManager().Events()
+		return res
+	}
+}
+
+func handleMsg(ctx sdk.Context, k Keeper, msg sdk.Msg) sdk.Result {
+	switch msg := msg.(type) {
+	case MsgCreateCreditClass:
+		_, err := k.CreateCreditClass(ctx, msg.CreditClassMetadata)
+		return sdk.ResultFromError(err)
+	case MsgIssueCredit:
+		_, err := k.IssueCredit(ctx, msg.CreditMetadata, msg.Holder)
+		return sdk.ResultFromError(err)
+	case MsgIssueCreditBatch:
+		id, err := k.IssueCreditBatch(ctx, msg.CreditMetadata, msg.Issuances)
+		if err != nil {
+			return sdk.ResultFromError(err)
+		}
+		return sdk.Result{Data: id}
+	case MsgSendCredit:
+		err := k.SendCredit(ctx, msg.Credit, msg.From, msg.To, msg.Units)
+		return sdk.ResultFromError(err)
+	case MsgMultiSendCredit:
+		err := k.MultiSendCredit(ctx, msg.From, msg.Outputs)
+		return sdk.ResultFromError(err)
+	case MsgApproveCredit:
+		err := k.ApproveCredit(ctx, msg.Holder, msg.Spender, msg.Credit, msg.Units, msg.Expiration)
+		return sdk.ResultFromError(err)
+	case MsgRevokeCredit:
+		err := k.RevokeCredit(ctx, msg.Holder, msg.Spender, msg.Credit)
+		return sdk.ResultFromError(err)
+	case MsgSendCreditFrom:
+		err := k.SendCreditFrom(ctx, msg.Spender, msg.Credit, msg.From, msg.To, msg.Units)
+		return sdk.ResultFromError(err)
+	case MsgBurnCredit:
+		id, err := k.BurnCredit(ctx, msg.Credit, msg.Holder, msg.Units, msg.RetirementInfo)
+		if err != nil {
+			return sdk.ResultFromError(err)
+		}
+		return sdk.Result{Data: id}
+	case MsgCreateSellOrder:
+		id, err := k.CreateSellOrder(ctx, msg.Seller, msg.Credit, msg.Units, msg.Price)
+		if err != nil {
+			return sdk.ResultFromError(err)
+		}
+		return sdk.Result{Data: id}
+	case MsgUpdateSellOrder:
+		err := k.UpdateSellOrder(ctx, msg.Order, msg.Seller, msg.Units, msg.Price)
+		return sdk.ResultFromError(err)
+	case MsgCancelSellOrder:
+		err := k.CancelSellOrder(ctx, msg.Order, msg.Seller)
+		return sdk.ResultFromError(err)
+	case MsgBuyCredit:
+		id, err := k.BuyCredit(ctx, msg.Buyer, msg.Order, msg.Units, msg.MaxPrice, msg.Retire, msg.RetirementInfo)
+		if err != nil {
+			return sdk.ResultFromError(err)
+		}
+		return sdk.Result{Data: id}
+	case MsgCreateAuction:
+		id, err := k.CreateAuction(ctx, msg.Seller, msg.Credit, msg.Units, msg.MinPrice, msg.EndTime)
+		if err != nil {
+			return sdk.ResultFromError(err)
+		}
+		return sdk.Result{Data: id}
+	case MsgPlaceBid:
+		id, err := k.PlaceBid(ctx, msg.Bidder, msg.Auction, msg.Units, msg.Price)
+		if err != nil {
+			return sdk.ResultFromError(err)
+		}
+		return sdk.Result{Data: id}
+	case MsgWrapCredit:
+		_, err := k.WrapCredit(ctx, msg.Holder, msg.Credit, msg.Units)
+		return sdk.ResultFromError(err)
+	case MsgUnwrapCredit:
+		err := k.UnwrapCredit(ctx, msg.Holder, msg.Credit, msg.Units)
+		return sdk.ResultFromError(err)
+	case MsgTransferCredit:
+		err := k.TransferCredit(ctx, msg.SourceChannel, msg.Credit, msg.Units, msg.Sender, msg.Receiver)
+		if err != nil {
 			return sdk.ResultFromError(err)
-		case MsgIssueCredit:
-			_, err := k.IssueCredit(ctx, msg.CreditMetadata, msg.Holder)
+		}
+		return sdk.Result{}
+	case MsgRecvCreditPacket:
+		ack, err := k.ReceiveCreditPacket(ctx, msg.Packet, msg.Proof, msg.Height)
+		if err != nil {
 			return sdk.ResultFromError(err)
-		case MsgSendCredit:
-			return sdk.Result{}
-		case MsgBurnCredit:
-			return sdk.Result{}
-		default:
-			errMsg := fmt.Sprintf("Unrecognized data Msg type: %s", ModuleName)
-			return sdk.ErrUnknownRequest(errMsg).Result()
 		}
+		return sdk.Result{Data: ModuleCdc.MustMarshalJSON(ack)}
+	case MsgAcknowledgeCreditPacket:
+		err := k.AcknowledgeCreditPacket(ctx, msg.Packet, msg.Acknowledgement, msg.Proof, msg.Height)
+		if err != nil {
+			return sdk.ResultFromError(err)
+		}
+		return sdk.Result{}
+	case MsgTimeoutCreditPacket:
+		err := k.TimeoutCreditPacket(ctx, msg.Packet, msg.Proof, msg.Height, msg.NextSequenceRecv)
+		if err != nil {
+			return sdk.ResultFromError(err)
+		}
+		return sdk.Result{}
+	case MsgReverseCredit:
+		id, err := k.ReverseCredit(ctx, msg.Credit, msg.Reverser, msg.Units, msg.Reason)
+		if err != nil {
+			return sdk.ResultFromError(err)
+		}
+		return sdk.Result{Data: id}
+	case MsgSplitCredit:
+		id, err := k.SplitCredit(ctx, msg.Holder, msg.Credit, msg.Units, msg.Parts)
+		if err != nil {
+			return sdk.ResultFromError(err)
+		}
+		return sdk.Result{Data: id}
+	case MsgMergeCredits:
+		id, err := k.MergeCredits(ctx, msg.Holder, msg.Credits, msg.GeoPolygon)
+		if err != nil {
+			return sdk.ResultFromError(err)
+		}
+		return sdk.Result{Data: id}
+	case MsgAnchorData:
+		err := k.AnchorData(ctx, msg.Sender, msg.Hash, msg.URI, msg.MediaType)
+		if err != nil {
+			return sdk.ResultFromError(err)
+		}
+		return sdk.Result{}
+	case MsgAttestData:
+		err := k.AttestData(ctx, msg.Verifier, msg.CreditClass, msg.Hash)
+		if err != nil {
+			return sdk.ResultFromError(err)
+		}
+		return sdk.Result{}
+	case MsgPostBond:
+		err := k.PostBond(ctx, msg.Issuer, msg.CreditClass, msg.Amount)
+		if err != nil {
+			return sdk.ResultFromError(err)
+		}
+		return sdk.Result{}
+	case MsgUnbondBond:
+		id, err := k.UnbondBond(ctx, msg.Issuer, msg.CreditClass, msg.Amount)
+		if err != nil {
+			return sdk.ResultFromError(err)
+		}
+		return sdk.Result{Data: id}
+	case MsgSlashBond:
+		_, err := k.SlashBond(ctx, msg.Designer, msg.CreditClass, msg.Issuer, msg.Credit, msg.Fraction, msg.Reason)
+		if err != nil {
+			return sdk.ResultFromError(err)
+		}
+		return sdk.Result{}
+	case MsgAddCreditClassIssuer:
+		if err := k.AddCreditClassIssuer(ctx, msg.CreditClass, msg.Designer, msg.Issuer); err != nil {
+			return sdk.ResultFromError(err)
+		}
+		return sdk.Result{}
+	case MsgRemoveCreditClassIssuer:
+		if err := k.RemoveCreditClassIssuer(ctx, msg.CreditClass, msg.Designer, msg.Issuer); err != nil {
+			return sdk.ResultFromError(err)
+		}
+		return sdk.Result{}
+	case MsgAddCreditClassVerifier:
+		if err := k.AddCreditClassVerifier(ctx, msg.CreditClass, msg.Designer, msg.Verifier); err != nil {
+			return sdk.ResultFromError(err)
+		}
+		return sdk.Result{}
+	case MsgRemoveCreditClassVerifier:
+		if err := k.RemoveCreditClassVerifier(ctx, msg.CreditClass, msg.Designer, msg.Verifier); err != nil {
+			return sdk.ResultFromError(err)
+		}
+		return sdk.Result{}
+	case MsgTransferCreditClassDesigner:
+		if err := k.TransferCreditClassDesigner(ctx, msg.CreditClass, msg.Designer, msg.NewDesigner); err != nil {
+			return sdk.ResultFromError(err)
+		}
+		return sdk.Result{}
+	case MsgDeprecateCreditClass:
+		if err := k.DeprecateCreditClass(ctx, msg.CreditClass, msg.Designer); err != nil {
+			return sdk.ResultFromError(err)
+		}
+		return sdk.Result{}
+	default:
+		errMsg := fmt.Sprintf("Unrecognized data Msg type: %s", ModuleName)
+		return sdk.ErrUnknownRequest(errMsg).Result()
 	}
 }
+
+// messageEvent returns the standard message event identifying the module and the sender of the message
+func messageEvent(sender sdk.AccAddress) sdk.Event {
+	return sdk.NewEvent(
+		sdk.EventTypeMessage,
+		sdk.NewAttribute(sdk.AttributeKeyModule, AttributeValueCategory),
+		sdk.NewAttribute(sdk.AttributeKeySender, sender.String()),
+	)
+}
@@ -0,0 +1,92 @@
+package ecocredit
+
+import (
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+// EcocreditHooks lets other modules react to credit classes and credits within the transaction changing them, e.g.
+// to track the credits issued for an area instead of polling IterateCreditsByGeoPolygon. Hooks are called after the
+// change is stored and after its events were emitted, see SetHooks
+type EcocreditHooks interface {
+	// AfterClassCreated is called when a credit class is created
+	AfterClassCreated(ctx sdk.Context, class CreditClassID, metadata CreditClassMetadata)
+	// AfterCreditIssued is called when an issuer issues a credit, after its units were distributed to the holders.
+	// It isn't called for credits derived by splits and merges or received from other chains
+	AfterCreditIssued(ctx sdk.Context, credit CreditID, metadata CreditMetadata)
+	// AfterCreditSent is called whenever liquid units move between holdings, including the escrow holdings of the
+	// module, see transferCredit
+	AfterCreditSent(ctx sdk.Context, credit CreditID, from sdk.AccAddress, to sdk.AccAddress, units sdk.Dec)
+	// AfterCreditRetired is called for every retirement certificate, including those of units issued as retired
+	AfterCreditRetired(ctx sdk.Context, id RetirementID, retirement Retirement)
+}
+
+// MultiEcocreditHooks combines multiple hooks, which are called in order
+type MultiEcocreditHooks []EcocreditHooks
+
+func NewMultiEcocreditHooks(hooks ...EcocreditHooks) MultiEcocreditHooks {
+	return hooks
+}
+
+func (h MultiEcocreditHooks) AfterClassCreated(ctx sdk.Context, class CreditClassID, metadata CreditClassMetadata) {
+	for i := range h {
+		h[i].AfterClassCreated(ctx, class, metadata)
+	}
+}
+
+func (h MultiEcocreditHooks) AfterCreditIssued(ctx sdk.Context, credit CreditID, metadata CreditMetadata) {
+	for i := range h {
+		h[i].AfterCreditIssued(ctx, credit, metadata)
+	}
+}
+
+func (h MultiEcocreditHooks) AfterCreditSent(ctx sdk.Context, credit CreditID, from sdk.AccAddress, to sdk.AccAddress, units sdk.Dec) {
+	for i := range h {
+		h[i].AfterCreditSent(ctx, credit, from, to, units)
+	}
+}
+
+func (h MultiEcocreditHooks) AfterCreditRetired(ctx sdk.Context, id RetirementID, retirement Retirement) {
+	for i := range h {
+		h[i].AfterCreditRetired(ctx, id, retirement)
+	}
+}
+
+var _ EcocreditHooks = Keeper{}
+
+// SetHooks sets the hooks called by the keeper, it can only be called once. Use NewMultiEcocreditHooks to set the
+// hooks of several modules. Like the staking keeper, keepers copied before the hooks are set don't call them
+func (k *Keeper) SetHooks(hooks EcocreditHooks) *Keeper {
+	if k.hooks != nil {
+		panic("cannot set ecocredit hooks twice")
+	}
+	k.hooks = hooks
+	return k
+}
+
+// AfterClassCreated calls the hook if set
+func (k Keeper) AfterClassCreated(ctx sdk.Context, class CreditClassID, metadata CreditClassMetadata) {
+	if k.hooks != nil {
+		k.hooks.AfterClassCreated(ctx, class, metadata)
+	}
+}
+
+// AfterCreditIssued calls the hook if set
+func (k Keeper) AfterCreditIssued(ctx sdk.Context, credit CreditID, metadata CreditMetadata) {
+	if k.hooks != nil {
+		k.hooks.AfterCreditIssued(ctx, credit, metadata)
+	}
+}
+
+// AfterCreditSent calls the hook if set
+func (k Keeper) AfterCreditSent(ctx sdk.Context, credit CreditID, from sdk.AccAddress, to sdk.AccAddress, units sdk.Dec) {
+	if k.hooks != nil {
+		k.hooks.AfterCreditSent(ctx, credit, from, to, units)
+	}
+}
+
+// AfterCreditRetired calls the hook if set
+func (k Keeper) AfterCreditRetired(ctx sdk.Context, id RetirementID, retirement Retirement) {
+	if k.hooks != nil {
+		k.hooks.AfterCreditRetired(ctx, id, retirement)
+	}
+}
@@ -0,0 +1,494 @@
+package ecocredit
+
+import (
+	"fmt"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	channel "github.com/cosmos/cosmos-sdk/x/ibc/04-channel"
+	channelexported "github.com/cosmos/cosmos-sdk/x/ibc/04-channel/exported"
+	commitment "github.com/cosmos/cosmos-sdk/x/ibc/23-commitment"
+	"github.com/cosmos/cosmos-sdk/x/supply"
+	"github.com/cosmos/gaia/orm"
+	"strconv"
+	"strings"
+)
+
+// PortID is the IBC port the ecocredit module binds to exchange credits with the ecocredit module of other chains
+const PortID = ModuleName
+
+// DefaultCreditPacketTimeout is the number of blocks after which a credit packet which wasn't received times out
+const DefaultCreditPacketTimeout = 1000
+
+// ChannelKeeper is the part of the IBC channel keeper used to send and receive credit packets
+type ChannelKeeper interface {
+	GetChannel(ctx sdk.Context, srcPort, srcChan string) (channel channel.Channel, found bool)
+	GetNextSequenceSend(ctx sdk.Context, portID, channelID string) (uint64, bool)
+	SendPacket(ctx sdk.Context, packet channelexported.PacketI, portCapability sdk.CapabilityKey) error
+	RecvPacket(ctx sdk.Context, packet channelexported.PacketI, proof commitment.ProofI, proofHeight uint64, acknowledgement []byte, portCapability sdk.CapabilityKey) (channelexported.PacketI, error)
+	AcknowledgePacket(ctx sdk.Context, packet channelexported.PacketI, acknowledgement []byte, proof commitment.ProofI, proofHeight uint64, portCapability sdk.CapabilityKey) (channelexported.PacketI, error)
+	TimeoutPacket(ctx sdk.Context, packet channelexported.PacketI, proof commitment.ProofI, proofHeight uint64, nextSequenceRecv uint64, portCapability sdk.CapabilityKey) (channelexported.PacketI, error)
+}
+
+// CreditVoucher records that a credit represents units of a credit of another chain received over IBC. Path is the
+// provenance of the units, the port and channel of every hop they took to reach this chain with the last hop first,
+// each in the form "port/channel/". Origin is the ID of the credit on the chain which issued it
+type CreditVoucher struct {
+	Credit CreditID `json:"credit"`
+	Path   string   `json:"path"`
+	Origin CreditID `json:"origin"`
+}
+
+func (v CreditVoucher) ID() []byte {
+	return v.Credit
+}
+
+// CreditClassVoucher records that a credit class holds the vouchers of the credits of a credit class of another chain
+// received over the same path. Voucher classes are deprecated so that no credits can be issued under them, and their
+// designer and issuers, which are accounts of the origin chain, can't administer them
+type CreditClassVoucher struct {
+	CreditClass CreditClassID `json:"credit_class"`
+	Path        string        `json:"path"`
+	Origin      CreditClassID `json:"origin"`
+}
+
+func (v CreditClassVoucher) ID() []byte {
+	return v.CreditClass
+}
+
+// CreditEscrow records a channel of the ecocredit port over which units were sent to other chains, so that its escrow
+// address is known to hold units on behalf of others
+type CreditEscrow struct {
+	Channel string         `json:"channel"`
+	Address sdk.AccAddress `json:"address"`
+}
+
+func (e CreditEscrow) ID() []byte {
+	return e.Address
+}
+
+// voucherTrace identifies the voucher of a class or credit of another chain received over a path
+func voucherTrace(path string, origin []byte) []byte {
+	return []byte(fmt.Sprintf("%s%x", path, origin))
+}
+
+// channelPath returns the hop of the provenance path of units received over a channel of the ecocredit port
+func channelPath(channelID string) string {
+	return fmt.Sprintf("%s/%s/", PortID, channelID)
+}
+
+// CreditEscrowAddress returns the address holding the units sent to other chains over a channel of the ecocredit port
+// until they come back
+func CreditEscrowAddress(channelID string) sdk.AccAddress {
+	return supply.NewModuleAddress(fmt.Sprintf("%s/%s", ModuleName, channelID))
+}
+
+// CreditPacketData is the payload of a packet sending units of a credit to the ecocredit module of another chain. It
+// carries the metadata of the credit and its class, but Credit and the credit class of Metadata are their IDs on the
+// chain which issued the credit and Path is the provenance path of the units on the sending chain. Source is set if
+// the units are escrowed on the sending chain, it is unset if they return to the receiving chain, in which case the
+// vouchers are burned on the sending chain and the receiving chain releases the units it escrowed
+type CreditPacketData struct {
+	Credit   CreditID            `json:"credit"`
+	Path     string              `json:"path"`
+	Class    CreditClassMetadata `json:"class"`
+	Metadata CreditMetadata      `json:"metadata"`
+	Units    sdk.Dec             `json:"units"`
+	Sender   sdk.AccAddress      `json:"sender"`
+	Receiver sdk.AccAddress      `json:"receiver"`
+	Source   bool                `json:"source"`
+}
+
+func (d CreditPacketData) validate() sdk.Error {
+	if len(d.Credit) == 0 || len(d.Metadata.CreditClass) == 0 {
+		return ErrInvalidCreditPacket(DefaultCodespace, "missing credit")
+	}
+	if d.Units.IsNil() || !d.Units.IsPositive() {
+		return ErrInvalidUnits(DefaultCodespace, "units must be positive")
+	}
+	if d.Sender.Empty() || d.Receiver.Empty() {
+		return sdk.ErrInvalidAddress("missing sender or receiver address")
+	}
+	if err := d.Class.validate(); err != nil {
+		return err
+	}
+	return d.Metadata.validate()
+}
+
+// CreditPacketAcknowledgement is written by the receiving chain for every credit packet. A packet which couldn't be
+// applied is acknowledged with the Error explaining why and the sending chain refunds its units
+type CreditPacketAcknowledgement struct {
+	Success bool   `json:"success"`
+	Error   string `json:"error"`
+}
+
+// GetCreditVoucher gets the provenance of a credit received over IBC, it isn't found for credits issued on this chain
+func (k Keeper) GetCreditVoucher(ctx sdk.Context, credit CreditID) (voucher CreditVoucher, found bool) {
+	voucher = CreditVoucher{Credit: credit}
+	err := k.creditVoucherBucket.GetOne(ctx, &voucher)
+	if err != nil {
+		return voucher, false
+	}
+	return voucher, true
+}
+
+// GetCreditClassVoucher gets the provenance of a credit class created for credits received over IBC
+func (k Keeper) GetCreditClassVoucher(ctx sdk.Context, class CreditClassID) (voucher CreditClassVoucher, found bool) {
+	voucher = CreditClassVoucher{CreditClass: class}
+	err := k.creditClassVoucherBucket.GetOne(ctx, &voucher)
+	if err != nil {
+		return voucher, false
+	}
+	return voucher, true
+}
+
+// isCreditEscrowAddress returns whether the address holds the units sent to other chains over a channel
+func (k Keeper) isCreditEscrowAddress(ctx sdk.Context, addr sdk.AccAddress) bool {
+	found, err := k.creditEscrowBucket.Has(ctx, addr)
+	return err == nil && found
+}
+
+// IterateCreditEscrows iterates over the channels over which units were sent to other chains
+func (k Keeper) IterateCreditEscrows(ctx sdk.Context, callback func(escrow CreditEscrow) (stop bool)) {
+	iterator, err := k.creditEscrowBucket.PrefixScan(ctx, nil, nil, false)
+	if err != nil {
+		return
+	}
+	defer iterator.Release()
+	for {
+		var escrow CreditEscrow
+		_, err := iterator.LoadNext(&escrow)
+		if err != nil {
+			break
+		}
+		if callback(escrow) {
+			return
+		}
+	}
+}
+
+// IterateCreditVouchers iterates over the provenance of all credits received over IBC
+func (k Keeper) IterateCreditVouchers(ctx sdk.Context, callback func(voucher CreditVoucher) (stop bool)) {
+	iterator, err := k.creditVoucherBucket.PrefixScan(ctx, nil, nil, false)
+	if err != nil {
+		return
+	}
+	defer iterator.Release()
+	for {
+		var voucher CreditVoucher
+		_, err := iterator.LoadNext(&voucher)
+		if err != nil {
+			break
+		}
+		if callback(voucher) {
+			return
+		}
+	}
+}
+
+// IterateCreditClassVouchers iterates over the provenance of all credit classes created for credits received over IBC
+func (k Keeper) IterateCreditClassVouchers(ctx sdk.Context, callback func(voucher CreditClassVoucher) (stop bool)) {
+	iterator, err := k.creditClassVoucherBucket.PrefixScan(ctx, nil, nil, false)
+	if err != nil {
+		return
+	}
+	defer iterator.Release()
+	for {
+		var voucher CreditClassVoucher
+		_, err := iterator.LoadNext(&voucher)
+		if err != nil {
+			break
+		}
+		if callback(voucher) {
+			return
+		}
+	}
+}
+
+// loadByTrace loads the voucher with the trace from a voucher bucket
+func loadByTrace(ctx sdk.Context, bucket orm.NaturalKeyBucket, trace []byte, dest interface{}) bool {
+	iterator, err := bucket.ByIndex(ctx, IndexByTrace, trace)
+	if iterator != nil {
+		defer iterator.Release()
+	}
+	if err != nil {
+		return false
+	}
+	_, err = iterator.LoadNext(dest)
+	return err == nil
+}
+
+// creditByTrace returns the credit holding the units of the origin credit received over the path, which is the origin
+// credit itself for an empty path
+func (k Keeper) creditByTrace(ctx sdk.Context, path string, origin CreditID) (CreditID, bool) {
+	if path == "" {
+		_, found := k.GetCredit(ctx, origin)
+		_, isVoucher := k.GetCreditVoucher(ctx, origin)
+		return origin, found && !isVoucher
+	}
+	var voucher CreditVoucher
+	if !loadByTrace(ctx, k.creditVoucherBucket, voucherTrace(path, origin), &voucher) {
+		return nil, false
+	}
+	return voucher.Credit, true
+}
+
+// voucherCredit returns the voucher of the credit of a packet received over the path, creating it and the voucher of
+// its credit class with the metadata of the packet the first time units of the credit are received over the path.
+// Vouchers don't go through the checks of issuance as they represent credits issued under the rules of their origin,
+// but they expire with the validity period of their class like the credits of the origin chain
+func (k Keeper) voucherCredit(ctx sdk.Context, path string, data CreditPacketData) (CreditID, error) {
+	if credit, found := k.creditByTrace(ctx, path, data.Credit); found {
+		return credit, nil
+	}
+	class := data.Class
+	var classVoucher CreditClassVoucher
+	if !loadByTrace(ctx, k.creditClassVoucherBucket, voucherTrace(path, data.Metadata.CreditClass), &classVoucher) {
+		class.Deprecated = true
+		id, err := k.creditClassBucket.Create(ctx, class)
+		if err != nil {
+			return nil, err
+		}
+		classVoucher = CreditClassVoucher{CreditClass: id, Path: path, Origin: data.Metadata.CreditClass}
+		if err := k.creditClassVoucherBucket.Save(ctx, classVoucher); err != nil {
+			return nil, err
+		}
+	}
+	metadata := data.Metadata
+	metadata.CreditClass = classVoucher.CreditClass
+	id, err := k.creditBucket.Create(ctx, metadata)
+	if err != nil {
+		return nil, err
+	}
+	if err := k.scheduleCreditExpiry(ctx, id, class, metadata); err != nil {
+		return nil, err
+	}
+	return id, k.creditVoucherBucket.Save(ctx, CreditVoucher{Credit: id, Path: path, Origin: data.Credit})
+}
+
+// mintVoucher adds units of a voucher to the holder and to the supply of the voucher
+func (k Keeper) mintVoucher(ctx sdk.Context, credit CreditID, holder sdk.AccAddress, units sdk.Dec) error {
+	if err := k.checkPrecision(ctx, credit, units); err != nil {
+		return err
+	}
+	holding, found := k.GetCreditHolding(ctx, credit, holder)
+	if !found {
+		holding = CreditHolding{Credit: credit, Holder: holder, LiquidUnits: sdk.ZeroDec(), BurnedUnits: sdk.ZeroDec()}
+	}
+	holding.LiquidUnits = holding.LiquidUnits.Add(units)
+	if err := k.creditHoldingsBucket.Save(ctx, holding); err != nil {
+		return err
+	}
+	return k.addCreditSupply(ctx, credit, units, sdk.ZeroDec())
+}
+
+// burnVoucher removes units of a voucher from the holder and from the supply of the voucher
+func (k Keeper) burnVoucher(ctx sdk.Context, credit CreditID, holder sdk.AccAddress, units sdk.Dec) error {
+	if err := k.checkPrecision(ctx, credit, units); err != nil {
+		return err
+	}
+	holding := CreditHolding{Credit: credit, Holder: holder}
+	err := k.creditHoldingsBucket.GetOne(ctx, &holding)
+	if err != nil {
+		return err
+	}
+	holding.LiquidUnits = holding.LiquidUnits.Sub(units)
+	if holding.LiquidUnits.IsNegative() {
+		return fmt.Errorf("not enough units")
+	}
+	if err := k.creditHoldingsBucket.Save(ctx, holding); err != nil {
+		return err
+	}
+	supply, found := k.GetCreditSupply(ctx, credit)
+	if !found {
+		return ErrInvalidCredit(DefaultCodespace, fmt.Sprintf("no supply of credit %s", credit))
+	}
+	supply.Issued = supply.Issued.Sub(units)
+	supply.Liquid = supply.Liquid.Sub(units)
+	return k.creditSupplyBucket.Save(ctx, supply)
+}
+
+// TransferCredit sends units of a credit held by the sender over a channel of the ecocredit port to the receiver on
+// the counterparty chain. Vouchers which came over the same channel are burned as their units return to the chain
+// they came from, any other units are escrowed until they come back. The packet times out after
+// DefaultCreditPacketTimeout blocks, in which case the units are refunded. Units of expired credits can't be sent
+func (k Keeper) TransferCredit(ctx sdk.Context, sourceChannel string, credit CreditID, units sdk.Dec, sender sdk.AccAddress, receiver sdk.AccAddress) error {
+	ch, found := k.channelKeeper.GetChannel(ctx, PortID, sourceChannel)
+	if !found {
+		return channel.ErrChannelNotFound(DefaultCodespace, PortID, sourceChannel)
+	}
+	sequence, found := k.channelKeeper.GetNextSequenceSend(ctx, PortID, sourceChannel)
+	if !found {
+		return channel.ErrSequenceNotFound(DefaultCodespace, "send")
+	}
+	metadata, found := k.GetCredit(ctx, credit)
+	if !found {
+		return ErrInvalidCredit(DefaultCodespace, fmt.Sprintf("credit %s not found", credit))
+	}
+	class, found := k.GetCreditClass(ctx, metadata.CreditClass)
+	if !found {
+		return ErrInvalidCreditClass(DefaultCodespace, fmt.Sprintf("credit class %s not found", metadata.CreditClass))
+	}
+	if err := k.checkCreditNotExpired(ctx, credit); err != nil {
+		return err
+	}
+	data := CreditPacketData{Credit: credit, Class: class, Metadata: metadata, Units: units, Sender: sender, Receiver: receiver, Source: true}
+	if voucher, found := k.GetCreditVoucher(ctx, credit); found {
+		data.Credit, data.Path = voucher.Origin, voucher.Path
+	}
+	if voucher, found := k.GetCreditClassVoucher(ctx, metadata.CreditClass); found {
+		data.Metadata.CreditClass = voucher.Origin
+	}
+	var err error
+	if strings.HasPrefix(data.Path, channelPath(sourceChannel)) {
+		data.Path = strings.TrimPrefix(data.Path, channelPath(sourceChannel))
+		data.Source = false
+		err = k.burnVoucher(ctx, credit, sender, units)
+	} else {
+		escrow := CreditEscrow{Channel: sourceChannel, Address: CreditEscrowAddress(sourceChannel)}
+		if err := k.creditEscrowBucket.Save(ctx, escrow); err != nil {
+			return err
+		}
+		err = k.transferCredit(ctx, credit, sender, escrow.Address, units)
+	}
+	if err != nil {
+		return err
+	}
+	packet := channel.NewPacket(sequence, uint64(ctx.BlockHeight())+DefaultCreditPacketTimeout, PortID, sourceChannel,
+		ch.Counterparty.PortID, ch.Counterparty.ChannelID, ModuleCdc.MustMarshalJSON(data))
+	if err := k.channelKeeper.SendPacket(ctx, packet, k.portKey); err != nil {
+		return err
+	}
+	ctx.EventManager().EmitEvent(sdk.NewEvent(
+		EventTypeSendCreditPacket,
+		sdk.NewAttribute(AttributeKeyCredit, credit.String()),
+		sdk.NewAttribute(AttributeKeyUnits, units.String()),
+		sdk.NewAttribute(AttributeKeyReceiver, receiver.String()),
+	))
+	return nil
+}
+
+// ReceiveCreditPacket receives a credit packet relayed from the counterparty chain with the proof of its commitment
+// and writes its acknowledgement. Units escrowed on the sending chain are minted to the receiver as vouchers, units
+// returning to this chain are released from escrow. A packet which can't be applied, e.g. because it returns more
+// units than were escrowed, is acknowledged with an error so that the sending chain refunds the units
+func (k Keeper) ReceiveCreditPacket(ctx sdk.Context, packet channelexported.PacketI, proof commitment.ProofI, proofHeight uint64) (CreditPacketAcknowledgement, error) {
+	if packet.GetDestPort() != PortID {
+		return CreditPacketAcknowledgement{}, ErrInvalidCreditPacket(DefaultCodespace, fmt.Sprintf("packet is not addressed to port %s", PortID))
+	}
+	var data CreditPacketData
+	ack := CreditPacketAcknowledgement{Success: true}
+	// the packet is applied in a cache so that a failure leaves no partial state behind but is still acknowledged
+	cacheCtx, write := ctx.CacheContext()
+	credit, err := k.receiveCredit(cacheCtx, packet, &data)
+	if err != nil {
+		ack = CreditPacketAcknowledgement{Error: err.Error()}
+	}
+	if _, err := k.channelKeeper.RecvPacket(ctx, packet, proof, proofHeight, ModuleCdc.MustMarshalJSON(ack), k.portKey); err != nil {
+		return ack, err
+	}
+	if !ack.Success {
+		ctx.EventManager().EmitEvent(sdk.NewEvent(
+			EventTypeReceiveCreditPacket,
+			sdk.NewAttribute(AttributeKeySuccess, strconv.FormatBool(false)),
+		))
+		return ack, nil
+	}
+	write()
+	// the cache context has its own event manager
+	ctx.EventManager().EmitEvents(cacheCtx.EventManager().Events())
+	ctx.EventManager().EmitEvent(sdk.NewEvent(
+		EventTypeReceiveCreditPacket,
+		sdk.NewAttribute(AttributeKeySuccess, strconv.FormatBool(true)),
+		sdk.NewAttribute(AttributeKeyCredit, credit.String()),
+		sdk.NewAttribute(AttributeKeyUnits, data.Units.String()),
+		sdk.NewAttribute(AttributeKeyReceiver, data.Receiver.String()),
+	))
+	return ack, nil
+}
+
+func (k Keeper) receiveCredit(ctx sdk.Context, packet channelexported.PacketI, data *CreditPacketData) (CreditID, error) {
+	if err := ModuleCdc.UnmarshalJSON(packet.GetData(), data); err != nil {
+		return nil, ErrInvalidCreditPacket(DefaultCodespace, err.Error())
+	}
+	if err := data.validate(); err != nil {
+		return nil, err
+	}
+	if k.isEscrow(ctx, data.Receiver) || data.Receiver.Equals(CreditEscrowAddress(packet.GetDestChannel())) {
+		return nil, sdk.ErrInvalidAddress(fmt.Sprintf("can't send credits to escrow address %s", data.Receiver))
+	}
+	if data.Source {
+		credit, err := k.voucherCredit(ctx, channelPath(packet.GetDestChannel())+data.Path, *data)
+		if err != nil {
+			return nil, err
+		}
+		return credit, k.mintVoucher(ctx, credit, data.Receiver, data.Units)
+	}
+	credit, found := k.creditByTrace(ctx, data.Path, data.Credit)
+	if !found {
+		return nil, ErrInvalidCredit(DefaultCodespace, fmt.Sprintf("credit %s with path %q not found", data.Credit, data.Path))
+	}
+	return credit, k.transferCredit(ctx, credit, CreditEscrowAddress(packet.GetDestChannel()), data.Receiver, data.Units)
+}
+
+// AcknowledgeCreditPacket processes the acknowledgement of a credit packet sent by this chain with the proof that the
+// counterparty chain wrote it. The units of packets acknowledged with an error are refunded to the sender
+func (k Keeper) AcknowledgeCreditPacket(ctx sdk.Context, packet channelexported.PacketI, acknowledgement []byte, proof commitment.ProofI, proofHeight uint64) error {
+	if packet.GetSourcePort() != PortID {
+		return ErrInvalidCreditPacket(DefaultCodespace, fmt.Sprintf("packet was not sent from port %s", PortID))
+	}
+	var ack CreditPacketAcknowledgement
+	if err := ModuleCdc.UnmarshalJSON(acknowledgement, &ack); err != nil {
+		return ErrInvalidCreditPacket(DefaultCodespace, fmt.Sprintf("invalid acknowledgement: %s", err))
+	}
+	if _, err := k.channelKeeper.AcknowledgePacket(ctx, packet, acknowledgement, proof, proofHeight, k.portKey); err != nil {
+		return err
+	}
+	if ack.Success {
+		return nil
+	}
+	return k.refundCreditPacket(ctx, packet)
+}
+
+// TimeoutCreditPacket refunds the units of a credit packet sent by this chain with the proof that the counterparty
+// chain didn't receive it before it timed out
+func (k Keeper) TimeoutCreditPacket(ctx sdk.Context, packet channelexported.PacketI, proof commitment.ProofI, proofHeight uint64, nextSequenceRecv uint64) error {
+	if packet.GetSourcePort() != PortID {
+		return ErrInvalidCreditPacket(DefaultCodespace, fmt.Sprintf("packet was not sent from port %s", PortID))
+	}
+	if _, err := k.channelKeeper.TimeoutPacket(ctx, packet, proof, proofHeight, nextSequenceRecv, k.portKey); err != nil {
+		return err
+	}
+	return k.refundCreditPacket(ctx, packet)
+}
+
+// refundCreditPacket returns the units of a credit packet which wasn't applied by the counterparty chain to the sender,
+// releasing them from escrow or minting back the burned vouchers
+func (k Keeper) refundCreditPacket(ctx sdk.Context, packet channelexported.PacketI) error {
+	var data CreditPacketData
+	if err := ModuleCdc.UnmarshalJSON(packet.GetData(), &data); err != nil {
+		return ErrInvalidCreditPacket(DefaultCodespace, err.Error())
+	}
+	path := data.Path
+	if !data.Source {
+		path = channelPath(packet.GetSourceChannel()) + path
+	}
+	credit, found := k.creditByTrace(ctx, path, data.Credit)
+	if !found {
+		return ErrInvalidCredit(DefaultCodespace, fmt.Sprintf("credit %s with path %q not found", data.Credit, path))
+	}
+	var err error
+	if data.Source {
+		err = k.transferCredit(ctx, credit, CreditEscrowAddress(packet.GetSourceChannel()), data.Sender, data.Units)
+	} else {
+		err = k.mintVoucher(ctx, credit, data.Sender, data.Units)
+	}
+	if err != nil {
+		return err
+	}
+	ctx.EventManager().EmitEvent(sdk.NewEvent(
+		EventTypeRefundCreditPacket,
+		sdk.NewAttribute(AttributeKeyCredit, credit.String()),
+		sdk.NewAttribute(AttributeKeyUnits, data.Units.String()),
+		sdk.NewAttribute(sdk.AttributeKeySender, data.Sender.String()),
+	))
+	return nil
+}
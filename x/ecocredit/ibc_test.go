@@ -0,0 +1,314 @@
+package ecocredit
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	channel "github.com/cosmos/cosmos-sdk/x/ibc/04-channel"
+	channelexported "github.com/cosmos/cosmos-sdk/x/ibc/04-channel/exported"
+	commitment "github.com/cosmos/cosmos-sdk/x/ibc/23-commitment"
+)
+
+const (
+	channelAtoB = "channelatob"
+	channelBtoA = "channelbtoa"
+)
+
+// testChannelKeeper is an in-memory channel keeper for the channels of the ecocredit port of a test chain. It
+// enforces sequences, timeouts and the lifecycle of packet commitments but doesn't verify proofs, which is the job of
+// the IBC module, so that tests can relay packets between two in-process chains directly
+type testChannelKeeper struct {
+	channels    map[string]channel.Channel
+	nextSend    map[string]uint64
+	commitments map[string][]byte
+	received    map[string]bool
+	// sent are the packets sent by the chain in order, for the test relayer to pick up
+	sent []channel.Packet
+}
+
+func newTestChannelKeeper() *testChannelKeeper {
+	return &testChannelKeeper{
+		channels:    make(map[string]channel.Channel),
+		nextSend:    make(map[string]uint64),
+		commitments: make(map[string][]byte),
+		received:    make(map[string]bool),
+	}
+}
+
+func (k *testChannelKeeper) openChannel(channelID string, counterpartyChannel string) {
+	k.channels[channelID] = channel.NewChannel(channel.OPEN, channel.UNORDERED,
+		channel.NewCounterparty(PortID, counterpartyChannel), []string{"connectionid"}, "")
+	k.nextSend[channelID] = 1
+}
+
+func packetKey(channelID string, sequence uint64) string {
+	return fmt.Sprintf("%s/%d", channelID, sequence)
+}
+
+func (k *testChannelKeeper) GetChannel(ctx sdk.Context, srcPort, srcChan string) (channel.Channel, bool) {
+	ch, found := k.channels[srcChan]
+	return ch, found && srcPort == PortID
+}
+
+func (k *testChannelKeeper) GetNextSequenceSend(ctx sdk.Context, portID, channelID string) (uint64, bool) {
+	sequence, found := k.nextSend[channelID]
+	return sequence, found
+}
+
+func (k *testChannelKeeper) SendPacket(ctx sdk.Context, packet channelexported.PacketI, portCapability sdk.CapabilityKey) error {
+	if err := packet.ValidateBasic(); err != nil {
+		return err
+	}
+	if packet.GetSequence() != k.nextSend[packet.GetSourceChannel()] {
+		return fmt.Errorf("unexpected sequence %d", packet.GetSequence())
+	}
+	k.nextSend[packet.GetSourceChannel()]++
+	k.commitments[packetKey(packet.GetSourceChannel(), packet.GetSequence())] = packet.GetData()
+	k.sent = append(k.sent, packet.(channel.Packet))
+	return nil
+}
+
+func (k *testChannelKeeper) RecvPacket(ctx sdk.Context, packet channelexported.PacketI, proof commitment.ProofI, proofHeight uint64, acknowledgement []byte, portCapability sdk.CapabilityKey) (channelexported.PacketI, error) {
+	ch, found := k.channels[packet.GetDestChannel()]
+	if !found || ch.Counterparty.ChannelID != packet.GetSourceChannel() {
+		return nil, fmt.Errorf("packet doesn't come from the counterparty channel")
+	}
+	if uint64(ctx.BlockHeight()) >= packet.GetTimeoutHeight() {
+		return nil, channel.ErrPacketTimeout(DefaultCodespace)
+	}
+	key := packetKey(packet.GetDestChannel(), packet.GetSequence())
+	if k.received[key] {
+		return nil, fmt.Errorf("packet already received")
+	}
+	k.received[key] = true
+	return packet, nil
+}
+
+func (k *testChannelKeeper) AcknowledgePacket(ctx sdk.Context, packet channelexported.PacketI, acknowledgement []byte, proof commitment.ProofI, proofHeight uint64, portCapability sdk.CapabilityKey) (channelexported.PacketI, error) {
+	return packet, k.deleteCommitment(packet)
+}
+
+func (k *testChannelKeeper) TimeoutPacket(ctx sdk.Context, packet channelexported.PacketI, proof commitment.ProofI, proofHeight uint64, nextSequenceRecv uint64, portCapability sdk.CapabilityKey) (channelexported.PacketI, error) {
+	if proofHeight < packet.GetTimeoutHeight() {
+		return nil, channel.ErrPacketTimeout(DefaultCodespace)
+	}
+	return packet, k.deleteCommitment(packet)
+}
+
+func (k *testChannelKeeper) deleteCommitment(packet channelexported.PacketI) error {
+	key := packetKey(packet.GetSourceChannel(), packet.GetSequence())
+	if _, found := k.commitments[key]; !found {
+		return fmt.Errorf("packet hasn't been sent")
+	}
+	delete(k.commitments, key)
+	return nil
+}
+
+// testChain is an in-process chain running the ecocredit module with an open channel to another test chain
+type testChain struct {
+	ctx      sdk.Context
+	keeper   Keeper
+	channels *testChannelKeeper
+}
+
+func newTestChain(t *testing.T, channelID string, counterpartyChannel string) testChain {
+	channels := newTestChannelKeeper()
+	channels.openChannel(channelID, counterpartyChannel)
+	ctx, k := createTestInputWithChannels(t, channels)
+	return testChain{ctx: ctx, keeper: k, channels: channels}
+}
+
+// relay receives the last packet sent by the source chain on the destination chain and acknowledges it on the
+// source chain
+func relay(t *testing.T, src testChain, dst testChain) CreditPacketAcknowledgement {
+	packet := src.channels.sent[len(src.channels.sent)-1]
+	ack, err := dst.keeper.ReceiveCreditPacket(dst.ctx, packet, nil, 1)
+	require.NoError(t, err)
+	require.NoError(t, src.keeper.AcknowledgeCreditPacket(src.ctx, packet, ModuleCdc.MustMarshalJSON(ack), nil, 1))
+	return ack
+}
+
+func requireLiquidUnits(t *testing.T, chain testChain, credit CreditID, holder sdk.AccAddress, units int64) {
+	holding, found := chain.keeper.GetCreditHolding(chain.ctx, credit, holder)
+	require.True(t, found)
+	require.Equal(t, sdk.NewDec(units), holding.LiquidUnits)
+}
+
+func requireInvariants(t *testing.T, chain testChain) {
+	_, broken := SupplyInvariant(chain.keeper)(chain.ctx)
+	require.False(t, broken)
+	_, broken = NonnegativeHoldingsInvariant(chain.keeper)(chain.ctx)
+	require.False(t, broken)
+	require.NoError(t, ValidateGenesis(ExportGenesis(chain.ctx, chain.keeper)))
+}
+
+func TestIBCTransferCredit(t *testing.T) {
+	a := newTestChain(t, channelAtoB, channelBtoA)
+	b := newTestChain(t, channelBtoA, channelAtoB)
+	addr3 := sdk.AccAddress([]byte("addr3_______________"))
+
+	classMetadata := testCreditClass()
+	class, err := a.keeper.CreateCreditClass(a.ctx, classMetadata)
+	require.NoError(t, err)
+	metadata := testCredit(class)
+	metadata.Attributes = []CreditAttribute{{Key: "project", Value: "p1"}}
+	credit, err := a.keeper.IssueCredit(a.ctx, metadata, addr1)
+	require.NoError(t, err)
+
+	// units leaving their origin chain are escrowed and received as a voucher carrying the original metadata
+	require.NoError(t, a.keeper.TransferCredit(a.ctx, channelAtoB, credit, sdk.NewDec(30), addr1, addr2))
+	requireLiquidUnits(t, a, credit, addr1, 70)
+	requireLiquidUnits(t, a, credit, CreditEscrowAddress(channelAtoB), 30)
+	require.Equal(t, CreditPacketAcknowledgement{Success: true}, relay(t, a, b))
+
+	var voucher CreditID
+	b.keeper.IterateHoldingsByHolder(b.ctx, addr2, func(holding CreditHolding) (stop bool) {
+		voucher = holding.Credit
+		return true
+	})
+	requireLiquidUnits(t, b, voucher, addr2, 30)
+	provenance, found := b.keeper.GetCreditVoucher(b.ctx, voucher)
+	require.True(t, found)
+	require.Equal(t, CreditVoucher{Credit: voucher, Path: "ecocredit/channelbtoa/", Origin: credit}, provenance)
+	voucherMetadata, found := b.keeper.GetCredit(b.ctx, voucher)
+	require.True(t, found)
+	classVoucher, found := b.keeper.GetCreditClassVoucher(b.ctx, voucherMetadata.CreditClass)
+	require.True(t, found)
+	require.Equal(t, class, classVoucher.Origin)
+	expected := metadata
+	expected.CreditClass = voucherMetadata.CreditClass
+	require.Equal(t, expected, voucherMetadata)
+	voucherClass, found := b.keeper.GetCreditClass(b.ctx, voucherMetadata.CreditClass)
+	require.True(t, found)
+	classMetadata.Deprecated = true
+	require.Equal(t, classMetadata, voucherClass)
+	// the designer and issuers of the origin class have no rights over the voucher class
+	err = b.keeper.AddCreditClassIssuer(b.ctx, voucherMetadata.CreditClass, addr1, addr2)
+	require.Error(t, err)
+	require.Equal(t, CodeInvalidCreditClass, err.(sdk.Error).Code())
+	err = b.keeper.TransferCreditClassDesigner(b.ctx, voucherMetadata.CreditClass, addr1, addr2)
+	require.Error(t, err)
+	err = b.keeper.PostBond(b.ctx, addr1, voucherMetadata.CreditClass, sdk.NewCoins())
+	require.Error(t, err)
+
+	// further units of the same credit are added to the same voucher
+	require.NoError(t, a.keeper.TransferCredit(a.ctx, channelAtoB, credit, sdk.NewDec(20), addr1, addr2))
+	relay(t, a, b)
+	requireLiquidUnits(t, b, voucher, addr2, 50)
+	supply, _ := b.keeper.GetCreditSupply(b.ctx, voucher)
+	require.Equal(t, sdk.NewDec(50), supply.Liquid)
+
+	// vouchers sent back are burned and the units are released from escrow on the origin chain
+	require.NoError(t, b.keeper.TransferCredit(b.ctx, channelBtoA, voucher, sdk.NewDec(10), addr2, addr3))
+	requireLiquidUnits(t, b, voucher, addr2, 40)
+	supply, _ = b.keeper.GetCreditSupply(b.ctx, voucher)
+	require.Equal(t, sdk.NewDec(40), supply.Issued)
+	require.Equal(t, CreditPacketAcknowledgement{Success: true}, relay(t, b, a))
+	requireLiquidUnits(t, a, credit, addr3, 10)
+	requireLiquidUnits(t, a, credit, CreditEscrowAddress(channelAtoB), 40)
+
+	// a packet rejected by the counterparty chain is refunded when its acknowledgement is relayed back
+	require.NoError(t, b.keeper.TransferCredit(b.ctx, channelBtoA, voucher, sdk.NewDec(5), addr2, SellOrderEscrowAddress))
+	requireLiquidUnits(t, b, voucher, addr2, 35)
+	ack := relay(t, b, a)
+	require.False(t, ack.Success)
+	require.NotEmpty(t, ack.Error)
+	requireLiquidUnits(t, b, voucher, addr2, 40)
+	requireLiquidUnits(t, a, credit, CreditEscrowAddress(channelAtoB), 40)
+
+	// a packet which timed out can't be received anymore and is refunded
+	require.NoError(t, a.keeper.TransferCredit(a.ctx, channelAtoB, credit, sdk.NewDec(5), addr1, addr2))
+	requireLiquidUnits(t, a, credit, addr1, 45)
+	packet := a.channels.sent[len(a.channels.sent)-1]
+	_, err = b.keeper.ReceiveCreditPacket(b.ctx.WithBlockHeight(int64(packet.Timeout)), packet, nil, 1)
+	require.Error(t, err)
+	require.Error(t, a.keeper.TimeoutCreditPacket(a.ctx, packet, nil, packet.Timeout-1, 0))
+	require.NoError(t, a.keeper.TimeoutCreditPacket(a.ctx, packet, nil, packet.Timeout, 0))
+	requireLiquidUnits(t, a, credit, addr1, 50)
+	requireLiquidUnits(t, a, credit, CreditEscrowAddress(channelAtoB), 40)
+	// the packet can't be refunded twice
+	require.Error(t, a.keeper.TimeoutCreditPacket(a.ctx, packet, nil, packet.Timeout, 0))
+
+	requireInvariants(t, a)
+	requireInvariants(t, b)
+}
+
+func TestIBCEscrowReversal(t *testing.T) {
+	a := newTestChain(t, channelAtoB, channelBtoA)
+	class, err := a.keeper.CreateCreditClass(a.ctx, testCreditClass())
+	require.NoError(t, err)
+	credit, err := a.keeper.IssueCredit(a.ctx, testCredit(class), addr1)
+	require.NoError(t, err)
+	require.NoError(t, a.keeper.TransferCredit(a.ctx, channelAtoB, credit, sdk.NewDec(30), addr1, addr2))
+	escrow := CreditEscrowAddress(channelAtoB)
+
+	// the units sent to other chains can't be topped up or issued to outside of IBC
+	err = a.keeper.SendCredit(a.ctx, credit, addr1, escrow, sdk.NewDec(1))
+	require.Error(t, err)
+	require.Equal(t, sdk.CodeInvalidAddress, err.(sdk.Error).Code())
+	_, err = a.keeper.IssueCredit(a.ctx, testCredit(class), escrow)
+	require.Error(t, err)
+	require.Equal(t, sdk.CodeInvalidAddress, err.(sdk.Error).Code())
+	data := CreditPacketData{Credit: credit, Metadata: CreditMetadata{CreditClass: class}, Units: sdk.NewDec(1),
+		Sender: addr2, Receiver: escrow}
+	packet := channel.NewPacket(1, 100, PortID, channelBtoA, PortID, channelAtoB, ModuleCdc.MustMarshalJSON(data))
+	ack, err := a.keeper.ReceiveCreditPacket(a.ctx, packet, nil, 1)
+	require.NoError(t, err)
+	require.False(t, ack.Success)
+
+	// a reversal leaves the escrowed units alone so that the packet can still be refunded
+	_, err = a.keeper.ReverseCredit(a.ctx, credit, addr1, sdk.NewDec(70), "fire")
+	require.NoError(t, err)
+	requireLiquidUnits(t, a, credit, addr1, 0)
+	requireLiquidUnits(t, a, credit, escrow, 30)
+	_, err = a.keeper.ReverseCredit(a.ctx, credit, addr1, sdk.NewDec(1), "fire")
+	require.Error(t, err)
+	require.Equal(t, CodeInvalidReversal, err.(sdk.Error).Code())
+	sent := a.channels.sent[len(a.channels.sent)-1]
+	require.NoError(t, a.keeper.TimeoutCreditPacket(a.ctx, sent, nil, sent.Timeout, 0))
+	requireLiquidUnits(t, a, credit, addr1, 30)
+	requireLiquidUnits(t, a, credit, escrow, 0)
+
+	requireInvariants(t, a)
+}
+
+func TestIBCReceiveInvalidCreditPacket(t *testing.T) {
+	a := newTestChain(t, channelAtoB, channelBtoA)
+
+	// units which were never escrowed can't be released by a packet claiming to return them
+	data := CreditPacketData{Credit: CreditID{1}, Metadata: CreditMetadata{CreditClass: CreditClassID{1}},
+		Units: sdk.NewDec(1), Sender: addr1, Receiver: addr2}
+	packet := channel.NewPacket(1, 100, PortID, channelBtoA, PortID, channelAtoB, ModuleCdc.MustMarshalJSON(data))
+	ack, err := a.keeper.ReceiveCreditPacket(a.ctx, packet, nil, 1)
+	require.NoError(t, err)
+	require.False(t, ack.Success)
+
+	// a foreign class with a precision beyond the decimals of units is rejected without creating vouchers
+	class := testCreditClass()
+	class.Precision = sdk.Precision + 1
+	data = CreditPacketData{Credit: CreditID{1}, Class: class, Metadata: testCredit(CreditClassID{1}),
+		Units: sdk.NewDec(1), Sender: addr1, Receiver: addr2, Source: true}
+	packet = channel.NewPacket(2, 100, PortID, channelBtoA, PortID, channelAtoB, ModuleCdc.MustMarshalJSON(data))
+	ack, err = a.keeper.ReceiveCreditPacket(a.ctx, packet, nil, 1)
+	require.NoError(t, err)
+	require.False(t, ack.Success)
+	// so is a foreign credit with malformed metadata
+	data.Class.Precision = 6
+	data.Metadata.EndDate = data.Metadata.StartDate
+	packet = channel.NewPacket(3, 100, PortID, channelBtoA, PortID, channelAtoB, ModuleCdc.MustMarshalJSON(data))
+	ack, err = a.keeper.ReceiveCreditPacket(a.ctx, packet, nil, 1)
+	require.NoError(t, err)
+	require.False(t, ack.Success)
+	a.keeper.IterateCreditClasses(a.ctx, func(id CreditClassID, metadata CreditClassMetadata) (stop bool) {
+		t.Errorf("unexpected credit class %s", id)
+		return true
+	})
+
+	// packets of other ports are not handled by the ecocredit module
+	packet = channel.NewPacket(4, 100, "transfer", channelBtoA, "transfer", channelAtoB, ModuleCdc.MustMarshalJSON(data))
+	_, err = a.keeper.ReceiveCreditPacket(a.ctx, packet, nil, 1)
+	require.Error(t, err)
+}
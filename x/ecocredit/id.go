@@ -0,0 +1,240 @@
+package ecocredit
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/tendermint/tendermint/libs/bech32"
+	"strconv"
+)
+
+const (
+	// Bech32PrefixCreditClass is the human readable part of bech32 encoded credit class IDs
+	Bech32PrefixCreditClass = "ecocls"
+	// Bech32PrefixCredit is the human readable part of bech32 encoded credit IDs
+	Bech32PrefixCredit = "ecocrd"
+	// Bech32PrefixRetirement is the human readable part of bech32 encoded retirement certificate IDs
+	Bech32PrefixRetirement = "ecoret"
+	// Bech32PrefixSellOrder is the human readable part of bech32 encoded sell order IDs
+	Bech32PrefixSellOrder = "ecosel"
+	// Bech32PrefixAuction is the human readable part of bech32 encoded auction IDs
+	Bech32PrefixAuction = "ecoauc"
+	// Bech32PrefixAuctionBid is the human readable part of bech32 encoded auction bid IDs
+	Bech32PrefixAuctionBid = "ecobid"
+	// Bech32PrefixReversal is the human readable part of bech32 encoded reversal IDs
+	Bech32PrefixReversal = "ecorev"
+	// Bech32PrefixConversion is the human readable part of bech32 encoded conversion IDs
+	Bech32PrefixConversion = "ecocnv"
+	// Bech32PrefixBondUnbonding is the human readable part of bech32 encoded bond unbonding IDs
+	Bech32PrefixBondUnbonding = "ecounb"
+	// Bech32PrefixTransfer is the human readable part of bech32 encoded transfer IDs
+	Bech32PrefixTransfer = "ecotrf"
+)
+
+// CreditClassFromBech32 decodes a bech32 encoded credit class ID
+func CreditClassFromBech32(bech string) (CreditClassID, error) {
+	return idFromBech32(Bech32PrefixCreditClass, "credit class", bech)
+}
+
+// CreditFromBech32 decodes a bech32 encoded credit ID
+func CreditFromBech32(bech string) (CreditID, error) {
+	return idFromBech32(Bech32PrefixCredit, "credit", bech)
+}
+
+// RetirementFromBech32 decodes a bech32 encoded retirement ID
+func RetirementFromBech32(bech string) (RetirementID, error) {
+	return idFromBech32(Bech32PrefixRetirement, "retirement", bech)
+}
+
+// SellOrderFromBech32 decodes a bech32 encoded sell order ID
+func SellOrderFromBech32(bech string) (SellOrderID, error) {
+	return idFromBech32(Bech32PrefixSellOrder, "sell order", bech)
+}
+
+// AuctionFromBech32 decodes a bech32 encoded auction ID
+func AuctionFromBech32(bech string) (AuctionID, error) {
+	return idFromBech32(Bech32PrefixAuction, "auction", bech)
+}
+
+// AuctionBidFromBech32 decodes a bech32 encoded auction bid ID
+func AuctionBidFromBech32(bech string) (AuctionBidID, error) {
+	return idFromBech32(Bech32PrefixAuctionBid, "auction bid", bech)
+}
+
+// ReversalFromBech32 decodes a bech32 encoded reversal ID
+func ReversalFromBech32(bech string) (ReversalID, error) {
+	return idFromBech32(Bech32PrefixReversal, "reversal", bech)
+}
+
+// ConversionFromBech32 decodes a bech32 encoded conversion ID
+func ConversionFromBech32(bech string) (ConversionID, error) {
+	return idFromBech32(Bech32PrefixConversion, "conversion", bech)
+}
+
+// BondUnbondingFromBech32 decodes a bech32 encoded bond unbonding ID
+func BondUnbondingFromBech32(bech string) (BondUnbondingID, error) {
+	return idFromBech32(Bech32PrefixBondUnbonding, "bond unbonding", bech)
+}
+
+// TransferFromBech32 decodes a bech32 encoded transfer ID
+func TransferFromBech32(bech string) (TransferID, error) {
+	return idFromBech32(Bech32PrefixTransfer, "transfer", bech)
+}
+
+func idFromBech32(prefix string, kind string, bech string) ([]byte, error) {
+	hrp, bz, err := bech32.DecodeAndConvert(bech)
+	if err == nil && hrp != prefix {
+		err = fmt.Errorf("expected prefix %s, got %s", prefix, hrp)
+	}
+	if err == nil && len(bz) == 0 {
+		err = fmt.Errorf("empty ID")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s ID %s: %s", kind, bech, err)
+	}
+	return bz, nil
+}
+
+func idToBech32(prefix string, id []byte) string {
+	if len(id) == 0 {
+		return ""
+	}
+	bech, err := bech32.ConvertAndEncode(prefix, id)
+	if err != nil {
+		panic(err)
+	}
+	return bech
+}
+
+// formatID formats an ID with its bech32 encoding for the %s and %v verbs and with the raw bytes for any other verb,
+// so that %x keeps formatting IDs in hex as store keys and coin denominations do. Flags, width and precision apply
+// as usual
+func formatID(s fmt.State, verb rune, prefix string, id []byte) {
+	switch verb {
+	case 's', 'v':
+		_, _ = fmt.Fprintf(s, formatDirective(s, 's'), idToBech32(prefix, id))
+	default:
+		_, _ = fmt.Fprintf(s, formatDirective(s, verb), id)
+	}
+}
+
+// formatDirective rebuilds the directive for the verb with the flags, width and precision of the state
+func formatDirective(s fmt.State, verb rune) string {
+	directive := "%"
+	for _, flag := range "+-# 0" {
+		if s.Flag(int(flag)) {
+			directive += string(flag)
+		}
+	}
+	if width, ok := s.Width(); ok {
+		directive += strconv.Itoa(width)
+	}
+	if precision, ok := s.Precision(); ok {
+		directive += "." + strconv.Itoa(precision)
+	}
+	return directive + string(verb)
+}
+
+// unmarshalJSONID decodes an ID from a JSON string holding its bech32 encoding, an empty string decodes to an empty ID
+func unmarshalJSONID(data []byte, prefix string, kind string, id *[]byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	if s == "" {
+		*id = nil
+		return nil
+	}
+	bz, err := idFromBech32(prefix, kind, s)
+	if err != nil {
+		return err
+	}
+	*id = bz
+	return nil
+}
+
+// All ID types are strings of bytes that are encoded in bech32 with their own prefix when printed with %s or %v and in
+// JSON and YAML, see idToBech32, formatID and unmarshalJSONID
+
+func (id CreditClassID) String() string { return idToBech32(Bech32PrefixCreditClass, id) }
+func (id CreditClassID) Format(s fmt.State, verb rune) {
+	formatID(s, verb, Bech32PrefixCreditClass, id)
+}
+func (id CreditClassID) MarshalJSON() ([]byte, error)      { return json.Marshal(id.String()) }
+func (id CreditClassID) MarshalYAML() (interface{}, error) { return id.String(), nil }
+func (id *CreditClassID) UnmarshalJSON(data []byte) error {
+	return unmarshalJSONID(data, Bech32PrefixCreditClass, "credit class", (*[]byte)(id))
+}
+
+func (id CreditID) String() string                    { return idToBech32(Bech32PrefixCredit, id) }
+func (id CreditID) Format(s fmt.State, verb rune)     { formatID(s, verb, Bech32PrefixCredit, id) }
+func (id CreditID) MarshalJSON() ([]byte, error)      { return json.Marshal(id.String()) }
+func (id CreditID) MarshalYAML() (interface{}, error) { return id.String(), nil }
+func (id *CreditID) UnmarshalJSON(data []byte) error {
+	return unmarshalJSONID(data, Bech32PrefixCredit, "credit", (*[]byte)(id))
+}
+
+func (id RetirementID) String() string                    { return idToBech32(Bech32PrefixRetirement, id) }
+func (id RetirementID) Format(s fmt.State, verb rune)     { formatID(s, verb, Bech32PrefixRetirement, id) }
+func (id RetirementID) MarshalJSON() ([]byte, error)      { return json.Marshal(id.String()) }
+func (id RetirementID) MarshalYAML() (interface{}, error) { return id.String(), nil }
+func (id *RetirementID) UnmarshalJSON(data []byte) error {
+	return unmarshalJSONID(data, Bech32PrefixRetirement, "retirement", (*[]byte)(id))
+}
+
+func (id SellOrderID) String() string                    { return idToBech32(Bech32PrefixSellOrder, id) }
+func (id SellOrderID) Format(s fmt.State, verb rune)     { formatID(s, verb, Bech32PrefixSellOrder, id) }
+func (id SellOrderID) MarshalJSON() ([]byte, error)      { return json.Marshal(id.String()) }
+func (id SellOrderID) MarshalYAML() (interface{}, error) { return id.String(), nil }
+func (id *SellOrderID) UnmarshalJSON(data []byte) error {
+	return unmarshalJSONID(data, Bech32PrefixSellOrder, "sell order", (*[]byte)(id))
+}
+
+func (id AuctionID) String() string                    { return idToBech32(Bech32PrefixAuction, id) }
+func (id AuctionID) Format(s fmt.State, verb rune)     { formatID(s, verb, Bech32PrefixAuction, id) }
+func (id AuctionID) MarshalJSON() ([]byte, error)      { return json.Marshal(id.String()) }
+func (id AuctionID) MarshalYAML() (interface{}, error) { return id.String(), nil }
+func (id *AuctionID) UnmarshalJSON(data []byte) error {
+	return unmarshalJSONID(data, Bech32PrefixAuction, "auction", (*[]byte)(id))
+}
+
+func (id AuctionBidID) String() string                    { return idToBech32(Bech32PrefixAuctionBid, id) }
+func (id AuctionBidID) Format(s fmt.State, verb rune)     { formatID(s, verb, Bech32PrefixAuctionBid, id) }
+func (id AuctionBidID) MarshalJSON() ([]byte, error)      { return json.Marshal(id.String()) }
+func (id AuctionBidID) MarshalYAML() (interface{}, error) { return id.String(), nil }
+func (id *AuctionBidID) UnmarshalJSON(data []byte) error {
+	return unmarshalJSONID(data, Bech32PrefixAuctionBid, "auction bid", (*[]byte)(id))
+}
+
+func (id ReversalID) String() string                    { return idToBech32(Bech32PrefixReversal, id) }
+func (id ReversalID) Format(s fmt.State, verb rune)     { formatID(s, verb, Bech32PrefixReversal, id) }
+func (id ReversalID) MarshalJSON() ([]byte, error)      { return json.Marshal(id.String()) }
+func (id ReversalID) MarshalYAML() (interface{}, error) { return id.String(), nil }
+func (id *ReversalID) UnmarshalJSON(data []byte) error {
+	return unmarshalJSONID(data, Bech32PrefixReversal, "reversal", (*[]byte)(id))
+}
+
+func (id ConversionID) String() string                    { return idToBech32(Bech32PrefixConversion, id) }
+func (id ConversionID) Format(s fmt.State, verb rune)     { formatID(s, verb, Bech32PrefixConversion, id) }
+func (id ConversionID) MarshalJSON() ([]byte, error)      { return json.Marshal(id.String()) }
+func (id ConversionID) MarshalYAML() (interface{}, error) { return id.String(), nil }
+func (id *ConversionID) UnmarshalJSON(data []byte) error {
+	return unmarshalJSONID(data, Bech32PrefixConversion, "conversion", (*[]byte)(id))
+}
+
+func (id BondUnbondingID) String() string { return idToBech32(Bech32PrefixBondUnbonding, id) }
+func (id BondUnbondingID) Format(s fmt.State, verb rune) {
+	formatID(s, verb, Bech32PrefixBondUnbonding, id)
+}
+func (id BondUnbondingID) MarshalJSON() ([]byte, error)      { return json.Marshal(id.String()) }
+func (id BondUnbondingID) MarshalYAML() (interface{}, error) { return id.String(), nil }
+func (id *BondUnbondingID) UnmarshalJSON(data []byte) error {
+	return unmarshalJSONID(data, Bech32PrefixBondUnbonding, "bond unbonding", (*[]byte)(id))
+}
+
+func (id TransferID) String() string                    { return idToBech32(Bech32PrefixTransfer, id) }
+func (id TransferID) Format(s fmt.State, verb rune)     { formatID(s, verb, Bech32PrefixTransfer, id) }
+func (id TransferID) MarshalJSON() ([]byte, error)      { return json.Marshal(id.String()) }
+func (id TransferID) MarshalYAML() (interface{}, error) { return id.String(), nil }
+func (id *TransferID) UnmarshalJSON(data []byte) error {
+	return unmarshalJSONID(data, Bech32PrefixTransfer, "transfer", (*[]byte)(id))
+}
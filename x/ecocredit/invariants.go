@@ -0,0 +1,291 @@
+package ecocredit
+
+import (
+	"fmt"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"sort"
+	"strings"
+)
+
+// RegisterInvariants registers the ecocredit module invariants
+func RegisterInvariants(ir sdk.InvariantRegistry, k Keeper) {
+	ir.RegisterRoute(ModuleName, "nonnegative-holdings", NonnegativeHoldingsInvariant(k))
+	ir.RegisterRoute(ModuleName, "supply", SupplyInvariant(k))
+	ir.RegisterRoute(ModuleName, "sell-order-escrow", SellOrderEscrowInvariant(k))
+	ir.RegisterRoute(ModuleName, "auction-escrow", AuctionEscrowInvariant(k))
+	ir.RegisterRoute(ModuleName, "wrapped-supply", WrappedSupplyInvariant(k))
+	ir.RegisterRoute(ModuleName, "bond-pool", BondPoolInvariant(k))
+}
+
+// NonnegativeHoldingsInvariant checks that no holding has negative liquid or burned units
+func NonnegativeHoldingsInvariant(k Keeper) sdk.Invariant {
+	return func(ctx sdk.Context) (string, bool) {
+		var msg string
+		var count int
+
+		k.IterateCreditHoldings(ctx, func(holding CreditHolding) (stop bool) {
+			if holding.LiquidUnits.IsNegative() || holding.BurnedUnits.IsNegative() {
+				count++
+				msg += fmt.Sprintf("\t%s holds %s liquid and %s burned units of credit %s\n",
+					holding.Holder, holding.LiquidUnits, holding.BurnedUnits, holding.Credit)
+			}
+			return false
+		})
+		broken := count != 0
+
+		return sdk.FormatInvariant(ModuleName, "nonnegative-holdings",
+			fmt.Sprintf("amount of negative holdings found %d\n%s", count, msg)), broken
+	}
+}
+
+// SupplyInvariant checks that the liquid and burned units of all holdings of each credit add up to its liquid and
+// retired supply, that its expired holdings add up to its expired supply, that the units converted from it by splits
+// and merges add up to its converted supply, and that its issued supply is the sum of these and of its reversed supply
+func SupplyInvariant(k Keeper) sdk.Invariant {
+	return func(ctx sdk.Context) (string, bool) {
+		liquid := make(map[string]sdk.Dec)
+		retired := make(map[string]sdk.Dec)
+		k.IterateCreditHoldings(ctx, func(holding CreditHolding) (stop bool) {
+			key := string(holding.Credit)
+			if _, found := liquid[key]; !found {
+				liquid[key], retired[key] = sdk.ZeroDec(), sdk.ZeroDec()
+			}
+			liquid[key] = liquid[key].Add(holding.LiquidUnits)
+			retired[key] = retired[key].Add(holding.BurnedUnits)
+			return false
+		})
+		expired := make(map[string]sdk.Dec)
+		k.IterateExpiredHoldings(ctx, func(holding ExpiredHolding) (stop bool) {
+			key := string(holding.Credit)
+			if _, found := expired[key]; !found {
+				expired[key] = sdk.ZeroDec()
+			}
+			expired[key] = expired[key].Add(holding.Units)
+			return false
+		})
+		converted := make(map[string]sdk.Dec)
+		k.IterateConversions(ctx, func(id ConversionID, conversion Conversion) (stop bool) {
+			for _, source := range conversion.Sources {
+				key := string(source.Credit)
+				if _, found := converted[key]; !found {
+					converted[key] = sdk.ZeroDec()
+				}
+				converted[key] = converted[key].Add(source.Units)
+			}
+			return false
+		})
+
+		var msg string
+		var count int
+		k.IterateCreditSupplies(ctx, func(supply CreditSupply) (stop bool) {
+			key := string(supply.Credit)
+			held, found := liquid[key]
+			if !found {
+				held, retired[key] = sdk.ZeroDec(), sdk.ZeroDec()
+			}
+			delete(liquid, key)
+			outdated, found := expired[key]
+			if !found {
+				outdated = sdk.ZeroDec()
+			}
+			sources, found := converted[key]
+			if !found {
+				sources = sdk.ZeroDec()
+			}
+			if !held.Equal(supply.Liquid) || !retired[key].Equal(supply.Retired) || !outdated.Equal(supply.Expired) ||
+				!sources.Equal(supply.Converted) ||
+				!supply.Issued.Equal(supply.Liquid.Add(supply.Retired).Add(supply.Reversed).Add(supply.Expired).Add(supply.Converted)) {
+				count++
+				msg += fmt.Sprintf("\tcredit %s has a supply of %s issued, %s liquid, %s retired, %s reversed, %s expired and %s converted units but holdings of %s liquid, %s burned and %s expired units and %s converted units\n",
+					supply.Credit, supply.Issued, supply.Liquid, supply.Retired, supply.Reversed, supply.Expired, supply.Converted, held, retired[key], outdated, sources)
+			}
+			return false
+		})
+		for _, key := range sortedKeys(liquid) {
+			count++
+			msg += fmt.Sprintf("\tcredit %s has holdings of %s liquid and %s burned units but no supply\n",
+				CreditID(key), liquid[key], retired[key])
+		}
+		broken := count != 0
+
+		return sdk.FormatInvariant(ModuleName, "supply",
+			fmt.Sprintf("amount of credits with mismatched supply found %d\n%s", count, msg)), broken
+	}
+}
+
+// SellOrderEscrowInvariant checks that the liquid units held in escrow for each credit equal the remaining units of
+// its open sell orders
+func SellOrderEscrowInvariant(k Keeper) sdk.Invariant {
+	return func(ctx sdk.Context) (string, bool) {
+		offered := make(map[string]sdk.Dec)
+		k.IterateSellOrders(ctx, func(id SellOrderID, order SellOrder) (stop bool) {
+			key := string(order.Credit)
+			if _, found := offered[key]; !found {
+				offered[key] = sdk.ZeroDec()
+			}
+			offered[key] = offered[key].Add(order.Units)
+			return false
+		})
+
+		var msg string
+		var count int
+		k.IterateHoldingsByHolder(ctx, SellOrderEscrowAddress, func(holding CreditHolding) (stop bool) {
+			key := string(holding.Credit)
+			units, found := offered[key]
+			if !found {
+				units = sdk.ZeroDec()
+			}
+			delete(offered, key)
+			if !units.Equal(holding.LiquidUnits) {
+				count++
+				msg += fmt.Sprintf("\tcredit %s has %s units in escrow but %s units offered by sell orders\n",
+					holding.Credit, holding.LiquidUnits, units)
+			}
+			return false
+		})
+		for _, key := range sortedKeys(offered) {
+			count++
+			msg += fmt.Sprintf("\tcredit %s has no units in escrow but %s units offered by sell orders\n", CreditID(key), offered[key])
+		}
+		broken := count != 0
+
+		return sdk.FormatInvariant(ModuleName, "sell-order-escrow",
+			fmt.Sprintf("amount of credits with mismatched escrow found %d\n%s", count, msg)), broken
+	}
+}
+
+// AuctionEscrowInvariant checks that the liquid units held in escrow for each credit equal the units of its open
+// auctions and that the coins held in escrow cover the coins locked by all bids. Coins can't be kept from being sent to
+// the escrow address, so it may hold more
+func AuctionEscrowInvariant(k Keeper) sdk.Invariant {
+	return func(ctx sdk.Context) (string, bool) {
+		offered := make(map[string]sdk.Dec)
+		k.IterateAuctions(ctx, func(id AuctionID, auction Auction) (stop bool) {
+			key := string(auction.Credit)
+			if _, found := offered[key]; !found {
+				offered[key] = sdk.ZeroDec()
+			}
+			offered[key] = offered[key].Add(auction.Units)
+			return false
+		})
+		locked := sdk.NewCoins()
+		k.IterateAuctionBids(ctx, func(id AuctionBidID, bid AuctionBid) (stop bool) {
+			locked = locked.Add(sdk.NewCoins(bid.Locked()))
+			return false
+		})
+
+		var msg string
+		var count int
+		k.IterateHoldingsByHolder(ctx, AuctionEscrowAddress, func(holding CreditHolding) (stop bool) {
+			key := string(holding.Credit)
+			units, found := offered[key]
+			if !found {
+				units = sdk.ZeroDec()
+			}
+			delete(offered, key)
+			if !units.Equal(holding.LiquidUnits) {
+				count++
+				msg += fmt.Sprintf("\tcredit %s has %s units in escrow but %s units offered by auctions\n",
+					holding.Credit, holding.LiquidUnits, units)
+			}
+			return false
+		})
+		for _, key := range sortedKeys(offered) {
+			count++
+			msg += fmt.Sprintf("\tcredit %s has no units in escrow but %s units offered by auctions\n", CreditID(key), offered[key])
+		}
+		if escrowed := k.bankKeeper.GetCoins(ctx, AuctionEscrowAddress); !escrowed.IsAllGTE(locked) {
+			count++
+			msg += fmt.Sprintf("\t%s in escrow but %s locked by bids\n", escrowed, locked)
+		}
+		broken := count != 0
+
+		return sdk.FormatInvariant(ModuleName, "auction-escrow",
+			fmt.Sprintf("amount of mismatched auction escrows found %d\n%s", count, msg)), broken
+	}
+}
+
+// WrappedSupplyInvariant checks that the supply of the wrapped denom of each credit equals the units locked by wrapping
+// times the wrap ratio of the credit
+func WrappedSupplyInvariant(k Keeper) sdk.Invariant {
+	return func(ctx sdk.Context) (string, bool) {
+		wrapped := make(map[string]sdk.Int)
+		for _, coin := range k.supplyKeeper.GetSupply(ctx).GetTotal() {
+			if strings.HasPrefix(coin.Denom, WrappedDenomPrefix) {
+				wrapped[coin.Denom] = coin.Amount
+			}
+		}
+
+		var msg string
+		var count int
+		k.IterateHoldingsByHolder(ctx, WrapEscrowAddress, func(holding CreditHolding) (stop bool) {
+			denom := WrappedDenom(holding.Credit)
+			supply, found := wrapped[denom]
+			if !found {
+				supply = sdk.ZeroInt()
+			}
+			delete(wrapped, denom)
+			ratio, err := k.wrapRatio(ctx, holding.Credit)
+			if err != nil || !holding.LiquidUnits.MulInt(ratio).Equal(supply.ToDec()) {
+				count++
+				msg += fmt.Sprintf("\tcredit %s has %s units locked but a wrapped supply of %s%s\n",
+					holding.Credit, holding.LiquidUnits, supply, denom)
+			}
+			return false
+		})
+		denoms := make([]string, 0, len(wrapped))
+		for denom := range wrapped {
+			denoms = append(denoms, denom)
+		}
+		sort.Strings(denoms)
+		for _, denom := range denoms {
+			supply := wrapped[denom]
+			if supply.IsZero() {
+				continue
+			}
+			count++
+			msg += fmt.Sprintf("\twrapped supply of %s%s but no units locked\n", supply, denom)
+		}
+		broken := count != 0
+
+		return sdk.FormatInvariant(ModuleName, "wrapped-supply",
+			fmt.Sprintf("amount of mismatched wrapped supplies found %d\n%s", count, msg)), broken
+	}
+}
+
+// BondPoolInvariant checks that the bond pool holds at least the coins of all issuer bonds and pending bond
+// unbondings
+func BondPoolInvariant(k Keeper) sdk.Invariant {
+	return func(ctx sdk.Context) (string, bool) {
+		var bonded sdk.Coins
+		k.IterateIssuerBonds(ctx, func(bond IssuerBond) (stop bool) {
+			bonded = bonded.Add(bond.Amount)
+			return false
+		})
+		k.IterateBondUnbondings(ctx, func(id BondUnbondingID, unbonding BondUnbonding) (stop bool) {
+			bonded = bonded.Add(unbonding.Amount)
+			return false
+		})
+
+		var msg string
+		var count int
+		if pool := k.bankKeeper.GetCoins(ctx, BondPoolAddress()); !pool.IsAllGTE(bonded) {
+			count++
+			msg += fmt.Sprintf("\t%s in the bond pool but %s bonded\n", pool, bonded)
+		}
+		broken := count != 0
+
+		return sdk.FormatInvariant(ModuleName, "bond-pool",
+			fmt.Sprintf("amount of mismatched bond pools found %d\n%s", count, msg)), broken
+	}
+}
+
+// sortedKeys returns the keys of units by credit in order, so that broken invariant messages are deterministic
+func sortedKeys(units map[string]sdk.Dec) []string {
+	keys := make([]string, 0, len(units))
+	for key := range units {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
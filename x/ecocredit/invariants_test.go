@@ -0,0 +1,68 @@
+package ecocredit
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+func TestSupplyInvariants(t *testing.T) {
+	ctx, k := createTestInput(t)
+	class, err := k.CreateCreditClass(ctx, testCreditClass())
+	require.NoError(t, err)
+	metadata := testCredit(class)
+	metadata.BurnedUnits = sdk.NewDec(5)
+	credit, err := k.IssueCredit(ctx, metadata, addr1)
+	require.NoError(t, err)
+	require.NoError(t, k.SendCredit(ctx, credit, addr1, addr2, sdk.NewDec(40)))
+	_, err = k.BurnCredit(ctx, credit, addr2, sdk.NewDec(10), RetirementInfo{})
+	require.NoError(t, err)
+
+	supply, found := k.GetCreditSupply(ctx, credit)
+	require.True(t, found)
+	require.Equal(t, CreditSupply{Credit: credit, Issued: sdk.NewDec(105), Liquid: sdk.NewDec(90), Retired: sdk.NewDec(15),
+		Reversed: sdk.ZeroDec(), Expired: sdk.ZeroDec(), Converted: sdk.ZeroDec()}, supply)
+
+	_, broken := SupplyInvariant(k)(ctx)
+	require.False(t, broken)
+	_, broken = NonnegativeHoldingsInvariant(k)(ctx)
+	require.False(t, broken)
+
+	// a failed send or burn leaves the supply untouched
+	require.Error(t, k.SendCredit(ctx, credit, addr2, addr1, sdk.NewDec(31)))
+	_, err = k.BurnCredit(ctx, credit, addr2, sdk.NewDec(31), RetirementInfo{})
+	require.Error(t, err)
+	_, broken = SupplyInvariant(k)(ctx)
+	require.False(t, broken)
+
+	holding, _ := k.GetCreditHolding(ctx, credit, addr2)
+	holding.LiquidUnits = sdk.NewDec(-1)
+	require.NoError(t, k.creditHoldingsBucket.Save(ctx, holding))
+	_, broken = SupplyInvariant(k)(ctx)
+	require.True(t, broken)
+	_, broken = NonnegativeHoldingsInvariant(k)(ctx)
+	require.True(t, broken)
+}
+
+func TestInvariantMessageOrder(t *testing.T) {
+	ctx, k := createTestInput(t)
+	for i := byte(1); i <= 5; i++ {
+		holding := CreditHolding{Credit: CreditID{0, 0, 0, 0, 0, 0, 0, i}, Holder: addr1, LiquidUnits: sdk.NewDec(1),
+			BurnedUnits: sdk.ZeroDec()}
+		require.NoError(t, k.creditHoldingsBucket.Save(ctx, holding))
+	}
+
+	// holdings without a supply are reported in the order of their credits on every run
+	msg, broken := SupplyInvariant(k)(ctx)
+	require.True(t, broken)
+	for i := 0; i < 10; i++ {
+		again, _ := SupplyInvariant(k)(ctx)
+		require.Equal(t, msg, again)
+	}
+	first := strings.Index(msg, CreditID{0, 0, 0, 0, 0, 0, 0, 1}.String())
+	last := strings.Index(msg, CreditID{0, 0, 0, 0, 0, 0, 0, 5}.String())
+	require.True(t, first >= 0 && first < last)
+}
@@ -4,52 +4,202 @@ for usage as ecosystem service credits. The model could be generalized for other
 package ecocredit
 
 import (
+	"bytes"
 	"fmt"
-	"github.com/btcsuite/btcutil/bech32"
 	"github.com/cosmos/cosmos-sdk/codec"
 	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/x/bank"
+	"github.com/cosmos/cosmos-sdk/x/supply"
+	"github.com/cosmos/gaia/geo"
 	"github.com/cosmos/gaia/orm"
+	"github.com/tendermint/tendermint/libs/log"
 )
 
 type Keeper struct {
-	cdc                  *codec.Codec
-	storeKey             sdk.StoreKey
-	creditClassBucket    orm.AutoIDBucket
-	creditBucket         orm.AutoIDBucket
-	creditHoldingsBucket orm.NaturalKeyBucket
+	cdc                      *codec.Codec
+	storeKey                 sdk.StoreKey
+	bankKeeper               bank.Keeper
+	supplyKeeper             supply.Keeper
+	channelKeeper            ChannelKeeper
+	portKey                  sdk.CapabilityKey
+	creditClassBucket        orm.AutoIDBucket
+	creditBucket             orm.AutoIDBucket
+	creditHoldingsBucket     orm.NaturalKeyBucket
+	creditSupplyBucket       orm.NaturalKeyBucket
+	creditAllowanceBucket    orm.NaturalKeyBucket
+	retirementBucket         orm.AutoIDBucket
+	sellOrderBucket          orm.AutoIDBucket
+	auctionBucket            orm.AutoIDBucket
+	auctionBidBucket         orm.AutoIDBucket
+	creditVoucherBucket      orm.NaturalKeyBucket
+	creditClassVoucherBucket orm.NaturalKeyBucket
+	creditEscrowBucket       orm.NaturalKeyBucket
+	reversalBucket           orm.AutoIDBucket
+	anchoredDataBucket       orm.NaturalKeyBucket
+	attestationBucket        orm.NaturalKeyBucket
+	issuerBondBucket         orm.NaturalKeyBucket
+	bondUnbondingBucket      orm.AutoIDBucket
+	creditExpiryBucket       orm.NaturalKeyBucket
+	expiredHoldingBucket     orm.NaturalKeyBucket
+	conversionBucket         orm.AutoIDBucket
+	creditLineageBucket      orm.NaturalKeyBucket
+	issuanceBucket           orm.NaturalKeyBucket
+	transferBucket           orm.AutoIDBucket
+	hooks                    EcocreditHooks
 }
 
 const (
-	IndexByGeoPolygon = "polygon"
+	IndexByGeoPolygon  = "polygon"
+	IndexByCreditClass = "class"
+	IndexByHolder      = "holder"
+	IndexByCredit      = "credit"
+	IndexBySeller      = "seller"
+	IndexByEndTime     = "end-time"
+	IndexByAuction     = "auction"
+	IndexByTrace       = "trace"
+	IndexByDataHash    = "data-hash"
+	IndexByIssuerBond  = "issuer-bond"
 )
 
-func NewKeeper(cdc *codec.Codec, storeKey sdk.StoreKey) Keeper {
-	return Keeper{cdc: cdc, storeKey: storeKey,
+// NewKeeper creates the ecocredit keeper. The channel keeper and the capability key of the ecocredit port, see PortID,
+// are used to exchange credits with other chains over IBC
+func NewKeeper(cdc *codec.Codec, storeKey sdk.StoreKey, bankKeeper bank.Keeper, supplyKeeper supply.Keeper, channelKeeper ChannelKeeper, portKey sdk.CapabilityKey) Keeper {
+	return Keeper{cdc: cdc, storeKey: storeKey, bankKeeper: bankKeeper, supplyKeeper: supplyKeeper,
+		channelKeeper: channelKeeper, portKey: portKey,
 		creditClassBucket: orm.NewAutoIDBucket(storeKey, "credit-class", cdc, nil, nil),
 		creditBucket: orm.NewAutoIDBucket(storeKey, "credit", cdc, []orm.Index{
-			{IndexByGeoPolygon, func(key []byte, value interface{}) (indexValue []byte, err error) {
+			{Name: IndexByGeoPolygon, Indexer: func(key []byte, value interface{}) (indexValue []byte, err error) {
 				meta := value.(CreditMetadata)
 				return meta.GeoPolygon, nil
 			}},
+			{Name: IndexByCreditClass, Indexer: func(key []byte, value interface{}) (indexValue []byte, err error) {
+				meta := value.(CreditMetadata)
+				return meta.CreditClass, nil
+			}},
+		}, nil),
+		creditHoldingsBucket: orm.NewNaturalKeyBucket(storeKey, "credit-holdings", cdc, []orm.Index{
+			{Name: IndexByHolder, Indexer: func(key []byte, value interface{}) (indexValue []byte, err error) {
+				holding := value.(CreditHolding)
+				return holding.Holder, nil
+			}},
+			{Name: IndexByCredit, Indexer: func(key []byte, value interface{}) (indexValue []byte, err error) {
+				holding := value.(CreditHolding)
+				return holding.Credit, nil
+			}},
+		}),
+		creditSupplyBucket:    orm.NewNaturalKeyBucket(storeKey, "credit-supply", cdc, nil),
+		creditAllowanceBucket: orm.NewNaturalKeyBucket(storeKey, "credit-allowance", cdc, nil),
+		retirementBucket: orm.NewAutoIDBucket(storeKey, "retirement", cdc, []orm.Index{
+			{Name: IndexByHolder, Indexer: func(key []byte, value interface{}) (indexValue []byte, err error) {
+				retirement := value.(Retirement)
+				return retirement.Holder, nil
+			}},
+			{Name: IndexByCredit, Indexer: func(key []byte, value interface{}) (indexValue []byte, err error) {
+				retirement := value.(Retirement)
+				return retirement.Credit, nil
+			}},
+		}, nil),
+		sellOrderBucket: orm.NewAutoIDBucket(storeKey, "sell-order", cdc, []orm.Index{
+			{Name: IndexBySeller, Indexer: func(key []byte, value interface{}) (indexValue []byte, err error) {
+				order := value.(SellOrder)
+				return order.Seller, nil
+			}},
+			{Name: IndexByCredit, Indexer: func(key []byte, value interface{}) (indexValue []byte, err error) {
+				order := value.(SellOrder)
+				return order.Credit, nil
+			}},
+		}, nil),
+		auctionBucket: orm.NewAutoIDBucket(storeKey, "auction", cdc, []orm.Index{
+			{Name: IndexByEndTime, Indexer: func(key []byte, value interface{}) (indexValue []byte, err error) {
+				auction := value.(Auction)
+				return sdk.FormatTimeBytes(auction.EndTime), nil
+			}},
+		}, nil),
+		auctionBidBucket: orm.NewAutoIDBucket(storeKey, "auction-bid", cdc, []orm.Index{
+			{Name: IndexByAuction, Indexer: func(key []byte, value interface{}) (indexValue []byte, err error) {
+				bid := value.(AuctionBid)
+				return bid.Auction, nil
+			}},
+		}, nil),
+		creditVoucherBucket: orm.NewNaturalKeyBucket(storeKey, "credit-voucher", cdc, []orm.Index{
+			{Name: IndexByTrace, Indexer: func(key []byte, value interface{}) (indexValue []byte, err error) {
+				voucher := value.(CreditVoucher)
+				return voucherTrace(voucher.Path, voucher.Origin), nil
+			}},
+		}),
+		creditClassVoucherBucket: orm.NewNaturalKeyBucket(storeKey, "credit-class-voucher", cdc, []orm.Index{
+			{Name: IndexByTrace, Indexer: func(key []byte, value interface{}) (indexValue []byte, err error) {
+				voucher := value.(CreditClassVoucher)
+				return voucherTrace(voucher.Path, voucher.Origin), nil
+			}},
+		}),
+		creditEscrowBucket: orm.NewNaturalKeyBucket(storeKey, "credit-escrow", cdc, nil),
+		reversalBucket: orm.NewAutoIDBucket(storeKey, "reversal", cdc, []orm.Index{
+			{Name: IndexByCredit, Indexer: func(key []byte, value interface{}) (indexValue []byte, err error) {
+				reversal := value.(Reversal)
+				return reversal.Credit, nil
+			}},
+		}, nil),
+		anchoredDataBucket: orm.NewNaturalKeyBucket(storeKey, "anchored-data", cdc, nil),
+		attestationBucket: orm.NewNaturalKeyBucket(storeKey, "attestation", cdc, []orm.Index{
+			{Name: IndexByDataHash, Indexer: func(key []byte, value interface{}) (indexValue []byte, err error) {
+				attestation := value.(Attestation)
+				return attestation.Hash, nil
+			}},
+		}),
+		issuerBondBucket: orm.NewNaturalKeyBucket(storeKey, "issuer-bond", cdc, nil),
+		bondUnbondingBucket: orm.NewAutoIDBucket(storeKey, "bond-unbonding", cdc, []orm.Index{
+			{Name: IndexByEndTime, Indexer: func(key []byte, value interface{}) (indexValue []byte, err error) {
+				unbonding := value.(BondUnbonding)
+				return sdk.FormatTimeBytes(unbonding.CompletionTime), nil
+			}},
+			{Name: IndexByIssuerBond, Indexer: func(key []byte, value interface{}) (indexValue []byte, err error) {
+				unbonding := value.(BondUnbonding)
+				return IssuerBond{CreditClass: unbonding.CreditClass, Issuer: unbonding.Issuer}.ID(), nil
+			}},
+		}, nil),
+		creditExpiryBucket: orm.NewNaturalKeyBucket(storeKey, "credit-expiry", cdc, []orm.Index{
+			{Name: IndexByEndTime, Indexer: func(key []byte, value interface{}) (indexValue []byte, err error) {
+				expiry := value.(CreditExpiry)
+				return sdk.FormatTimeBytes(expiry.ExpiryTime), nil
+			}},
+		}),
+		expiredHoldingBucket: orm.NewNaturalKeyBucket(storeKey, "expired-holding", cdc, []orm.Index{
+			{Name: IndexByCredit, Indexer: func(key []byte, value interface{}) (indexValue []byte, err error) {
+				holding := value.(ExpiredHolding)
+				return holding.Credit, nil
+			}},
+		}),
+		conversionBucket:    orm.NewAutoIDBucket(storeKey, "conversion", cdc, nil, nil),
+		creditLineageBucket: orm.NewNaturalKeyBucket(storeKey, "credit-lineage", cdc, nil),
+		issuanceBucket:      orm.NewNaturalKeyBucket(storeKey, "issuance", cdc, nil),
+		transferBucket: orm.NewAutoIDBucket(storeKey, "transfer", cdc, []orm.Index{
+			{Name: IndexByCredit, Indexer: func(key []byte, value interface{}) (indexValue []byte, err error) {
+				transfer := value.(CreditTransfer)
+				return transfer.Credit, nil
+			}},
 		}, nil),
-		creditHoldingsBucket: orm.NewNaturalKeyBucket(storeKey, "credit-holdings", cdc, nil),
 	}
 }
 
-func CreditClassFromBech32(bech string) (CreditClassID, error) {
-	hrp, bz, err := bech32.Decode(bech)
-	if err != nil {
-		return nil, err
-	}
-	if hrp != "ecocls" {
-		return nil, fmt.Errorf("not a credit class %s", bech)
-	}
-	return bz, err
+// Logger returns a module-specific logger
+func (k Keeper) Logger(ctx sdk.Context) log.Logger {
+	return ctx.Logger().With("module", fmt.Sprintf("x/%s", ModuleName))
 }
 
 // CreateCreditClass creates a new credit class with a set of authorized issuers
 func (k Keeper) CreateCreditClass(ctx sdk.Context, metadata CreditClassMetadata) (CreditClassID, error) {
-	return k.creditClassBucket.Create(ctx, metadata)
+	id, err := k.creditClassBucket.Create(ctx, metadata)
+	if err != nil {
+		return nil, err
+	}
+	ctx.EventManager().EmitEvent(sdk.NewEvent(
+		EventTypeCreateCreditClass,
+		sdk.NewAttribute(AttributeKeyCreditClass, CreditClassID(id).String()),
+		sdk.NewAttribute(AttributeKeyDesigner, metadata.Designer.String()),
+	))
+	k.AfterClassCreated(ctx, id, metadata)
+	return id, nil
 }
 
 // CreditHolding describes the fractional holdings of a specific credit including units burned or in the language
@@ -65,21 +215,187 @@ func (c CreditHolding) ID() []byte {
 	return []byte(fmt.Sprintf("%x/%x", c.Credit, c.Holder))
 }
 
-// Issue credits issues some units of a credit class for a specific land area over a specific date range
+// Credit pairs the metadata of an issued credit with its ID
+type Credit struct {
+	ID       CreditID       `json:"id"`
+	Metadata CreditMetadata `json:"metadata"`
+}
+
+// Issue credits issues some units of a credit class for a specific land area over a specific date range to a single
+// holder. It is a batch issuance with a single entry, see IssueCreditBatch
 func (k Keeper) IssueCredit(ctx sdk.Context, metadata CreditMetadata, holder sdk.AccAddress) (CreditID, error) {
+	return k.IssueCreditBatch(ctx, metadata, []CreditIssuance{
+		{Holder: holder, LiquidUnits: metadata.LiquidUnits, RetiredUnits: metadata.BurnedUnits},
+	})
+}
+
+// IssueCreditBatch issues a single credit distributed over many holders. The liquid and burned units of the metadata
+// are set to the totals of the issuances. The issuer must be authorized by the credit class, which must not be
+// deprecated. The units must respect the precision of the class and the credit must provide the attributes the
+// class requires. Issuance fails if the polygon and dates overlap with those of an existing credit of the same
+// class and, if the class requires a bond, the issuer must have bonded at least that much. The datasets referenced
+// by the credit must be anchored and attested as the class requires, see
+// checkAttestations. Units issued directly into retired state, such as pre-sold offsets, are recorded as a retirement
+// certificate of their holder. The buffer rate of the class is withheld from the liquid units of every issuance and
+// held in the buffer pool of the class, see ReverseCredit. The issuances are recorded for registry reports with the
+// withheld units as an issuance to the buffer pool, see Report
+func (k Keeper) IssueCreditBatch(ctx sdk.Context, metadata CreditMetadata, issuances []CreditIssuance) (CreditID, error) {
+	class, found := k.GetCreditClass(ctx, metadata.CreditClass)
+	if !found {
+		return nil, ErrInvalidCreditClass(DefaultCodespace, fmt.Sprintf("credit class %s not found", metadata.CreditClass))
+	}
+	if class.Deprecated {
+		return nil, ErrCreditClassDeprecated(DefaultCodespace, metadata.CreditClass)
+	}
+	if !class.IsIssuer(metadata.Issuer) {
+		return nil, sdk.ErrUnauthorized(fmt.Sprintf("%s is not an issuer of the credit class", metadata.Issuer))
+	}
+	if err := k.checkIssuerBond(ctx, metadata.CreditClass, class, metadata.Issuer); err != nil {
+		return nil, err
+	}
+	normalized := make([]CreditIssuance, len(issuances))
+	for i, issuance := range issuances {
+		if k.isEscrow(ctx, issuance.Holder) {
+			return nil, sdk.ErrInvalidAddress(fmt.Sprintf("can't issue credits to escrow address %s", issuance.Holder))
+		}
+		issuance = issuance.withDefaults()
+		if err := class.CheckPrecision(issuance.LiquidUnits); err != nil {
+			return nil, err
+		}
+		if err := class.CheckPrecision(issuance.RetiredUnits); err != nil {
+			return nil, err
+		}
+		normalized[i] = issuance
+	}
+	issuances = normalized
+	metadata.LiquidUnits, metadata.BurnedUnits = issuancesTotal(issuances)
+	if err := class.CheckAttributes(metadata.Attributes); err != nil {
+		return nil, err
+	}
+	if err := k.checkAttestations(ctx, metadata.CreditClass, class, metadata.Datasets); err != nil {
+		return nil, err
+	}
+	conflicts, err := k.GetConflictingCredits(ctx, metadata)
+	if err != nil {
+		return nil, err
+	}
+	if len(conflicts) != 0 {
+		return nil, ErrCreditOverlap(DefaultCodespace, conflicts[0].ID)
+	}
 	id, err := k.creditBucket.Create(ctx, metadata)
 	if err != nil {
 		return nil, err
 	}
-	err = k.creditHoldingsBucket.Save(ctx, CreditHolding{Credit: id, Holder: holder, LiquidUnits: metadata.LiquidUnits, BurnedUnits: metadata.BurnedUnits})
+	buffer := sdk.ZeroDec()
+	var retirements []RetirementCertificate
+	// the issuances are recorded with the units their holders actually receive and the units withheld for the buffer
+	// pool as an issuance of their own, so that the serial ranges of the report follow the units
+	recorded := make([]CreditIssuance, 0, len(issuances)+1)
+	for _, issuance := range issuances {
+		holding, found := k.GetCreditHolding(ctx, id, issuance.Holder)
+		if !found {
+			holding = CreditHolding{Credit: id, Holder: issuance.Holder, LiquidUnits: sdk.ZeroDec(), BurnedUnits: sdk.ZeroDec()}
+		}
+		withheld := class.bufferUnits(issuance.LiquidUnits)
+		buffer = buffer.Add(withheld)
+		issuance.LiquidUnits = issuance.LiquidUnits.Sub(withheld)
+		holding.LiquidUnits = holding.LiquidUnits.Add(issuance.LiquidUnits)
+		holding.BurnedUnits = holding.BurnedUnits.Add(issuance.RetiredUnits)
+		err = k.creditHoldingsBucket.Save(ctx, holding)
+		if err != nil {
+			return nil, err
+		}
+		recorded = append(recorded, issuance)
+		emitIssueCredit(ctx, id, metadata, issuance)
+		if issuance.RetiredUnits.IsPositive() {
+			retirement := Retirement{
+				Credit:         id,
+				Units:          issuance.RetiredUnits,
+				Holder:         issuance.Holder,
+				RetirementInfo: issuance.RetirementInfo,
+				Timestamp:      ctx.BlockHeader().Time,
+			}
+			retirementID, err := k.createRetirement(ctx, retirement)
+			if err != nil {
+				return nil, err
+			}
+			retirements = append(retirements, RetirementCertificate{ID: retirementID, Retirement: retirement})
+		}
+	}
+	if buffer.IsPositive() {
+		err = k.creditHoldingsBucket.Save(ctx, CreditHolding{Credit: id, Holder: BufferPoolAddress, LiquidUnits: buffer, BurnedUnits: sdk.ZeroDec()})
+		if err != nil {
+			return nil, err
+		}
+		issuance := CreditIssuance{Holder: BufferPoolAddress, LiquidUnits: buffer, RetiredUnits: sdk.ZeroDec()}
+		recorded = append(recorded, issuance)
+		emitIssueCredit(ctx, id, metadata, issuance)
+	}
+	err = k.issuanceBucket.Save(ctx, Issuance{Credit: id, Issuances: recorded, Timestamp: ctx.BlockHeader().Time})
+	if err != nil {
+		return nil, err
+	}
+	err = k.addCreditSupply(ctx, id, metadata.LiquidUnits, metadata.BurnedUnits)
 	if err != nil {
 		return nil, err
 	}
-	return id, err
+	err = k.scheduleCreditExpiry(ctx, id, class, metadata)
+	if err != nil {
+		return nil, err
+	}
+	k.AfterCreditIssued(ctx, id, metadata)
+	for _, certificate := range retirements {
+		k.AfterCreditRetired(ctx, certificate.ID, certificate.Retirement)
+	}
+	return id, nil
 }
 
-// SendCredit sends fractional units of a credit from one account to another account
+// emitIssueCredit emits the event of an issuance of a credit to a holder
+func emitIssueCredit(ctx sdk.Context, id CreditID, metadata CreditMetadata, issuance CreditIssuance) {
+	ctx.EventManager().EmitEvent(sdk.NewEvent(
+		EventTypeIssueCredit,
+		sdk.NewAttribute(AttributeKeyCreditClass, metadata.CreditClass.String()),
+		sdk.NewAttribute(AttributeKeyCredit, id.String()),
+		sdk.NewAttribute(AttributeKeyIssuer, metadata.Issuer.String()),
+		sdk.NewAttribute(AttributeKeyTo, issuance.Holder.String()),
+		sdk.NewAttribute(AttributeKeyUnits, issuance.LiquidUnits.String()),
+		sdk.NewAttribute(AttributeKeyRetiredUnits, issuance.RetiredUnits.String()),
+		sdk.NewAttribute(AttributeKeyPolygonHash, polygonHash(metadata.GeoPolygon)),
+	))
+}
+
+// SendCredit sends fractional units of a credit from one account to another account. The supply of the credit is
+// unchanged. Units can't be sent to the escrow addresses of the module, as the units they hold must always match the
+// units of open sell orders and auctions, the wrapped coins and the units sent to other chains. Units of expired
+// credits can't be sent
 func (k Keeper) SendCredit(ctx sdk.Context, credit CreditID, from sdk.AccAddress, to sdk.AccAddress, units sdk.Dec) error {
+	if k.isEscrow(ctx, to) {
+		return sdk.ErrInvalidAddress(fmt.Sprintf("can't send credits to escrow address %s", to))
+	}
+	if err := k.checkCreditNotExpired(ctx, credit); err != nil {
+		return err
+	}
+	return k.transferCredit(ctx, credit, from, to, units)
+}
+
+// isEscrowAddress returns whether the address holds units on behalf of others
+func isEscrowAddress(addr sdk.AccAddress) bool {
+	return addr.Equals(SellOrderEscrowAddress) || addr.Equals(AuctionEscrowAddress) || addr.Equals(WrapEscrowAddress) ||
+		addr.Equals(BufferPoolAddress)
+}
+
+// isEscrow returns whether the address holds units on behalf of others, including the units sent to other chains
+func (k Keeper) isEscrow(ctx sdk.Context, addr sdk.AccAddress) bool {
+	return isEscrowAddress(addr) || k.isCreditEscrowAddress(ctx, addr)
+}
+
+// transferCredit moves liquid units of a credit between two holdings, including the escrow holdings of the module,
+// and records the transfer for registry reports. Units of expired credits released from escrow to a holder expire
+// right away
+func (k Keeper) transferCredit(ctx sdk.Context, credit CreditID, from sdk.AccAddress, to sdk.AccAddress, units sdk.Dec) error {
+	if err := k.checkPrecision(ctx, credit, units); err != nil {
+		return err
+	}
 	holding := CreditHolding{Credit: credit, Holder: from}
 	err := k.creditHoldingsBucket.GetOne(ctx, &holding)
 	if err != nil {
@@ -103,31 +419,135 @@ func (k Keeper) SendCredit(ctx sdk.Context, credit CreditID, from sdk.AccAddress
 	} else {
 		holding2.LiquidUnits = holding2.LiquidUnits.Add(units)
 		err = k.creditHoldingsBucket.Save(ctx, holding2)
+		if err != nil {
+			return err
+		}
+	}
+	_, err = k.transferBucket.Create(ctx, CreditTransfer{Credit: credit, From: from, To: to, Units: units, Timestamp: ctx.BlockHeader().Time})
+	if err != nil {
+		return err
+	}
+	ctx.EventManager().EmitEvent(sdk.NewEvent(
+		EventTypeSendCredit,
+		sdk.NewAttribute(AttributeKeyCredit, credit.String()),
+		sdk.NewAttribute(AttributeKeyFrom, from.String()),
+		sdk.NewAttribute(AttributeKeyTo, to.String()),
+		sdk.NewAttribute(AttributeKeyUnits, units.String()),
+	))
+	k.AfterCreditSent(ctx, credit, from, to, units)
+	if !isEscrowAddress(to) && k.isCreditExpired(ctx, credit) {
+		holding2, _ = k.GetCreditHolding(ctx, credit, to)
+		return k.expireHolding(ctx, holding2)
+	}
+	return nil
+}
+
+// GasPerCreditOutput is the gas consumed by MultiSendCredit for each output on top of the gas for store access, so
+// that gas scales with the number of outputs even when they touch the same holdings
+const GasPerCreditOutput = 1000
+
+// MultiSendCredit sends units of possibly several credits from one account to many accounts. The outputs are sent
+// atomically: if any of them fails, none of them is applied
+func (k Keeper) MultiSendCredit(ctx sdk.Context, from sdk.AccAddress, outputs []CreditOutput) error {
+	cacheCtx, write := ctx.CacheContext()
+	for _, output := range outputs {
+		ctx.GasMeter().ConsumeGas(GasPerCreditOutput, "credit multi-send output")
+		err := k.SendCredit(cacheCtx, output.Credit, from, output.To, output.Units)
+		if err != nil {
+			return err
+		}
 	}
+	write()
+	// the cache context has its own event manager
+	ctx.EventManager().EmitEvents(cacheCtx.EventManager().Events())
 	return nil
 }
 
 // BurnCredit burns some units of a credit that the holder holds. Burned units are still attached to the account that
 // burned them for record of where they were ultimately "retired". In the language of carbon credits, retirement
 // is used to take credits out of circulation which means that the holder retiring them is using them as an offset.
-// So basically "burning" credits corresponds to the actual usage of ecosystem services.
-func (k Keeper) BurnCredit(ctx sdk.Context, credit CreditID, holder sdk.AccAddress, units sdk.Dec) error {
+// So basically "burning" credits corresponds to the actual usage of ecosystem services. The burned units move from the
+// liquid to the retired supply of the credit and every burn is recorded as a retirement certificate whose ID is
+// returned. Units of expired credits can't be retired.
+func (k Keeper) BurnCredit(ctx sdk.Context, credit CreditID, holder sdk.AccAddress, units sdk.Dec, info RetirementInfo) (RetirementID, error) {
+	if err := k.checkPrecision(ctx, credit, units); err != nil {
+		return nil, err
+	}
+	if err := k.checkCreditNotExpired(ctx, credit); err != nil {
+		return nil, err
+	}
 	holding := CreditHolding{Credit: credit, Holder: holder}
 	err := k.creditHoldingsBucket.GetOne(ctx, &holding)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	holding.LiquidUnits = holding.LiquidUnits.Sub(units)
 	if holding.LiquidUnits.IsNegative() {
-		return fmt.Errorf("not enough units")
+		return nil, fmt.Errorf("not enough units")
 	}
 	holding.BurnedUnits = holding.BurnedUnits.Add(units)
 	err = k.creditHoldingsBucket.Save(ctx, holding)
 	if err != nil {
-		return err
+		return nil, err
+	}
+	err = k.retireCreditSupply(ctx, credit, units)
+	if err != nil {
+		return nil, err
+	}
+	retirement := Retirement{
+		Credit:         credit,
+		Units:          units,
+		Holder:         holder,
+		RetirementInfo: info,
+		Timestamp:      ctx.BlockHeader().Time,
+	}
+	id, err := k.createRetirement(ctx, retirement)
+	if err != nil {
+		return nil, err
+	}
+	k.AfterCreditRetired(ctx, id, retirement)
+	return id, nil
+}
+
+// GetCredit gets the metadata of an issued credit
+func (k Keeper) GetCredit(ctx sdk.Context, id CreditID) (metadata CreditMetadata, found bool) {
+	err := k.creditBucket.GetOne(ctx, id, &metadata)
+	if err != nil {
+		return metadata, false
+	}
+	return metadata, true
+}
+
+// IterateCredits iterates over all issued credits in the order they were issued
+func (k Keeper) IterateCredits(ctx sdk.Context, callback func(id CreditID, metadata CreditMetadata) (stop bool)) {
+	iterator, err := k.creditBucket.PrefixScan(ctx, nil, nil, false)
+	if err != nil {
+		return
+	}
+	defer iterator.Release()
+	for {
+		var metadata CreditMetadata
+		id, err := iterator.LoadNext(&metadata)
+		if err != nil {
+			break
+		}
+		if callback(id, metadata) {
+			return
+		}
 	}
-	// TODO update credit metadata
-	return nil
+}
+
+// checkPrecision checks that units of the credit respect the precision of its credit class
+func (k Keeper) checkPrecision(ctx sdk.Context, credit CreditID, units sdk.Dec) error {
+	metadata, found := k.GetCredit(ctx, credit)
+	if !found {
+		return ErrInvalidCredit(DefaultCodespace, fmt.Sprintf("credit %s not found", credit))
+	}
+	class, found := k.GetCreditClass(ctx, metadata.CreditClass)
+	if !found {
+		return ErrInvalidCreditClass(DefaultCodespace, fmt.Sprintf("credit class %s not found", metadata.CreditClass))
+	}
+	return class.CheckPrecision(units)
 }
 
 // GetCreditHolding gets the holdings of a specific credit by a specific holder
@@ -140,6 +560,76 @@ func (k Keeper) GetCreditHolding(ctx sdk.Context, credit CreditID, holder sdk.Ac
 	return holding, true
 }
 
+// IterateCreditHoldings iterates over the holdings of all credits by all holders
+func (k Keeper) IterateCreditHoldings(ctx sdk.Context, callback func(holding CreditHolding) (stop bool)) {
+	iterator, err := k.creditHoldingsBucket.PrefixScan(ctx, nil, nil, false)
+	if err != nil {
+		return
+	}
+	defer iterator.Release()
+	for {
+		var holding CreditHolding
+		_, err := iterator.LoadNext(&holding)
+		if err != nil {
+			break
+		}
+		if callback(holding) {
+			return
+		}
+	}
+}
+
+// IterateHoldingsByHolder iterates over the holdings of all credits held by the holder, i.e. the holder's portfolio
+func (k Keeper) IterateHoldingsByHolder(ctx sdk.Context, holder sdk.AccAddress, callback func(holding CreditHolding) (stop bool)) {
+	k.iterateHoldings(ctx, IndexByHolder, holder, callback)
+}
+
+// IterateHoldersOfCredit iterates over the holdings of the credit by all its holders
+func (k Keeper) IterateHoldersOfCredit(ctx sdk.Context, credit CreditID, callback func(holding CreditHolding) (stop bool)) {
+	k.iterateHoldings(ctx, IndexByCredit, credit, callback)
+}
+
+func (k Keeper) iterateHoldings(ctx sdk.Context, index string, key []byte, callback func(holding CreditHolding) (stop bool)) {
+	iterator, err := k.creditHoldingsBucket.ByIndex(ctx, index, key)
+	if iterator != nil {
+		defer iterator.Release()
+	}
+	if err != nil {
+		return
+	}
+	for {
+		var holding CreditHolding
+		_, err := iterator.LoadNext(&holding)
+		if err != nil {
+			break
+		}
+		if callback(holding) {
+			return
+		}
+	}
+}
+
+// IterateCreditsByClass iterates over all credits of the class in the order they were issued
+func (k Keeper) IterateCreditsByClass(ctx sdk.Context, class CreditClassID, callback func(id CreditID, metadata CreditMetadata) (stop bool)) {
+	iterator, err := k.creditBucket.ByIndex(ctx, IndexByCreditClass, class)
+	if iterator != nil {
+		defer iterator.Release()
+	}
+	if err != nil {
+		return
+	}
+	for {
+		var metadata CreditMetadata
+		id, err := iterator.LoadNext(&metadata)
+		if err != nil {
+			break
+		}
+		if callback(id, metadata) {
+			return
+		}
+	}
+}
+
 // IterateCreditsByGeoPolygon iterators overall all credits for a specific geo-polygon. NOTE: this approach is not
 // for use in production as it does not handle polygon overlaps. This method is used for demonstration purposes only
 // until we have on-chain geo-index support or this iteration gets moved off-chain.
@@ -162,3 +652,89 @@ func (k Keeper) IterateCreditsByGeoPolygon(ctx sdk.Context, geoPolygon []byte, c
 		}
 	}
 }
+
+// GasPerConflictCandidate is the gas consumed by GetConflictingCredits for each existing credit of the class it checks,
+// GasPerTriangulationStep the gas consumed for each of the n² steps it takes at most to triangulate a polygon with n
+// vertices and GasPerTrianglePair the gas consumed for each pair of triangles tested for overlap. Together with the gas
+// for store access they make gas scale with the size of the class and with the work of comparing polygons
+const (
+	GasPerConflictCandidate = 100
+	GasPerTriangulationStep = 1
+	GasPerTrianglePair      = 2
+)
+
+// triangulate triangulates a polygon and consumes gas for the steps it takes at most
+func triangulate(ctx sdk.Context, polygon geo.Polygon) (geo.Triangulation, error) {
+	ctx.GasMeter().ConsumeGas(GasPerTriangulationStep*uint64(len(polygon))*uint64(len(polygon)), "triangulate polygon")
+	triangulation, err := geo.Triangulate(polygon)
+	if err != nil {
+		return triangulation, ErrInvalidGeoPolygon(DefaultCodespace, err)
+	}
+	return triangulation, nil
+}
+
+// meterTrianglePairs returns a callback for geo which consumes gas for every pair of triangles tested for overlap
+func meterTrianglePairs(ctx sdk.Context) func() {
+	return func() {
+		ctx.GasMeter().ConsumeGas(GasPerTrianglePair, "triangle pair")
+	}
+}
+
+// GetConflictingCredits returns all existing credits of the same credit class whose polygon and dates overlap with
+// those of the provided credit metadata. Issuing a credit with conflicts would double count the same land area for
+// the same period of time
+func (k Keeper) GetConflictingCredits(ctx sdk.Context, metadata CreditMetadata) ([]Credit, error) {
+	polygon, err := geo.Unmarshal(metadata.GeoPolygon)
+	if err != nil {
+		return nil, ErrInvalidGeoPolygon(DefaultCodespace, err)
+	}
+	// the proposed polygon is triangulated once for all candidates
+	triangulation, err := triangulate(ctx, polygon)
+	if err != nil {
+		return nil, err
+	}
+	iterator, err := k.creditBucket.ByIndex(ctx, IndexByCreditClass, metadata.CreditClass)
+	if iterator != nil {
+		defer iterator.Release()
+	}
+	if err != nil {
+		return nil, err
+	}
+	var conflicts []Credit
+	for {
+		var existing CreditMetadata
+		id, err := iterator.LoadNext(&existing)
+		if err != nil {
+			break
+		}
+		ctx.GasMeter().ConsumeGas(GasPerConflictCandidate, "credit conflict candidate")
+		overlaps, err := creditOverlaps(ctx, triangulation, metadata, existing)
+		if err != nil {
+			return nil, err
+		}
+		if overlaps {
+			conflicts = append(conflicts, Credit{ID: id, Metadata: existing})
+		}
+	}
+	return conflicts, nil
+}
+
+// creditOverlaps checks whether a proposed credit with the already triangulated polygon overlaps with an existing
+// credit. Date ranges are treated as half-open intervals and polygons which only share a boundary don't overlap, so
+// consecutive vintages and adjacent parcels are allowed. Existing credits whose polygons can't be decoded (which
+// could be issued before polygons were canonically encoded) are only considered overlapping if their raw polygon is
+// identical
+func creditOverlaps(ctx sdk.Context, polygon geo.Triangulation, proposed CreditMetadata, existing CreditMetadata) (bool, error) {
+	if !proposed.StartDate.Before(existing.EndDate) || !existing.StartDate.Before(proposed.EndDate) {
+		return false, nil
+	}
+	existingPolygon, err := geo.Unmarshal(existing.GeoPolygon)
+	if err != nil {
+		return bytes.Equal(proposed.GeoPolygon, existing.GeoPolygon), nil
+	}
+	existingTriangulation, err := triangulate(ctx, existingPolygon)
+	if err != nil {
+		return false, err
+	}
+	return polygon.Intersects(existingTriangulation, meterTrianglePairs(ctx)), nil
+}
@@ -0,0 +1,1315 @@
+package ecocredit
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	abci "github.com/tendermint/tendermint/abci/types"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/x/bank"
+	"github.com/cosmos/cosmos-sdk/x/supply"
+)
+
+func TestHoldingIndexes(t *testing.T) {
+	ctx, k := createTestInput(t)
+	class, err := k.CreateCreditClass(ctx, testCreditClass())
+	require.NoError(t, err)
+	credit1, err := k.IssueCredit(ctx, testCredit(class), addr1)
+	require.NoError(t, err)
+	metadata := testCredit(class)
+	metadata.StartDate, metadata.EndDate = endDate, endDate.AddDate(1, 0, 0)
+	credit2, err := k.IssueCredit(ctx, metadata, addr1)
+	require.NoError(t, err)
+	require.NoError(t, k.SendCredit(ctx, credit2, addr1, addr2, sdk.NewDec(30)))
+
+	var portfolio []CreditID
+	k.IterateHoldingsByHolder(ctx, addr1, func(holding CreditHolding) (stop bool) {
+		require.Equal(t, addr1, holding.Holder)
+		portfolio = append(portfolio, holding.Credit)
+		return false
+	})
+	require.Equal(t, []CreditID{credit1, credit2}, portfolio)
+
+	portfolio = nil
+	k.IterateHoldingsByHolder(ctx, addr2, func(holding CreditHolding) (stop bool) {
+		portfolio = append(portfolio, holding.Credit)
+		require.Equal(t, sdk.NewDec(30), holding.LiquidUnits)
+		return false
+	})
+	require.Equal(t, []CreditID{credit2}, portfolio)
+
+	var holders []sdk.AccAddress
+	k.IterateHoldersOfCredit(ctx, credit2, func(holding CreditHolding) (stop bool) {
+		require.Equal(t, credit2, holding.Credit)
+		holders = append(holders, holding.Holder)
+		return false
+	})
+	require.Equal(t, []sdk.AccAddress{addr1, addr2}, holders)
+}
+
+func TestCreditOverlap(t *testing.T) {
+	ctx, k := createTestInput(t)
+	class, err := k.CreateCreditClass(ctx, testCreditClass())
+	require.NoError(t, err)
+	credit, err := k.IssueCredit(ctx, testCredit(class), addr1)
+	require.NoError(t, err)
+
+	// an issuance overlapping an existing credit of the class in both area and dates double counts it
+	overlapping := testCredit(class)
+	overlapping.GeoPolygon = mustGeoPolygon("POLYGON ((0.5 0.5, 1.5 0.5, 1.5 1.5, 0.5 1.5, 0.5 0.5))")
+	overlapping.StartDate = startDate.Add(time.Hour)
+	_, err = k.IssueCredit(ctx, overlapping, addr1)
+	require.Error(t, err)
+	require.Equal(t, CodeCreditOverlap, err.(sdk.Error).Code())
+	conflicts, err := k.GetConflictingCredits(ctx, overlapping)
+	require.NoError(t, err)
+	require.Len(t, conflicts, 1)
+	require.Equal(t, credit, conflicts[0].ID)
+
+	// adjacent parcels only share a boundary and consecutive vintages only share an end date
+	adjacent := testCredit(class)
+	adjacent.GeoPolygon = mustGeoPolygon("POLYGON ((1 0, 2 0, 2 1, 1 1, 1 0))")
+	_, err = k.IssueCredit(ctx, adjacent, addr1)
+	require.NoError(t, err)
+	consecutive := testCredit(class)
+	consecutive.StartDate = endDate
+	consecutive.EndDate = endDate.Add(365 * 24 * time.Hour)
+	_, err = k.IssueCredit(ctx, consecutive, addr1)
+	require.NoError(t, err)
+	// credits of other classes don't conflict
+	otherClass, err := k.CreateCreditClass(ctx, testCreditClass())
+	require.NoError(t, err)
+	_, err = k.IssueCredit(ctx, testCredit(otherClass), addr1)
+	require.NoError(t, err)
+
+	// checking conflicts consumes gas for every credit of the class, for triangulating the proposed polygon once and
+	// the polygons of the credits with overlapping dates, and for every pair of triangles tested
+	ctx = ctx.WithGasMeter(sdk.NewInfiniteGasMeter())
+	conflicts, err = k.GetConflictingCredits(ctx, overlapping)
+	require.NoError(t, err)
+	require.Len(t, conflicts, 2)
+	require.True(t, ctx.GasMeter().GasConsumed() >= 3*GasPerConflictCandidate+3*4*4*GasPerTriangulationStep+2*GasPerTrianglePair)
+
+	// the gas for comparing polygons grows with the square of their vertices
+	large := testCredit(otherClass)
+	large.GeoPolygon = mustGeoCircle(10, 0.4, 200)
+	_, err = k.IssueCredit(ctx, large, addr1)
+	require.NoError(t, err)
+	large.GeoPolygon = mustGeoCircle(10.1, 0.4, 200)
+	ctx = ctx.WithGasMeter(sdk.NewInfiniteGasMeter())
+	conflicts, err = k.GetConflictingCredits(ctx, large)
+	require.NoError(t, err)
+	require.Len(t, conflicts, 1)
+	require.True(t, ctx.GasMeter().GasConsumed() >= 2*GasPerConflictCandidate+2*200*200*GasPerTriangulationStep)
+}
+
+func TestCreditClassAdministration(t *testing.T) {
+	ctx, k := createTestInput(t)
+	addr3 := sdk.AccAddress([]byte("addr3_______________"))
+	class, err := k.CreateCreditClass(ctx, testCreditClass())
+	require.NoError(t, err)
+	credit, err := k.IssueCredit(ctx, testCredit(class), addr1)
+	require.NoError(t, err)
+
+	// only the designer administers the class
+	err = k.AddCreditClassIssuer(ctx, class, addr2, addr2)
+	require.Error(t, err)
+	require.Equal(t, sdk.CodeUnauthorized, err.(sdk.Error).Code())
+	ctx = ctx.WithEventManager(sdk.NewEventManager())
+	require.NoError(t, k.AddCreditClassIssuer(ctx, class, addr1, addr2))
+	require.NoError(t, k.RemoveCreditClassIssuer(ctx, class, addr1, addr1))
+	// the last issuer can't be removed
+	err = k.RemoveCreditClassIssuer(ctx, class, addr1, addr2)
+	require.Error(t, err)
+	require.Equal(t, CodeInvalidIssuers, err.(sdk.Error).Code())
+	metadata, _ := k.GetCreditClass(ctx, class)
+	require.Equal(t, []sdk.AccAddress{addr2}, metadata.Issuers)
+
+	// the new designer takes over and the old designer loses its rights
+	require.NoError(t, k.TransferCreditClassDesigner(ctx, class, addr1, addr3))
+	err = k.AddCreditClassVerifier(ctx, class, addr1, addr2)
+	require.Error(t, err)
+	require.Equal(t, sdk.CodeUnauthorized, err.(sdk.Error).Code())
+	require.NoError(t, k.AddCreditClassVerifier(ctx, class, addr3, addr2))
+	require.NoError(t, k.RemoveCreditClassVerifier(ctx, class, addr3, addr2))
+	require.Error(t, k.DeprecateCreditClass(ctx, class, addr1))
+
+	// a deprecated class issues no more credits but its credits can still be sent
+	require.NoError(t, k.DeprecateCreditClass(ctx, class, addr3))
+	err = k.DeprecateCreditClass(ctx, class, addr3)
+	require.Error(t, err)
+	require.Equal(t, CodeCreditClassDeprecated, err.(sdk.Error).Code())
+	metadata2 := testCredit(class)
+	metadata2.Issuer = addr2
+	metadata2.StartDate = endDate
+	metadata2.EndDate = endDate.Add(time.Hour)
+	_, err = k.IssueCredit(ctx, metadata2, addr2)
+	require.Error(t, err)
+	require.Equal(t, CodeCreditClassDeprecated, err.(sdk.Error).Code())
+	require.NoError(t, k.SendCredit(ctx, credit, addr1, addr2, sdk.NewDec(10)))
+
+	// every change is emitted by the keeper
+	require.Equal(t, []string{EventTypeAddCreditClassIssuer, EventTypeRemoveCreditClassIssuer,
+		EventTypeTransferCreditClassDesigner, EventTypeAddCreditClassVerifier, EventTypeRemoveCreditClassVerifier,
+		EventTypeDeprecateCreditClass, EventTypeSendCredit}, eventTypes(ctx.EventManager().Events()))
+}
+
+func TestIssueCreditSchema(t *testing.T) {
+	ctx, k := createTestInput(t)
+	classMetadata := testCreditClass()
+	classMetadata.RequiredAttributes = []string{"project", "methodology"}
+	class, err := k.CreateCreditClass(ctx, classMetadata)
+	require.NoError(t, err)
+	metadata := testCredit(class)
+	metadata.Attributes = []CreditAttribute{{Key: "project", Value: "p1"}, {Key: "methodology", Value: "VM0007"}}
+
+	// units can't be finer than the precision of the class
+	finer := metadata
+	finer.LiquidUnits = sdk.NewDecWithPrec(1, 7)
+	_, err = k.IssueCredit(ctx, finer, addr1)
+	require.Error(t, err)
+	require.Equal(t, CodeInvalidUnits, err.(sdk.Error).Code())
+
+	// every required attribute needs a non-blank value
+	missing := metadata
+	missing.Attributes = []CreditAttribute{{Key: "project", Value: "p1"}}
+	_, err = k.IssueCredit(ctx, missing, addr1)
+	require.Error(t, err)
+	require.Equal(t, CodeInvalidCreditAttributes, err.(sdk.Error).Code())
+	missing.Attributes = []CreditAttribute{{Key: "project", Value: "p1"}, {Key: "methodology", Value: " "}}
+	_, err = k.IssueCredit(ctx, missing, addr1)
+	require.Error(t, err)
+	require.Equal(t, CodeInvalidCreditAttributes, err.(sdk.Error).Code())
+
+	metadata.LiquidUnits = sdk.NewDecWithPrec(1, 6)
+	_, err = k.IssueCredit(ctx, metadata, addr1)
+	require.NoError(t, err)
+
+	// the finest precision is the precision of decimals
+	classMetadata.Precision = sdk.Precision
+	classMetadata.RequiredAttributes = nil
+	class, err = k.CreateCreditClass(ctx, classMetadata)
+	require.NoError(t, err)
+	metadata = testCredit(class)
+	metadata.LiquidUnits = sdk.NewDecWithPrec(1, sdk.Precision)
+	_, err = k.IssueCredit(ctx, metadata, addr1)
+	require.NoError(t, err)
+}
+
+func TestIssueCreditBatch(t *testing.T) {
+	ctx, k := createTestInput(t)
+	class, err := k.CreateCreditClass(ctx, testCreditClass())
+	require.NoError(t, err)
+	addr3 := sdk.AccAddress([]byte("addr3_______________"))
+	credit, err := k.IssueCreditBatch(ctx, testCredit(class), []CreditIssuance{
+		{Holder: addr1, LiquidUnits: sdk.NewDec(10)},
+		{Holder: addr2, LiquidUnits: sdk.NewDec(3), RetiredUnits: sdk.NewDec(2)},
+		{Holder: addr3, RetiredUnits: sdk.NewDec(5), RetirementInfo: RetirementInfo{Beneficiary: "ACME"}},
+	})
+	require.NoError(t, err)
+
+	metadata, found := k.GetCredit(ctx, credit)
+	require.True(t, found)
+	require.Equal(t, sdk.NewDec(13), metadata.LiquidUnits)
+	require.Equal(t, sdk.NewDec(7), metadata.BurnedUnits)
+	supply, _ := k.GetCreditSupply(ctx, credit)
+	require.Equal(t, CreditSupply{Credit: credit, Issued: sdk.NewDec(20), Liquid: sdk.NewDec(13), Retired: sdk.NewDec(7),
+		Reversed: sdk.ZeroDec(), Expired: sdk.ZeroDec(), Converted: sdk.ZeroDec()}, supply)
+
+	holding, _ := k.GetCreditHolding(ctx, credit, addr2)
+	require.Equal(t, sdk.NewDec(3), holding.LiquidUnits)
+	require.Equal(t, sdk.NewDec(2), holding.BurnedUnits)
+	holding, _ = k.GetCreditHolding(ctx, credit, addr3)
+	require.Equal(t, sdk.ZeroDec(), holding.LiquidUnits)
+	require.Equal(t, sdk.NewDec(5), holding.BurnedUnits)
+
+	// pre-sold offsets get a retirement certificate
+	var retirements []Retirement
+	k.IterateRetirementsByCredit(ctx, credit, func(id RetirementID, retirement Retirement) (stop bool) {
+		retirements = append(retirements, retirement)
+		return false
+	})
+	require.Len(t, retirements, 2)
+	require.Equal(t, addr3, retirements[1].Holder)
+	require.Equal(t, "ACME", retirements[1].Beneficiary)
+
+	_, broken := SupplyInvariant(k)(ctx)
+	require.False(t, broken)
+}
+
+func TestMultiSendCredit(t *testing.T) {
+	ctx, k := createTestInput(t)
+	class, err := k.CreateCreditClass(ctx, testCreditClass())
+	require.NoError(t, err)
+	credit1, err := k.IssueCredit(ctx, testCredit(class), addr1)
+	require.NoError(t, err)
+	metadata := testCredit(class)
+	metadata.StartDate, metadata.EndDate = endDate, endDate.AddDate(1, 0, 0)
+	credit2, err := k.IssueCredit(ctx, metadata, addr1)
+	require.NoError(t, err)
+	addr3 := sdk.AccAddress([]byte("addr3_______________"))
+	outputs := []CreditOutput{
+		{Credit: credit1, To: addr2, Units: sdk.NewDec(10)},
+		{Credit: credit1, To: addr3, Units: sdk.NewDec(20)},
+		{Credit: credit2, To: addr3, Units: sdk.NewDec(30)},
+	}
+
+	// each output costs GasPerCreditOutput on top of the gas of sending its units on their own
+	sendCtx, _ := ctx.CacheContext()
+	sendCtx = sendCtx.WithGasMeter(sdk.NewInfiniteGasMeter())
+	for _, output := range outputs {
+		require.NoError(t, k.SendCredit(sendCtx, output.Credit, addr1, output.To, output.Units))
+	}
+	multiSendCtx, _ := ctx.CacheContext()
+	multiSendCtx = multiSendCtx.WithGasMeter(sdk.NewInfiniteGasMeter())
+	require.NoError(t, k.MultiSendCredit(multiSendCtx, addr1, outputs))
+	require.Equal(t, sendCtx.GasMeter().GasConsumed()+3*GasPerCreditOutput, multiSendCtx.GasMeter().GasConsumed())
+
+	gasBefore := ctx.GasMeter().GasConsumed()
+	err = k.MultiSendCredit(ctx, addr1, outputs)
+	require.NoError(t, err)
+	require.True(t, ctx.GasMeter().GasConsumed()-gasBefore >= 3*GasPerCreditOutput)
+	holding, _ := k.GetCreditHolding(ctx, credit1, addr1)
+	require.Equal(t, sdk.NewDec(70), holding.LiquidUnits)
+	holding, _ = k.GetCreditHolding(ctx, credit2, addr3)
+	require.Equal(t, sdk.NewDec(30), holding.LiquidUnits)
+
+	// the last output exceeds the holding so nothing is sent
+	err = k.MultiSendCredit(ctx, addr1, []CreditOutput{
+		{Credit: credit1, To: addr2, Units: sdk.NewDec(10)},
+		{Credit: credit2, To: addr2, Units: sdk.NewDec(71)},
+	})
+	require.Error(t, err)
+	holding, _ = k.GetCreditHolding(ctx, credit1, addr2)
+	require.Equal(t, sdk.NewDec(10), holding.LiquidUnits)
+	_, found := k.GetCreditHolding(ctx, credit2, addr2)
+	require.False(t, found)
+}
+
+func TestSendCreditFrom(t *testing.T) {
+	ctx, k := createTestInput(t)
+	class, err := k.CreateCreditClass(ctx, testCreditClass())
+	require.NoError(t, err)
+	credit, err := k.IssueCredit(ctx, testCredit(class), addr1)
+	require.NoError(t, err)
+	spender := sdk.AccAddress([]byte("spender_____________"))
+
+	require.Error(t, k.SendCreditFrom(ctx, spender, credit, addr1, addr2, sdk.NewDec(1)))
+
+	require.NoError(t, k.ApproveCredit(ctx, addr1, spender, credit, sdk.NewDec(10), time.Time{}))
+	require.NoError(t, k.SendCreditFrom(ctx, spender, credit, addr1, addr2, sdk.NewDec(4)))
+	allowance, found := k.GetCreditAllowance(ctx, addr1, spender, credit)
+	require.True(t, found)
+	require.Equal(t, sdk.NewDec(6), allowance.Units)
+	err = k.SendCreditFrom(ctx, spender, credit, addr1, addr2, sdk.NewDec(7))
+	require.Error(t, err)
+	require.Equal(t, CodeInsufficientAllowance, err.(sdk.Error).Code())
+
+	// using up the allowance removes it
+	require.NoError(t, k.SendCreditFrom(ctx, spender, credit, addr1, addr2, sdk.NewDec(6)))
+	_, found = k.GetCreditAllowance(ctx, addr1, spender, credit)
+	require.False(t, found)
+	holding, _ := k.GetCreditHolding(ctx, credit, addr2)
+	require.Equal(t, sdk.NewDec(10), holding.LiquidUnits)
+
+	// an allowance for all credits is used when there is none for the credit
+	require.NoError(t, k.ApproveCredit(ctx, addr1, spender, nil, sdk.NewDec(5), ctx.BlockHeader().Time.Add(time.Hour)))
+	require.NoError(t, k.SendCreditFrom(ctx, spender, credit, addr1, addr2, sdk.NewDec(5)))
+
+	// expired and revoked allowances can't be used
+	require.NoError(t, k.ApproveCredit(ctx, addr1, spender, nil, sdk.NewDec(5), ctx.BlockHeader().Time.Add(time.Hour)))
+	later := ctx.WithBlockHeader(abci.Header{Time: ctx.BlockHeader().Time.Add(time.Hour)})
+	require.Error(t, k.SendCreditFrom(later, spender, credit, addr1, addr2, sdk.NewDec(1)))
+	require.NoError(t, k.RevokeCredit(ctx, addr1, spender, nil))
+	require.Error(t, k.SendCreditFrom(ctx, spender, credit, addr1, addr2, sdk.NewDec(1)))
+	require.Error(t, k.ApproveCredit(later, addr1, spender, nil, sdk.NewDec(5), ctx.BlockHeader().Time))
+}
+
+func TestSellOrders(t *testing.T) {
+	ctx, k := createTestInput(t)
+	class, err := k.CreateCreditClass(ctx, testCreditClass())
+	require.NoError(t, err)
+	credit, err := k.IssueCredit(ctx, testCredit(class), addr1)
+	require.NoError(t, err)
+	price := sdk.NewCoins(sdk.NewInt64Coin("uatom", 15))
+	_, err = k.bankKeeper.AddCoins(ctx, addr2, sdk.NewCoins(sdk.NewInt64Coin("uatom", 400)))
+	require.NoError(t, err)
+
+	// units offered for sale are escrowed
+	_, err = k.CreateSellOrder(ctx, addr1, credit, sdk.NewDec(101), price)
+	require.Error(t, err)
+	order, err := k.CreateSellOrder(ctx, addr1, credit, sdk.NewDec(40), price)
+	require.NoError(t, err)
+	holding, _ := k.GetCreditHolding(ctx, credit, addr1)
+	require.Equal(t, sdk.NewDec(60), holding.LiquidUnits)
+	require.NoError(t, k.UpdateSellOrder(ctx, order, addr1, sdk.NewDec(30), price))
+	holding, _ = k.GetCreditHolding(ctx, credit, addr1)
+	require.Equal(t, sdk.NewDec(70), holding.LiquidUnits)
+	err = k.UpdateSellOrder(ctx, order, addr2, sdk.NewDec(30), price)
+	require.Error(t, err)
+	require.Equal(t, sdk.CodeUnauthorized, err.(sdk.Error).Code())
+
+	// the price is capped by the buyer and rounded up in favor of the seller
+	_, err = k.BuyCredit(ctx, addr2, order, sdk.NewDec(1), sdk.NewCoins(sdk.NewInt64Coin("uatom", 14)), false, RetirementInfo{})
+	require.Error(t, err)
+	require.Equal(t, CodePriceExceeded, err.(sdk.Error).Code())
+	_, err = k.BuyCredit(ctx, addr2, order, sdk.NewDecWithPrec(15, 1), price, false, RetirementInfo{})
+	require.NoError(t, err)
+	require.Equal(t, sdk.NewInt(23), k.bankKeeper.GetCoins(ctx, addr1).AmountOf("uatom"))
+	require.Equal(t, sdk.NewInt(377), k.bankKeeper.GetCoins(ctx, addr2).AmountOf("uatom"))
+	holding, _ = k.GetCreditHolding(ctx, credit, addr2)
+	require.Equal(t, sdk.NewDecWithPrec(15, 1), holding.LiquidUnits)
+
+	// a failed payment leaves the order untouched
+	_, err = k.BuyCredit(ctx, addr2, order, sdk.NewDec(28), price, false, RetirementInfo{})
+	require.Error(t, err)
+	remaining, found := k.GetSellOrder(ctx, order)
+	require.True(t, found)
+	require.Equal(t, sdk.NewDecWithPrec(285, 1), remaining.Units)
+
+	// filling the order with retirement closes it and creates a retirement certificate
+	require.NoError(t, k.UpdateSellOrder(ctx, order, addr1, sdk.NewDec(10), sdk.NewCoins(sdk.NewInt64Coin("uatom", 1))))
+	retirement, err := k.BuyCredit(ctx, addr2, order, sdk.NewDec(10), price, true, RetirementInfo{Beneficiary: "ACME"})
+	require.NoError(t, err)
+	_, found = k.GetSellOrder(ctx, order)
+	require.False(t, found)
+	certificate, found := k.GetRetirement(ctx, retirement)
+	require.True(t, found)
+	require.Equal(t, addr2, certificate.Holder)
+	holding, _ = k.GetCreditHolding(ctx, credit, addr2)
+	require.Equal(t, sdk.NewDec(10), holding.BurnedUnits)
+
+	// cancelling returns the escrowed units
+	order, err = k.CreateSellOrder(ctx, addr1, credit, sdk.NewDec(5), price)
+	require.NoError(t, err)
+	var orders []SellOrderID
+	k.IterateSellOrdersByCredit(ctx, credit, func(id SellOrderID, order SellOrder) (stop bool) {
+		orders = append(orders, id)
+		return false
+	})
+	require.Equal(t, []SellOrderID{order}, orders)
+	require.NoError(t, k.CancelSellOrder(ctx, order, addr1))
+	holding, _ = k.GetCreditHolding(ctx, credit, addr1)
+	require.Equal(t, sdk.NewDecWithPrec(885, 1), holding.LiquidUnits)
+	holding, _ = k.GetCreditHolding(ctx, credit, SellOrderEscrowAddress)
+	require.True(t, holding.LiquidUnits.IsZero())
+	_, broken := SellOrderEscrowInvariant(k)(ctx)
+	require.False(t, broken)
+}
+
+func TestAuction(t *testing.T) {
+	ctx, k := createTestInput(t)
+	class, err := k.CreateCreditClass(ctx, testCreditClass())
+	require.NoError(t, err)
+	credit, err := k.IssueCredit(ctx, testCredit(class), addr1)
+	require.NoError(t, err)
+	addr3 := sdk.AccAddress([]byte("addr3_______________"))
+	addr4 := sdk.AccAddress([]byte("addr4_______________"))
+	for _, bidder := range []sdk.AccAddress{addr2, addr3, addr4} {
+		_, err = k.bankKeeper.AddCoins(ctx, bidder, sdk.NewCoins(sdk.NewInt64Coin("uatom", 1000)))
+		require.NoError(t, err)
+	}
+	endTime := startDate.Add(time.Hour)
+
+	// credits can only get into escrow through an auction or sell order
+	require.Error(t, k.SendCredit(ctx, credit, addr1, AuctionEscrowAddress, sdk.NewDec(1)))
+	_, err = k.CreateAuction(ctx, addr1, credit, sdk.NewDec(50), sdk.NewInt64Coin("uatom", 10), startDate)
+	require.Error(t, err)
+	auction, err := k.CreateAuction(ctx, addr1, credit, sdk.NewDec(50), sdk.NewInt64Coin("uatom", 10), endTime)
+	require.NoError(t, err)
+
+	_, err = k.PlaceBid(ctx, addr2, auction, sdk.NewDec(30), sdk.NewInt64Coin("uatom", 9))
+	require.Error(t, err)
+	_, err = k.PlaceBid(ctx, addr2, auction, sdk.NewDec(30), sdk.NewInt64Coin("uatom", 12))
+	require.NoError(t, err)
+	_, err = k.PlaceBid(ctx, addr3, auction, sdk.NewDec(30), sdk.NewInt64Coin("uatom", 15))
+	require.NoError(t, err)
+	_, err = k.PlaceBid(ctx, addr4, auction, sdk.NewDec(10), sdk.NewInt64Coin("uatom", 11))
+	require.NoError(t, err)
+	require.Equal(t, sdk.NewInt(640), k.bankKeeper.GetCoins(ctx, addr2).AmountOf("uatom"))
+	_, broken := AuctionEscrowInvariant(k)(ctx)
+	require.False(t, broken)
+
+	// nothing is cleared before the end time
+	k.ClearAuctions(ctx)
+	_, found := k.GetAuction(ctx, auction)
+	require.True(t, found)
+
+	// the highest bid is filled first and all winners pay the price of the lowest winning bid
+	ctx = ctx.WithBlockHeader(abci.Header{Time: endTime})
+	_, err = k.PlaceBid(ctx, addr4, auction, sdk.NewDec(10), sdk.NewInt64Coin("uatom", 20))
+	require.Error(t, err)
+	k.ClearAuctions(ctx)
+	_, found = k.GetAuction(ctx, auction)
+	require.False(t, found)
+	expected := []struct {
+		holder sdk.AccAddress
+		units  sdk.Dec
+		coins  int64
+	}{
+		{addr1, sdk.NewDec(50), 600},
+		{addr2, sdk.NewDec(20), 760},
+		{addr3, sdk.NewDec(30), 640},
+		{addr4, sdk.ZeroDec(), 1000},
+	}
+	for _, e := range expected {
+		holding, _ := k.GetCreditHolding(ctx, credit, e.holder)
+		if e.units.IsZero() {
+			require.True(t, holding.LiquidUnits.IsNil() || holding.LiquidUnits.IsZero())
+		} else {
+			require.Equal(t, e.units, holding.LiquidUnits)
+		}
+		require.Equal(t, sdk.NewInt(e.coins), k.bankKeeper.GetCoins(ctx, e.holder).AmountOf("uatom"))
+	}
+	require.True(t, k.bankKeeper.GetCoins(ctx, AuctionEscrowAddress).IsZero())
+	_, broken = AuctionEscrowInvariant(k)(ctx)
+	require.False(t, broken)
+}
+
+func TestClearAuctionFailures(t *testing.T) {
+	ctx, k := createTestInput(t)
+	class, err := k.CreateCreditClass(ctx, testCreditClass())
+	require.NoError(t, err)
+	credit, err := k.IssueCredit(ctx, testCredit(class), addr1)
+	require.NoError(t, err)
+	other := testCredit(class)
+	other.GeoPolygon = mustGeoPolygon("POLYGON ((2 2, 3 2, 3 3, 2 3, 2 2))")
+	otherCredit, err := k.IssueCredit(ctx, other, addr1)
+	require.NoError(t, err)
+	_, err = k.bankKeeper.AddCoins(ctx, addr2, sdk.NewCoins(sdk.NewInt64Coin("uatom", 10000)))
+	require.NoError(t, err)
+	endTime := startDate.Add(time.Hour)
+	price := sdk.NewInt64Coin("uatom", 10)
+
+	// auctions take a bounded number of bids
+	cleared, err := k.CreateAuction(ctx, addr1, credit, sdk.NewDec(50), price, endTime)
+	require.NoError(t, err)
+	for i := 0; i < MaxAuctionBids; i++ {
+		_, err = k.PlaceBid(ctx, addr2, cleared, sdk.NewDec(1), price)
+		require.NoError(t, err)
+	}
+	_, err = k.PlaceBid(ctx, addr2, cleared, sdk.NewDec(1), price)
+	require.Error(t, err)
+	require.Equal(t, CodeInvalidAuction, err.(sdk.Error).Code())
+
+	// an auction which can't be cleared is cancelled, here because of a bid finer than the precision of the class
+	cancelled, err := k.CreateAuction(ctx, addr1, credit, sdk.NewDec(10), price, endTime)
+	require.NoError(t, err)
+	bid := AuctionBid{Auction: cancelled, Bidder: addr2, Units: sdk.NewDecWithPrec(1, 7), Price: price}
+	require.NoError(t, k.bankKeeper.SendCoins(ctx, addr2, AuctionEscrowAddress, sdk.NewCoins(bid.Locked())))
+	_, err = k.auctionBidBucket.Create(ctx, bid)
+	require.NoError(t, err)
+
+	// an auction which can't be cancelled either is left in place without affecting the others
+	stuck, err := k.CreateAuction(ctx, addr1, otherCredit, sdk.NewDec(10), price, endTime)
+	require.NoError(t, err)
+	_, err = k.PlaceBid(ctx, addr2, stuck, sdk.NewDec(5), price)
+	require.NoError(t, err)
+	require.NoError(t, k.creditHoldingsBucket.Save(ctx, CreditHolding{Credit: otherCredit, Holder: AuctionEscrowAddress,
+		LiquidUnits: sdk.ZeroDec(), BurnedUnits: sdk.ZeroDec()}))
+
+	ctx = ctx.WithBlockHeader(abci.Header{Time: endTime}).WithEventManager(sdk.NewEventManager())
+	require.NotPanics(t, func() { k.ClearAuctions(ctx) })
+	_, found := k.GetAuction(ctx, cleared)
+	require.False(t, found)
+	_, found = k.GetAuction(ctx, cancelled)
+	require.False(t, found)
+	_, found = k.GetAuction(ctx, stuck)
+	require.True(t, found)
+	holding, _ := k.GetCreditHolding(ctx, credit, addr1)
+	require.Equal(t, sdk.NewDec(50), holding.LiquidUnits)
+	holding, _ = k.GetCreditHolding(ctx, credit, addr2)
+	require.Equal(t, sdk.NewDec(50), holding.LiquidUnits)
+	// only the coins locked by the bid of the auction left in place are still in escrow
+	require.Equal(t, sdk.NewInt(9450), k.bankKeeper.GetCoins(ctx, addr2).AmountOf("uatom"))
+	require.Equal(t, sdk.NewInt(50), k.bankKeeper.GetCoins(ctx, AuctionEscrowAddress).AmountOf("uatom"))
+	types := eventTypes(ctx.EventManager().Events())
+	require.Contains(t, types, EventTypeClearAuction)
+	require.Contains(t, types, EventTypeCancelAuction)
+}
+
+func TestWrapCredit(t *testing.T) {
+	ctx, k := createTestInput(t)
+	class, err := k.CreateCreditClass(ctx, testCreditClass())
+	require.NoError(t, err)
+	credit, err := k.IssueCredit(ctx, testCredit(class), addr1)
+	require.NoError(t, err)
+	denom := WrappedDenom(credit)
+	parsed, err := CreditFromWrappedDenom(denom)
+	require.NoError(t, err)
+	require.Equal(t, credit, parsed)
+
+	// one coin is worth the smallest fraction of a unit allowed by the precision of the class
+	coins, err := k.WrapCredit(ctx, addr1, credit, sdk.NewDecWithPrec(15, 1))
+	require.NoError(t, err)
+	require.Equal(t, sdk.NewCoins(sdk.NewInt64Coin(denom, 1500000)), coins)
+	require.Equal(t, coins, k.bankKeeper.GetCoins(ctx, addr1))
+	_, err = k.WrapCredit(ctx, addr1, credit, sdk.NewDec(99))
+	require.Error(t, err)
+
+	// wrapped coins move through the bank and can be unwrapped by any holder
+	require.NoError(t, k.bankKeeper.SendCoins(ctx, addr1, addr2, sdk.NewCoins(sdk.NewInt64Coin(denom, 500000))))
+	require.NoError(t, k.UnwrapCredit(ctx, addr2, credit, sdk.NewDecWithPrec(5, 1)))
+	require.Error(t, k.UnwrapCredit(ctx, addr2, credit, sdk.NewDecWithPrec(1, 1)))
+	holding, _ := k.GetCreditHolding(ctx, credit, addr2)
+	require.Equal(t, sdk.NewDecWithPrec(5, 1), holding.LiquidUnits)
+	holding, _ = k.GetCreditHolding(ctx, credit, WrapEscrowAddress)
+	require.Equal(t, sdk.NewDec(1), holding.LiquidUnits)
+	require.Equal(t, sdk.NewInt(1000000), k.supplyKeeper.GetSupply(ctx).GetTotal().AmountOf(denom))
+	_, broken := WrappedSupplyInvariant(k)(ctx)
+	require.False(t, broken)
+	_, broken = SupplyInvariant(k)(ctx)
+	require.False(t, broken)
+}
+
+func TestReverseCredit(t *testing.T) {
+	ctx, k := createTestInput(t)
+	addr3 := sdk.AccAddress([]byte("addr3_______________"))
+	classMetadata := testCreditClass()
+	classMetadata.BufferRate = sdk.NewDecWithPrec(1, 1)
+	class, err := k.CreateCreditClass(ctx, classMetadata)
+	require.NoError(t, err)
+
+	// the buffer rate is withheld from the liquid units of every issuance but not from units retired at issuance
+	metadata := testCredit(class)
+	credit, err := k.IssueCreditBatch(ctx, metadata, []CreditIssuance{
+		{Holder: addr1, LiquidUnits: sdk.NewDec(60)},
+		{Holder: addr2, LiquidUnits: sdk.NewDec(40), RetiredUnits: sdk.NewDec(5)},
+	})
+	require.NoError(t, err)
+	other := testCredit(class)
+	other.GeoPolygon = mustGeoPolygon("POLYGON ((2 2, 3 2, 3 3, 2 3, 2 2))")
+	otherCredit, err := k.IssueCredit(ctx, other, addr3)
+	require.NoError(t, err)
+	for _, e := range []struct {
+		credit CreditID
+		holder sdk.AccAddress
+		units  sdk.Dec
+	}{
+		{credit, addr1, sdk.NewDec(54)},
+		{credit, addr2, sdk.NewDec(36)},
+		{credit, BufferPoolAddress, sdk.NewDec(10)},
+		{otherCredit, BufferPoolAddress, sdk.NewDec(10)},
+	} {
+		holding, _ := k.GetCreditHolding(ctx, e.credit, e.holder)
+		require.Equal(t, e.units, holding.LiquidUnits)
+	}
+	require.Error(t, k.SendCredit(ctx, credit, addr1, BufferPoolAddress, sdk.NewDec(1)))
+	_, err = k.CreateSellOrder(ctx, addr2, credit, sdk.NewDec(6), sdk.NewCoins(sdk.NewInt64Coin("uatom", 10)))
+	require.NoError(t, err)
+
+	// only the issuer or the designer can reverse a credit
+	_, err = k.ReverseCredit(ctx, credit, addr2, sdk.NewDec(1), "fire")
+	require.Error(t, err)
+	require.Equal(t, sdk.CodeUnauthorized, err.(sdk.Error).Code())
+
+	// units are cancelled from the buffer of the credit, the buffers of the class and then pro rata from holders
+	// outside of escrow, with the indivisible part left over going to the first holder
+	ctx = ctx.WithEventManager(sdk.NewEventManager())
+	id, err := k.ReverseCredit(ctx, credit, addr1, sdk.NewDec(25), "fire")
+	require.NoError(t, err)
+	reversal, found := k.GetReversal(ctx, id)
+	require.True(t, found)
+	require.Equal(t, []CreditCancellation{
+		{Credit: credit, Holder: BufferPoolAddress, Units: sdk.NewDec(10)},
+		{Credit: otherCredit, Holder: BufferPoolAddress, Units: sdk.NewDec(10)},
+		{Credit: credit, Holder: addr1, Units: sdk.NewDecWithPrec(3214286, 6)},
+		{Credit: credit, Holder: addr2, Units: sdk.NewDecWithPrec(1785714, 6)},
+	}, reversal.Cancellations)
+	require.Equal(t, "fire", reversal.Reason)
+	require.Len(t, ctx.EventManager().Events(), 5)
+	holding, _ := k.GetCreditHolding(ctx, credit, SellOrderEscrowAddress)
+	require.Equal(t, sdk.NewDec(6), holding.LiquidUnits)
+	supply, _ := k.GetCreditSupply(ctx, credit)
+	require.Equal(t, CreditSupply{Credit: credit, Issued: sdk.NewDec(105), Liquid: sdk.NewDec(85), Retired: sdk.NewDec(5),
+		Reversed: sdk.NewDec(15), Expired: sdk.ZeroDec(), Converted: sdk.ZeroDec()}, supply)
+	otherSupply, _ := k.GetCreditSupply(ctx, otherCredit)
+	require.Equal(t, sdk.NewDec(10), otherSupply.Reversed)
+
+	// reversals can't cancel more than the liquid units outside of escrow
+	_, err = k.ReverseCredit(ctx, credit, addr1, sdk.NewDec(80), "fire")
+	require.Error(t, err)
+	require.Equal(t, CodeInvalidReversal, err.(sdk.Error).Code())
+	_, broken := SupplyInvariant(k)(ctx)
+	require.False(t, broken)
+
+	// the reversed supply is restored from the reversals at genesis
+	exported := ExportGenesis(ctx, k)
+	require.NoError(t, ValidateGenesis(exported))
+	require.Len(t, exported.Reversals, 1)
+	ctx2, k2 := createTestInput(t)
+	InitGenesis(ctx2, k2, exported)
+	supply2, _ := k2.GetCreditSupply(ctx2, credit)
+	require.Equal(t, supply, supply2)
+	_, broken = SupplyInvariant(k2)(ctx2)
+	require.False(t, broken)
+}
+
+func TestIssueCreditWithAttestations(t *testing.T) {
+	ctx, k := createTestInput(t)
+	verifier1 := sdk.AccAddress([]byte("verifier1___________"))
+	verifier2 := sdk.AccAddress([]byte("verifier2___________"))
+	classMetadata := testCreditClass()
+	classMetadata.Verifiers = []sdk.AccAddress{verifier1, verifier2}
+	classMetadata.RequiredAttestations = 2
+	class, err := k.CreateCreditClass(ctx, classMetadata)
+	require.NoError(t, err)
+	hash := sha256.Sum256([]byte("sensor readings"))
+
+	// issuances of the class must reference datasets
+	metadata := testCredit(class)
+	_, err = k.IssueCredit(ctx, metadata, addr1)
+	require.Error(t, err)
+	require.Equal(t, CodeMissingAttestations, err.(sdk.Error).Code())
+	metadata.Datasets = [][]byte{hash[:]}
+	_, err = k.IssueCredit(ctx, metadata, addr1)
+	require.Error(t, err)
+	require.Equal(t, CodeInvalidData, err.(sdk.Error).Code())
+
+	// only registered verifiers can attest anchored datasets, once each
+	require.Error(t, k.AttestData(ctx, verifier1, class, hash[:]))
+	require.NoError(t, k.AnchorData(ctx, addr2, hash[:], "ipfs://sensor-readings", "text/csv"))
+	require.Error(t, k.AnchorData(ctx, addr1, hash[:], "ipfs://other", "text/csv"))
+	require.NoError(t, k.AttestData(ctx, verifier1, class, hash[:]))
+	require.Error(t, k.AttestData(ctx, verifier1, class, hash[:]))
+	err = k.AttestData(ctx, addr1, class, hash[:])
+	require.Error(t, err)
+	require.Equal(t, sdk.CodeUnauthorized, err.(sdk.Error).Code())
+	_, err = k.IssueCredit(ctx, metadata, addr1)
+	require.Error(t, err)
+	require.Equal(t, CodeMissingAttestations, err.(sdk.Error).Code())
+
+	// attestations of removed verifiers don't count
+	verifier3 := sdk.AccAddress([]byte("verifier3___________"))
+	require.Error(t, k.RemoveCreditClassVerifier(ctx, class, addr1, verifier1))
+	require.NoError(t, k.AddCreditClassVerifier(ctx, class, addr1, verifier3))
+	require.NoError(t, k.AttestData(ctx, verifier2, class, hash[:]))
+	require.NoError(t, k.RemoveCreditClassVerifier(ctx, class, addr1, verifier1))
+	_, err = k.IssueCredit(ctx, metadata, addr1)
+	require.Error(t, err)
+	require.NoError(t, k.AttestData(ctx, verifier3, class, hash[:]))
+	credit, err := k.IssueCredit(ctx, metadata, addr1)
+	require.NoError(t, err)
+	issued, _ := k.GetCredit(ctx, credit)
+	require.Equal(t, [][]byte{hash[:]}, issued.Datasets)
+
+	exported := ExportGenesis(ctx, k)
+	require.NoError(t, ValidateGenesis(exported))
+	require.Len(t, exported.AnchoredData, 1)
+	require.Len(t, exported.Attestations, 3)
+}
+
+func TestIssuerBond(t *testing.T) {
+	ctx, k := createTestInput(t)
+	classMetadata := testCreditClass()
+	classMetadata.Issuers = []sdk.AccAddress{addr1, addr2}
+	classMetadata.IssuerBond = sdk.NewCoins(sdk.NewInt64Coin("uatom", 100))
+	classMetadata.BondUnbondingPeriod = time.Hour
+	class, err := k.CreateCreditClass(ctx, classMetadata)
+	require.NoError(t, err)
+	coins := sdk.NewCoins(sdk.NewInt64Coin("uatom", 300))
+	_, err = k.bankKeeper.AddCoins(ctx, addr2, coins)
+	require.NoError(t, err)
+	k.supplyKeeper.SetSupply(ctx, supply.NewSupply(coins))
+	metadata := testCredit(class)
+	metadata.Issuer = addr2
+
+	// issuers must bond at least the minimum bond of the class to issue credits
+	_, err = k.IssueCredit(ctx, metadata, addr2)
+	require.Error(t, err)
+	require.Equal(t, CodeInsufficientBond, err.(sdk.Error).Code())
+	addr3 := sdk.AccAddress([]byte("addr3_______________"))
+	require.Error(t, k.PostBond(ctx, addr3, class, sdk.NewCoins(sdk.NewInt64Coin("uatom", 100))))
+	require.NoError(t, k.PostBond(ctx, addr2, class, sdk.NewCoins(sdk.NewInt64Coin("uatom", 200))))
+	credit, err := k.IssueCredit(ctx, metadata, addr2)
+	require.NoError(t, err)
+
+	// unbonded coins no longer count towards the bond
+	_, err = k.UnbondBond(ctx, addr2, class, sdk.NewCoins(sdk.NewInt64Coin("uatom", 300)))
+	require.Error(t, err)
+	_, err = k.UnbondBond(ctx, addr2, class, sdk.NewCoins(sdk.NewInt64Coin("uatom", 150)))
+	require.NoError(t, err)
+	status := k.GetIssuerBondStatus(ctx, class, addr2)
+	require.False(t, status.Sufficient)
+	require.Len(t, status.Unbondings, 1)
+	_, err = k.IssueCredit(ctx, metadata, addr2)
+	require.Error(t, err)
+
+	// the designer slashes the bond and the pending unbondings
+	_, err = k.SlashBond(ctx, addr2, class, addr2, credit, sdk.NewDecWithPrec(5, 1), "fraud")
+	require.Error(t, err)
+	_, err = k.SlashBond(ctx, addr1, class, addr1, credit, sdk.NewDecWithPrec(5, 1), "fraud")
+	require.Error(t, err)
+	slashed, err := k.SlashBond(ctx, addr1, class, addr2, credit, sdk.NewDecWithPrec(5, 1), "fraud")
+	require.NoError(t, err)
+	require.Equal(t, sdk.NewCoins(sdk.NewInt64Coin("uatom", 100)), slashed)
+
+	// so does governance
+	proposal := SlashBondProposal{Title: "fraud", Description: "double counted", CreditClass: class, Issuer: addr2,
+		Credit: credit, Fraction: sdk.NewDecWithPrec(2, 1)}
+	require.NoError(t, proposal.ValidateBasic())
+	require.NoError(t, NewSlashBondProposalHandler(k)(ctx, proposal))
+	status = k.GetIssuerBondStatus(ctx, class, addr2)
+	require.Equal(t, sdk.NewCoins(sdk.NewInt64Coin("uatom", 20)), status.Amount)
+	require.Equal(t, sdk.NewCoins(sdk.NewInt64Coin("uatom", 60)), status.Unbondings[0].Unbonding.Amount)
+	_, broken := BondPoolInvariant(k)(ctx)
+	require.False(t, broken)
+	exported := ExportGenesis(ctx, k)
+	require.NoError(t, ValidateGenesis(exported))
+	require.Len(t, exported.Bonds, 1)
+	require.Len(t, exported.BondUnbondings, 1)
+
+	// unbondings are returned once the unbonding period passed
+	k.CompleteBondUnbondings(ctx)
+	require.Equal(t, sdk.NewInt(100), k.bankKeeper.GetCoins(ctx, addr2).AmountOf("uatom"))
+	ctx = ctx.WithBlockHeader(abci.Header{Time: startDate.Add(time.Hour)})
+	k.CompleteBondUnbondings(ctx)
+	require.Equal(t, sdk.NewInt(160), k.bankKeeper.GetCoins(ctx, addr2).AmountOf("uatom"))
+	require.Equal(t, sdk.NewInt(20), k.bankKeeper.GetCoins(ctx, BondPoolAddress()).AmountOf("uatom"))
+	require.Empty(t, k.GetIssuerBondStatus(ctx, class, addr2).Unbondings)
+	_, broken = BondPoolInvariant(k)(ctx)
+	require.False(t, broken)
+}
+
+func TestSlashBondRounding(t *testing.T) {
+	ctx, k := createTestInput(t)
+	classMetadata := testCreditClass()
+	classMetadata.Issuers = []sdk.AccAddress{addr1, addr2}
+	classMetadata.IssuerBond = sdk.NewCoins(sdk.NewInt64Coin("uatom", 1))
+	classMetadata.BondUnbondingPeriod = time.Hour
+	class, err := k.CreateCreditClass(ctx, classMetadata)
+	require.NoError(t, err)
+	coins := sdk.NewCoins(sdk.NewInt64Coin("uatom", 135))
+	_, err = k.bankKeeper.AddCoins(ctx, addr2, coins)
+	require.NoError(t, err)
+	other := sdk.NewCoins(sdk.NewInt64Coin("uatom", 10))
+	_, err = k.bankKeeper.AddCoins(ctx, addr1, other)
+	require.NoError(t, err)
+	k.supplyKeeper.SetSupply(ctx, supply.NewSupply(coins.Add(other)))
+	require.NoError(t, k.PostBond(ctx, addr2, class, coins))
+	// the unbonding of another issuer of the class is not slashed
+	require.NoError(t, k.PostBond(ctx, addr1, class, other))
+	_, err = k.UnbondBond(ctx, addr1, class, other)
+	require.NoError(t, err)
+	metadata := testCredit(class)
+	metadata.Issuer = addr2
+	credit, err := k.IssueCredit(ctx, metadata, addr2)
+	require.NoError(t, err)
+	_, err = k.UnbondBond(ctx, addr2, class, sdk.NewCoins(sdk.NewInt64Coin("uatom", 33)))
+	require.NoError(t, err)
+	_, err = k.UnbondBond(ctx, addr2, class, sdk.NewCoins(sdk.NewInt64Coin("uatom", 1)))
+	require.NoError(t, err)
+	amounts := func() []int64 {
+		status := k.GetIssuerBondStatus(ctx, class, addr2)
+		amounts := []int64{status.Amount.AmountOf("uatom").Int64()}
+		for _, unbonding := range status.Unbondings {
+			amounts = append(amounts, unbonding.Unbonding.Amount.AmountOf("uatom").Int64())
+		}
+		return amounts
+	}
+	require.Equal(t, []int64{101, 33, 1}, amounts())
+
+	// the bond and each pending unbonding are rounded down separately
+	slashed, err := k.SlashBond(ctx, addr1, class, addr2, credit, sdk.NewDecWithPrec(5, 1), "fraud")
+	require.NoError(t, err)
+	require.Equal(t, sdk.NewCoins(sdk.NewInt64Coin("uatom", 66)), slashed)
+	require.Equal(t, []int64{51, 17, 1}, amounts())
+
+	// governance slashes the same way, a proposal with a credit of another issuer fails
+	proposal := SlashBondProposal{Title: "fraud", Description: "double counted", CreditClass: class, Issuer: addr1,
+		Credit: credit, Fraction: sdk.NewDecWithPrec(1, 1)}
+	err = HandleSlashBondProposal(ctx, k, proposal)
+	require.Error(t, err)
+	require.Equal(t, CodeInvalidBond, err.(sdk.Error).Code())
+	proposal.Issuer = addr2
+	require.NoError(t, HandleSlashBondProposal(ctx, k, proposal))
+	require.Equal(t, []int64{46, 16, 1}, amounts())
+	require.Equal(t, sdk.NewInt(73), k.bankKeeper.GetCoins(ctx, BondPoolAddress()).AmountOf("uatom"))
+	unbondings := k.GetIssuerBondStatus(ctx, class, addr1).Unbondings
+	require.Len(t, unbondings, 1)
+	require.Equal(t, other, unbondings[0].Unbonding.Amount)
+
+	// a slash which rounds down to nothing fails
+	proposal.Fraction = sdk.NewDecWithPrec(1, 3)
+	err = HandleSlashBondProposal(ctx, k, proposal)
+	require.Error(t, err)
+	require.Equal(t, CodeInsufficientBond, err.(sdk.Error).Code())
+	_, broken := BondPoolInvariant(k)(ctx)
+	require.False(t, broken)
+}
+
+func TestCompleteBondUnbondingFailure(t *testing.T) {
+	ctx, k := createTestInput(t)
+	addr3 := sdk.AccAddress([]byte("addr3_______________"))
+	classMetadata := testCreditClass()
+	classMetadata.Issuers = []sdk.AccAddress{addr2, addr3}
+	classMetadata.BondUnbondingPeriod = time.Hour
+	class, err := k.CreateCreditClass(ctx, classMetadata)
+	require.NoError(t, err)
+	bonds := []sdk.Coins{sdk.NewCoins(sdk.NewInt64Coin("uatom", 100)), sdk.NewCoins(sdk.NewInt64Coin("uatom", 40))}
+	k.supplyKeeper.SetSupply(ctx, supply.NewSupply(bonds[0].Add(bonds[1])))
+	for i, issuer := range []sdk.AccAddress{addr2, addr3} {
+		_, err = k.bankKeeper.AddCoins(ctx, issuer, bonds[i])
+		require.NoError(t, err)
+		require.NoError(t, k.PostBond(ctx, issuer, class, bonds[i]))
+		_, err = k.UnbondBond(ctx, issuer, class, bonds[i])
+		require.NoError(t, err)
+	}
+	// the bond pool lost coins so that the first unbonding can't be paid out anymore
+	require.NoError(t, k.supplyKeeper.BurnCoins(ctx, BondPoolName, sdk.NewCoins(sdk.NewInt64Coin("uatom", 50))))
+
+	ctx = ctx.WithBlockHeader(abci.Header{Time: startDate.Add(time.Hour)}).WithEventManager(sdk.NewEventManager())
+	require.NotPanics(t, func() { k.CompleteBondUnbondings(ctx) })
+	require.True(t, k.bankKeeper.GetCoins(ctx, addr2).IsZero())
+	require.Len(t, k.GetIssuerBondStatus(ctx, class, addr2).Unbondings, 1)
+	require.Equal(t, bonds[1], k.bankKeeper.GetCoins(ctx, addr3))
+	require.Empty(t, k.GetIssuerBondStatus(ctx, class, addr3).Unbondings)
+	require.Equal(t, []string{bank.EventTypeTransfer, sdk.EventTypeMessage, EventTypeCompleteBondUnbonding},
+		eventTypes(ctx.EventManager().Events()))
+}
+
+func TestCreditExpiry(t *testing.T) {
+	ctx, k := createTestInput(t)
+	classMetadata := testCreditClass()
+	classMetadata.ValidityPeriod = 24 * time.Hour
+	class, err := k.CreateCreditClass(ctx, classMetadata)
+	require.NoError(t, err)
+	credit, err := k.IssueCredit(ctx, testCredit(class), addr1)
+	require.NoError(t, err)
+	require.NoError(t, k.SendCredit(ctx, credit, addr1, addr2, sdk.NewDec(10)))
+	order, err := k.CreateSellOrder(ctx, addr1, credit, sdk.NewDec(20), sdk.NewCoins(sdk.NewInt64Coin("uatom", 1)))
+	require.NoError(t, err)
+	status, found := k.GetCreditExpiryStatus(ctx, credit)
+	require.True(t, found)
+	require.True(t, status.Expires)
+	require.Equal(t, endDate.Add(24*time.Hour), status.ExpiryTime)
+	require.False(t, status.Expired)
+
+	// expired units can't be sent, retired, offered or bought
+	ctx = ctx.WithBlockHeader(abci.Header{Time: endDate.Add(24 * time.Hour)})
+	err = k.UpdateSellOrder(ctx, order, addr1, sdk.NewDec(25), sdk.NewCoins(sdk.NewInt64Coin("uatom", 1)))
+	require.Error(t, err)
+	require.Equal(t, CodeCreditExpired, err.(sdk.Error).Code())
+	err = k.SendCredit(ctx, credit, addr1, addr2, sdk.NewDec(1))
+	require.Error(t, err)
+	require.Equal(t, CodeCreditExpired, err.(sdk.Error).Code())
+	_, err = k.BurnCredit(ctx, credit, addr1, sdk.NewDec(1), RetirementInfo{})
+	require.Error(t, err)
+	_, err = k.BuyCredit(ctx, addr2, order, sdk.NewDec(1), sdk.NewCoins(sdk.NewInt64Coin("uatom", 1)), false, RetirementInfo{})
+	require.Error(t, err)
+
+	// the sweep expires the liquid units outside of escrow, escrowed units expire once they are released
+	k.SweepExpiredCredits(ctx)
+	holding, _ := k.GetCreditHolding(ctx, credit, addr1)
+	require.True(t, holding.LiquidUnits.IsZero())
+	expired, found := k.GetExpiredHolding(ctx, credit, addr1)
+	require.True(t, found)
+	require.Equal(t, sdk.NewDec(70), expired.Units)
+	require.NoError(t, k.CancelSellOrder(ctx, order, addr1))
+	expired, _ = k.GetExpiredHolding(ctx, credit, addr1)
+	require.Equal(t, sdk.NewDec(90), expired.Units)
+	supply, _ := k.GetCreditSupply(ctx, credit)
+	require.Equal(t, CreditSupply{Credit: credit, Issued: sdk.NewDec(100), Liquid: sdk.ZeroDec(), Retired: sdk.ZeroDec(),
+		Reversed: sdk.ZeroDec(), Expired: sdk.NewDec(100), Converted: sdk.ZeroDec()}, supply)
+	status, _ = k.GetCreditExpiryStatus(ctx, credit)
+	require.True(t, status.Expired)
+	require.Len(t, status.Holdings, 2)
+	_, broken := SupplyInvariant(k)(ctx)
+	require.False(t, broken)
+
+	exported := ExportGenesis(ctx, k)
+	require.NoError(t, ValidateGenesis(exported))
+	require.Empty(t, exported.CreditExpiries)
+	require.Len(t, exported.ExpiredHoldings, 2)
+}
+
+func TestSweepExpiredCreditFailure(t *testing.T) {
+	ctx, k := createTestInput(t)
+	classMetadata := testCreditClass()
+	classMetadata.ValidityPeriod = 24 * time.Hour
+	class, err := k.CreateCreditClass(ctx, classMetadata)
+	require.NoError(t, err)
+	missing, err := k.IssueCredit(ctx, testCredit(class), addr1)
+	require.NoError(t, err)
+	other := testCredit(class)
+	other.GeoPolygon = mustGeoPolygon("POLYGON ((2 2, 3 2, 3 3, 2 3, 2 2))")
+	credit, err := k.IssueCredit(ctx, other, addr1)
+	require.NoError(t, err)
+	// the supply of the first credit went missing so that its units can't be expired
+	supply, _ := k.GetCreditSupply(ctx, missing)
+	require.NoError(t, k.creditSupplyBucket.Delete(ctx, supply))
+
+	ctx = ctx.WithBlockHeader(abci.Header{Time: endDate.Add(24 * time.Hour)}).WithEventManager(sdk.NewEventManager())
+	require.NotPanics(t, func() { k.SweepExpiredCredits(ctx) })
+	holding, _ := k.GetCreditHolding(ctx, missing, addr1)
+	require.Equal(t, sdk.NewDec(100), holding.LiquidUnits)
+	_, found := k.GetExpiredHolding(ctx, missing, addr1)
+	require.False(t, found)
+	holding, _ = k.GetCreditHolding(ctx, credit, addr1)
+	require.True(t, holding.LiquidUnits.IsZero())
+	expired, _ := k.GetExpiredHolding(ctx, credit, addr1)
+	require.Equal(t, sdk.NewDec(100), expired.Units)
+	require.Equal(t, []string{EventTypeExpireCredit}, eventTypes(ctx.EventManager().Events()))
+	// the credit which failed is retried at the next block
+	require.Len(t, ExportGenesis(ctx, k).CreditExpiries, 1)
+}
+
+func TestSplitCreditGas(t *testing.T) {
+	ctx, k := createTestInput(t)
+	class, err := k.CreateCreditClass(ctx, testCreditClass())
+	require.NoError(t, err)
+	credit, err := k.IssueCredit(ctx, testCredit(class), addr1)
+	require.NoError(t, err)
+	ctx = ctx.WithGasMeter(sdk.NewGasMeter(1000000))
+	left := mustGeoPolygon("POLYGON ((0 0, 0.25 0, 0.25 1, 0 1, 0 0))")
+	right := mustGeoPolygon("POLYGON ((0.25 0, 1 0, 1 1, 0.25 1, 0.25 0))")
+	_, err = k.SplitCredit(ctx, addr1, credit, sdk.NewDec(4), [][]byte{left, right})
+	require.NoError(t, err)
+
+	// every part is triangulated and compared with the other parts, so many parts with many vertices run out of gas
+	parts := make([][]byte, MaxConversionParts)
+	for i := range parts {
+		parts[i] = mustGeoCircle(0.5, 0.4, 200)
+	}
+	ctx = ctx.WithGasMeter(sdk.NewGasMeter(1000000))
+	require.Panics(t, func() {
+		_, _ = k.SplitCredit(ctx, addr1, credit, sdk.NewDec(32), parts)
+	})
+	require.True(t, ctx.GasMeter().IsOutOfGas())
+}
+
+func TestSplitAndMergeCredits(t *testing.T) {
+	ctx, k := createTestInput(t)
+	class, err := k.CreateCreditClass(ctx, testCreditClass())
+	require.NoError(t, err)
+	credit, err := k.IssueCredit(ctx, testCredit(class), addr1)
+	require.NoError(t, err)
+	require.NoError(t, k.SendCredit(ctx, credit, addr1, addr2, sdk.NewDec(40)))
+	left := mustGeoPolygon("POLYGON ((0 0, 0.25 0, 0.25 1, 0 1, 0 0))")
+	right := mustGeoPolygon("POLYGON ((0.25 0, 1 0, 1 1, 0.25 1, 0.25 0))")
+
+	// the parts must tile the polygon of the credit
+	_, err = k.SplitCredit(ctx, addr1, credit, sdk.NewDec(60), [][]byte{left, mustGeoPolygon("POLYGON ((0.25 0, 1 0, 1 0.5, 0.25 0.5, 0.25 0))")})
+	require.Error(t, err)
+	require.Equal(t, CodeInvalidTiling, err.(sdk.Error).Code())
+
+	// units are apportioned by area
+	ctx = ctx.WithEventManager(sdk.NewEventManager())
+	id, err := k.SplitCredit(ctx, addr1, credit, sdk.NewDec(60), [][]byte{left, right})
+	require.NoError(t, err)
+	require.Len(t, ctx.EventManager().Events(), 3)
+	conversion, found := k.GetConversion(ctx, id)
+	require.True(t, found)
+	children := []CreditID{conversion.Results[0].Credit, conversion.Results[1].Credit}
+	require.Equal(t, []CreditUnits{{children[0], sdk.NewDec(15)}, {children[1], sdk.NewDec(45)}}, conversion.Results)
+	child, _ := k.GetCredit(ctx, children[0])
+	require.Equal(t, left, child.GeoPolygon)
+	lineage, _ := k.GetCreditLineage(ctx, credit)
+	require.Equal(t, children, lineage.Children)
+	lineage, _ = k.GetCreditLineage(ctx, children[1])
+	require.Equal(t, []CreditID{credit}, lineage.Parents)
+
+	// later splits must use the same parts and add to the same children, the indivisible part left over goes to the
+	// first part
+	_, err = k.SplitCredit(ctx, addr2, credit, sdk.NewDec(10), [][]byte{
+		mustGeoPolygon("POLYGON ((0 0, 0.5 0, 0.5 1, 0 1, 0 0))"), mustGeoPolygon("POLYGON ((0.5 0, 1 0, 1 1, 0.5 1, 0.5 0))"),
+	})
+	require.Error(t, err)
+	require.Equal(t, CodeInvalidTiling, err.(sdk.Error).Code())
+	id, err = k.SplitCredit(ctx, addr2, credit, sdk.NewDecWithPrec(10000001, 6), [][]byte{right, left})
+	require.NoError(t, err)
+	conversion, _ = k.GetConversion(ctx, id)
+	require.Equal(t, []CreditUnits{{children[1], sdk.NewDecWithPrec(7500001, 6)}, {children[0], sdk.NewDecWithPrec(2500000, 6)}}, conversion.Results)
+	supply, _ := k.GetCreditSupply(ctx, credit)
+	require.Equal(t, CreditSupply{Credit: credit, Issued: sdk.NewDec(100), Liquid: sdk.NewDecWithPrec(29999999, 6),
+		Retired: sdk.ZeroDec(), Reversed: sdk.ZeroDec(), Expired: sdk.ZeroDec(), Converted: sdk.NewDecWithPrec(70000001, 6)}, supply)
+	child, _ = k.GetCredit(ctx, children[0])
+	require.Equal(t, sdk.NewDec(15).Add(sdk.NewDecWithPrec(2500000, 6)), child.LiquidUnits)
+
+	// credits of different vintages can't be merged
+	other := testCredit(class)
+	other.GeoPolygon = mustGeoPolygon("POLYGON ((1 0, 2 0, 2 1, 1 1, 1 0))")
+	other.StartDate, other.EndDate = endDate, endDate.AddDate(1, 0, 0)
+	otherCredit, err := k.IssueCredit(ctx, other, addr1)
+	require.NoError(t, err)
+	_, err = k.MergeCredits(ctx, addr1, []CreditUnits{{children[1], sdk.NewDec(1)}, {otherCredit, sdk.NewDec(1)}},
+		mustGeoPolygon("POLYGON ((0.25 0, 2 0, 2 1, 0.25 1, 0.25 0))"))
+	require.Error(t, err)
+	require.Equal(t, CodeIncompatibleCredits, err.(sdk.Error).Code())
+
+	// the children can be merged back into a credit for the whole polygon, which then can't be split again
+	id, err = k.MergeCredits(ctx, addr1, []CreditUnits{{children[0], sdk.NewDec(15)}, {children[1], sdk.NewDec(45)}},
+		testCredit(class).GeoPolygon)
+	require.NoError(t, err)
+	conversion, _ = k.GetConversion(ctx, id)
+	merged := conversion.Results[0].Credit
+	require.Equal(t, sdk.NewDec(60), conversion.Results[0].Units)
+	lineage, _ = k.GetCreditLineage(ctx, merged)
+	require.Equal(t, children, lineage.Parents)
+	_, err = k.SplitCredit(ctx, addr2, children[0], sdk.NewDec(1), [][]byte{
+		mustGeoPolygon("POLYGON ((0 0, 0.25 0, 0.25 0.5, 0 0.5, 0 0))"), mustGeoPolygon("POLYGON ((0 0.5, 0.25 0.5, 0.25 1, 0 1, 0 0.5))"),
+	})
+	require.Error(t, err)
+	require.Equal(t, CodeInvalidTiling, err.(sdk.Error).Code())
+	holding, _ := k.GetCreditHolding(ctx, merged, addr1)
+	require.Equal(t, sdk.NewDec(60), holding.LiquidUnits)
+	_, broken := SupplyInvariant(k)(ctx)
+	require.False(t, broken)
+
+	// the converted supply and the lineages are restored at genesis
+	exported := ExportGenesis(ctx, k)
+	require.NoError(t, ValidateGenesis(exported))
+	require.Len(t, exported.Conversions, 3)
+	require.Len(t, exported.CreditLineages, 4)
+	ctx2, k2 := createTestInput(t)
+	InitGenesis(ctx2, k2, exported)
+	require.Equal(t, exported, ExportGenesis(ctx2, k2))
+	_, broken = SupplyInvariant(k2)(ctx2)
+	require.False(t, broken)
+}
+
+func TestRetirements(t *testing.T) {
+	ctx, k := createTestInput(t)
+	class, err := k.CreateCreditClass(ctx, testCreditClass())
+	require.NoError(t, err)
+	credit1, err := k.IssueCredit(ctx, testCredit(class), addr1)
+	require.NoError(t, err)
+	adjacent := testCredit(class)
+	adjacent.GeoPolygon = mustGeoPolygon("POLYGON ((1 0, 2 0, 2 1, 1 1, 1 0))")
+	credit2, err := k.IssueCredit(ctx, adjacent, addr1)
+	require.NoError(t, err)
+	require.NoError(t, k.SendCredit(ctx, credit1, addr1, addr2, sdk.NewDec(10)))
+
+	ctx = ctx.WithBlockHeader(abci.Header{Time: startDate.Add(time.Hour)})
+	info := RetirementInfo{Beneficiary: "acme", Jurisdiction: "US-CA", Reason: "2019 emissions"}
+	id1, err := k.BurnCredit(ctx, credit1, addr1, sdk.NewDec(5), info)
+	require.NoError(t, err)
+	id2, err := k.BurnCredit(ctx, credit2, addr1, sdk.NewDec(3), RetirementInfo{})
+	require.NoError(t, err)
+	id3, err := k.BurnCredit(ctx, credit1, addr2, sdk.NewDec(2), RetirementInfo{})
+	require.NoError(t, err)
+
+	// the certificate records who retired what on whose behalf and when
+	retirement, found := k.GetRetirement(ctx, id1)
+	require.True(t, found)
+	require.Equal(t, Retirement{Credit: credit1, Units: sdk.NewDec(5), Holder: addr1, RetirementInfo: info,
+		Timestamp: startDate.Add(time.Hour)}, retirement)
+	_, found = k.GetRetirement(ctx, RetirementID("unknown"))
+	require.False(t, found)
+
+	collect := func(iterate func(callback func(id RetirementID, retirement Retirement) (stop bool))) []RetirementID {
+		var ids []RetirementID
+		iterate(func(id RetirementID, retirement Retirement) (stop bool) {
+			ids = append(ids, id)
+			return false
+		})
+		return ids
+	}
+	require.Equal(t, []RetirementID{id1, id2}, collect(func(callback func(id RetirementID, retirement Retirement) (stop bool)) {
+		k.IterateRetirementsByHolder(ctx, addr1, callback)
+	}))
+	require.Equal(t, []RetirementID{id3}, collect(func(callback func(id RetirementID, retirement Retirement) (stop bool)) {
+		k.IterateRetirementsByHolder(ctx, addr2, callback)
+	}))
+	require.Equal(t, []RetirementID{id1, id3}, collect(func(callback func(id RetirementID, retirement Retirement) (stop bool)) {
+		k.IterateRetirementsByCredit(ctx, credit1, callback)
+	}))
+	require.Equal(t, []RetirementID{id2}, collect(func(callback func(id RetirementID, retirement Retirement) (stop bool)) {
+		k.IterateRetirementsByCredit(ctx, credit2, callback)
+	}))
+	require.Empty(t, collect(func(callback func(id RetirementID, retirement Retirement) (stop bool)) {
+		k.IterateRetirementsByHolder(ctx, sdk.AccAddress([]byte("other_______________")), callback)
+	}))
+}
+
+func TestReport(t *testing.T) {
+	ctx, k := createTestInput(t)
+	meta := testCreditClass()
+	meta.Precision = 2
+	class, err := k.CreateCreditClass(ctx, meta)
+	require.NoError(t, err)
+	credit, err := k.IssueCredit(ctx, testCredit(class), addr1)
+	require.NoError(t, err)
+	serial := fmt.Sprintf("ECO-%06d-%08d-20190101-20200101", binary.BigEndian.Uint64(class), binary.BigEndian.Uint64(credit))
+	ctx = ctx.WithBlockHeader(abci.Header{Time: startDate.Add(time.Hour)})
+	require.NoError(t, k.SendCredit(ctx, credit, addr1, addr2, sdk.NewDec(40)))
+	ctx = ctx.WithBlockHeader(abci.Header{Time: startDate.Add(2 * time.Hour)})
+	retirement, err := k.BurnCredit(ctx, credit, addr2, sdk.NewDecWithPrec(125, 2), RetirementInfo{Beneficiary: "acme"})
+	require.NoError(t, err)
+
+	// the serial range of an issuance covers its indivisible parts
+	report, err := k.Report(ctx, class, time.Time{}, time.Time{})
+	require.NoError(t, err)
+	require.Equal(t, []ReportIssuance{{Credit: credit, Serial: serial, Issuer: addr1, Holder: addr1, Units: sdk.NewDec(100),
+		FirstSerial: serial + "-000000000001", LastSerial: serial + "-000000010000", Timestamp: startDate}}, report.Issuances)
+	require.Equal(t, []ReportTransfer{{Credit: credit, Serial: serial, From: addr1, To: addr2, Units: sdk.NewDec(40),
+		Timestamp: startDate.Add(time.Hour)}}, report.Transfers)
+	require.Len(t, report.Retirements, 1)
+	require.Equal(t, retirement, report.Retirements[0].ID)
+	require.Equal(t, "acme", report.Retirements[0].Beneficiary)
+
+	// entries outside of the time range are left out
+	report, err = k.Report(ctx, class, startDate.Add(time.Hour), startDate.Add(2*time.Hour))
+	require.NoError(t, err)
+	require.Empty(t, report.Issuances)
+	require.Len(t, report.Transfers, 1)
+	require.Empty(t, report.Retirements)
+
+	var buf bytes.Buffer
+	require.NoError(t, report.WriteCSV(&buf))
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 2)
+	require.True(t, strings.HasPrefix(lines[1], "transfer,2019-01-01T01:00:00Z,"))
+
+	// units withheld for the buffer pool are reported as an issuance to the buffer pool
+	meta.BufferRate = sdk.NewDecWithPrec(1, 1)
+	buffered, err := k.CreateCreditClass(ctx, meta)
+	require.NoError(t, err)
+	credit, err = k.IssueCredit(ctx, testCredit(buffered), addr1)
+	require.NoError(t, err)
+	serial = fmt.Sprintf("ECO-%06d-%08d-20190101-20200101", binary.BigEndian.Uint64(buffered), binary.BigEndian.Uint64(credit))
+	report, err = k.Report(ctx, buffered, time.Time{}, time.Time{})
+	require.NoError(t, err)
+	require.Equal(t, []ReportIssuance{
+		{Credit: credit, Serial: serial, Issuer: addr1, Holder: addr1, Units: sdk.NewDec(90),
+			FirstSerial: serial + "-000000000001", LastSerial: serial + "-000000009000", Timestamp: startDate.Add(2 * time.Hour)},
+		{Credit: credit, Serial: serial, Issuer: addr1, Holder: BufferPoolAddress, Units: sdk.NewDec(10),
+			FirstSerial: serial + "-000000009001", LastSerial: serial + "-000000010000", Timestamp: startDate.Add(2 * time.Hour)},
+	}, report.Issuances)
+
+	_, err = k.Report(ctx, CreditClassID([]byte("unknown")), time.Time{}, time.Time{})
+	require.Error(t, err)
+}
+
+func TestHandlerEvents(t *testing.T) {
+	ctx, k := createTestInput(t)
+	handler := NewHandler(k)
+	res := handler(ctx, MsgCreateCreditClass{CreditClassMetadata: testCreditClass()})
+	require.True(t, res.IsOK(), res.Log)
+	require.Equal(t, EventTypeCreateCreditClass, res.Events[0].Type)
+	require.Equal(t, sdk.EventTypeMessage, res.Events[1].Type)
+	class, err := CreditClassFromBech32(string(res.Events[0].Attributes[0].Value))
+	require.NoError(t, err)
+	_, found := k.GetCreditClass(ctx, class)
+	require.True(t, found)
+
+	credit := testCredit(class)
+	res = handler(ctx, MsgIssueCredit{CreditMetadata: credit, Holder: addr1})
+	require.True(t, res.IsOK(), res.Log)
+	id, err := CreditFromBech32(string(res.Events[0].Attributes[1].Value))
+	require.NoError(t, err)
+	require.Equal(t, sdk.NewEvent(EventTypeIssueCredit,
+		sdk.NewAttribute(AttributeKeyCreditClass, class.String()),
+		sdk.NewAttribute(AttributeKeyCredit, id.String()),
+		sdk.NewAttribute(AttributeKeyIssuer, addr1.String()),
+		sdk.NewAttribute(AttributeKeyTo, addr1.String()),
+		sdk.NewAttribute(AttributeKeyUnits, sdk.NewDec(100).String()),
+		sdk.NewAttribute(AttributeKeyRetiredUnits, sdk.ZeroDec().String()),
+		sdk.NewAttribute(AttributeKeyPolygonHash, polygonHash(credit.GeoPolygon)),
+	), res.Events[0])
+
+	res = handler(ctx, MsgSendCredit{Credit: id, From: addr1, To: addr2, Units: sdk.NewDec(10)})
+	require.True(t, res.IsOK(), res.Log)
+	require.Equal(t, sdk.NewEvent(EventTypeSendCredit,
+		sdk.NewAttribute(AttributeKeyCredit, id.String()),
+		sdk.NewAttribute(AttributeKeyFrom, addr1.String()),
+		sdk.NewAttribute(AttributeKeyTo, addr2.String()),
+		sdk.NewAttribute(AttributeKeyUnits, sdk.NewDec(10).String()),
+	), res.Events[0])
+	require.Equal(t, messageEvent(addr1), res.Events[1])
+
+	res = handler(ctx, MsgBurnCredit{Credit: id, Holder: addr2, Units: sdk.NewDec(1), RetirementInfo: RetirementInfo{Beneficiary: "acme"}})
+	require.True(t, res.IsOK(), res.Log)
+	require.Equal(t, EventTypeRetireCredit, res.Events[0].Type)
+	require.Equal(t, messageEvent(addr2), res.Events[1])
+
+	// the events of changes applied in a cache context are kept
+	res = handler(ctx, MsgMultiSendCredit{From: addr1, Outputs: []CreditOutput{
+		{Credit: id, To: addr2, Units: sdk.NewDec(1)}, {Credit: id, To: addr2, Units: sdk.NewDec(2)},
+	}})
+	require.True(t, res.IsOK(), res.Log)
+	require.Equal(t, []string{EventTypeSendCredit, EventTypeSendCredit, sdk.EventTypeMessage}, eventTypes(res.Events))
+	require.Equal(t, []byte(sdk.NewDec(2).String()), res.Events[1].Attributes[3].Value)
+
+	order, err := k.CreateSellOrder(ctx, addr1, id, sdk.NewDec(10), sdk.NewCoins(sdk.NewInt64Coin("uatom", 2)))
+	require.NoError(t, err)
+	_, err = k.bankKeeper.AddCoins(ctx, addr2, sdk.NewCoins(sdk.NewInt64Coin("uatom", 10)))
+	require.NoError(t, err)
+	res = handler(ctx, MsgBuyCredit{Buyer: addr2, Order: order, Units: sdk.NewDec(5),
+		MaxPrice: sdk.NewCoins(sdk.NewInt64Coin("uatom", 2)), Retire: true})
+	require.True(t, res.IsOK(), res.Log)
+	// the payment emits the events of the bank
+	require.Equal(t, []string{bank.EventTypeTransfer, sdk.EventTypeMessage, EventTypeSendCredit, EventTypeRetireCredit,
+		EventTypeBuyCredit, sdk.EventTypeMessage}, eventTypes(res.Events))
+
+	// failed messages emit nothing
+	res = handler(ctx, MsgSendCredit{Credit: id, From: addr1, To: addr2, Units: sdk.NewDec(1000)})
+	require.False(t, res.IsOK())
+	require.Empty(t, res.Events)
+}
+
+// recordingHooks records the calls of the ecocredit hooks
+type recordingHooks struct {
+	calls *[]string
+}
+
+func (h recordingHooks) AfterClassCreated(ctx sdk.Context, class CreditClassID, metadata CreditClassMetadata) {
+	*h.calls = append(*h.calls, fmt.Sprintf("class %s", class))
+}
+
+func (h recordingHooks) AfterCreditIssued(ctx sdk.Context, credit CreditID, metadata CreditMetadata) {
+	*h.calls = append(*h.calls, fmt.Sprintf("issued %s %s", credit, metadata.LiquidUnits))
+}
+
+func (h recordingHooks) AfterCreditSent(ctx sdk.Context, credit CreditID, from sdk.AccAddress, to sdk.AccAddress, units sdk.Dec) {
+	*h.calls = append(*h.calls, fmt.Sprintf("sent %s %s %s %s", credit, from, to, units))
+}
+
+func (h recordingHooks) AfterCreditRetired(ctx sdk.Context, id RetirementID, retirement Retirement) {
+	*h.calls = append(*h.calls, fmt.Sprintf("retired %s %s %s", retirement.Credit, retirement.Holder, retirement.Units))
+}
+
+func TestHooks(t *testing.T) {
+	ctx, k := createTestInput(t)
+	var calls []string
+	k = *k.SetHooks(NewMultiEcocreditHooks(recordingHooks{calls: &calls}))
+	require.Panics(t, func() { k.SetHooks(recordingHooks{calls: &calls}) })
+
+	class, err := k.CreateCreditClass(ctx, testCreditClass())
+	require.NoError(t, err)
+	metadata := testCredit(class)
+	metadata.BurnedUnits = sdk.NewDec(5)
+	credit, err := k.IssueCredit(ctx, metadata, addr1)
+	require.NoError(t, err)
+	require.NoError(t, k.SendCredit(ctx, credit, addr1, addr2, sdk.NewDec(10)))
+	_, err = k.BurnCredit(ctx, credit, addr2, sdk.NewDec(1), RetirementInfo{})
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		fmt.Sprintf("class %s", class),
+		fmt.Sprintf("issued %s %s", credit, sdk.NewDec(100)),
+		fmt.Sprintf("retired %s %s %s", credit, addr1, sdk.NewDec(5)),
+		fmt.Sprintf("sent %s %s %s %s", credit, addr1, addr2, sdk.NewDec(10)),
+		fmt.Sprintf("retired %s %s %s", credit, addr2, sdk.NewDec(1)),
+	}, calls)
+
+	// failed changes don't call the hooks
+	calls = nil
+	require.Error(t, k.SendCredit(ctx, credit, addr1, addr2, sdk.NewDec(1000)))
+	require.Empty(t, calls)
+}
+
+// eventTypes returns the types of the events in order
+func eventTypes(events sdk.Events) []string {
+	types := make([]string, len(events))
+	for i, event := range events {
+		types[i] = event.Type
+	}
+	return types
+}
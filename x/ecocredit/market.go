@@ -0,0 +1,250 @@
+package ecocredit
+
+import (
+	"bytes"
+	"fmt"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/x/supply"
+)
+
+// SellOrderEscrowAddress holds the units of all open sell orders in the credit holdings bucket. The units held by it
+// for each credit always equal the remaining units of the open sell orders of that credit
+var SellOrderEscrowAddress = supply.NewModuleAddress("ecocredit-sell-orders")
+
+type SellOrderID []byte
+
+// SellOrder offers the remaining Units of a credit escrowed from the seller at a price per unit
+type SellOrder struct {
+	Seller sdk.AccAddress `json:"seller"`
+	Credit CreditID       `json:"credit"`
+	Units  sdk.Dec        `json:"units"`
+	// Price is the price of a single unit, buyers pay the price times the units they buy rounded up
+	Price sdk.Coins `json:"price"`
+}
+
+// SellOrderWithID pairs a sell order with its ID
+type SellOrderWithID struct {
+	ID        SellOrderID `json:"id"`
+	SellOrder SellOrder   `json:"sell_order"`
+}
+
+// Cost returns the price of the given number of units of the sell order, rounding up fractions of the smallest coin
+// unit so that sellers are never underpaid
+func (o SellOrder) Cost(units sdk.Dec) sdk.Coins {
+	cost := sdk.NewCoins()
+	for _, coin := range o.Price {
+		amount := sdk.NewDecFromInt(coin.Amount).Mul(units).Ceil().TruncateInt()
+		cost = cost.Add(sdk.NewCoins(sdk.NewCoin(coin.Denom, amount)))
+	}
+	return cost
+}
+
+// GetSellOrder gets an open sell order
+func (k Keeper) GetSellOrder(ctx sdk.Context, id SellOrderID) (order SellOrder, found bool) {
+	err := k.sellOrderBucket.GetOne(ctx, id, &order)
+	if err != nil {
+		return order, false
+	}
+	return order, true
+}
+
+// IterateSellOrders iterates over all open sell orders
+func (k Keeper) IterateSellOrders(ctx sdk.Context, callback func(id SellOrderID, order SellOrder) (stop bool)) {
+	iterator, err := k.sellOrderBucket.PrefixScan(ctx, nil, nil, false)
+	if err != nil {
+		return
+	}
+	defer iterator.Release()
+	for {
+		var order SellOrder
+		id, err := iterator.LoadNext(&order)
+		if err != nil {
+			break
+		}
+		if callback(id, order) {
+			return
+		}
+	}
+}
+
+// IterateSellOrdersBySeller iterates over the open sell orders of the seller
+func (k Keeper) IterateSellOrdersBySeller(ctx sdk.Context, seller sdk.AccAddress, callback func(id SellOrderID, order SellOrder) (stop bool)) {
+	k.iterateSellOrders(ctx, IndexBySeller, seller, callback)
+}
+
+// IterateSellOrdersByCredit iterates over the open sell orders of the credit
+func (k Keeper) IterateSellOrdersByCredit(ctx sdk.Context, credit CreditID, callback func(id SellOrderID, order SellOrder) (stop bool)) {
+	k.iterateSellOrders(ctx, IndexByCredit, credit, callback)
+}
+
+func (k Keeper) iterateSellOrders(ctx sdk.Context, index string, key []byte, callback func(id SellOrderID, order SellOrder) (stop bool)) {
+	iterator, err := k.sellOrderBucket.ByIndex(ctx, index, key)
+	if iterator != nil {
+		defer iterator.Release()
+	}
+	if err != nil {
+		return
+	}
+	for {
+		var order SellOrder
+		id, err := iterator.LoadNext(&order)
+		if err != nil {
+			break
+		}
+		if callback(id, order) {
+			return
+		}
+	}
+}
+
+// CreateSellOrder escrows units of a credit held by the seller and offers them for sale at a price per unit. Units of
+// expired credits can't be offered
+func (k Keeper) CreateSellOrder(ctx sdk.Context, seller sdk.AccAddress, credit CreditID, units sdk.Dec, price sdk.Coins) (SellOrderID, error) {
+	if err := k.checkCreditNotExpired(ctx, credit); err != nil {
+		return nil, err
+	}
+	err := k.transferCredit(ctx, credit, seller, SellOrderEscrowAddress, units)
+	if err != nil {
+		return nil, err
+	}
+	id, err := k.sellOrderBucket.Create(ctx, SellOrder{Seller: seller, Credit: credit, Units: units, Price: price})
+	if err != nil {
+		return nil, err
+	}
+	ctx.EventManager().EmitEvent(sdk.NewEvent(
+		EventTypeCreateSellOrder,
+		sdk.NewAttribute(AttributeKeySellOrder, SellOrderID(id).String()),
+		sdk.NewAttribute(AttributeKeySeller, seller.String()),
+		sdk.NewAttribute(AttributeKeyCredit, credit.String()),
+		sdk.NewAttribute(AttributeKeyUnits, units.String()),
+		sdk.NewAttribute(AttributeKeyPrice, price.String()),
+	))
+	return id, nil
+}
+
+// getSellOrderAsSeller loads a sell order and checks that seller created it
+func (k Keeper) getSellOrderAsSeller(ctx sdk.Context, id SellOrderID, seller sdk.AccAddress) (SellOrder, error) {
+	order, found := k.GetSellOrder(ctx, id)
+	if !found {
+		return order, ErrInvalidSellOrder(DefaultCodespace, fmt.Sprintf("sell order %s not found", id))
+	}
+	if !bytes.Equal(order.Seller, seller) {
+		return order, sdk.ErrUnauthorized("only the seller can change the sell order")
+	}
+	return order, nil
+}
+
+// UpdateSellOrder changes the remaining units and the price of a sell order. Additional units are escrowed from the
+// seller and units which are no longer offered are returned to the seller. Units of expired credits can't be added
+func (k Keeper) UpdateSellOrder(ctx sdk.Context, id SellOrderID, seller sdk.AccAddress, units sdk.Dec, price sdk.Coins) error {
+	order, err := k.getSellOrderAsSeller(ctx, id, seller)
+	if err != nil {
+		return err
+	}
+	switch {
+	case units.GT(order.Units):
+		if err := k.checkCreditNotExpired(ctx, order.Credit); err != nil {
+			return err
+		}
+		err = k.transferCredit(ctx, order.Credit, seller, SellOrderEscrowAddress, units.Sub(order.Units))
+	case units.LT(order.Units):
+		err = k.transferCredit(ctx, order.Credit, SellOrderEscrowAddress, seller, order.Units.Sub(units))
+	}
+	if err != nil {
+		return err
+	}
+	order.Units = units
+	order.Price = price
+	if err := k.sellOrderBucket.Save(ctx, id, order); err != nil {
+		return err
+	}
+	ctx.EventManager().EmitEvent(sdk.NewEvent(
+		EventTypeUpdateSellOrder,
+		sdk.NewAttribute(AttributeKeySellOrder, id.String()),
+		sdk.NewAttribute(AttributeKeySeller, seller.String()),
+		sdk.NewAttribute(AttributeKeyCredit, order.Credit.String()),
+		sdk.NewAttribute(AttributeKeyUnits, units.String()),
+		sdk.NewAttribute(AttributeKeyPrice, price.String()),
+	))
+	return nil
+}
+
+// CancelSellOrder closes a sell order and returns its remaining units to the seller
+func (k Keeper) CancelSellOrder(ctx sdk.Context, id SellOrderID, seller sdk.AccAddress) error {
+	order, err := k.getSellOrderAsSeller(ctx, id, seller)
+	if err != nil {
+		return err
+	}
+	err = k.transferCredit(ctx, order.Credit, SellOrderEscrowAddress, seller, order.Units)
+	if err != nil {
+		return err
+	}
+	if err := k.sellOrderBucket.Delete(ctx, id); err != nil {
+		return err
+	}
+	ctx.EventManager().EmitEvent(sdk.NewEvent(
+		EventTypeCancelSellOrder,
+		sdk.NewAttribute(AttributeKeySellOrder, id.String()),
+		sdk.NewAttribute(AttributeKeySeller, seller.String()),
+		sdk.NewAttribute(AttributeKeyCredit, order.Credit.String()),
+	))
+	return nil
+}
+
+// BuyCredit buys units of a sell order, fully or partially filling it. The buyer pays the cost of the units to the
+// seller, which must not exceed maxPrice per unit so that the buyer is protected against price updates. If retire is
+// set, the bought units are retired on behalf of the buyer right away and the ID of the retirement certificate is
+// returned. Fully filled sell orders are closed. Sell orders of expired credits can only be cancelled
+func (k Keeper) BuyCredit(ctx sdk.Context, buyer sdk.AccAddress, id SellOrderID, units sdk.Dec, maxPrice sdk.Coins, retire bool, info RetirementInfo) (RetirementID, error) {
+	order, found := k.GetSellOrder(ctx, id)
+	if !found {
+		return nil, ErrInvalidSellOrder(DefaultCodespace, fmt.Sprintf("sell order %s not found", id))
+	}
+	if err := k.checkCreditNotExpired(ctx, order.Credit); err != nil {
+		return nil, err
+	}
+	if units.GT(order.Units) {
+		return nil, ErrInvalidUnits(DefaultCodespace, fmt.Sprintf("sell order only has %s units left", order.Units))
+	}
+	if !order.Price.IsAllLTE(maxPrice) {
+		return nil, ErrPriceExceeded(DefaultCodespace, order.Price, maxPrice)
+	}
+	// the payment, the transfer and the retirement are applied together or not at all
+	cacheCtx, write := ctx.CacheContext()
+	if err := k.bankKeeper.SendCoins(cacheCtx, buyer, order.Seller, order.Cost(units)); err != nil {
+		return nil, err
+	}
+	err := k.transferCredit(cacheCtx, order.Credit, SellOrderEscrowAddress, buyer, units)
+	if err != nil {
+		return nil, err
+	}
+	order.Units = order.Units.Sub(units)
+	if order.Units.IsZero() {
+		err = k.sellOrderBucket.Delete(cacheCtx, id)
+	} else {
+		err = k.sellOrderBucket.Save(cacheCtx, id, order)
+	}
+	if err != nil {
+		return nil, err
+	}
+	var retirement RetirementID
+	if retire {
+		retirement, err = k.BurnCredit(cacheCtx, order.Credit, buyer, units, info)
+		if err != nil {
+			return nil, err
+		}
+	}
+	write()
+	// the cache context has its own event manager
+	ctx.EventManager().EmitEvents(cacheCtx.EventManager().Events())
+	ctx.EventManager().EmitEvent(sdk.NewEvent(
+		EventTypeBuyCredit,
+		sdk.NewAttribute(AttributeKeySellOrder, id.String()),
+		sdk.NewAttribute(AttributeKeyBuyer, buyer.String()),
+		sdk.NewAttribute(AttributeKeySeller, order.Seller.String()),
+		sdk.NewAttribute(AttributeKeyCredit, order.Credit.String()),
+		sdk.NewAttribute(AttributeKeyUnits, units.String()),
+		sdk.NewAttribute(AttributeKeyPrice, order.Price.String()),
+	))
+	return retirement, nil
+}
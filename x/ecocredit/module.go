@@ -36,15 +36,20 @@ func (AppModuleBasic) RegisterCodec(cdc *codec.Codec) {
 	RegisterCodec(cdc)
 }
 
-// DefaultGenesis returns default genesis state as raw bytes for the fee_grant
+// DefaultGenesis returns default genesis state as raw bytes for the ecocredit
 // module.
 func (AppModuleBasic) DefaultGenesis() json.RawMessage {
-	return []byte("[]")
+	return ModuleCdc.MustMarshalJSON(DefaultGenesisState())
 }
 
-// ValidateGenesis performs genesis state validation for the fee_grant module.
+// ValidateGenesis performs genesis state validation for the ecocredit module.
 func (a AppModuleBasic) ValidateGenesis(bz json.RawMessage) error {
-	return nil
+	var data GenesisState
+	err := ModuleCdc.UnmarshalJSON(bz, &data)
+	if err != nil {
+		return err
+	}
+	return ValidateGenesis(data)
 }
 
 // RegisterRESTRoutes registers the REST routes for the fee_grant module.
@@ -58,11 +63,9 @@ func (AppModuleBasic) GetTxCmd(cdc *codec.Codec) *cobra.Command {
 	return GetTxCmd(StoreKey, cdc)
 }
 
-// GetQueryCmd returns no root query command for the fee_grant module.
+// GetQueryCmd returns the root query command for the ecocredit module.
 func (AppModuleBasic) GetQueryCmd(cdc *codec.Codec) *cobra.Command {
-	// TODO
-	return nil
-	// return cli.GetQueryCmd(cdc)
+	return GetQueryCmd(QuerierRoute, cdc)
 }
 
 //____________________________________________________________________________
@@ -86,8 +89,10 @@ func (AppModule) Name() string {
 	return ModuleName
 }
 
-// RegisterInvariants registers the fee_grant module invariants.
-func (am AppModule) RegisterInvariants(ir sdk.InvariantRegistry) {}
+// RegisterInvariants registers the ecocredit module invariants.
+func (am AppModule) RegisterInvariants(ir sdk.InvariantRegistry) {
+	RegisterInvariants(ir, am.keeper)
+}
 
 // Route returns the message routing key for the fee_grant module.
 func (AppModule) Route() string {
@@ -109,23 +114,30 @@ func (am AppModule) NewQuerierHandler() sdk.Querier {
 	return NewQuerier(am.keeper)
 }
 
-// InitGenesis performs genesis initialization for the fee_grant module. It returns
+// InitGenesis performs genesis initialization for the ecocredit module. It returns
 // no validator updates.
 func (am AppModule) InitGenesis(ctx sdk.Context, data json.RawMessage) []abci.ValidatorUpdate {
+	var genesisState GenesisState
+	ModuleCdc.MustUnmarshalJSON(data, &genesisState)
+	InitGenesis(ctx, am.keeper, genesisState)
 	return nil
 }
 
-// ExportGenesis returns the exported genesis state as raw bytes for the fee_grant
+// ExportGenesis returns the exported genesis state as raw bytes for the ecocredit
 // module.
 func (am AppModule) ExportGenesis(ctx sdk.Context) json.RawMessage {
-	return nil
+	gs := ExportGenesis(ctx, am.keeper)
+	return ModuleCdc.MustMarshalJSON(gs)
 }
 
 // BeginBlock returns the begin blocker for the fee_grant module.
 func (am AppModule) BeginBlock(_ sdk.Context, _ abci.RequestBeginBlock) {}
 
-// EndBlock returns the end blocker for the fee_grant module. It returns no validator
-// updates.
-func (AppModule) EndBlock(_ sdk.Context, _ abci.RequestEndBlock) []abci.ValidatorUpdate {
+// EndBlock clears the auctions which have ended, returns the bonds whose unbonding completed and sweeps the liquid
+// units of credits which expired. It returns no validator updates.
+func (am AppModule) EndBlock(ctx sdk.Context, _ abci.RequestEndBlock) []abci.ValidatorUpdate {
+	am.keeper.ClearAuctions(ctx)
+	am.keeper.CompleteBondUnbondings(ctx)
+	am.keeper.SweepExpiredCredits(ctx)
 	return []abci.ValidatorUpdate{}
 }
@@ -0,0 +1,195 @@
+package ecocredit
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"time"
+)
+
+// MaxDataURILength is the maximum length of the URI of an anchored dataset
+const MaxDataURILength = 512
+
+// AnchoredData records the content hash of an MRV (monitoring, reporting and verification) dataset, such as the
+// sensor readings or the verification report an issuance is based on. The dataset itself is stored off-chain at
+// its URI and anyone can check it against the hash. Anchored data is never modified
+type AnchoredData struct {
+	Hash      []byte         `json:"hash"`
+	URI       string         `json:"uri"`
+	MediaType string         `json:"media_type"`
+	Sender    sdk.AccAddress `json:"sender"`
+	Timestamp time.Time      `json:"timestamp"`
+}
+
+func (d AnchoredData) ID() []byte {
+	return d.Hash
+}
+
+// Attestation records that a verifier registered by a credit class vouches for an anchored dataset
+type Attestation struct {
+	Hash        []byte         `json:"hash"`
+	CreditClass CreditClassID  `json:"credit_class"`
+	Verifier    sdk.AccAddress `json:"verifier"`
+	Timestamp   time.Time      `json:"timestamp"`
+}
+
+func (a Attestation) ID() []byte {
+	return []byte(fmt.Sprintf("%x/%x/%x", a.Hash, a.CreditClass, a.Verifier))
+}
+
+// AnchoredDataWithAttestations is the result of the anchored data query
+type AnchoredDataWithAttestations struct {
+	AnchoredData `json:"data"`
+	Attestations []Attestation `json:"attestations"`
+}
+
+// AnchorData anchors the hash of an MRV dataset. A dataset can only be anchored once, the sender of the first
+// anchoring is recorded
+func (k Keeper) AnchorData(ctx sdk.Context, sender sdk.AccAddress, hash []byte, uri string, mediaType string) error {
+	if _, found := k.GetAnchoredData(ctx, hash); found {
+		return ErrInvalidData(DefaultCodespace, fmt.Sprintf("dataset %x is already anchored", hash))
+	}
+	err := k.anchoredDataBucket.Save(ctx, AnchoredData{
+		Hash:      hash,
+		URI:       uri,
+		MediaType: mediaType,
+		Sender:    sender,
+		Timestamp: ctx.BlockHeader().Time,
+	})
+	if err != nil {
+		return err
+	}
+	ctx.EventManager().EmitEvent(sdk.NewEvent(
+		EventTypeAnchorData,
+		sdk.NewAttribute(AttributeKeyDataHash, hex.EncodeToString(hash)),
+		sdk.NewAttribute(AttributeKeyURI, uri),
+		sdk.NewAttribute(AttributeKeyMediaType, mediaType),
+	))
+	return nil
+}
+
+// AttestData records the attestation of an anchored dataset by a verifier of the credit class. Each verifier can
+// attest a dataset once per class
+func (k Keeper) AttestData(ctx sdk.Context, verifier sdk.AccAddress, class CreditClassID, hash []byte) error {
+	if _, found := k.GetAnchoredData(ctx, hash); !found {
+		return ErrInvalidData(DefaultCodespace, fmt.Sprintf("dataset %x is not anchored", hash))
+	}
+	metadata, found := k.GetCreditClass(ctx, class)
+	if !found {
+		return ErrInvalidCreditClass(DefaultCodespace, fmt.Sprintf("credit class %s not found", class))
+	}
+	if !metadata.IsVerifier(verifier) {
+		return sdk.ErrUnauthorized(fmt.Sprintf("%s is not a verifier of the credit class", verifier))
+	}
+	attestation := Attestation{Hash: hash, CreditClass: class, Verifier: verifier}
+	if err := k.attestationBucket.GetOne(ctx, &attestation); err == nil {
+		return ErrInvalidData(DefaultCodespace, fmt.Sprintf("%s already attested dataset %x", verifier, hash))
+	}
+	attestation.Timestamp = ctx.BlockHeader().Time
+	if err := k.attestationBucket.Save(ctx, attestation); err != nil {
+		return err
+	}
+	ctx.EventManager().EmitEvent(sdk.NewEvent(
+		EventTypeAttestData,
+		sdk.NewAttribute(AttributeKeyDataHash, hex.EncodeToString(hash)),
+		sdk.NewAttribute(AttributeKeyCreditClass, class.String()),
+		sdk.NewAttribute(AttributeKeyVerifier, verifier.String()),
+	))
+	return nil
+}
+
+// checkAttestations checks that the datasets of an issuance are anchored and, if the class requires attestations,
+// that there is at least one dataset and each is attested by enough of the current verifiers of the class.
+// Attestations of verifiers which were removed from the class don't count
+func (k Keeper) checkAttestations(ctx sdk.Context, class CreditClassID, metadata CreditClassMetadata, datasets [][]byte) error {
+	if metadata.RequiredAttestations > 0 && len(datasets) == 0 {
+		return ErrMissingAttestations(DefaultCodespace, "credit class requires issuances to reference attested datasets")
+	}
+	for _, hash := range datasets {
+		if _, found := k.GetAnchoredData(ctx, hash); !found {
+			return ErrInvalidData(DefaultCodespace, fmt.Sprintf("dataset %x is not anchored", hash))
+		}
+		var count uint32
+		k.IterateAttestations(ctx, hash, func(attestation Attestation) (stop bool) {
+			if bytes.Equal(attestation.CreditClass, class) && metadata.IsVerifier(attestation.Verifier) {
+				count++
+			}
+			return false
+		})
+		if count < metadata.RequiredAttestations {
+			return ErrMissingAttestations(DefaultCodespace, fmt.Sprintf("dataset %x is attested by %d of %d required verifiers",
+				hash, count, metadata.RequiredAttestations))
+		}
+	}
+	return nil
+}
+
+// GetAnchoredData gets an anchored dataset by its hash
+func (k Keeper) GetAnchoredData(ctx sdk.Context, hash []byte) (data AnchoredData, found bool) {
+	data = AnchoredData{Hash: hash}
+	err := k.anchoredDataBucket.GetOne(ctx, &data)
+	if err != nil {
+		return data, false
+	}
+	return data, true
+}
+
+// IterateAnchoredData iterates over all anchored datasets in the order of their hashes
+func (k Keeper) IterateAnchoredData(ctx sdk.Context, callback func(data AnchoredData) (stop bool)) {
+	iterator, err := k.anchoredDataBucket.PrefixScan(ctx, nil, nil, false)
+	if err != nil {
+		return
+	}
+	defer iterator.Release()
+	for {
+		var data AnchoredData
+		_, err := iterator.LoadNext(&data)
+		if err != nil {
+			break
+		}
+		if callback(data) {
+			return
+		}
+	}
+}
+
+// IterateAttestations iterates over the attestations of an anchored dataset by the verifiers of all classes
+func (k Keeper) IterateAttestations(ctx sdk.Context, hash []byte, callback func(attestation Attestation) (stop bool)) {
+	iterator, err := k.attestationBucket.ByIndex(ctx, IndexByDataHash, hash)
+	if iterator != nil {
+		defer iterator.Release()
+	}
+	if err != nil {
+		return
+	}
+	for {
+		var attestation Attestation
+		_, err := iterator.LoadNext(&attestation)
+		if err != nil {
+			break
+		}
+		if callback(attestation) {
+			return
+		}
+	}
+}
+
+// IterateAllAttestations iterates over all attestations of all datasets
+func (k Keeper) IterateAllAttestations(ctx sdk.Context, callback func(attestation Attestation) (stop bool)) {
+	iterator, err := k.attestationBucket.PrefixScan(ctx, nil, nil, false)
+	if err != nil {
+		return
+	}
+	defer iterator.Release()
+	for {
+		var attestation Attestation
+		_, err := iterator.LoadNext(&attestation)
+		if err != nil {
+			break
+		}
+		if callback(attestation) {
+			return
+		}
+	}
+}
@@ -1,10 +1,21 @@
 package ecocredit
 
 import (
-	"time"
+	"crypto/sha256"
+	"fmt"
 	sdk "github.com/cosmos/cosmos-sdk/types"
+	channel "github.com/cosmos/cosmos-sdk/x/ibc/04-channel"
+	commitment "github.com/cosmos/cosmos-sdk/x/ibc/23-commitment"
+	host "github.com/cosmos/cosmos-sdk/x/ibc/24-host"
+	"github.com/cosmos/gaia/geo"
+	"mime"
+	"strings"
+	"time"
 )
 
+// MaxRetirementInfoLength is the maximum length of each of the fields of RetirementInfo
+const MaxRetirementInfoLength = 256
+
 type CreditClassMetadata struct {
 	// Designer is the entity which designs a credit class at the top-level and
 	// certifies issuers
@@ -14,6 +25,46 @@ type CreditClassMetadata struct {
 	Name string
 	// Issuers are those entities authorized to issue credits via MsgIssueCredit
 	Issuers []sdk.AccAddress
+	// Deprecated classes can't be used to issue new credits, but existing credits remain tradable
+	Deprecated bool
+	// CreditType is the kind of ecosystem service the credits represent, e.g. "carbon" or "biodiversity"
+	CreditType string
+	// UnitOfMeasure is what one unit of a credit of this class measures, e.g. "tCO2e" or "ha"
+	UnitOfMeasure string
+	// MethodologyURI references the methodology document credits of this class are issued under
+	MethodologyURI string
+	// MethodologyHash is the SHA-256 hash of the methodology document, so that the document can't be changed
+	// without changing the class
+	MethodologyHash []byte
+	// Precision is the maximum number of decimal places of units of credits of this class
+	Precision uint32
+	// RequiredAttributes are the keys of attributes every issuance of a credit of this class must provide
+	RequiredAttributes []string
+	// BufferRate is the fraction of the liquid units of every issuance withheld in the buffer pool of the class to
+	// cover reversals, e.g. 0.2 for 20%. It must be lower than 1, an empty rate withholds nothing
+	BufferRate sdk.Dec
+	// Verifiers are the entities registered to attest the MRV (monitoring, reporting and verification) datasets
+	// backing issuances of this class, see MsgAttestData
+	Verifiers []sdk.AccAddress
+	// RequiredAttestations is the number of distinct verifiers of the class which must attest each dataset an
+	// issuance references. If it is positive, every issuance must reference at least one dataset
+	RequiredAttestations uint32
+	// IssuerBond is the minimum bond each issuer must have posted for this class to issue credits, see MsgPostBond.
+	// Classes with an empty bond don't require bonds
+	IssuerBond sdk.Coins
+	// BondUnbondingPeriod is how long bonds withdrawn by issuers stay slashable before they are returned
+	BondUnbondingPeriod time.Duration
+	// ValidityPeriod is how long after their end date credits of this class stay valid. The liquid units of expired
+	// credits can't be used anymore and are moved to the expired holdings of their holders. Credits of classes
+	// without a validity period never expire
+	ValidityPeriod time.Duration
+}
+
+// CreditAttribute is a key-value pair describing an issuance, such as the project ID or the verification report
+// the issuance is based on
+type CreditAttribute struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
 }
 
 // MsgCreateCreditClass creates a class of credits and returns a new CreditClassID
@@ -23,15 +74,70 @@ type MsgCreateCreditClass struct {
 
 type CreditClassID []byte
 
+// MsgAddCreditClassIssuer authorizes a new issuer for the credit class. It must be
+// signed by the designer of the class
+type MsgAddCreditClassIssuer struct {
+	CreditClass CreditClassID  `json:"credit_class"`
+	Designer    sdk.AccAddress `json:"designer"`
+	Issuer      sdk.AccAddress `json:"issuer"`
+}
+
+// MsgRemoveCreditClassIssuer revokes the authorization of an issuer of the credit
+// class. It must be signed by the designer of the class
+type MsgRemoveCreditClassIssuer struct {
+	CreditClass CreditClassID  `json:"credit_class"`
+	Designer    sdk.AccAddress `json:"designer"`
+	Issuer      sdk.AccAddress `json:"issuer"`
+}
+
+// MsgTransferCreditClassDesigner transfers the designer rights of the credit class
+// to NewDesigner. It must be signed by the current designer of the class
+type MsgTransferCreditClassDesigner struct {
+	CreditClass CreditClassID  `json:"credit_class"`
+	Designer    sdk.AccAddress `json:"designer"`
+	NewDesigner sdk.AccAddress `json:"new_designer"`
+}
+
+// MsgAddCreditClassVerifier registers a new verifier for the credit class. It must
+// be signed by the designer of the class
+type MsgAddCreditClassVerifier struct {
+	CreditClass CreditClassID  `json:"credit_class"`
+	Designer    sdk.AccAddress `json:"designer"`
+	Verifier    sdk.AccAddress `json:"verifier"`
+}
+
+// MsgRemoveCreditClassVerifier unregisters a verifier of the credit class. It must
+// be signed by the designer of the class
+type MsgRemoveCreditClassVerifier struct {
+	CreditClass CreditClassID  `json:"credit_class"`
+	Designer    sdk.AccAddress `json:"designer"`
+	Verifier    sdk.AccAddress `json:"verifier"`
+}
+
+// MsgDeprecateCreditClass blocks any new issuance of credits of the credit class
+// while keeping existing credits tradable. It must be signed by the designer of
+// the class and can't be undone
+type MsgDeprecateCreditClass struct {
+	CreditClass CreditClassID  `json:"credit_class"`
+	Designer    sdk.AccAddress `json:"designer"`
+}
+
 type CreditMetadata struct {
 	Issuer      sdk.AccAddress `json:"issuer"`
 	CreditClass CreditClassID  `json:"credit_class"`
-	GeoPolygon  []byte         `json:"geo_polygon"`
-	StartDate   time.Time      `json:"start_date"`
-	EndDate     time.Time      `json:"end_date"`
-	// LiquidUnits specifies how many tradeable units of this credit are issued for this polygon
+	// GeoPolygon is the canonical binary encoding of the credit's land area as produced by geo.Polygon.Marshal
+	GeoPolygon []byte    `json:"geo_polygon"`
+	StartDate  time.Time `json:"start_date"`
+	EndDate    time.Time `json:"end_date"`
+	// LiquidUnits specifies how many tradeable units of this credit are issued for this polygon. The issued units are
+	// never updated afterwards, the outstanding units are tracked by the CreditSupply of the credit
 	LiquidUnits sdk.Dec `json:"liquid_units"`
 	BurnedUnits sdk.Dec `json:"burned_units"`
+	// Attributes must include every attribute required by the credit class
+	Attributes []CreditAttribute `json:"attributes"`
+	// Datasets are the SHA-256 hashes of the anchored MRV datasets backing the issuance, each of which must be
+	// attested by as many verifiers as the credit class requires
+	Datasets [][]byte `json:"datasets"`
 }
 
 // MsgIssueCredit issues a credit to the Holder with the number of LiquidUnits provided
@@ -45,6 +151,25 @@ type MsgIssueCredit struct {
 	Holder sdk.AccAddress `json:"holder"`
 }
 
+// CreditIssuance is the part of a batch issuance of a credit received by a single holder
+type CreditIssuance struct {
+	Holder      sdk.AccAddress `json:"holder"`
+	LiquidUnits sdk.Dec        `json:"liquid_units"`
+	// RetiredUnits are issued directly into retired state, e.g. for offsets which were sold before issuance
+	RetiredUnits sdk.Dec `json:"retired_units"`
+	// RetirementInfo is recorded in the retirement certificate created for the RetiredUnits
+	RetirementInfo `json:"retirement_info"`
+}
+
+// MsgIssueCreditBatch issues a single credit distributed over many holders. The LiquidUnits and
+// BurnedUnits of the metadata are ignored and set to the totals of the issuances. It is illegal
+// to issue a credit where the provided polygon and dates overlaps with those of an existing credit
+// of the same class
+type MsgIssueCreditBatch struct {
+	CreditMetadata `json:"metadata"`
+	Issuances      []CreditIssuance `json:"issuances"`
+}
+
 type CreditID []byte
 
 // MsgSendCredit sends the provided number of units of the credit from the from
@@ -56,15 +181,259 @@ type MsgSendCredit struct {
 	Units  sdk.Dec
 }
 
+// MsgApproveCredit allows the spender to send up to Units units of the holder's credit on
+// behalf of the holder, replacing any previous allowance. If Credit is empty the allowance
+// applies to all credits of the holder. If Expiration is zero the allowance never expires
+type MsgApproveCredit struct {
+	Holder     sdk.AccAddress `json:"holder"`
+	Spender    sdk.AccAddress `json:"spender"`
+	Credit     CreditID       `json:"credit"`
+	Units      sdk.Dec        `json:"units"`
+	Expiration time.Time      `json:"expiration"`
+}
+
+// MsgRevokeCredit removes the allowance of the spender for the holder's credit, or for all
+// credits of the holder if Credit is empty
+type MsgRevokeCredit struct {
+	Holder  sdk.AccAddress `json:"holder"`
+	Spender sdk.AccAddress `json:"spender"`
+	Credit  CreditID       `json:"credit"`
+}
+
+// MsgSendCreditFrom sends units of the credit from the from address to the to address on
+// behalf of the from address, consuming the allowance of the spender
+type MsgSendCreditFrom struct {
+	Spender sdk.AccAddress `json:"spender"`
+	Credit  CreditID       `json:"credit"`
+	From    sdk.AccAddress `json:"from"`
+	To      sdk.AccAddress `json:"to"`
+	Units   sdk.Dec        `json:"units"`
+}
+
+// CreditOutput is a single transfer of units of a credit to a recipient within MsgMultiSendCredit
+type CreditOutput struct {
+	Credit CreditID       `json:"credit"`
+	To     sdk.AccAddress `json:"to"`
+	Units  sdk.Dec        `json:"units"`
+}
+
+// MsgMultiSendCredit sends units of possibly several credits from the from address to many
+// recipients. Either all outputs are sent or none is
+type MsgMultiSendCredit struct {
+	From    sdk.AccAddress `json:"from"`
+	Outputs []CreditOutput `json:"outputs"`
+}
+
 // MsgBurnCredit consumes the provided number of units of the credit, essentially
 // burning or retiring those units. This operation is used to actually use
 // the credit as an offset. Otherwise, the holder of the credit is simply
 // holding the credit as an asset but has not claimed the offset. Once a
-// credit has been consumed, it can no longer be transferred
+// credit has been consumed, it can no longer be transferred. A retirement certificate
+// is created and its RetirementID is returned
 type MsgBurnCredit struct {
 	Credit CreditID
 	Holder sdk.AccAddress
 	Units  sdk.Dec
+	// RetirementInfo is recorded in the retirement certificate created for the burned units
+	RetirementInfo `json:"retirement_info"`
+}
+
+// MsgCreateSellOrder escrows units of the seller's credit and offers them for sale
+// at Price per unit. The SellOrderID of the new order is returned
+type MsgCreateSellOrder struct {
+	Seller sdk.AccAddress `json:"seller"`
+	Credit CreditID       `json:"credit"`
+	Units  sdk.Dec        `json:"units"`
+	Price  sdk.Coins      `json:"price"`
+}
+
+// MsgUpdateSellOrder changes the units offered by a sell order and its price per unit,
+// escrowing additional units or returning units no longer offered to the seller
+type MsgUpdateSellOrder struct {
+	Seller sdk.AccAddress `json:"seller"`
+	Order  SellOrderID    `json:"order"`
+	Units  sdk.Dec        `json:"units"`
+	Price  sdk.Coins      `json:"price"`
+}
+
+// MsgCancelSellOrder closes a sell order and returns its remaining units to the seller
+type MsgCancelSellOrder struct {
+	Seller sdk.AccAddress `json:"seller"`
+	Order  SellOrderID    `json:"order"`
+}
+
+// MsgBuyCredit buys units of a sell order, paying at most MaxPrice per unit. If Retire
+// is set the bought units are retired right away with RetirementInfo and the
+// RetirementID of the retirement certificate is returned
+type MsgBuyCredit struct {
+	Buyer    sdk.AccAddress `json:"buyer"`
+	Order    SellOrderID    `json:"order"`
+	Units    sdk.Dec        `json:"units"`
+	MaxPrice sdk.Coins      `json:"max_price"`
+	Retire   bool           `json:"retire"`
+	// RetirementInfo is recorded in the retirement certificate created for the bought units if Retire is set
+	RetirementInfo `json:"retirement_info"`
+}
+
+// MsgCreateAuction escrows units of the seller's credit and auctions them off in a
+// uniform-price batch auction cleared at EndTime. The AuctionID of the new auction is returned
+type MsgCreateAuction struct {
+	Seller   sdk.AccAddress `json:"seller"`
+	Credit   CreditID       `json:"credit"`
+	Units    sdk.Dec        `json:"units"`
+	MinPrice sdk.Coin       `json:"min_price"`
+	EndTime  time.Time      `json:"end_time"`
+}
+
+// MsgPlaceBid bids for up to Units units of an auction at Price per unit, locking the
+// coins needed to pay for all of them until the auction is cleared. The AuctionBidID
+// of the bid is returned
+type MsgPlaceBid struct {
+	Bidder  sdk.AccAddress `json:"bidder"`
+	Auction AuctionID      `json:"auction"`
+	Units   sdk.Dec        `json:"units"`
+	Price   sdk.Coin       `json:"price"`
+}
+
+// MsgWrapCredit locks units of the holder's credit and mints the holder the
+// corresponding coins of the wrapped denom of the credit, see WrappedDenom
+type MsgWrapCredit struct {
+	Holder sdk.AccAddress `json:"holder"`
+	Credit CreditID       `json:"credit"`
+	Units  sdk.Dec        `json:"units"`
+}
+
+// MsgUnwrapCredit burns the holder's wrapped coins of units of the credit and
+// releases the locked units to the holder
+type MsgUnwrapCredit struct {
+	Holder sdk.AccAddress `json:"holder"`
+	Credit CreditID       `json:"credit"`
+	Units  sdk.Dec        `json:"units"`
+}
+
+// MsgTransferCredit sends units of the sender's credit over an IBC channel of the
+// ecocredit port to the receiver on the counterparty chain, see Keeper.TransferCredit
+type MsgTransferCredit struct {
+	SourceChannel string         `json:"source_channel"`
+	Credit        CreditID       `json:"credit"`
+	Units         sdk.Dec        `json:"units"`
+	Sender        sdk.AccAddress `json:"sender"`
+	Receiver      sdk.AccAddress `json:"receiver"`
+}
+
+// MsgRecvCreditPacket relays a credit packet committed by the counterparty chain
+// together with the proof of its commitment at the proof height. It can be
+// submitted by any relayer
+type MsgRecvCreditPacket struct {
+	Packet channel.Packet   `json:"packet"`
+	Proof  commitment.Proof `json:"proof"`
+	Height uint64           `json:"height"`
+	Signer sdk.AccAddress   `json:"signer"`
+}
+
+// MsgAcknowledgeCreditPacket relays the acknowledgement of a credit packet
+// written by the counterparty chain together with the proof that it was written
+type MsgAcknowledgeCreditPacket struct {
+	Packet          channel.Packet   `json:"packet"`
+	Acknowledgement []byte           `json:"acknowledgement"`
+	Proof           commitment.Proof `json:"proof"`
+	Height          uint64           `json:"height"`
+	Signer          sdk.AccAddress   `json:"signer"`
+}
+
+// MsgTimeoutCreditPacket proves that a credit packet timed out before the
+// counterparty chain received it so that its units are refunded
+type MsgTimeoutCreditPacket struct {
+	Packet           channel.Packet   `json:"packet"`
+	Proof            commitment.Proof `json:"proof"`
+	Height           uint64           `json:"height"`
+	NextSequenceRecv uint64           `json:"next_sequence_recv"`
+	Signer           sdk.AccAddress   `json:"signer"`
+}
+
+// MsgAnchorData records the SHA-256 hash of an MRV dataset together with the URI
+// it can be retrieved from and its media type, e.g. text/csv, so that issuances
+// can reference the dataset and verifiers can attest to it
+type MsgAnchorData struct {
+	Sender    sdk.AccAddress `json:"sender"`
+	Hash      []byte         `json:"hash"`
+	URI       string         `json:"uri"`
+	MediaType string         `json:"media_type"`
+}
+
+// MsgAttestData attests an anchored MRV dataset on behalf of a verifier registered
+// by the credit class, see CreditClassMetadata.RequiredAttestations
+type MsgAttestData struct {
+	Verifier    sdk.AccAddress `json:"verifier"`
+	CreditClass CreditClassID  `json:"credit_class"`
+	Hash        []byte         `json:"hash"`
+}
+
+// MsgPostBond adds coins of an issuer of the credit class to its bond for the
+// class, which must cover the IssuerBond of the class for the issuer to issue credits
+type MsgPostBond struct {
+	Issuer      sdk.AccAddress `json:"issuer"`
+	CreditClass CreditClassID  `json:"credit_class"`
+	Amount      sdk.Coins      `json:"amount"`
+}
+
+// MsgUnbondBond withdraws coins from the bond of the issuer for the credit class.
+// They are returned after the BondUnbondingPeriod of the class and can be slashed
+// until then. The BondUnbondingID of the unbonding is returned
+type MsgUnbondBond struct {
+	Issuer      sdk.AccAddress `json:"issuer"`
+	CreditClass CreditClassID  `json:"credit_class"`
+	Amount      sdk.Coins      `json:"amount"`
+}
+
+// MsgSlashBond burns Fraction of the bond of the issuer for the credit class and
+// of its pending unbondings after a fraudulent issuance of Credit. It must be
+// signed by the designer of the class
+type MsgSlashBond struct {
+	Designer    sdk.AccAddress `json:"designer"`
+	CreditClass CreditClassID  `json:"credit_class"`
+	Issuer      sdk.AccAddress `json:"issuer"`
+	Credit      CreditID       `json:"credit"`
+	Fraction    sdk.Dec        `json:"fraction"`
+	Reason      string         `json:"reason"`
+}
+
+// MsgReverseCredit cancels units of a credit whose ecosystem service was reversed,
+// first from the buffer pool of its class and then pro rata from its holders. It
+// is signed by the issuer of the credit or the designer of its class and the
+// ReversalID of the reversal record is returned, see Keeper.ReverseCredit
+type MsgReverseCredit struct {
+	Reverser sdk.AccAddress `json:"reverser"`
+	Credit   CreditID       `json:"credit"`
+	Units    sdk.Dec        `json:"units"`
+	Reason   string         `json:"reason"`
+}
+
+// MsgSplitCredit converts Units of a credit held by the Holder into units of child
+// credits, one for each of the Parts, whose canonically encoded polygons must tile
+// the polygon of the credit. The units are apportioned to the parts by area and
+// the ConversionID of the conversion record is returned, see Keeper.SplitCredit
+type MsgSplitCredit struct {
+	Holder sdk.AccAddress `json:"holder"`
+	Credit CreditID       `json:"credit"`
+	Units  sdk.Dec        `json:"units"`
+	Parts  [][]byte       `json:"parts"`
+}
+
+// CreditUnits are units of a single credit
+type CreditUnits struct {
+	Credit CreditID `json:"credit"`
+	Units  sdk.Dec  `json:"units"`
+}
+
+// MsgMergeCredits converts units of compatible credits held by the Holder into
+// units of a single merged credit whose GeoPolygon is tiled by the polygons of
+// the credits. The ConversionID of the conversion record is returned, see
+// Keeper.MergeCredits
+type MsgMergeCredits struct {
+	Holder     sdk.AccAddress `json:"holder"`
+	Credits    []CreditUnits  `json:"credits"`
+	GeoPolygon []byte         `json:"geo_polygon"`
 }
 
 func (m MsgCreateCreditClass) Route() string {
@@ -76,6 +445,86 @@ func (m MsgCreateCreditClass) Type() string {
 }
 
 func (m MsgCreateCreditClass) ValidateBasic() sdk.Error {
+	if err := m.CreditClassMetadata.validate(); err != nil {
+		return err
+	}
+	if m.Deprecated {
+		return ErrInvalidCreditClass(DefaultCodespace, "a new credit class can't be deprecated")
+	}
+	return nil
+}
+
+// validate performs the stateless checks of the credit class metadata shared by MsgCreateCreditClass and genesis
+func (m CreditClassMetadata) validate() sdk.Error {
+	if m.Designer.Empty() {
+		return sdk.ErrInvalidAddress("missing designer address")
+	}
+	if len(strings.TrimSpace(m.Name)) == 0 {
+		return ErrInvalidCreditClassName(DefaultCodespace)
+	}
+	if len(m.Issuers) == 0 {
+		return ErrInvalidIssuers(DefaultCodespace, "credit class must have at least one issuer")
+	}
+	seen := make(map[string]bool, len(m.Issuers))
+	for _, issuer := range m.Issuers {
+		if issuer.Empty() {
+			return ErrInvalidIssuers(DefaultCodespace, "issuer address can't be empty")
+		}
+		if seen[issuer.String()] {
+			return ErrInvalidIssuers(DefaultCodespace, fmt.Sprintf("duplicate issuer %s", issuer))
+		}
+		seen[issuer.String()] = true
+	}
+	if len(strings.TrimSpace(m.CreditType)) == 0 {
+		return ErrInvalidCreditClassSchema(DefaultCodespace, "credit type can't be empty")
+	}
+	if len(strings.TrimSpace(m.UnitOfMeasure)) == 0 {
+		return ErrInvalidCreditClassSchema(DefaultCodespace, "unit of measure can't be empty")
+	}
+	if len(m.MethodologyHash) != 0 && len(m.MethodologyHash) != sha256.Size {
+		return ErrInvalidCreditClassSchema(DefaultCodespace, "methodology hash must be a SHA-256 hash")
+	}
+	if len(m.MethodologyHash) != 0 && len(m.MethodologyURI) == 0 {
+		return ErrInvalidCreditClassSchema(DefaultCodespace, "methodology hash requires a methodology URI")
+	}
+	if m.Precision > sdk.Precision {
+		return ErrInvalidCreditClassSchema(DefaultCodespace, fmt.Sprintf("precision can't be more than %d", sdk.Precision))
+	}
+	if !m.BufferRate.IsNil() && (m.BufferRate.IsNegative() || m.BufferRate.GTE(sdk.OneDec())) {
+		return ErrInvalidCreditClassSchema(DefaultCodespace, "buffer rate must be at least 0 and lower than 1")
+	}
+	required := make(map[string]bool, len(m.RequiredAttributes))
+	for _, key := range m.RequiredAttributes {
+		if len(strings.TrimSpace(key)) == 0 {
+			return ErrInvalidCreditClassSchema(DefaultCodespace, "required attribute key can't be empty")
+		}
+		if required[key] {
+			return ErrInvalidCreditClassSchema(DefaultCodespace, fmt.Sprintf("duplicate required attribute %s", key))
+		}
+		required[key] = true
+	}
+	verifiers := make(map[string]bool, len(m.Verifiers))
+	for _, verifier := range m.Verifiers {
+		if verifier.Empty() {
+			return ErrInvalidVerifiers(DefaultCodespace, "verifier address can't be empty")
+		}
+		if verifiers[verifier.String()] {
+			return ErrInvalidVerifiers(DefaultCodespace, fmt.Sprintf("duplicate verifier %s", verifier))
+		}
+		verifiers[verifier.String()] = true
+	}
+	if int(m.RequiredAttestations) > len(m.Verifiers) {
+		return ErrInvalidVerifiers(DefaultCodespace, "credit class requires more attestations than it has verifiers")
+	}
+	if !m.IssuerBond.IsValid() {
+		return ErrInvalidBond(DefaultCodespace, fmt.Sprintf("invalid issuer bond %s", m.IssuerBond))
+	}
+	if m.BondUnbondingPeriod < 0 || (!m.IssuerBond.IsZero() && m.BondUnbondingPeriod == 0) {
+		return ErrInvalidBond(DefaultCodespace, "bond unbonding period must be positive for classes requiring an issuer bond")
+	}
+	if m.ValidityPeriod < 0 {
+		return ErrInvalidCreditClassSchema(DefaultCodespace, "validity period can't be negative")
+	}
 	return nil
 }
 
@@ -96,6 +545,59 @@ func (m MsgIssueCredit) Type() string {
 }
 
 func (m MsgIssueCredit) ValidateBasic() sdk.Error {
+	if m.Holder.Empty() {
+		return sdk.ErrInvalidAddress("missing holder address")
+	}
+	return m.CreditMetadata.validate()
+}
+
+// validate performs the stateless checks of the credit metadata shared by MsgIssueCredit and genesis
+func (m CreditMetadata) validate() sdk.Error {
+	if m.Issuer.Empty() {
+		return sdk.ErrInvalidAddress("missing issuer address")
+	}
+	if len(m.CreditClass) == 0 {
+		return ErrInvalidCreditClass(DefaultCodespace, "credit class can't be empty")
+	}
+	if _, err := geo.Unmarshal(m.GeoPolygon); err != nil {
+		return ErrInvalidGeoPolygon(DefaultCodespace, err)
+	}
+	if !m.EndDate.After(m.StartDate) {
+		return ErrInvalidDateRange(DefaultCodespace)
+	}
+	if m.LiquidUnits.IsNil() || m.LiquidUnits.IsNegative() {
+		return ErrInvalidUnits(DefaultCodespace, "liquid units must be non-negative")
+	}
+	burned := m.BurnedUnits
+	if burned.IsNil() {
+		burned = sdk.ZeroDec()
+	}
+	if burned.IsNegative() {
+		return ErrInvalidUnits(DefaultCodespace, "burned units must be non-negative")
+	}
+	if !m.LiquidUnits.Add(burned).IsPositive() {
+		return ErrInvalidUnits(DefaultCodespace, "at least one unit must be issued")
+	}
+	seen := make(map[string]bool, len(m.Attributes))
+	for _, attr := range m.Attributes {
+		if len(strings.TrimSpace(attr.Key)) == 0 {
+			return ErrInvalidCreditAttributes(DefaultCodespace, "attribute key can't be empty")
+		}
+		if seen[attr.Key] {
+			return ErrInvalidCreditAttributes(DefaultCodespace, fmt.Sprintf("duplicate attribute %s", attr.Key))
+		}
+		seen[attr.Key] = true
+	}
+	datasets := make(map[string]bool, len(m.Datasets))
+	for _, hash := range m.Datasets {
+		if len(hash) != sha256.Size {
+			return ErrInvalidData(DefaultCodespace, "dataset hash must be a SHA-256 hash")
+		}
+		if datasets[string(hash)] {
+			return ErrInvalidData(DefaultCodespace, fmt.Sprintf("duplicate dataset %x", hash))
+		}
+		datasets[string(hash)] = true
+	}
 	return nil
 }
 
@@ -107,6 +609,73 @@ func (m MsgIssueCredit) GetSigners() []sdk.AccAddress {
 	return []sdk.AccAddress{m.Issuer}
 }
 
+// withDefaults treats nil units as zero
+func (c CreditIssuance) withDefaults() CreditIssuance {
+	if c.LiquidUnits.IsNil() {
+		c.LiquidUnits = sdk.ZeroDec()
+	}
+	if c.RetiredUnits.IsNil() {
+		c.RetiredUnits = sdk.ZeroDec()
+	}
+	return c
+}
+
+// issuancesTotal returns the total liquid and retired units of the issuances
+func issuancesTotal(issuances []CreditIssuance) (liquid sdk.Dec, retired sdk.Dec) {
+	liquid, retired = sdk.ZeroDec(), sdk.ZeroDec()
+	for _, issuance := range issuances {
+		issuance = issuance.withDefaults()
+		liquid = liquid.Add(issuance.LiquidUnits)
+		retired = retired.Add(issuance.RetiredUnits)
+	}
+	return liquid, retired
+}
+
+func (m MsgIssueCreditBatch) Route() string {
+	return "ecocredit"
+}
+
+func (m MsgIssueCreditBatch) Type() string {
+	return "issue-credit-batch"
+}
+
+func (m MsgIssueCreditBatch) ValidateBasic() sdk.Error {
+	if len(m.Issuances) == 0 {
+		return ErrInvalidIssuances(DefaultCodespace, "at least one issuance is required")
+	}
+	seen := make(map[string]bool, len(m.Issuances))
+	for _, issuance := range m.Issuances {
+		if issuance.Holder.Empty() {
+			return sdk.ErrInvalidAddress("missing holder address")
+		}
+		if seen[issuance.Holder.String()] {
+			return ErrInvalidIssuances(DefaultCodespace, fmt.Sprintf("duplicate holder %s", issuance.Holder))
+		}
+		seen[issuance.Holder.String()] = true
+		issuance = issuance.withDefaults()
+		if issuance.LiquidUnits.IsNegative() || issuance.RetiredUnits.IsNegative() {
+			return ErrInvalidUnits(DefaultCodespace, "issued units must be non-negative")
+		}
+		if !issuance.LiquidUnits.Add(issuance.RetiredUnits).IsPositive() {
+			return ErrInvalidUnits(DefaultCodespace, fmt.Sprintf("no units issued to %s", issuance.Holder))
+		}
+		if err := issuance.RetirementInfo.validate(); err != nil {
+			return err
+		}
+	}
+	metadata := m.CreditMetadata
+	metadata.LiquidUnits, metadata.BurnedUnits = issuancesTotal(m.Issuances)
+	return metadata.validate()
+}
+
+func (m MsgIssueCreditBatch) GetSignBytes() []byte {
+	return sdk.MustSortJSON(ModuleCdc.MustMarshalJSON(m))
+}
+
+func (m MsgIssueCreditBatch) GetSigners() []sdk.AccAddress {
+	return []sdk.AccAddress{m.Issuer}
+}
+
 func (m MsgSendCredit) Route() string {
 	return "ecocredit"
 }
@@ -116,6 +685,18 @@ func (m MsgSendCredit) Type() string {
 }
 
 func (m MsgSendCredit) ValidateBasic() sdk.Error {
+	if len(m.Credit) == 0 {
+		return ErrInvalidCredit(DefaultCodespace, "credit can't be empty")
+	}
+	if m.From.Empty() {
+		return sdk.ErrInvalidAddress("missing sender address")
+	}
+	if m.To.Empty() {
+		return sdk.ErrInvalidAddress("missing recipient address")
+	}
+	if m.Units.IsNil() || !m.Units.IsPositive() {
+		return ErrInvalidUnits(DefaultCodespace, "units must be positive")
+	}
 	return nil
 }
 
@@ -127,6 +708,124 @@ func (m MsgSendCredit) GetSigners() []sdk.AccAddress {
 	return []sdk.AccAddress{m.From}
 }
 
+func (m MsgMultiSendCredit) Route() string {
+	return "ecocredit"
+}
+
+func (m MsgMultiSendCredit) Type() string {
+	return "multi-send-credit"
+}
+
+func (m MsgMultiSendCredit) ValidateBasic() sdk.Error {
+	if m.From.Empty() {
+		return sdk.ErrInvalidAddress("missing sender address")
+	}
+	if len(m.Outputs) == 0 {
+		return ErrInvalidOutputs(DefaultCodespace, "at least one output is required")
+	}
+	for _, output := range m.Outputs {
+		if len(output.Credit) == 0 {
+			return ErrInvalidCredit(DefaultCodespace, "credit can't be empty")
+		}
+		if output.To.Empty() {
+			return sdk.ErrInvalidAddress("missing recipient address")
+		}
+		if output.Units.IsNil() || !output.Units.IsPositive() {
+			return ErrInvalidUnits(DefaultCodespace, "units must be positive")
+		}
+	}
+	return nil
+}
+
+func (m MsgMultiSendCredit) GetSignBytes() []byte {
+	return sdk.MustSortJSON(ModuleCdc.MustMarshalJSON(m))
+}
+
+func (m MsgMultiSendCredit) GetSigners() []sdk.AccAddress {
+	return []sdk.AccAddress{m.From}
+}
+
+func (m MsgApproveCredit) Route() string {
+	return "ecocredit"
+}
+
+func (m MsgApproveCredit) Type() string {
+	return "approve-credit"
+}
+
+func (m MsgApproveCredit) ValidateBasic() sdk.Error {
+	if m.Holder.Empty() {
+		return sdk.ErrInvalidAddress("missing holder address")
+	}
+	if m.Spender.Empty() {
+		return sdk.ErrInvalidAddress("missing spender address")
+	}
+	if m.Holder.Equals(m.Spender) {
+		return ErrInvalidAllowance(DefaultCodespace, "holder can't approve itself")
+	}
+	if m.Units.IsNil() || !m.Units.IsPositive() {
+		return ErrInvalidUnits(DefaultCodespace, "units must be positive")
+	}
+	return nil
+}
+
+func (m MsgApproveCredit) GetSignBytes() []byte {
+	return sdk.MustSortJSON(ModuleCdc.MustMarshalJSON(m))
+}
+
+func (m MsgApproveCredit) GetSigners() []sdk.AccAddress {
+	return []sdk.AccAddress{m.Holder}
+}
+
+func (m MsgRevokeCredit) Route() string {
+	return "ecocredit"
+}
+
+func (m MsgRevokeCredit) Type() string {
+	return "revoke-credit"
+}
+
+func (m MsgRevokeCredit) ValidateBasic() sdk.Error {
+	if m.Holder.Empty() {
+		return sdk.ErrInvalidAddress("missing holder address")
+	}
+	if m.Spender.Empty() {
+		return sdk.ErrInvalidAddress("missing spender address")
+	}
+	return nil
+}
+
+func (m MsgRevokeCredit) GetSignBytes() []byte {
+	return sdk.MustSortJSON(ModuleCdc.MustMarshalJSON(m))
+}
+
+func (m MsgRevokeCredit) GetSigners() []sdk.AccAddress {
+	return []sdk.AccAddress{m.Holder}
+}
+
+func (m MsgSendCreditFrom) Route() string {
+	return "ecocredit"
+}
+
+func (m MsgSendCreditFrom) Type() string {
+	return "send-credit-from"
+}
+
+func (m MsgSendCreditFrom) ValidateBasic() sdk.Error {
+	if m.Spender.Empty() {
+		return sdk.ErrInvalidAddress("missing spender address")
+	}
+	return MsgSendCredit{Credit: m.Credit, From: m.From, To: m.To, Units: m.Units}.ValidateBasic()
+}
+
+func (m MsgSendCreditFrom) GetSignBytes() []byte {
+	return sdk.MustSortJSON(ModuleCdc.MustMarshalJSON(m))
+}
+
+func (m MsgSendCreditFrom) GetSigners() []sdk.AccAddress {
+	return []sdk.AccAddress{m.Spender}
+}
+
 func (m MsgBurnCredit) Route() string {
 	return "ecocredit"
 }
@@ -136,7 +835,16 @@ func (m MsgBurnCredit) Type() string {
 }
 
 func (m MsgBurnCredit) ValidateBasic() sdk.Error {
-	return nil
+	if len(m.Credit) == 0 {
+		return ErrInvalidCredit(DefaultCodespace, "credit can't be empty")
+	}
+	if m.Holder.Empty() {
+		return sdk.ErrInvalidAddress("missing holder address")
+	}
+	if m.Units.IsNil() || !m.Units.IsPositive() {
+		return ErrInvalidUnits(DefaultCodespace, "units must be positive")
+	}
+	return m.RetirementInfo.validate()
 }
 
 func (m MsgBurnCredit) GetSignBytes() []byte {
@@ -146,3 +854,809 @@ func (m MsgBurnCredit) GetSignBytes() []byte {
 func (m MsgBurnCredit) GetSigners() []sdk.AccAddress {
 	return []sdk.AccAddress{m.Holder}
 }
+
+func (m MsgAddCreditClassIssuer) Route() string {
+	return "ecocredit"
+}
+
+func (m MsgAddCreditClassIssuer) Type() string {
+	return "add-credit-class-issuer"
+}
+
+func (m MsgAddCreditClassIssuer) ValidateBasic() sdk.Error {
+	if len(m.CreditClass) == 0 {
+		return ErrInvalidCreditClass(DefaultCodespace, "credit class can't be empty")
+	}
+	if m.Designer.Empty() {
+		return sdk.ErrInvalidAddress("missing designer address")
+	}
+	if m.Issuer.Empty() {
+		return sdk.ErrInvalidAddress("missing issuer address")
+	}
+	return nil
+}
+
+func (m MsgAddCreditClassIssuer) GetSignBytes() []byte {
+	return sdk.MustSortJSON(ModuleCdc.MustMarshalJSON(m))
+}
+
+func (m MsgAddCreditClassIssuer) GetSigners() []sdk.AccAddress {
+	return []sdk.AccAddress{m.Designer}
+}
+
+func (m MsgRemoveCreditClassIssuer) Route() string {
+	return "ecocredit"
+}
+
+func (m MsgRemoveCreditClassIssuer) Type() string {
+	return "remove-credit-class-issuer"
+}
+
+func (m MsgRemoveCreditClassIssuer) ValidateBasic() sdk.Error {
+	if len(m.CreditClass) == 0 {
+		return ErrInvalidCreditClass(DefaultCodespace, "credit class can't be empty")
+	}
+	if m.Designer.Empty() {
+		return sdk.ErrInvalidAddress("missing designer address")
+	}
+	if m.Issuer.Empty() {
+		return sdk.ErrInvalidAddress("missing issuer address")
+	}
+	return nil
+}
+
+func (m MsgRemoveCreditClassIssuer) GetSignBytes() []byte {
+	return sdk.MustSortJSON(ModuleCdc.MustMarshalJSON(m))
+}
+
+func (m MsgRemoveCreditClassIssuer) GetSigners() []sdk.AccAddress {
+	return []sdk.AccAddress{m.Designer}
+}
+
+func (m MsgTransferCreditClassDesigner) Route() string {
+	return "ecocredit"
+}
+
+func (m MsgTransferCreditClassDesigner) Type() string {
+	return "transfer-credit-class-designer"
+}
+
+func (m MsgTransferCreditClassDesigner) ValidateBasic() sdk.Error {
+	if len(m.CreditClass) == 0 {
+		return ErrInvalidCreditClass(DefaultCodespace, "credit class can't be empty")
+	}
+	if m.Designer.Empty() {
+		return sdk.ErrInvalidAddress("missing designer address")
+	}
+	if m.NewDesigner.Empty() {
+		return sdk.ErrInvalidAddress("missing new designer address")
+	}
+	return nil
+}
+
+func (m MsgTransferCreditClassDesigner) GetSignBytes() []byte {
+	return sdk.MustSortJSON(ModuleCdc.MustMarshalJSON(m))
+}
+
+func (m MsgTransferCreditClassDesigner) GetSigners() []sdk.AccAddress {
+	return []sdk.AccAddress{m.Designer}
+}
+
+func (m MsgDeprecateCreditClass) Route() string {
+	return "ecocredit"
+}
+
+func (m MsgDeprecateCreditClass) Type() string {
+	return "deprecate-credit-class"
+}
+
+func (m MsgDeprecateCreditClass) ValidateBasic() sdk.Error {
+	if len(m.CreditClass) == 0 {
+		return ErrInvalidCreditClass(DefaultCodespace, "credit class can't be empty")
+	}
+	if m.Designer.Empty() {
+		return sdk.ErrInvalidAddress("missing designer address")
+	}
+	return nil
+}
+
+func (m MsgDeprecateCreditClass) GetSignBytes() []byte {
+	return sdk.MustSortJSON(ModuleCdc.MustMarshalJSON(m))
+}
+
+func (m MsgDeprecateCreditClass) GetSigners() []sdk.AccAddress {
+	return []sdk.AccAddress{m.Designer}
+}
+
+// validatePrice checks that a price is valid and not zero
+func validatePrice(price sdk.Coins) sdk.Error {
+	if !price.IsValid() || price.IsZero() {
+		return ErrInvalidPrice(DefaultCodespace, fmt.Sprintf("invalid price %s", price))
+	}
+	return nil
+}
+
+func (m MsgCreateSellOrder) Route() string {
+	return "ecocredit"
+}
+
+func (m MsgCreateSellOrder) Type() string {
+	return "create-sell-order"
+}
+
+func (m MsgCreateSellOrder) ValidateBasic() sdk.Error {
+	if m.Seller.Empty() {
+		return sdk.ErrInvalidAddress("missing seller address")
+	}
+	if len(m.Credit) == 0 {
+		return ErrInvalidCredit(DefaultCodespace, "credit can't be empty")
+	}
+	if m.Units.IsNil() || !m.Units.IsPositive() {
+		return ErrInvalidUnits(DefaultCodespace, "units must be positive")
+	}
+	return validatePrice(m.Price)
+}
+
+func (m MsgCreateSellOrder) GetSignBytes() []byte {
+	return sdk.MustSortJSON(ModuleCdc.MustMarshalJSON(m))
+}
+
+func (m MsgCreateSellOrder) GetSigners() []sdk.AccAddress {
+	return []sdk.AccAddress{m.Seller}
+}
+
+func (m MsgUpdateSellOrder) Route() string {
+	return "ecocredit"
+}
+
+func (m MsgUpdateSellOrder) Type() string {
+	return "update-sell-order"
+}
+
+func (m MsgUpdateSellOrder) ValidateBasic() sdk.Error {
+	if m.Seller.Empty() {
+		return sdk.ErrInvalidAddress("missing seller address")
+	}
+	if len(m.Order) == 0 {
+		return ErrInvalidSellOrder(DefaultCodespace, "sell order can't be empty")
+	}
+	if m.Units.IsNil() || !m.Units.IsPositive() {
+		return ErrInvalidUnits(DefaultCodespace, "units must be positive")
+	}
+	return validatePrice(m.Price)
+}
+
+func (m MsgUpdateSellOrder) GetSignBytes() []byte {
+	return sdk.MustSortJSON(ModuleCdc.MustMarshalJSON(m))
+}
+
+func (m MsgUpdateSellOrder) GetSigners() []sdk.AccAddress {
+	return []sdk.AccAddress{m.Seller}
+}
+
+func (m MsgCancelSellOrder) Route() string {
+	return "ecocredit"
+}
+
+func (m MsgCancelSellOrder) Type() string {
+	return "cancel-sell-order"
+}
+
+func (m MsgCancelSellOrder) ValidateBasic() sdk.Error {
+	if m.Seller.Empty() {
+		return sdk.ErrInvalidAddress("missing seller address")
+	}
+	if len(m.Order) == 0 {
+		return ErrInvalidSellOrder(DefaultCodespace, "sell order can't be empty")
+	}
+	return nil
+}
+
+func (m MsgCancelSellOrder) GetSignBytes() []byte {
+	return sdk.MustSortJSON(ModuleCdc.MustMarshalJSON(m))
+}
+
+func (m MsgCancelSellOrder) GetSigners() []sdk.AccAddress {
+	return []sdk.AccAddress{m.Seller}
+}
+
+func (m MsgBuyCredit) Route() string {
+	return "ecocredit"
+}
+
+func (m MsgBuyCredit) Type() string {
+	return "buy-credit"
+}
+
+func (m MsgBuyCredit) ValidateBasic() sdk.Error {
+	if m.Buyer.Empty() {
+		return sdk.ErrInvalidAddress("missing buyer address")
+	}
+	if len(m.Order) == 0 {
+		return ErrInvalidSellOrder(DefaultCodespace, "sell order can't be empty")
+	}
+	if m.Units.IsNil() || !m.Units.IsPositive() {
+		return ErrInvalidUnits(DefaultCodespace, "units must be positive")
+	}
+	if err := validatePrice(m.MaxPrice); err != nil {
+		return err
+	}
+	if m.Retire {
+		return m.RetirementInfo.validate()
+	}
+	return nil
+}
+
+func (m MsgBuyCredit) GetSignBytes() []byte {
+	return sdk.MustSortJSON(ModuleCdc.MustMarshalJSON(m))
+}
+
+func (m MsgBuyCredit) GetSigners() []sdk.AccAddress {
+	return []sdk.AccAddress{m.Buyer}
+}
+
+// validateCoinPrice checks that a single coin price is valid and not zero
+func validateCoinPrice(price sdk.Coin) sdk.Error {
+	if price.Amount == (sdk.Int{}) || !price.IsValid() || price.IsZero() {
+		return ErrInvalidPrice(DefaultCodespace, fmt.Sprintf("invalid price %s", price))
+	}
+	return nil
+}
+
+func (m MsgCreateAuction) Route() string {
+	return "ecocredit"
+}
+
+func (m MsgCreateAuction) Type() string {
+	return "create-auction"
+}
+
+func (m MsgCreateAuction) ValidateBasic() sdk.Error {
+	if m.Seller.Empty() {
+		return sdk.ErrInvalidAddress("missing seller address")
+	}
+	if len(m.Credit) == 0 {
+		return ErrInvalidCredit(DefaultCodespace, "credit can't be empty")
+	}
+	if m.Units.IsNil() || !m.Units.IsPositive() {
+		return ErrInvalidUnits(DefaultCodespace, "units must be positive")
+	}
+	if m.EndTime.IsZero() {
+		return ErrInvalidAuction(DefaultCodespace, "end time can't be empty")
+	}
+	return validateCoinPrice(m.MinPrice)
+}
+
+func (m MsgCreateAuction) GetSignBytes() []byte {
+	return sdk.MustSortJSON(ModuleCdc.MustMarshalJSON(m))
+}
+
+func (m MsgCreateAuction) GetSigners() []sdk.AccAddress {
+	return []sdk.AccAddress{m.Seller}
+}
+
+func (m MsgPlaceBid) Route() string {
+	return "ecocredit"
+}
+
+func (m MsgPlaceBid) Type() string {
+	return "place-bid"
+}
+
+func (m MsgPlaceBid) ValidateBasic() sdk.Error {
+	if m.Bidder.Empty() {
+		return sdk.ErrInvalidAddress("missing bidder address")
+	}
+	if len(m.Auction) == 0 {
+		return ErrInvalidAuction(DefaultCodespace, "auction can't be empty")
+	}
+	if m.Units.IsNil() || !m.Units.IsPositive() {
+		return ErrInvalidUnits(DefaultCodespace, "units must be positive")
+	}
+	return validateCoinPrice(m.Price)
+}
+
+func (m MsgPlaceBid) GetSignBytes() []byte {
+	return sdk.MustSortJSON(ModuleCdc.MustMarshalJSON(m))
+}
+
+func (m MsgPlaceBid) GetSigners() []sdk.AccAddress {
+	return []sdk.AccAddress{m.Bidder}
+}
+
+func (m MsgWrapCredit) Route() string {
+	return "ecocredit"
+}
+
+func (m MsgWrapCredit) Type() string {
+	return "wrap-credit"
+}
+
+func (m MsgWrapCredit) ValidateBasic() sdk.Error {
+	if m.Holder.Empty() {
+		return sdk.ErrInvalidAddress("missing holder address")
+	}
+	if len(m.Credit) == 0 {
+		return ErrInvalidCredit(DefaultCodespace, "credit can't be empty")
+	}
+	if m.Units.IsNil() || !m.Units.IsPositive() {
+		return ErrInvalidUnits(DefaultCodespace, "units must be positive")
+	}
+	return nil
+}
+
+func (m MsgWrapCredit) GetSignBytes() []byte {
+	return sdk.MustSortJSON(ModuleCdc.MustMarshalJSON(m))
+}
+
+func (m MsgWrapCredit) GetSigners() []sdk.AccAddress {
+	return []sdk.AccAddress{m.Holder}
+}
+
+func (m MsgUnwrapCredit) Route() string {
+	return "ecocredit"
+}
+
+func (m MsgUnwrapCredit) Type() string {
+	return "unwrap-credit"
+}
+
+func (m MsgUnwrapCredit) ValidateBasic() sdk.Error {
+	if m.Holder.Empty() {
+		return sdk.ErrInvalidAddress("missing holder address")
+	}
+	if len(m.Credit) == 0 {
+		return ErrInvalidCredit(DefaultCodespace, "credit can't be empty")
+	}
+	if m.Units.IsNil() || !m.Units.IsPositive() {
+		return ErrInvalidUnits(DefaultCodespace, "units must be positive")
+	}
+	return nil
+}
+
+func (m MsgUnwrapCredit) GetSignBytes() []byte {
+	return sdk.MustSortJSON(ModuleCdc.MustMarshalJSON(m))
+}
+
+func (m MsgUnwrapCredit) GetSigners() []sdk.AccAddress {
+	return []sdk.AccAddress{m.Holder}
+}
+
+func (m MsgTransferCredit) Route() string {
+	return "ecocredit"
+}
+
+func (m MsgTransferCredit) Type() string {
+	return "transfer-credit"
+}
+
+func (m MsgTransferCredit) ValidateBasic() sdk.Error {
+	if err := host.DefaultChannelIdentifierValidator(m.SourceChannel); err != nil {
+		return ErrInvalidCreditPacket(DefaultCodespace, fmt.Sprintf("invalid channel ID: %s", err))
+	}
+	if len(m.Credit) == 0 {
+		return ErrInvalidCredit(DefaultCodespace, "credit can't be empty")
+	}
+	if m.Units.IsNil() || !m.Units.IsPositive() {
+		return ErrInvalidUnits(DefaultCodespace, "units must be positive")
+	}
+	if m.Sender.Empty() {
+		return sdk.ErrInvalidAddress("missing sender address")
+	}
+	if m.Receiver.Empty() {
+		return sdk.ErrInvalidAddress("missing receiver address")
+	}
+	return nil
+}
+
+func (m MsgTransferCredit) GetSignBytes() []byte {
+	return sdk.MustSortJSON(ModuleCdc.MustMarshalJSON(m))
+}
+
+func (m MsgTransferCredit) GetSigners() []sdk.AccAddress {
+	return []sdk.AccAddress{m.Sender}
+}
+
+// validateRelay checks the fields shared by the messages relaying credit packets
+func validateRelay(packet channel.Packet, proof commitment.Proof, height uint64, signer sdk.AccAddress) sdk.Error {
+	if height < 1 {
+		return ErrInvalidCreditPacket(DefaultCodespace, "invalid proof height")
+	}
+	if proof.Proof == nil {
+		return ErrInvalidCreditPacket(DefaultCodespace, "missing proof")
+	}
+	if signer.Empty() {
+		return sdk.ErrInvalidAddress("missing signer address")
+	}
+	return packet.ValidateBasic()
+}
+
+func (m MsgRecvCreditPacket) Route() string {
+	return "ecocredit"
+}
+
+func (m MsgRecvCreditPacket) Type() string {
+	return "recv-credit-packet"
+}
+
+func (m MsgRecvCreditPacket) ValidateBasic() sdk.Error {
+	return validateRelay(m.Packet, m.Proof, m.Height, m.Signer)
+}
+
+func (m MsgRecvCreditPacket) GetSignBytes() []byte {
+	return sdk.MustSortJSON(ModuleCdc.MustMarshalJSON(m))
+}
+
+func (m MsgRecvCreditPacket) GetSigners() []sdk.AccAddress {
+	return []sdk.AccAddress{m.Signer}
+}
+
+func (m MsgAcknowledgeCreditPacket) Route() string {
+	return "ecocredit"
+}
+
+func (m MsgAcknowledgeCreditPacket) Type() string {
+	return "acknowledge-credit-packet"
+}
+
+func (m MsgAcknowledgeCreditPacket) ValidateBasic() sdk.Error {
+	if len(m.Acknowledgement) == 0 {
+		return ErrInvalidCreditPacket(DefaultCodespace, "missing acknowledgement")
+	}
+	return validateRelay(m.Packet, m.Proof, m.Height, m.Signer)
+}
+
+func (m MsgAcknowledgeCreditPacket) GetSignBytes() []byte {
+	return sdk.MustSortJSON(ModuleCdc.MustMarshalJSON(m))
+}
+
+func (m MsgAcknowledgeCreditPacket) GetSigners() []sdk.AccAddress {
+	return []sdk.AccAddress{m.Signer}
+}
+
+func (m MsgTimeoutCreditPacket) Route() string {
+	return "ecocredit"
+}
+
+func (m MsgTimeoutCreditPacket) Type() string {
+	return "timeout-credit-packet"
+}
+
+func (m MsgTimeoutCreditPacket) ValidateBasic() sdk.Error {
+	return validateRelay(m.Packet, m.Proof, m.Height, m.Signer)
+}
+
+func (m MsgTimeoutCreditPacket) GetSignBytes() []byte {
+	return sdk.MustSortJSON(ModuleCdc.MustMarshalJSON(m))
+}
+
+func (m MsgTimeoutCreditPacket) GetSigners() []sdk.AccAddress {
+	return []sdk.AccAddress{m.Signer}
+}
+
+func (m MsgReverseCredit) Route() string {
+	return "ecocredit"
+}
+
+func (m MsgReverseCredit) Type() string {
+	return "reverse-credit"
+}
+
+func (m MsgReverseCredit) ValidateBasic() sdk.Error {
+	if m.Reverser.Empty() {
+		return sdk.ErrInvalidAddress("missing reverser address")
+	}
+	if len(m.Credit) == 0 {
+		return ErrInvalidCredit(DefaultCodespace, "credit can't be empty")
+	}
+	if m.Units.IsNil() || !m.Units.IsPositive() {
+		return ErrInvalidUnits(DefaultCodespace, "units must be positive")
+	}
+	if len(strings.TrimSpace(m.Reason)) == 0 || len(m.Reason) > MaxReversalReasonLength {
+		return ErrInvalidReversal(DefaultCodespace, fmt.Sprintf("reason must be non-empty and at most %d characters", MaxReversalReasonLength))
+	}
+	return nil
+}
+
+func (m MsgReverseCredit) GetSignBytes() []byte {
+	return sdk.MustSortJSON(ModuleCdc.MustMarshalJSON(m))
+}
+
+func (m MsgReverseCredit) GetSigners() []sdk.AccAddress {
+	return []sdk.AccAddress{m.Reverser}
+}
+
+func (m MsgAddCreditClassVerifier) Route() string {
+	return "ecocredit"
+}
+
+func (m MsgAddCreditClassVerifier) Type() string {
+	return "add-credit-class-verifier"
+}
+
+func (m MsgAddCreditClassVerifier) ValidateBasic() sdk.Error {
+	if len(m.CreditClass) == 0 {
+		return ErrInvalidCreditClass(DefaultCodespace, "credit class can't be empty")
+	}
+	if m.Designer.Empty() {
+		return sdk.ErrInvalidAddress("missing designer address")
+	}
+	if m.Verifier.Empty() {
+		return sdk.ErrInvalidAddress("missing verifier address")
+	}
+	return nil
+}
+
+func (m MsgAddCreditClassVerifier) GetSignBytes() []byte {
+	return sdk.MustSortJSON(ModuleCdc.MustMarshalJSON(m))
+}
+
+func (m MsgAddCreditClassVerifier) GetSigners() []sdk.AccAddress {
+	return []sdk.AccAddress{m.Designer}
+}
+
+func (m MsgRemoveCreditClassVerifier) Route() string {
+	return "ecocredit"
+}
+
+func (m MsgRemoveCreditClassVerifier) Type() string {
+	return "remove-credit-class-verifier"
+}
+
+func (m MsgRemoveCreditClassVerifier) ValidateBasic() sdk.Error {
+	if len(m.CreditClass) == 0 {
+		return ErrInvalidCreditClass(DefaultCodespace, "credit class can't be empty")
+	}
+	if m.Designer.Empty() {
+		return sdk.ErrInvalidAddress("missing designer address")
+	}
+	if m.Verifier.Empty() {
+		return sdk.ErrInvalidAddress("missing verifier address")
+	}
+	return nil
+}
+
+func (m MsgRemoveCreditClassVerifier) GetSignBytes() []byte {
+	return sdk.MustSortJSON(ModuleCdc.MustMarshalJSON(m))
+}
+
+func (m MsgRemoveCreditClassVerifier) GetSigners() []sdk.AccAddress {
+	return []sdk.AccAddress{m.Designer}
+}
+
+func (m MsgAnchorData) Route() string {
+	return "ecocredit"
+}
+
+func (m MsgAnchorData) Type() string {
+	return "anchor-data"
+}
+
+func (m MsgAnchorData) ValidateBasic() sdk.Error {
+	if m.Sender.Empty() {
+		return sdk.ErrInvalidAddress("missing sender address")
+	}
+	if len(m.Hash) != sha256.Size {
+		return ErrInvalidData(DefaultCodespace, "dataset hash must be a SHA-256 hash")
+	}
+	if len(strings.TrimSpace(m.URI)) == 0 || len(m.URI) > MaxDataURILength {
+		return ErrInvalidData(DefaultCodespace, fmt.Sprintf("URI must be non-empty and at most %d characters", MaxDataURILength))
+	}
+	if _, _, err := mime.ParseMediaType(m.MediaType); err != nil {
+		return ErrInvalidData(DefaultCodespace, fmt.Sprintf("invalid media type %q", m.MediaType))
+	}
+	return nil
+}
+
+func (m MsgAnchorData) GetSignBytes() []byte {
+	return sdk.MustSortJSON(ModuleCdc.MustMarshalJSON(m))
+}
+
+func (m MsgAnchorData) GetSigners() []sdk.AccAddress {
+	return []sdk.AccAddress{m.Sender}
+}
+
+func (m MsgAttestData) Route() string {
+	return "ecocredit"
+}
+
+func (m MsgAttestData) Type() string {
+	return "attest-data"
+}
+
+func (m MsgAttestData) ValidateBasic() sdk.Error {
+	if m.Verifier.Empty() {
+		return sdk.ErrInvalidAddress("missing verifier address")
+	}
+	if len(m.CreditClass) == 0 {
+		return ErrInvalidCreditClass(DefaultCodespace, "credit class can't be empty")
+	}
+	if len(m.Hash) != sha256.Size {
+		return ErrInvalidData(DefaultCodespace, "dataset hash must be a SHA-256 hash")
+	}
+	return nil
+}
+
+func (m MsgAttestData) GetSignBytes() []byte {
+	return sdk.MustSortJSON(ModuleCdc.MustMarshalJSON(m))
+}
+
+func (m MsgAttestData) GetSigners() []sdk.AccAddress {
+	return []sdk.AccAddress{m.Verifier}
+}
+
+func (m MsgPostBond) Route() string {
+	return "ecocredit"
+}
+
+func (m MsgPostBond) Type() string {
+	return "post-bond"
+}
+
+func (m MsgPostBond) ValidateBasic() sdk.Error {
+	return validateBondAmount(m.Issuer, m.CreditClass, m.Amount)
+}
+
+// validateBondAmount performs the stateless checks shared by MsgPostBond and MsgUnbondBond
+func validateBondAmount(issuer sdk.AccAddress, class CreditClassID, amount sdk.Coins) sdk.Error {
+	if issuer.Empty() {
+		return sdk.ErrInvalidAddress("missing issuer address")
+	}
+	if len(class) == 0 {
+		return ErrInvalidCreditClass(DefaultCodespace, "credit class can't be empty")
+	}
+	if !amount.IsValid() || amount.IsZero() {
+		return ErrInvalidBond(DefaultCodespace, fmt.Sprintf("invalid bond amount %s", amount))
+	}
+	return nil
+}
+
+func (m MsgPostBond) GetSignBytes() []byte {
+	return sdk.MustSortJSON(ModuleCdc.MustMarshalJSON(m))
+}
+
+func (m MsgPostBond) GetSigners() []sdk.AccAddress {
+	return []sdk.AccAddress{m.Issuer}
+}
+
+func (m MsgUnbondBond) Route() string {
+	return "ecocredit"
+}
+
+func (m MsgUnbondBond) Type() string {
+	return "unbond-bond"
+}
+
+func (m MsgUnbondBond) ValidateBasic() sdk.Error {
+	return validateBondAmount(m.Issuer, m.CreditClass, m.Amount)
+}
+
+func (m MsgUnbondBond) GetSignBytes() []byte {
+	return sdk.MustSortJSON(ModuleCdc.MustMarshalJSON(m))
+}
+
+func (m MsgUnbondBond) GetSigners() []sdk.AccAddress {
+	return []sdk.AccAddress{m.Issuer}
+}
+
+func (m MsgSlashBond) Route() string {
+	return "ecocredit"
+}
+
+func (m MsgSlashBond) Type() string {
+	return "slash-bond"
+}
+
+func (m MsgSlashBond) ValidateBasic() sdk.Error {
+	if m.Designer.Empty() {
+		return sdk.ErrInvalidAddress("missing designer address")
+	}
+	if len(strings.TrimSpace(m.Reason)) == 0 || len(m.Reason) > MaxSlashReasonLength {
+		return ErrInvalidBond(DefaultCodespace, fmt.Sprintf("reason must be non-empty and at most %d characters", MaxSlashReasonLength))
+	}
+	return validateSlash(m.CreditClass, m.Issuer, m.Credit, m.Fraction)
+}
+
+// validateSlash performs the stateless checks shared by MsgSlashBond and SlashBondProposal
+func validateSlash(class CreditClassID, issuer sdk.AccAddress, credit CreditID, fraction sdk.Dec) sdk.Error {
+	if len(class) == 0 {
+		return ErrInvalidCreditClass(DefaultCodespace, "credit class can't be empty")
+	}
+	if issuer.Empty() {
+		return sdk.ErrInvalidAddress("missing issuer address")
+	}
+	if len(credit) == 0 {
+		return ErrInvalidCredit(DefaultCodespace, "credit can't be empty")
+	}
+	if fraction.IsNil() || !fraction.IsPositive() || fraction.GT(sdk.OneDec()) {
+		return ErrInvalidBond(DefaultCodespace, "slash fraction must be positive and at most 1")
+	}
+	return nil
+}
+
+func (m MsgSlashBond) GetSignBytes() []byte {
+	return sdk.MustSortJSON(ModuleCdc.MustMarshalJSON(m))
+}
+
+func (m MsgSlashBond) GetSigners() []sdk.AccAddress {
+	return []sdk.AccAddress{m.Designer}
+}
+
+func (m MsgSplitCredit) Route() string {
+	return "ecocredit"
+}
+
+func (m MsgSplitCredit) Type() string {
+	return "split-credit"
+}
+
+func (m MsgSplitCredit) ValidateBasic() sdk.Error {
+	if m.Holder.Empty() {
+		return sdk.ErrInvalidAddress("missing holder address")
+	}
+	if len(m.Credit) == 0 {
+		return ErrInvalidCredit(DefaultCodespace, "credit can't be empty")
+	}
+	if m.Units.IsNil() || !m.Units.IsPositive() {
+		return ErrInvalidUnits(DefaultCodespace, "units must be positive")
+	}
+	if len(m.Parts) < 2 || len(m.Parts) > MaxConversionParts {
+		return ErrInvalidTiling(DefaultCodespace, fmt.Sprintf("a credit must be split into 2 to %d parts", MaxConversionParts))
+	}
+	for _, part := range m.Parts {
+		if _, err := geo.Unmarshal(part); err != nil {
+			return ErrInvalidGeoPolygon(DefaultCodespace, err)
+		}
+	}
+	return nil
+}
+
+func (m MsgSplitCredit) GetSignBytes() []byte {
+	return sdk.MustSortJSON(ModuleCdc.MustMarshalJSON(m))
+}
+
+func (m MsgSplitCredit) GetSigners() []sdk.AccAddress {
+	return []sdk.AccAddress{m.Holder}
+}
+
+func (m MsgMergeCredits) Route() string {
+	return "ecocredit"
+}
+
+func (m MsgMergeCredits) Type() string {
+	return "merge-credits"
+}
+
+func (m MsgMergeCredits) ValidateBasic() sdk.Error {
+	if m.Holder.Empty() {
+		return sdk.ErrInvalidAddress("missing holder address")
+	}
+	if len(m.Credits) < 2 || len(m.Credits) > MaxConversionParts {
+		return ErrIncompatibleCredits(DefaultCodespace, fmt.Sprintf("2 to %d credits must be merged", MaxConversionParts))
+	}
+	seen := make(map[string]bool, len(m.Credits))
+	for _, credit := range m.Credits {
+		if len(credit.Credit) == 0 {
+			return ErrInvalidCredit(DefaultCodespace, "credit can't be empty")
+		}
+		if seen[string(credit.Credit)] {
+			return ErrIncompatibleCredits(DefaultCodespace, fmt.Sprintf("duplicate credit %s", credit.Credit))
+		}
+		seen[string(credit.Credit)] = true
+		if credit.Units.IsNil() || !credit.Units.IsPositive() {
+			return ErrInvalidUnits(DefaultCodespace, "units must be positive")
+		}
+	}
+	if _, err := geo.Unmarshal(m.GeoPolygon); err != nil {
+		return ErrInvalidGeoPolygon(DefaultCodespace, err)
+	}
+	return nil
+}
+
+func (m MsgMergeCredits) GetSignBytes() []byte {
+	return sdk.MustSortJSON(ModuleCdc.MustMarshalJSON(m))
+}
+
+func (m MsgMergeCredits) GetSigners() []sdk.AccAddress {
+	return []sdk.AccAddress{m.Holder}
+}
@@ -0,0 +1,639 @@
+package ecocredit
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	channel "github.com/cosmos/cosmos-sdk/x/ibc/04-channel"
+	"github.com/cosmos/gaia/geo"
+)
+
+var (
+	addr1 = sdk.AccAddress([]byte("addr1_______________"))
+	addr2 = sdk.AccAddress([]byte("addr2_______________"))
+
+	startDate = time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC)
+	endDate   = time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+)
+
+func mustGeoPolygon(wkt string) []byte {
+	polygon, err := geo.ParseWKT(wkt)
+	if err != nil {
+		panic(err)
+	}
+	bz, err := polygon.Marshal()
+	if err != nil {
+		panic(err)
+	}
+	return bz
+}
+
+func TestMsgValidateBasic(t *testing.T) {
+	polygon := mustGeoPolygon("POLYGON ((0 0, 1 0, 1 1, 0 1, 0 0))")
+	validMetadata := func() CreditMetadata {
+		return CreditMetadata{
+			Issuer:      addr1,
+			CreditClass: CreditClassID{1},
+			GeoPolygon:  polygon,
+			StartDate:   startDate,
+			EndDate:     endDate,
+			LiquidUnits: sdk.NewDec(10),
+			BurnedUnits: sdk.ZeroDec(),
+		}
+	}
+	createClass := func(modify func(m *CreditClassMetadata)) MsgCreateCreditClass {
+		metadata := CreditClassMetadata{Designer: addr1, Name: "carbon", Issuers: []sdk.AccAddress{addr2},
+			CreditType: "carbon", UnitOfMeasure: "tCO2e"}
+		modify(&metadata)
+		return MsgCreateCreditClass{metadata}
+	}
+	issue := func(modify func(m *MsgIssueCredit)) MsgIssueCredit {
+		msg := MsgIssueCredit{CreditMetadata: validMetadata(), Holder: addr2}
+		modify(&msg)
+		return msg
+	}
+
+	batch := func(modify func(m *MsgIssueCreditBatch)) MsgIssueCreditBatch {
+		metadata := validMetadata()
+		metadata.LiquidUnits, metadata.BurnedUnits = sdk.Dec{}, sdk.Dec{}
+		msg := MsgIssueCreditBatch{CreditMetadata: metadata, Issuances: []CreditIssuance{
+			{Holder: addr1, LiquidUnits: sdk.NewDec(10)},
+			{Holder: addr2, LiquidUnits: sdk.ZeroDec(), RetiredUnits: sdk.NewDec(5), RetirementInfo: RetirementInfo{Beneficiary: "ACME Corp"}},
+		}}
+		modify(&msg)
+		return msg
+	}
+
+	cases := map[string]struct {
+		msg  sdk.Msg
+		code sdk.CodeType
+	}{
+		"create class": {
+			msg: createClass(func(m *CreditClassMetadata) {}),
+		},
+		"create class with schema": {
+			msg: createClass(func(m *CreditClassMetadata) {
+				m.MethodologyURI = "https://example.org/methodology.pdf"
+				m.MethodologyHash = make([]byte, 32)
+				m.Precision = sdk.Precision
+				m.RequiredAttributes = []string{"project", "verification-report"}
+			}),
+		},
+		"create class without designer": {
+			msg:  createClass(func(m *CreditClassMetadata) { m.Designer = nil }),
+			code: sdk.CodeInvalidAddress,
+		},
+		"create class with blank name": {
+			msg:  createClass(func(m *CreditClassMetadata) { m.Name = " " }),
+			code: CodeInvalidCreditClassName,
+		},
+		"create class without issuers": {
+			msg:  createClass(func(m *CreditClassMetadata) { m.Issuers = nil }),
+			code: CodeInvalidIssuers,
+		},
+		"create class with empty issuer": {
+			msg:  createClass(func(m *CreditClassMetadata) { m.Issuers = []sdk.AccAddress{addr2, nil} }),
+			code: CodeInvalidIssuers,
+		},
+		"create class with duplicate issuer": {
+			msg:  createClass(func(m *CreditClassMetadata) { m.Issuers = []sdk.AccAddress{addr2, addr2} }),
+			code: CodeInvalidIssuers,
+		},
+		"create deprecated class": {
+			msg:  createClass(func(m *CreditClassMetadata) { m.Deprecated = true }),
+			code: CodeInvalidCreditClass,
+		},
+		"create class without credit type": {
+			msg:  createClass(func(m *CreditClassMetadata) { m.CreditType = "" }),
+			code: CodeInvalidCreditClassSchema,
+		},
+		"create class without unit of measure": {
+			msg:  createClass(func(m *CreditClassMetadata) { m.UnitOfMeasure = " " }),
+			code: CodeInvalidCreditClassSchema,
+		},
+		"create class with short methodology hash": {
+			msg: createClass(func(m *CreditClassMetadata) {
+				m.MethodologyURI = "https://example.org/methodology.pdf"
+				m.MethodologyHash = make([]byte, 20)
+			}),
+			code: CodeInvalidCreditClassSchema,
+		},
+		"create class with methodology hash but no URI": {
+			msg:  createClass(func(m *CreditClassMetadata) { m.MethodologyHash = make([]byte, 32) }),
+			code: CodeInvalidCreditClassSchema,
+		},
+		"create class with too much precision": {
+			msg:  createClass(func(m *CreditClassMetadata) { m.Precision = sdk.Precision + 1 }),
+			code: CodeInvalidCreditClassSchema,
+		},
+		"create class with buffer rate of 1": {
+			msg:  createClass(func(m *CreditClassMetadata) { m.BufferRate = sdk.OneDec() }),
+			code: CodeInvalidCreditClassSchema,
+		},
+		"create class requiring more attestations than verifiers": {
+			msg:  createClass(func(m *CreditClassMetadata) { m.RequiredAttestations = 1 }),
+			code: CodeInvalidVerifiers,
+		},
+		"create class requiring a bond without unbonding period": {
+			msg:  createClass(func(m *CreditClassMetadata) { m.IssuerBond = sdk.NewCoins(sdk.NewInt64Coin("uatom", 10)) }),
+			code: CodeInvalidBond,
+		},
+		"create class with negative validity period": {
+			msg:  createClass(func(m *CreditClassMetadata) { m.ValidityPeriod = -time.Hour }),
+			code: CodeInvalidCreditClassSchema,
+		},
+		"create class with empty required attribute": {
+			msg:  createClass(func(m *CreditClassMetadata) { m.RequiredAttributes = []string{""} }),
+			code: CodeInvalidCreditClassSchema,
+		},
+		"create class with duplicate required attribute": {
+			msg:  createClass(func(m *CreditClassMetadata) { m.RequiredAttributes = []string{"project", "project"} }),
+			code: CodeInvalidCreditClassSchema,
+		},
+		"issue": {
+			msg: issue(func(m *MsgIssueCredit) {}),
+		},
+		"issue with attributes": {
+			msg: issue(func(m *MsgIssueCredit) { m.Attributes = []CreditAttribute{{"project", "p1"}, {"vcs-id", "1234"}} }),
+		},
+		"issue with empty attribute key": {
+			msg:  issue(func(m *MsgIssueCredit) { m.Attributes = []CreditAttribute{{"", "p1"}} }),
+			code: CodeInvalidCreditAttributes,
+		},
+		"issue with duplicate attribute": {
+			msg:  issue(func(m *MsgIssueCredit) { m.Attributes = []CreditAttribute{{"project", "p1"}, {"project", "p2"}} }),
+			code: CodeInvalidCreditAttributes,
+		},
+		"issue with nil burned units": {
+			msg: issue(func(m *MsgIssueCredit) { m.BurnedUnits = sdk.Dec{} }),
+		},
+		"issue without issuer": {
+			msg:  issue(func(m *MsgIssueCredit) { m.Issuer = nil }),
+			code: sdk.CodeInvalidAddress,
+		},
+		"issue without holder": {
+			msg:  issue(func(m *MsgIssueCredit) { m.Holder = nil }),
+			code: sdk.CodeInvalidAddress,
+		},
+		"issue without credit class": {
+			msg:  issue(func(m *MsgIssueCredit) { m.CreditClass = nil }),
+			code: CodeInvalidCreditClass,
+		},
+		"issue with raw polygon": {
+			msg:  issue(func(m *MsgIssueCredit) { m.GeoPolygon = []byte("POLYGON ((0 0, 1 0, 1 1, 0 1, 0 0))") }),
+			code: CodeInvalidGeoPolygon,
+		},
+		"issue with end date before start date": {
+			msg:  issue(func(m *MsgIssueCredit) { m.StartDate, m.EndDate = endDate, startDate }),
+			code: CodeInvalidDateRange,
+		},
+		"issue with equal dates": {
+			msg:  issue(func(m *MsgIssueCredit) { m.EndDate = m.StartDate }),
+			code: CodeInvalidDateRange,
+		},
+		"issue with nil liquid units": {
+			msg:  issue(func(m *MsgIssueCredit) { m.LiquidUnits = sdk.Dec{} }),
+			code: CodeInvalidUnits,
+		},
+		"issue with negative liquid units": {
+			msg:  issue(func(m *MsgIssueCredit) { m.LiquidUnits = sdk.NewDec(-1) }),
+			code: CodeInvalidUnits,
+		},
+		"issue with negative burned units": {
+			msg:  issue(func(m *MsgIssueCredit) { m.BurnedUnits = sdk.NewDec(-1) }),
+			code: CodeInvalidUnits,
+		},
+		"issue with zero units": {
+			msg:  issue(func(m *MsgIssueCredit) { m.LiquidUnits = sdk.ZeroDec() }),
+			code: CodeInvalidUnits,
+		},
+		"issue batch": {
+			msg: batch(func(m *MsgIssueCreditBatch) {}),
+		},
+		"issue batch without issuances": {
+			msg:  batch(func(m *MsgIssueCreditBatch) { m.Issuances = nil }),
+			code: CodeInvalidIssuances,
+		},
+		"issue batch with duplicate holder": {
+			msg:  batch(func(m *MsgIssueCreditBatch) { m.Issuances[1].Holder = addr1 }),
+			code: CodeInvalidIssuances,
+		},
+		"issue batch without holder": {
+			msg:  batch(func(m *MsgIssueCreditBatch) { m.Issuances[1].Holder = nil }),
+			code: sdk.CodeInvalidAddress,
+		},
+		"issue batch with negative units": {
+			msg:  batch(func(m *MsgIssueCreditBatch) { m.Issuances[1].LiquidUnits = sdk.NewDec(-1) }),
+			code: CodeInvalidUnits,
+		},
+		"issue batch with empty issuance": {
+			msg:  batch(func(m *MsgIssueCreditBatch) { m.Issuances[1].RetiredUnits = sdk.ZeroDec() }),
+			code: CodeInvalidUnits,
+		},
+		"issue batch with too long beneficiary": {
+			msg: batch(func(m *MsgIssueCreditBatch) {
+				m.Issuances[1].Beneficiary = strings.Repeat("a", MaxRetirementInfoLength+1)
+			}),
+			code: CodeInvalidRetirementInfo,
+		},
+		"issue batch with invalid metadata": {
+			msg:  batch(func(m *MsgIssueCreditBatch) { m.EndDate = m.StartDate }),
+			code: CodeInvalidDateRange,
+		},
+		"send": {
+			msg: MsgSendCredit{Credit: CreditID{1}, From: addr1, To: addr2, Units: sdk.NewDecWithPrec(5, 1)},
+		},
+		"send without credit": {
+			msg:  MsgSendCredit{From: addr1, To: addr2, Units: sdk.NewDec(1)},
+			code: CodeInvalidCredit,
+		},
+		"send without sender": {
+			msg:  MsgSendCredit{Credit: CreditID{1}, To: addr2, Units: sdk.NewDec(1)},
+			code: sdk.CodeInvalidAddress,
+		},
+		"send without recipient": {
+			msg:  MsgSendCredit{Credit: CreditID{1}, From: addr1, Units: sdk.NewDec(1)},
+			code: sdk.CodeInvalidAddress,
+		},
+		"send nil units": {
+			msg:  MsgSendCredit{Credit: CreditID{1}, From: addr1, To: addr2},
+			code: CodeInvalidUnits,
+		},
+		"send zero units": {
+			msg:  MsgSendCredit{Credit: CreditID{1}, From: addr1, To: addr2, Units: sdk.ZeroDec()},
+			code: CodeInvalidUnits,
+		},
+		"send negative units": {
+			msg:  MsgSendCredit{Credit: CreditID{1}, From: addr1, To: addr2, Units: sdk.NewDec(-1)},
+			code: CodeInvalidUnits,
+		},
+		"multi-send": {
+			msg: MsgMultiSendCredit{From: addr1, Outputs: []CreditOutput{
+				{Credit: CreditID{1}, To: addr2, Units: sdk.NewDec(1)},
+				{Credit: CreditID{2}, To: addr2, Units: sdk.NewDec(2)},
+			}},
+		},
+		"multi-send without sender": {
+			msg:  MsgMultiSendCredit{Outputs: []CreditOutput{{Credit: CreditID{1}, To: addr2, Units: sdk.NewDec(1)}}},
+			code: sdk.CodeInvalidAddress,
+		},
+		"multi-send without outputs": {
+			msg:  MsgMultiSendCredit{From: addr1},
+			code: CodeInvalidOutputs,
+		},
+		"multi-send without credit": {
+			msg:  MsgMultiSendCredit{From: addr1, Outputs: []CreditOutput{{To: addr2, Units: sdk.NewDec(1)}}},
+			code: CodeInvalidCredit,
+		},
+		"multi-send without recipient": {
+			msg:  MsgMultiSendCredit{From: addr1, Outputs: []CreditOutput{{Credit: CreditID{1}, Units: sdk.NewDec(1)}}},
+			code: sdk.CodeInvalidAddress,
+		},
+		"multi-send zero units": {
+			msg:  MsgMultiSendCredit{From: addr1, Outputs: []CreditOutput{{Credit: CreditID{1}, To: addr2, Units: sdk.ZeroDec()}}},
+			code: CodeInvalidUnits,
+		},
+		"approve": {
+			msg: MsgApproveCredit{Holder: addr1, Spender: addr2, Credit: CreditID{1}, Units: sdk.NewDec(1)},
+		},
+		"approve all credits": {
+			msg: MsgApproveCredit{Holder: addr1, Spender: addr2, Units: sdk.NewDec(1), Expiration: endDate},
+		},
+		"approve without spender": {
+			msg:  MsgApproveCredit{Holder: addr1, Units: sdk.NewDec(1)},
+			code: sdk.CodeInvalidAddress,
+		},
+		"approve self": {
+			msg:  MsgApproveCredit{Holder: addr1, Spender: addr1, Units: sdk.NewDec(1)},
+			code: CodeInvalidAllowance,
+		},
+		"approve zero units": {
+			msg:  MsgApproveCredit{Holder: addr1, Spender: addr2, Units: sdk.ZeroDec()},
+			code: CodeInvalidUnits,
+		},
+		"revoke": {
+			msg: MsgRevokeCredit{Holder: addr1, Spender: addr2},
+		},
+		"revoke without holder": {
+			msg:  MsgRevokeCredit{Spender: addr2},
+			code: sdk.CodeInvalidAddress,
+		},
+		"send from": {
+			msg: MsgSendCreditFrom{Spender: addr2, Credit: CreditID{1}, From: addr1, To: addr2, Units: sdk.NewDec(1)},
+		},
+		"send from without spender": {
+			msg:  MsgSendCreditFrom{Credit: CreditID{1}, From: addr1, To: addr2, Units: sdk.NewDec(1)},
+			code: sdk.CodeInvalidAddress,
+		},
+		"send from without credit": {
+			msg:  MsgSendCreditFrom{Spender: addr2, From: addr1, To: addr2, Units: sdk.NewDec(1)},
+			code: CodeInvalidCredit,
+		},
+		"burn": {
+			msg: MsgBurnCredit{Credit: CreditID{1}, Holder: addr1, Units: sdk.NewDec(1)},
+		},
+		"burn with retirement info": {
+			msg: MsgBurnCredit{Credit: CreditID{1}, Holder: addr1, Units: sdk.NewDec(1),
+				RetirementInfo: RetirementInfo{Beneficiary: "ACME Corp", Jurisdiction: "US-CA", Reason: "2019 flights"}},
+		},
+		"burn with too long beneficiary": {
+			msg: MsgBurnCredit{Credit: CreditID{1}, Holder: addr1, Units: sdk.NewDec(1),
+				RetirementInfo: RetirementInfo{Beneficiary: strings.Repeat("a", MaxRetirementInfoLength+1)}},
+			code: CodeInvalidRetirementInfo,
+		},
+		"burn with too long jurisdiction": {
+			msg: MsgBurnCredit{Credit: CreditID{1}, Holder: addr1, Units: sdk.NewDec(1),
+				RetirementInfo: RetirementInfo{Jurisdiction: strings.Repeat("a", MaxRetirementInfoLength+1)}},
+			code: CodeInvalidRetirementInfo,
+		},
+		"burn with too long reason": {
+			msg: MsgBurnCredit{Credit: CreditID{1}, Holder: addr1, Units: sdk.NewDec(1),
+				RetirementInfo: RetirementInfo{Reason: strings.Repeat("a", MaxRetirementInfoLength+1)}},
+			code: CodeInvalidRetirementInfo,
+		},
+		"burn without credit": {
+			msg:  MsgBurnCredit{Holder: addr1, Units: sdk.NewDec(1)},
+			code: CodeInvalidCredit,
+		},
+		"burn without holder": {
+			msg:  MsgBurnCredit{Credit: CreditID{1}, Units: sdk.NewDec(1)},
+			code: sdk.CodeInvalidAddress,
+		},
+		"burn nil units": {
+			msg:  MsgBurnCredit{Credit: CreditID{1}, Holder: addr1},
+			code: CodeInvalidUnits,
+		},
+		"burn zero units": {
+			msg:  MsgBurnCredit{Credit: CreditID{1}, Holder: addr1, Units: sdk.ZeroDec()},
+			code: CodeInvalidUnits,
+		},
+		"burn negative units": {
+			msg:  MsgBurnCredit{Credit: CreditID{1}, Holder: addr1, Units: sdk.NewDec(-1)},
+			code: CodeInvalidUnits,
+		},
+		"add issuer": {
+			msg: MsgAddCreditClassIssuer{CreditClass: CreditClassID{1}, Designer: addr1, Issuer: addr2},
+		},
+		"add issuer without credit class": {
+			msg:  MsgAddCreditClassIssuer{Designer: addr1, Issuer: addr2},
+			code: CodeInvalidCreditClass,
+		},
+		"add issuer without designer": {
+			msg:  MsgAddCreditClassIssuer{CreditClass: CreditClassID{1}, Issuer: addr2},
+			code: sdk.CodeInvalidAddress,
+		},
+		"add issuer without issuer": {
+			msg:  MsgAddCreditClassIssuer{CreditClass: CreditClassID{1}, Designer: addr1},
+			code: sdk.CodeInvalidAddress,
+		},
+		"remove issuer": {
+			msg: MsgRemoveCreditClassIssuer{CreditClass: CreditClassID{1}, Designer: addr1, Issuer: addr2},
+		},
+		"remove issuer without credit class": {
+			msg:  MsgRemoveCreditClassIssuer{Designer: addr1, Issuer: addr2},
+			code: CodeInvalidCreditClass,
+		},
+		"remove issuer without designer": {
+			msg:  MsgRemoveCreditClassIssuer{CreditClass: CreditClassID{1}, Issuer: addr2},
+			code: sdk.CodeInvalidAddress,
+		},
+		"remove issuer without issuer": {
+			msg:  MsgRemoveCreditClassIssuer{CreditClass: CreditClassID{1}, Designer: addr1},
+			code: sdk.CodeInvalidAddress,
+		},
+		"transfer designer": {
+			msg: MsgTransferCreditClassDesigner{CreditClass: CreditClassID{1}, Designer: addr1, NewDesigner: addr2},
+		},
+		"transfer designer without credit class": {
+			msg:  MsgTransferCreditClassDesigner{Designer: addr1, NewDesigner: addr2},
+			code: CodeInvalidCreditClass,
+		},
+		"transfer designer without designer": {
+			msg:  MsgTransferCreditClassDesigner{CreditClass: CreditClassID{1}, NewDesigner: addr2},
+			code: sdk.CodeInvalidAddress,
+		},
+		"transfer designer without new designer": {
+			msg:  MsgTransferCreditClassDesigner{CreditClass: CreditClassID{1}, Designer: addr1},
+			code: sdk.CodeInvalidAddress,
+		},
+		"deprecate class": {
+			msg: MsgDeprecateCreditClass{CreditClass: CreditClassID{1}, Designer: addr1},
+		},
+		"deprecate class without credit class": {
+			msg:  MsgDeprecateCreditClass{Designer: addr1},
+			code: CodeInvalidCreditClass,
+		},
+		"deprecate class without designer": {
+			msg:  MsgDeprecateCreditClass{CreditClass: CreditClassID{1}},
+			code: sdk.CodeInvalidAddress,
+		},
+		"create sell order": {
+			msg: MsgCreateSellOrder{Seller: addr1, Credit: CreditID{1}, Units: sdk.NewDec(1), Price: sdk.NewCoins(sdk.NewInt64Coin("uatom", 10))},
+		},
+		"create sell order without price": {
+			msg:  MsgCreateSellOrder{Seller: addr1, Credit: CreditID{1}, Units: sdk.NewDec(1)},
+			code: CodeInvalidPrice,
+		},
+		"create sell order with invalid price": {
+			msg:  MsgCreateSellOrder{Seller: addr1, Credit: CreditID{1}, Units: sdk.NewDec(1), Price: sdk.Coins{sdk.Coin{Denom: "uatom", Amount: sdk.NewInt(-1)}}},
+			code: CodeInvalidPrice,
+		},
+		"create sell order without units": {
+			msg:  MsgCreateSellOrder{Seller: addr1, Credit: CreditID{1}, Units: sdk.ZeroDec(), Price: sdk.NewCoins(sdk.NewInt64Coin("uatom", 10))},
+			code: CodeInvalidUnits,
+		},
+		"update sell order without order": {
+			msg:  MsgUpdateSellOrder{Seller: addr1, Units: sdk.NewDec(1), Price: sdk.NewCoins(sdk.NewInt64Coin("uatom", 10))},
+			code: CodeInvalidSellOrder,
+		},
+		"cancel sell order without seller": {
+			msg:  MsgCancelSellOrder{Order: SellOrderID{1}},
+			code: sdk.CodeInvalidAddress,
+		},
+		"buy credit": {
+			msg: MsgBuyCredit{Buyer: addr2, Order: SellOrderID{1}, Units: sdk.NewDec(1), MaxPrice: sdk.NewCoins(sdk.NewInt64Coin("uatom", 10))},
+		},
+		"buy credit without max price": {
+			msg:  MsgBuyCredit{Buyer: addr2, Order: SellOrderID{1}, Units: sdk.NewDec(1)},
+			code: CodeInvalidPrice,
+		},
+		"buy and retire credit with invalid retirement info": {
+			msg: MsgBuyCredit{Buyer: addr2, Order: SellOrderID{1}, Units: sdk.NewDec(1), MaxPrice: sdk.NewCoins(sdk.NewInt64Coin("uatom", 10)),
+				Retire: true, RetirementInfo: RetirementInfo{Reason: strings.Repeat("x", MaxRetirementInfoLength+1)}},
+			code: CodeInvalidRetirementInfo,
+		},
+		"create auction": {
+			msg: MsgCreateAuction{Seller: addr1, Credit: CreditID{1}, Units: sdk.NewDec(1), MinPrice: sdk.NewInt64Coin("uatom", 10), EndTime: endDate},
+		},
+		"create auction without end time": {
+			msg:  MsgCreateAuction{Seller: addr1, Credit: CreditID{1}, Units: sdk.NewDec(1), MinPrice: sdk.NewInt64Coin("uatom", 10)},
+			code: CodeInvalidAuction,
+		},
+		"create auction without min price": {
+			msg:  MsgCreateAuction{Seller: addr1, Credit: CreditID{1}, Units: sdk.NewDec(1), EndTime: endDate},
+			code: CodeInvalidPrice,
+		},
+		"place bid": {
+			msg: MsgPlaceBid{Bidder: addr2, Auction: AuctionID{1}, Units: sdk.NewDec(1), Price: sdk.NewInt64Coin("uatom", 10)},
+		},
+		"place bid without auction": {
+			msg:  MsgPlaceBid{Bidder: addr2, Units: sdk.NewDec(1), Price: sdk.NewInt64Coin("uatom", 10)},
+			code: CodeInvalidAuction,
+		},
+		"place bid with zero price": {
+			msg:  MsgPlaceBid{Bidder: addr2, Auction: AuctionID{1}, Units: sdk.NewDec(1), Price: sdk.NewInt64Coin("uatom", 0)},
+			code: CodeInvalidPrice,
+		},
+		"wrap credit": {
+			msg: MsgWrapCredit{Holder: addr1, Credit: CreditID{1}, Units: sdk.NewDec(1)},
+		},
+		"wrap credit without units": {
+			msg:  MsgWrapCredit{Holder: addr1, Credit: CreditID{1}},
+			code: CodeInvalidUnits,
+		},
+		"unwrap credit without credit": {
+			msg:  MsgUnwrapCredit{Holder: addr1, Units: sdk.NewDec(1)},
+			code: CodeInvalidCredit,
+		},
+		"transfer credit": {
+			msg: MsgTransferCredit{SourceChannel: "channelatob", Credit: CreditID{1}, Units: sdk.NewDec(1), Sender: addr1, Receiver: addr2},
+		},
+		"transfer credit over invalid channel": {
+			msg:  MsgTransferCredit{SourceChannel: "channel-0", Credit: CreditID{1}, Units: sdk.NewDec(1), Sender: addr1, Receiver: addr2},
+			code: CodeInvalidCreditPacket,
+		},
+		"anchor data": {
+			msg: MsgAnchorData{Sender: addr1, Hash: make([]byte, 32), URI: "ipfs://data", MediaType: "text/csv"},
+		},
+		"anchor data with invalid media type": {
+			msg:  MsgAnchorData{Sender: addr1, Hash: make([]byte, 32), URI: "ipfs://data", MediaType: "csv file"},
+			code: CodeInvalidData,
+		},
+		"attest data with invalid hash": {
+			msg:  MsgAttestData{Verifier: addr1, CreditClass: CreditClassID{1}, Hash: []byte{1}},
+			code: CodeInvalidData,
+		},
+		"post bond": {
+			msg: MsgPostBond{Issuer: addr1, CreditClass: CreditClassID{1}, Amount: sdk.NewCoins(sdk.NewInt64Coin("uatom", 10))},
+		},
+		"unbond bond without amount": {
+			msg:  MsgUnbondBond{Issuer: addr1, CreditClass: CreditClassID{1}},
+			code: CodeInvalidBond,
+		},
+		"slash bond": {
+			msg: MsgSlashBond{Designer: addr1, CreditClass: CreditClassID{1}, Issuer: addr2, Credit: CreditID{1}, Fraction: sdk.NewDecWithPrec(5, 1), Reason: "fraud"},
+		},
+		"slash bond by more than the bond": {
+			msg:  MsgSlashBond{Designer: addr1, CreditClass: CreditClassID{1}, Issuer: addr2, Credit: CreditID{1}, Fraction: sdk.NewDec(2), Reason: "fraud"},
+			code: CodeInvalidBond,
+		},
+		"slash bond with too long a reason": {
+			msg:  MsgSlashBond{Designer: addr1, CreditClass: CreditClassID{1}, Issuer: addr2, Credit: CreditID{1}, Fraction: sdk.NewDecWithPrec(5, 1), Reason: strings.Repeat("x", MaxSlashReasonLength+1)},
+			code: CodeInvalidBond,
+		},
+		"reverse credit": {
+			msg: MsgReverseCredit{Reverser: addr1, Credit: CreditID{1}, Units: sdk.NewDec(1), Reason: "fire"},
+		},
+		"reverse credit without reason": {
+			msg:  MsgReverseCredit{Reverser: addr1, Credit: CreditID{1}, Units: sdk.NewDec(1)},
+			code: CodeInvalidReversal,
+		},
+		"split credit": {
+			msg: MsgSplitCredit{Holder: addr1, Credit: CreditID{1}, Units: sdk.NewDec(1), Parts: [][]byte{
+				mustGeoPolygon("POLYGON ((0 0, 1 0, 1 1, 0 0))"), mustGeoPolygon("POLYGON ((0 0, 1 1, 0 1, 0 0))"),
+			}},
+		},
+		"split credit into one part": {
+			msg:  MsgSplitCredit{Holder: addr1, Credit: CreditID{1}, Units: sdk.NewDec(1), Parts: [][]byte{mustGeoPolygon("POLYGON ((0 0, 1 0, 1 1, 0 0))")}},
+			code: CodeInvalidTiling,
+		},
+		"merge credits": {
+			msg: MsgMergeCredits{Holder: addr1, Credits: []CreditUnits{{CreditID{1}, sdk.NewDec(1)}, {CreditID{2}, sdk.NewDec(1)}},
+				GeoPolygon: mustGeoPolygon("POLYGON ((0 0, 1 0, 1 1, 0 1, 0 0))")},
+		},
+		"merge credit with itself": {
+			msg: MsgMergeCredits{Holder: addr1, Credits: []CreditUnits{{CreditID{1}, sdk.NewDec(1)}, {CreditID{1}, sdk.NewDec(1)}},
+				GeoPolygon: mustGeoPolygon("POLYGON ((0 0, 1 0, 1 1, 0 1, 0 0))")},
+			code: CodeIncompatibleCredits,
+		},
+		"receive credit packet without proof": {
+			msg:  MsgRecvCreditPacket{Packet: channel.NewPacket(1, 100, PortID, "channelbtoa", PortID, "channelatob", []byte("{}")), Height: 1, Signer: addr1},
+			code: CodeInvalidCreditPacket,
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			err := tc.msg.ValidateBasic()
+			if tc.code == sdk.CodeOK {
+				require.Nil(t, err)
+				return
+			}
+			require.NotNil(t, err)
+			require.Equal(t, tc.code, err.Code(), err.Error())
+		})
+	}
+}
+
+func TestIDBech32(t *testing.T) {
+	class := CreditClassID{0, 0, 0, 0, 0, 0, 0, 1}
+	credit := CreditID{0, 0, 0, 0, 0, 0, 0, 1}
+	require.True(t, strings.HasPrefix(class.String(), "ecocls1"))
+	require.True(t, strings.HasPrefix(credit.String(), "ecocrd1"))
+	decodedClass, err := CreditClassFromBech32(class.String())
+	require.NoError(t, err)
+	require.Equal(t, class, decodedClass)
+	decodedCredit, err := CreditFromBech32(credit.String())
+	require.NoError(t, err)
+	require.Equal(t, credit, decodedCredit)
+
+	// the prefixes of classes and credits can't be mixed up
+	_, err = CreditFromBech32(class.String())
+	require.Error(t, err)
+	_, err = CreditClassFromBech32(credit.String())
+	require.Error(t, err)
+
+	// %s formats IDs in bech32 while %x keeps formatting them in hex
+	require.Equal(t, credit.String(), fmt.Sprintf("%s", credit))
+	require.Equal(t, "0000000000000001", fmt.Sprintf("%x", credit))
+	// with their flags and width
+	require.Equal(t, "00000000000000000001", fmt.Sprintf("%020x", credit))
+	require.Equal(t, "00 00 00 00 00 00 00 01", fmt.Sprintf("% x", credit))
+	require.Equal(t, fmt.Sprintf("%-60s|", credit.String()), fmt.Sprintf("%-60s|", credit))
+	require.Equal(t, fmt.Sprintf("%.6v", credit.String()), fmt.Sprintf("%.6v", credit))
+
+	bz, err := ModuleCdc.MarshalJSON(CreditUnits{Credit: credit, Units: sdk.NewDec(1)})
+	require.NoError(t, err)
+	require.Contains(t, string(bz), credit.String())
+	var units CreditUnits
+	require.NoError(t, ModuleCdc.UnmarshalJSON(bz, &units))
+	require.Equal(t, credit, units.Credit)
+
+	// the IDs of all other records are bech32 encoded as well
+	id := []byte{0, 0, 0, 0, 0, 0, 0, 1}
+	for _, test := range []struct {
+		id     fmt.Stringer
+		prefix string
+		decode func(string) (interface{}, error)
+	}{
+		{RetirementID(id), Bech32PrefixRetirement, func(s string) (interface{}, error) { return RetirementFromBech32(s) }},
+		{SellOrderID(id), Bech32PrefixSellOrder, func(s string) (interface{}, error) { return SellOrderFromBech32(s) }},
+		{AuctionID(id), Bech32PrefixAuction, func(s string) (interface{}, error) { return AuctionFromBech32(s) }},
+		{AuctionBidID(id), Bech32PrefixAuctionBid, func(s string) (interface{}, error) { return AuctionBidFromBech32(s) }},
+		{ReversalID(id), Bech32PrefixReversal, func(s string) (interface{}, error) { return ReversalFromBech32(s) }},
+		{ConversionID(id), Bech32PrefixConversion, func(s string) (interface{}, error) { return ConversionFromBech32(s) }},
+		{BondUnbondingID(id), Bech32PrefixBondUnbonding, func(s string) (interface{}, error) { return BondUnbondingFromBech32(s) }},
+		{TransferID(id), Bech32PrefixTransfer, func(s string) (interface{}, error) { return TransferFromBech32(s) }},
+	} {
+		require.True(t, strings.HasPrefix(test.id.String(), test.prefix+"1"))
+		decoded, err := test.decode(test.id.String())
+		require.NoError(t, err)
+		require.Equal(t, test.id, decoded)
+		_, err = test.decode(credit.String())
+		require.Error(t, err)
+	}
+}
@@ -0,0 +1,66 @@
+package ecocredit
+
+import (
+	"fmt"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	govtypes "github.com/cosmos/cosmos-sdk/x/gov/types"
+)
+
+// ProposalTypeSlashBond is the type of a SlashBondProposal
+const ProposalTypeSlashBond = "SlashBond"
+
+var _ govtypes.Content = SlashBondProposal{}
+
+func init() {
+	govtypes.RegisterProposalType(ProposalTypeSlashBond)
+	govtypes.RegisterProposalTypeCodec(SlashBondProposal{}, "ecocredit/SlashBondProposal")
+}
+
+// SlashBondProposal is a governance proposal to slash the bond of an issuer for a fraudulent issuance of Credit,
+// e.g. when the designer of the credit class doesn't act or is involved in the fraud
+type SlashBondProposal struct {
+	Title       string         `json:"title"`
+	Description string         `json:"description"`
+	CreditClass CreditClassID  `json:"credit_class"`
+	Issuer      sdk.AccAddress `json:"issuer"`
+	Credit      CreditID       `json:"credit"`
+	Fraction    sdk.Dec        `json:"fraction"`
+}
+
+func (p SlashBondProposal) GetTitle() string { return p.Title }
+
+func (p SlashBondProposal) GetDescription() string { return p.Description }
+
+func (p SlashBondProposal) ProposalRoute() string { return RouterKey }
+
+func (p SlashBondProposal) ProposalType() string { return ProposalTypeSlashBond }
+
+func (p SlashBondProposal) ValidateBasic() sdk.Error {
+	if err := govtypes.ValidateAbstract(DefaultCodespace, p); err != nil {
+		return err
+	}
+	return validateSlash(p.CreditClass, p.Issuer, p.Credit, p.Fraction)
+}
+
+func (p SlashBondProposal) String() string {
+	return fmt.Sprintf(`Slash Bond Proposal:
+  Title:        %s
+  Description:  %s
+  Credit Class: %s
+  Issuer:       %s
+  Credit:       %s
+  Fraction:     %s
+`, p.Title, p.Description, p.CreditClass, p.Issuer, p.Credit, p.Fraction)
+}
+
+// NewSlashBondProposalHandler returns the governance handler executing passed SlashBondProposals
+func NewSlashBondProposalHandler(k Keeper) govtypes.Handler {
+	return func(ctx sdk.Context, content govtypes.Content) sdk.Error {
+		switch c := content.(type) {
+		case SlashBondProposal:
+			return HandleSlashBondProposal(ctx, k, c)
+		default:
+			return sdk.ErrUnknownRequest(fmt.Sprintf("unrecognized %s proposal content type: %T", ModuleName, c))
+		}
+	}
+}
@@ -1,12 +1,528 @@
 package ecocredit
 
 import (
+	"fmt"
+	"github.com/cosmos/cosmos-sdk/codec"
 	sdk "github.com/cosmos/cosmos-sdk/types"
 	"github.com/tendermint/tendermint/abci/types"
+	"time"
 )
 
+const (
+	QueryCreditClass         = "credit-class"
+	QueryConflicts           = "conflicts"
+	QuerySupply              = "supply"
+	QueryHoldingsByHolder    = "holdings-by-holder"
+	QueryHoldersOfCredit     = "holders-of-credit"
+	QueryAllowance           = "allowance"
+	QueryRetirement          = "retirement"
+	QueryRetirementsByHolder = "retirements-by-holder"
+	QueryRetirementsByCredit = "retirements-by-credit"
+	QuerySellOrder           = "sell-order"
+	QuerySellOrdersBySeller  = "sell-orders-by-seller"
+	QuerySellOrdersByCredit  = "sell-orders-by-credit"
+	QueryAuction             = "auction"
+	QueryAuctions            = "auctions"
+	QueryCreditVoucher       = "credit-voucher"
+	QueryReversal            = "reversal"
+	QueryReversalsByCredit   = "reversals-by-credit"
+	QueryBufferPool          = "buffer-pool"
+	QueryAnchoredData        = "anchored-data"
+	QueryBond                = "bond"
+	QueryExpiry              = "expiry"
+	QueryConversion          = "conversion"
+	QueryLineage             = "lineage"
+	QueryReport              = "report"
+)
+
+// QueryCreditClassParams are the parameters of the credit class query
+type QueryCreditClassParams struct {
+	CreditClass CreditClassID `json:"credit_class"`
+}
+
+// QueryAllowanceParams are the parameters of the allowance query. An empty credit queries the
+// allowance for all credits of the holder
+type QueryAllowanceParams struct {
+	Holder  sdk.AccAddress `json:"holder"`
+	Spender sdk.AccAddress `json:"spender"`
+	Credit  CreditID       `json:"credit"`
+}
+
+// QueryRetirementParams are the parameters of the retirement query
+type QueryRetirementParams struct {
+	ID RetirementID `json:"id"`
+}
+
+// QueryReversalParams are the parameters of the reversal query
+type QueryReversalParams struct {
+	ID ReversalID `json:"id"`
+}
+
+// QueryConversionParams are the parameters of the conversion query
+type QueryConversionParams struct {
+	ID ConversionID `json:"id"`
+}
+
+// QueryReportParams are the parameters of the report query, a zero From or To leaves the time range open on that side
+type QueryReportParams struct {
+	CreditClass CreditClassID `json:"credit_class"`
+	From        time.Time     `json:"from"`
+	To          time.Time     `json:"to"`
+}
+
+// QueryAnchoredDataParams are the parameters of the anchored data query
+type QueryAnchoredDataParams struct {
+	Hash []byte `json:"hash"`
+}
+
+// QueryBondParams are the parameters of the bond query
+type QueryBondParams struct {
+	CreditClass CreditClassID  `json:"credit_class"`
+	Issuer      sdk.AccAddress `json:"issuer"`
+}
+
+// QuerySellOrderParams are the parameters of the sell order query
+type QuerySellOrderParams struct {
+	ID SellOrderID `json:"id"`
+}
+
+// QueryAuctionParams are the parameters of the auction query
+type QueryAuctionParams struct {
+	ID AuctionID `json:"id"`
+}
+
+// AuctionWithBids is the result of the auction query
+type AuctionWithBids struct {
+	AuctionWithID `json:"auction"`
+	Bids          []AuctionBidWithID `json:"bids"`
+}
+
+// QueryHolderParams are the parameters of queries for a specific holder
+type QueryHolderParams struct {
+	Holder sdk.AccAddress `json:"holder"`
+}
+
+// QueryCreditParams are the parameters of queries for a specific credit
+type QueryCreditParams struct {
+	Credit CreditID `json:"credit"`
+}
+
 func NewQuerier(keeper Keeper) sdk.Querier {
 	return func(ctx sdk.Context, path []string, req types.RequestQuery) (res []byte, err sdk.Error) {
-		return nil, nil
+		switch path[0] {
+		case QueryCreditClass:
+			return queryCreditClass(ctx, req, keeper)
+		case QueryConflicts:
+			return queryConflicts(ctx, req, keeper)
+		case QuerySupply:
+			return querySupply(ctx, req, keeper)
+		case QueryHoldingsByHolder:
+			return queryHoldingsByHolder(ctx, req, keeper)
+		case QueryHoldersOfCredit:
+			return queryHoldersOfCredit(ctx, req, keeper)
+		case QueryAllowance:
+			return queryAllowance(ctx, req, keeper)
+		case QueryRetirement:
+			return queryRetirement(ctx, req, keeper)
+		case QueryRetirementsByHolder:
+			return queryRetirementsByHolder(ctx, req, keeper)
+		case QueryRetirementsByCredit:
+			return queryRetirementsByCredit(ctx, req, keeper)
+		case QuerySellOrder:
+			return querySellOrder(ctx, req, keeper)
+		case QuerySellOrdersBySeller:
+			return querySellOrdersBySeller(ctx, req, keeper)
+		case QuerySellOrdersByCredit:
+			return querySellOrdersByCredit(ctx, req, keeper)
+		case QueryAuction:
+			return queryAuction(ctx, req, keeper)
+		case QueryAuctions:
+			return queryAuctions(ctx, keeper)
+		case QueryCreditVoucher:
+			return queryCreditVoucher(ctx, req, keeper)
+		case QueryReversal:
+			return queryReversal(ctx, req, keeper)
+		case QueryReversalsByCredit:
+			return queryReversalsByCredit(ctx, req, keeper)
+		case QueryBufferPool:
+			return queryBufferPool(ctx, req, keeper)
+		case QueryAnchoredData:
+			return queryAnchoredData(ctx, req, keeper)
+		case QueryBond:
+			return queryBond(ctx, req, keeper)
+		case QueryExpiry:
+			return queryExpiry(ctx, req, keeper)
+		case QueryConversion:
+			return queryConversion(ctx, req, keeper)
+		case QueryLineage:
+			return queryLineage(ctx, req, keeper)
+		case QueryReport:
+			return queryReport(ctx, req, keeper)
+		default:
+			return nil, sdk.ErrUnknownRequest(fmt.Sprintf("unknown %s query endpoint", ModuleName))
+		}
+	}
+}
+
+func queryCreditClass(ctx sdk.Context, req types.RequestQuery, keeper Keeper) ([]byte, sdk.Error) {
+	var params QueryCreditClassParams
+	err := ModuleCdc.UnmarshalJSON(req.Data, &params)
+	if err != nil {
+		return nil, sdk.ErrUnknownRequest(fmt.Sprintf("incorrectly formatted request data: %s", err))
+	}
+	metadata, found := keeper.GetCreditClass(ctx, params.CreditClass)
+	if !found {
+		return nil, sdk.ErrUnknownRequest(fmt.Sprintf("credit class %s not found", params.CreditClass))
+	}
+	return marshalJSON(metadata)
+}
+
+// queryConflicts reports the existing credits that a proposed issuance passed as JSON encoded CreditMetadata would
+// conflict with
+func queryConflicts(ctx sdk.Context, req types.RequestQuery, keeper Keeper) ([]byte, sdk.Error) {
+	var metadata CreditMetadata
+	err := ModuleCdc.UnmarshalJSON(req.Data, &metadata)
+	if err != nil {
+		return nil, sdk.ErrUnknownRequest(fmt.Sprintf("incorrectly formatted request data: %s", err))
+	}
+	conflicts, err := keeper.GetConflictingCredits(ctx, metadata)
+	if err != nil {
+		return nil, toSDKError(err)
+	}
+	if conflicts == nil {
+		conflicts = []Credit{}
+	}
+	return marshalJSON(conflicts)
+}
+
+func querySupply(ctx sdk.Context, req types.RequestQuery, keeper Keeper) ([]byte, sdk.Error) {
+	var params QueryCreditParams
+	err := ModuleCdc.UnmarshalJSON(req.Data, &params)
+	if err != nil {
+		return nil, sdk.ErrUnknownRequest(fmt.Sprintf("incorrectly formatted request data: %s", err))
+	}
+	supply, found := keeper.GetCreditSupply(ctx, params.Credit)
+	if !found {
+		return nil, sdk.ErrUnknownRequest(fmt.Sprintf("credit %s not found", params.Credit))
+	}
+	return marshalJSON(supply)
+}
+
+// queryHoldingsByHolder lists the holdings of all credits held by an address, i.e. its portfolio
+func queryHoldingsByHolder(ctx sdk.Context, req types.RequestQuery, keeper Keeper) ([]byte, sdk.Error) {
+	var params QueryHolderParams
+	err := ModuleCdc.UnmarshalJSON(req.Data, &params)
+	if err != nil {
+		return nil, sdk.ErrUnknownRequest(fmt.Sprintf("incorrectly formatted request data: %s", err))
+	}
+	holdings := []CreditHolding{}
+	keeper.IterateHoldingsByHolder(ctx, params.Holder, func(holding CreditHolding) (stop bool) {
+		holdings = append(holdings, holding)
+		return false
+	})
+	return marshalJSON(holdings)
+}
+
+func queryHoldersOfCredit(ctx sdk.Context, req types.RequestQuery, keeper Keeper) ([]byte, sdk.Error) {
+	var params QueryCreditParams
+	err := ModuleCdc.UnmarshalJSON(req.Data, &params)
+	if err != nil {
+		return nil, sdk.ErrUnknownRequest(fmt.Sprintf("incorrectly formatted request data: %s", err))
+	}
+	holdings := []CreditHolding{}
+	keeper.IterateHoldersOfCredit(ctx, params.Credit, func(holding CreditHolding) (stop bool) {
+		holdings = append(holdings, holding)
+		return false
+	})
+	return marshalJSON(holdings)
+}
+
+func queryAllowance(ctx sdk.Context, req types.RequestQuery, keeper Keeper) ([]byte, sdk.Error) {
+	var params QueryAllowanceParams
+	err := ModuleCdc.UnmarshalJSON(req.Data, &params)
+	if err != nil {
+		return nil, sdk.ErrUnknownRequest(fmt.Sprintf("incorrectly formatted request data: %s", err))
+	}
+	allowance, found := keeper.GetCreditAllowance(ctx, params.Holder, params.Spender, params.Credit)
+	if !found {
+		return nil, sdk.ErrUnknownRequest(fmt.Sprintf("no allowance of %s for %s", params.Spender, params.Holder))
+	}
+	return marshalJSON(allowance)
+}
+
+func queryRetirement(ctx sdk.Context, req types.RequestQuery, keeper Keeper) ([]byte, sdk.Error) {
+	var params QueryRetirementParams
+	err := ModuleCdc.UnmarshalJSON(req.Data, &params)
+	if err != nil {
+		return nil, sdk.ErrUnknownRequest(fmt.Sprintf("incorrectly formatted request data: %s", err))
+	}
+	retirement, found := keeper.GetRetirement(ctx, params.ID)
+	if !found {
+		return nil, sdk.ErrUnknownRequest(fmt.Sprintf("retirement %s not found", params.ID))
+	}
+	return marshalJSON(RetirementCertificate{ID: params.ID, Retirement: retirement})
+}
+
+func queryRetirementsByHolder(ctx sdk.Context, req types.RequestQuery, keeper Keeper) ([]byte, sdk.Error) {
+	var params QueryHolderParams
+	err := ModuleCdc.UnmarshalJSON(req.Data, &params)
+	if err != nil {
+		return nil, sdk.ErrUnknownRequest(fmt.Sprintf("incorrectly formatted request data: %s", err))
+	}
+	certificates := []RetirementCertificate{}
+	keeper.IterateRetirementsByHolder(ctx, params.Holder, func(id RetirementID, retirement Retirement) (stop bool) {
+		certificates = append(certificates, RetirementCertificate{ID: id, Retirement: retirement})
+		return false
+	})
+	return marshalJSON(certificates)
+}
+
+func queryRetirementsByCredit(ctx sdk.Context, req types.RequestQuery, keeper Keeper) ([]byte, sdk.Error) {
+	var params QueryCreditParams
+	err := ModuleCdc.UnmarshalJSON(req.Data, &params)
+	if err != nil {
+		return nil, sdk.ErrUnknownRequest(fmt.Sprintf("incorrectly formatted request data: %s", err))
+	}
+	certificates := []RetirementCertificate{}
+	keeper.IterateRetirementsByCredit(ctx, params.Credit, func(id RetirementID, retirement Retirement) (stop bool) {
+		certificates = append(certificates, RetirementCertificate{ID: id, Retirement: retirement})
+		return false
+	})
+	return marshalJSON(certificates)
+}
+
+func querySellOrder(ctx sdk.Context, req types.RequestQuery, keeper Keeper) ([]byte, sdk.Error) {
+	var params QuerySellOrderParams
+	err := ModuleCdc.UnmarshalJSON(req.Data, &params)
+	if err != nil {
+		return nil, sdk.ErrUnknownRequest(fmt.Sprintf("incorrectly formatted request data: %s", err))
+	}
+	order, found := keeper.GetSellOrder(ctx, params.ID)
+	if !found {
+		return nil, sdk.ErrUnknownRequest(fmt.Sprintf("sell order %s not found", params.ID))
+	}
+	return marshalJSON(SellOrderWithID{ID: params.ID, SellOrder: order})
+}
+
+func querySellOrdersBySeller(ctx sdk.Context, req types.RequestQuery, keeper Keeper) ([]byte, sdk.Error) {
+	var params QueryHolderParams
+	err := ModuleCdc.UnmarshalJSON(req.Data, &params)
+	if err != nil {
+		return nil, sdk.ErrUnknownRequest(fmt.Sprintf("incorrectly formatted request data: %s", err))
+	}
+	orders := []SellOrderWithID{}
+	keeper.IterateSellOrdersBySeller(ctx, params.Holder, func(id SellOrderID, order SellOrder) (stop bool) {
+		orders = append(orders, SellOrderWithID{ID: id, SellOrder: order})
+		return false
+	})
+	return marshalJSON(orders)
+}
+
+func querySellOrdersByCredit(ctx sdk.Context, req types.RequestQuery, keeper Keeper) ([]byte, sdk.Error) {
+	var params QueryCreditParams
+	err := ModuleCdc.UnmarshalJSON(req.Data, &params)
+	if err != nil {
+		return nil, sdk.ErrUnknownRequest(fmt.Sprintf("incorrectly formatted request data: %s", err))
+	}
+	orders := []SellOrderWithID{}
+	keeper.IterateSellOrdersByCredit(ctx, params.Credit, func(id SellOrderID, order SellOrder) (stop bool) {
+		orders = append(orders, SellOrderWithID{ID: id, SellOrder: order})
+		return false
+	})
+	return marshalJSON(orders)
+}
+
+// queryAuction shows an open auction together with its bids
+func queryAuction(ctx sdk.Context, req types.RequestQuery, keeper Keeper) ([]byte, sdk.Error) {
+	var params QueryAuctionParams
+	err := ModuleCdc.UnmarshalJSON(req.Data, &params)
+	if err != nil {
+		return nil, sdk.ErrUnknownRequest(fmt.Sprintf("incorrectly formatted request data: %s", err))
+	}
+	auction, found := keeper.GetAuction(ctx, params.ID)
+	if !found {
+		return nil, sdk.ErrUnknownRequest(fmt.Sprintf("auction %s not found", params.ID))
+	}
+	res := AuctionWithBids{AuctionWithID: AuctionWithID{ID: params.ID, Auction: auction}, Bids: []AuctionBidWithID{}}
+	keeper.IterateBidsOfAuction(ctx, params.ID, func(id AuctionBidID, bid AuctionBid) (stop bool) {
+		res.Bids = append(res.Bids, AuctionBidWithID{ID: id, Bid: bid})
+		return false
+	})
+	return marshalJSON(res)
+}
+
+func queryAuctions(ctx sdk.Context, keeper Keeper) ([]byte, sdk.Error) {
+	auctions := []AuctionWithID{}
+	keeper.IterateAuctions(ctx, func(id AuctionID, auction Auction) (stop bool) {
+		auctions = append(auctions, AuctionWithID{ID: id, Auction: auction})
+		return false
+	})
+	return marshalJSON(auctions)
+}
+
+func queryCreditVoucher(ctx sdk.Context, req types.RequestQuery, keeper Keeper) ([]byte, sdk.Error) {
+	var params QueryCreditParams
+	err := ModuleCdc.UnmarshalJSON(req.Data, &params)
+	if err != nil {
+		return nil, sdk.ErrUnknownRequest(fmt.Sprintf("incorrectly formatted request data: %s", err))
+	}
+	voucher, found := keeper.GetCreditVoucher(ctx, params.Credit)
+	if !found {
+		return nil, sdk.ErrUnknownRequest(fmt.Sprintf("credit %s was not received over IBC", params.Credit))
+	}
+	return marshalJSON(voucher)
+}
+
+func queryReversal(ctx sdk.Context, req types.RequestQuery, keeper Keeper) ([]byte, sdk.Error) {
+	var params QueryReversalParams
+	err := ModuleCdc.UnmarshalJSON(req.Data, &params)
+	if err != nil {
+		return nil, sdk.ErrUnknownRequest(fmt.Sprintf("incorrectly formatted request data: %s", err))
+	}
+	reversal, found := keeper.GetReversal(ctx, params.ID)
+	if !found {
+		return nil, sdk.ErrUnknownRequest(fmt.Sprintf("reversal %s not found", params.ID))
+	}
+	return marshalJSON(ReversalRecord{ID: params.ID, Reversal: reversal})
+}
+
+func queryReversalsByCredit(ctx sdk.Context, req types.RequestQuery, keeper Keeper) ([]byte, sdk.Error) {
+	var params QueryCreditParams
+	err := ModuleCdc.UnmarshalJSON(req.Data, &params)
+	if err != nil {
+		return nil, sdk.ErrUnknownRequest(fmt.Sprintf("incorrectly formatted request data: %s", err))
+	}
+	records := []ReversalRecord{}
+	keeper.IterateReversalsByCredit(ctx, params.Credit, func(id ReversalID, reversal Reversal) (stop bool) {
+		records = append(records, ReversalRecord{ID: id, Reversal: reversal})
+		return false
+	})
+	return marshalJSON(records)
+}
+
+// queryBufferPool lists the holdings of the buffer pool of a credit class, one per credit of the class
+func queryBufferPool(ctx sdk.Context, req types.RequestQuery, keeper Keeper) ([]byte, sdk.Error) {
+	var params QueryCreditClassParams
+	err := ModuleCdc.UnmarshalJSON(req.Data, &params)
+	if err != nil {
+		return nil, sdk.ErrUnknownRequest(fmt.Sprintf("incorrectly formatted request data: %s", err))
+	}
+	holdings := []CreditHolding{}
+	keeper.IterateBufferPool(ctx, params.CreditClass, func(holding CreditHolding) (stop bool) {
+		holdings = append(holdings, holding)
+		return false
+	})
+	return marshalJSON(holdings)
+}
+
+// queryAnchoredData shows an anchored dataset with the attestations of the verifiers of all credit classes
+func queryAnchoredData(ctx sdk.Context, req types.RequestQuery, keeper Keeper) ([]byte, sdk.Error) {
+	var params QueryAnchoredDataParams
+	err := ModuleCdc.UnmarshalJSON(req.Data, &params)
+	if err != nil {
+		return nil, sdk.ErrUnknownRequest(fmt.Sprintf("incorrectly formatted request data: %s", err))
+	}
+	data, found := keeper.GetAnchoredData(ctx, params.Hash)
+	if !found {
+		return nil, sdk.ErrUnknownRequest(fmt.Sprintf("dataset %x is not anchored", params.Hash))
+	}
+	result := AnchoredDataWithAttestations{AnchoredData: data, Attestations: []Attestation{}}
+	keeper.IterateAttestations(ctx, params.Hash, func(attestation Attestation) (stop bool) {
+		result.Attestations = append(result.Attestations, attestation)
+		return false
+	})
+	return marshalJSON(result)
+}
+
+// queryBond shows the bond of an issuer for a credit class with its pending unbondings
+func queryBond(ctx sdk.Context, req types.RequestQuery, keeper Keeper) ([]byte, sdk.Error) {
+	var params QueryBondParams
+	err := ModuleCdc.UnmarshalJSON(req.Data, &params)
+	if err != nil {
+		return nil, sdk.ErrUnknownRequest(fmt.Sprintf("incorrectly formatted request data: %s", err))
+	}
+	if _, found := keeper.GetCreditClass(ctx, params.CreditClass); !found {
+		return nil, sdk.ErrUnknownRequest(fmt.Sprintf("credit class %s not found", params.CreditClass))
+	}
+	return marshalJSON(keeper.GetIssuerBondStatus(ctx, params.CreditClass, params.Issuer))
+}
+
+// queryExpiry shows when a credit expires, whether it expired and its expired units by holder
+func queryExpiry(ctx sdk.Context, req types.RequestQuery, keeper Keeper) ([]byte, sdk.Error) {
+	var params QueryCreditParams
+	err := ModuleCdc.UnmarshalJSON(req.Data, &params)
+	if err != nil {
+		return nil, sdk.ErrUnknownRequest(fmt.Sprintf("incorrectly formatted request data: %s", err))
+	}
+	status, found := keeper.GetCreditExpiryStatus(ctx, params.Credit)
+	if !found {
+		return nil, sdk.ErrUnknownRequest(fmt.Sprintf("credit %s not found", params.Credit))
+	}
+	return marshalJSON(status)
+}
+
+func queryConversion(ctx sdk.Context, req types.RequestQuery, keeper Keeper) ([]byte, sdk.Error) {
+	var params QueryConversionParams
+	err := ModuleCdc.UnmarshalJSON(req.Data, &params)
+	if err != nil {
+		return nil, sdk.ErrUnknownRequest(fmt.Sprintf("incorrectly formatted request data: %s", err))
+	}
+	conversion, found := keeper.GetConversion(ctx, params.ID)
+	if !found {
+		return nil, sdk.ErrUnknownRequest(fmt.Sprintf("conversion %s not found", params.ID))
+	}
+	return marshalJSON(ConversionRecord{ID: params.ID, Conversion: conversion})
+}
+
+// queryLineage shows the credits a credit was split or merged from and the credits derived from it
+func queryLineage(ctx sdk.Context, req types.RequestQuery, keeper Keeper) ([]byte, sdk.Error) {
+	var params QueryCreditParams
+	err := ModuleCdc.UnmarshalJSON(req.Data, &params)
+	if err != nil {
+		return nil, sdk.ErrUnknownRequest(fmt.Sprintf("incorrectly formatted request data: %s", err))
+	}
+	if _, found := keeper.GetCredit(ctx, params.Credit); !found {
+		return nil, sdk.ErrUnknownRequest(fmt.Sprintf("credit %s not found", params.Credit))
+	}
+	lineage, found := keeper.GetCreditLineage(ctx, params.Credit)
+	if !found {
+		lineage = CreditLineage{Credit: params.Credit}
+	}
+	if lineage.Parents == nil {
+		lineage.Parents = []CreditID{}
+	}
+	if lineage.Children == nil {
+		lineage.Children = []CreditID{}
+	}
+	return marshalJSON(lineage)
+}
+
+// queryReport lists the issuances, transfers and retirements of the credits of a class within a time range
+func queryReport(ctx sdk.Context, req types.RequestQuery, keeper Keeper) ([]byte, sdk.Error) {
+	var params QueryReportParams
+	err := ModuleCdc.UnmarshalJSON(req.Data, &params)
+	if err != nil {
+		return nil, sdk.ErrUnknownRequest(fmt.Sprintf("incorrectly formatted request data: %s", err))
+	}
+	report, err := keeper.Report(ctx, params.CreditClass, params.From, params.To)
+	if err != nil {
+		return nil, toSDKError(err)
+	}
+	return marshalJSON(report)
+}
+
+func marshalJSON(o interface{}) ([]byte, sdk.Error) {
+	bz, err := codec.MarshalJSONIndent(ModuleCdc, o)
+	if err != nil {
+		return nil, sdk.ErrInternal(sdk.AppendMsgToErr("could not marshal result to JSON", err.Error()))
+	}
+	return bz, nil
+}
+
+// toSDKError passes through errors which are already an sdk.Error and wraps all others as an internal error
+func toSDKError(err error) sdk.Error {
+	if sdkErr, ok := err.(sdk.Error); ok {
+		return sdkErr
 	}
+	return sdk.ErrInternal(err.Error())
 }
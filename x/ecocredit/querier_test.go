@@ -0,0 +1,79 @@
+package ecocredit
+
+import (
+	"testing"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/stretchr/testify/require"
+	abci "github.com/tendermint/tendermint/abci/types"
+)
+
+func TestQueryConflicts(t *testing.T) {
+	ctx, k := createTestInput(t)
+	querier := NewQuerier(k)
+	class, err := k.CreateCreditClass(ctx, testCreditClass())
+	require.NoError(t, err)
+	credit, err := k.IssueCredit(ctx, testCredit(class), addr1)
+	require.NoError(t, err)
+
+	// a proposed issuance reports the credits it would conflict with
+	proposed := testCredit(class)
+	proposed.GeoPolygon = mustGeoPolygon("POLYGON ((0.5 0.5, 1.5 0.5, 1.5 1.5, 0.5 1.5, 0.5 0.5))")
+	res, err := querier(ctx, []string{QueryConflicts}, abci.RequestQuery{Data: ModuleCdc.MustMarshalJSON(proposed)})
+	require.NoError(t, err)
+	var conflicts []Credit
+	require.NoError(t, ModuleCdc.UnmarshalJSON(res, &conflicts))
+	require.Equal(t, []Credit{{ID: credit, Metadata: testCredit(class)}}, conflicts)
+
+	// an adjacent parcel has none
+	proposed.GeoPolygon = mustGeoPolygon("POLYGON ((1 0, 2 0, 2 1, 1 1, 1 0))")
+	res, err = querier(ctx, []string{QueryConflicts}, abci.RequestQuery{Data: ModuleCdc.MustMarshalJSON(proposed)})
+	require.NoError(t, err)
+	require.NoError(t, ModuleCdc.UnmarshalJSON(res, &conflicts))
+	require.Empty(t, conflicts)
+
+	_, err = querier(ctx, []string{QueryConflicts}, abci.RequestQuery{Data: []byte("{")})
+	require.Error(t, err)
+}
+
+func TestQueryRetirements(t *testing.T) {
+	ctx, k := createTestInput(t)
+	querier := NewQuerier(k)
+	class, err := k.CreateCreditClass(ctx, testCreditClass())
+	require.NoError(t, err)
+	credit, err := k.IssueCredit(ctx, testCredit(class), addr1)
+	require.NoError(t, err)
+	ctx = ctx.WithBlockHeader(abci.Header{Time: startDate})
+	info := RetirementInfo{Beneficiary: "acme", Jurisdiction: "US-CA", Reason: "2019 emissions"}
+	id, err := k.BurnCredit(ctx, credit, addr1, sdk.NewDec(5), info)
+	require.NoError(t, err)
+	expected := RetirementCertificate{ID: id, Retirement: Retirement{Credit: credit, Units: sdk.NewDec(5), Holder: addr1,
+		RetirementInfo: info, Timestamp: startDate}}
+
+	res, err := querier(ctx, []string{QueryRetirement}, abci.RequestQuery{Data: ModuleCdc.MustMarshalJSON(QueryRetirementParams{ID: id})})
+	require.NoError(t, err)
+	require.Contains(t, string(res), id.String())
+	var certificate RetirementCertificate
+	require.NoError(t, ModuleCdc.UnmarshalJSON(res, &certificate))
+	require.Equal(t, expected, certificate)
+
+	res, err = querier(ctx, []string{QueryRetirementsByHolder}, abci.RequestQuery{Data: ModuleCdc.MustMarshalJSON(QueryHolderParams{Holder: addr1})})
+	require.NoError(t, err)
+	var certificates []RetirementCertificate
+	require.NoError(t, ModuleCdc.UnmarshalJSON(res, &certificates))
+	require.Equal(t, []RetirementCertificate{expected}, certificates)
+
+	res, err = querier(ctx, []string{QueryRetirementsByCredit}, abci.RequestQuery{Data: ModuleCdc.MustMarshalJSON(QueryCreditParams{Credit: credit})})
+	require.NoError(t, err)
+	require.NoError(t, ModuleCdc.UnmarshalJSON(res, &certificates))
+	require.Equal(t, []RetirementCertificate{expected}, certificates)
+
+	// holders without retirements get an empty list rather than null
+	res, err = querier(ctx, []string{QueryRetirementsByHolder}, abci.RequestQuery{Data: ModuleCdc.MustMarshalJSON(QueryHolderParams{Holder: addr2})})
+	require.NoError(t, err)
+	require.NoError(t, ModuleCdc.UnmarshalJSON(res, &certificates))
+	require.Empty(t, certificates)
+
+	_, err = querier(ctx, []string{QueryRetirement}, abci.RequestQuery{Data: ModuleCdc.MustMarshalJSON(QueryRetirementParams{ID: RetirementID("unknown")})})
+	require.Error(t, err)
+}
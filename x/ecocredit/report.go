@@ -0,0 +1,318 @@
+package ecocredit
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/csv"
+	"encoding/hex"
+	"fmt"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"io"
+	"sort"
+	"strings"
+	"time"
+)
+
+// SerialPrefix is the prefix of the registry serial numbers of credits, see CreditSerial
+const SerialPrefix = "ECO"
+
+// unitSerialDigits is the minimum number of digits of the unit part of a unit serial number
+const unitSerialDigits = 12
+
+// Issuance records the issuances of a credit to its holders and when the credit was issued
+type Issuance struct {
+	Credit    CreditID         `json:"credit"`
+	Issuances []CreditIssuance `json:"issuances"`
+	Timestamp time.Time        `json:"timestamp"`
+}
+
+func (i Issuance) ID() []byte {
+	return i.Credit
+}
+
+type TransferID []byte
+
+// CreditTransfer records liquid units of a credit moved from one holding to another, including the escrow holdings
+// of the module. Transfers are never modified once created
+type CreditTransfer struct {
+	Credit    CreditID       `json:"credit"`
+	From      sdk.AccAddress `json:"from"`
+	To        sdk.AccAddress `json:"to"`
+	Units     sdk.Dec        `json:"units"`
+	Timestamp time.Time      `json:"timestamp"`
+}
+
+// TransferRecord pairs a transfer with its ID
+type TransferRecord struct {
+	ID       TransferID     `json:"id"`
+	Transfer CreditTransfer `json:"transfer"`
+}
+
+// Report lists the issuances, transfers and retirements of the credits of a class within a time range in the form
+// filed with traditional registries. From and To bound the half-open range [From, To), a zero time leaves the range
+// open on that side. Entries are ordered by time and then by credit
+type Report struct {
+	CreditClass CreditClassID      `json:"credit_class"`
+	From        time.Time          `json:"from"`
+	To          time.Time          `json:"to"`
+	Issuances   []ReportIssuance   `json:"issuances"`
+	Transfers   []ReportTransfer   `json:"transfers"`
+	Retirements []ReportRetirement `json:"retirements"`
+}
+
+// ReportIssuance is an issuance of units of a credit to a holder. Units converted into a credit by a split or merge
+// are reported as derived issuances. FirstSerial and LastSerial are the serial numbers of the first and last unit
+// issued, see UnitSerial
+type ReportIssuance struct {
+	Credit      CreditID       `json:"credit"`
+	Serial      string         `json:"serial"`
+	Issuer      sdk.AccAddress `json:"issuer"`
+	Holder      sdk.AccAddress `json:"holder"`
+	Units       sdk.Dec        `json:"units"`
+	FirstSerial string         `json:"first_serial"`
+	LastSerial  string         `json:"last_serial"`
+	Derived     bool           `json:"derived"`
+	Timestamp   time.Time      `json:"timestamp"`
+}
+
+// ReportTransfer is a transfer of units of a credit
+type ReportTransfer struct {
+	Credit    CreditID       `json:"credit"`
+	Serial    string         `json:"serial"`
+	From      sdk.AccAddress `json:"from"`
+	To        sdk.AccAddress `json:"to"`
+	Units     sdk.Dec        `json:"units"`
+	Timestamp time.Time      `json:"timestamp"`
+}
+
+// ReportRetirement is a retirement certificate of units of a credit
+type ReportRetirement struct {
+	ID             RetirementID   `json:"id"`
+	Credit         CreditID       `json:"credit"`
+	Serial         string         `json:"serial"`
+	Holder         sdk.AccAddress `json:"holder"`
+	Units          sdk.Dec        `json:"units"`
+	RetirementInfo `json:"retirement_info"`
+	Timestamp      time.Time `json:"timestamp"`
+}
+
+// CreditSerial returns the registry serial number of a credit. It is derived from the IDs of its class and of the
+// credit and from its vintage, e.g. ECO-000001-00000012-20190101-20200101 for credit 12 of class 1, so it never
+// changes once the credit is issued
+func CreditSerial(credit CreditID, metadata CreditMetadata) string {
+	return fmt.Sprintf("%s-%s-%s-%s-%s", SerialPrefix, serialNumber(metadata.CreditClass, 6), serialNumber(credit, 8),
+		metadata.StartDate.UTC().Format("20060102"), metadata.EndDate.UTC().Format("20060102"))
+}
+
+// serialNumber formats an auto-generated ID as a zero-padded decimal number and any other ID in upper case hex
+func serialNumber(id []byte, digits int) string {
+	if len(id) != 8 {
+		return strings.ToUpper(hex.EncodeToString(id))
+	}
+	return fmt.Sprintf("%0*d", digits, binary.BigEndian.Uint64(id))
+}
+
+// UnitSerial returns the serial number of the n-th indivisible part of the issued units of the credit with the serial
+// number, counting from 1 in the order of issuance. Units are divisible to the precision of their class, so for a
+// class with a precision of 2 the first unit issued consists of the parts 1 to 100
+func UnitSerial(creditSerial string, n sdk.Int) string {
+	s := n.String()
+	if len(s) < unitSerialDigits {
+		s = strings.Repeat("0", unitSerialDigits-len(s)) + s
+	}
+	return fmt.Sprintf("%s-%s", creditSerial, s)
+}
+
+// Report lists the issuances, transfers and retirements of the credits of the class within the time range [from, to).
+// Serial ranges are numbered over all issuances of a credit, including those outside of the time range
+func (k Keeper) Report(ctx sdk.Context, class CreditClassID, from time.Time, to time.Time) (Report, error) {
+	metadata, found := k.GetCreditClass(ctx, class)
+	if !found {
+		return Report{}, ErrInvalidCreditClass(DefaultCodespace, fmt.Sprintf("credit class %s not found", class))
+	}
+	scale := precisionScale(metadata.Precision)
+	inRange := func(t time.Time) bool {
+		return (from.IsZero() || !t.Before(from)) && (to.IsZero() || t.Before(to))
+	}
+	report := Report{CreditClass: class, From: from, To: to,
+		Issuances: []ReportIssuance{}, Transfers: []ReportTransfer{}, Retirements: []ReportRetirement{}}
+
+	credits := make(map[string]CreditMetadata)
+	issued := make(map[string]sdk.Int)
+	var ids []CreditID
+	k.IterateCreditsByClass(ctx, class, func(id CreditID, metadata CreditMetadata) (stop bool) {
+		credits[string(id)] = metadata
+		issued[string(id)] = sdk.ZeroInt()
+		ids = append(ids, id)
+		return false
+	})
+	// the serial ranges of each credit are assigned in the order of its issuance and of the conversions into it
+	addIssuance := func(credit CreditID, holder sdk.AccAddress, units sdk.Dec, derived bool, timestamp time.Time) {
+		metadata := credits[string(credit)]
+		serial := CreditSerial(credit, metadata)
+		entry := ReportIssuance{Credit: credit, Serial: serial, Issuer: metadata.Issuer, Holder: holder, Units: units,
+			Derived: derived, Timestamp: timestamp}
+		parts := units.MulInt(scale).TruncateInt()
+		if parts.IsPositive() {
+			first := issued[string(credit)].AddRaw(1)
+			issued[string(credit)] = issued[string(credit)].Add(parts)
+			entry.FirstSerial, entry.LastSerial = UnitSerial(serial, first), UnitSerial(serial, issued[string(credit)])
+		}
+		if inRange(timestamp) {
+			report.Issuances = append(report.Issuances, entry)
+		}
+	}
+	for _, id := range ids {
+		if issuance, found := k.GetIssuance(ctx, id); found {
+			for _, i := range issuance.Issuances {
+				addIssuance(id, i.Holder, i.LiquidUnits.Add(i.RetiredUnits), false, issuance.Timestamp)
+			}
+		}
+	}
+	k.IterateConversions(ctx, func(id ConversionID, conversion Conversion) (stop bool) {
+		for _, result := range conversion.Results {
+			if _, found := credits[string(result.Credit)]; found {
+				addIssuance(result.Credit, conversion.Holder, result.Units, true, conversion.Timestamp)
+			}
+		}
+		return false
+	})
+
+	for _, id := range ids {
+		serial := CreditSerial(id, credits[string(id)])
+		k.IterateTransfersByCredit(ctx, id, func(_ TransferID, transfer CreditTransfer) (stop bool) {
+			if inRange(transfer.Timestamp) {
+				report.Transfers = append(report.Transfers, ReportTransfer{Credit: id, Serial: serial, From: transfer.From,
+					To: transfer.To, Units: transfer.Units, Timestamp: transfer.Timestamp})
+			}
+			return false
+		})
+		k.IterateRetirementsByCredit(ctx, id, func(retirementID RetirementID, retirement Retirement) (stop bool) {
+			if inRange(retirement.Timestamp) {
+				report.Retirements = append(report.Retirements, ReportRetirement{ID: retirementID, Credit: id, Serial: serial,
+					Holder: retirement.Holder, Units: retirement.Units, RetirementInfo: retirement.RetirementInfo,
+					Timestamp: retirement.Timestamp})
+			}
+			return false
+		})
+	}
+	sort.SliceStable(report.Issuances, func(i, j int) bool {
+		return reportedBefore(report.Issuances[i].Timestamp, report.Issuances[i].Credit, report.Issuances[j].Timestamp, report.Issuances[j].Credit)
+	})
+	sort.SliceStable(report.Transfers, func(i, j int) bool {
+		return reportedBefore(report.Transfers[i].Timestamp, report.Transfers[i].Credit, report.Transfers[j].Timestamp, report.Transfers[j].Credit)
+	})
+	sort.SliceStable(report.Retirements, func(i, j int) bool {
+		return reportedBefore(report.Retirements[i].Timestamp, report.Retirements[i].Credit, report.Retirements[j].Timestamp, report.Retirements[j].Credit)
+	})
+	return report, nil
+}
+
+// reportedBefore orders report entries by time and then by credit
+func reportedBefore(t1 time.Time, credit1 CreditID, t2 time.Time, credit2 CreditID) bool {
+	if !t1.Equal(t2) {
+		return t1.Before(t2)
+	}
+	return bytes.Compare(credit1, credit2) < 0
+}
+
+// WriteCSV writes the report as a single CSV table with a header row. Every row is an issuance, derived issuance,
+// transfer or retirement, for issuances the issuer is in the from column and the holder in the to column
+func (r Report) WriteCSV(w io.Writer) error {
+	out := csv.NewWriter(w)
+	rows := [][]string{{"type", "timestamp", "credit", "serial", "first_serial", "last_serial", "from", "to", "units",
+		"retirement", "beneficiary", "jurisdiction", "reason"}}
+	timestamp := func(t time.Time) string {
+		return t.UTC().Format(time.RFC3339)
+	}
+	for _, i := range r.Issuances {
+		kind := "issuance"
+		if i.Derived {
+			kind = "derived-issuance"
+		}
+		rows = append(rows, []string{kind, timestamp(i.Timestamp), i.Credit.String(), i.Serial, i.FirstSerial,
+			i.LastSerial, i.Issuer.String(), i.Holder.String(), i.Units.String(), "", "", "", ""})
+	}
+	for _, t := range r.Transfers {
+		rows = append(rows, []string{"transfer", timestamp(t.Timestamp), t.Credit.String(), t.Serial, "", "",
+			t.From.String(), t.To.String(), t.Units.String(), "", "", "", ""})
+	}
+	for _, rt := range r.Retirements {
+		rows = append(rows, []string{"retirement", timestamp(rt.Timestamp), rt.Credit.String(), rt.Serial, "", "",
+			rt.Holder.String(), "", rt.Units.String(), rt.ID.String(), rt.Beneficiary, rt.Jurisdiction, rt.Reason})
+	}
+	if err := out.WriteAll(rows); err != nil {
+		return err
+	}
+	return out.Error()
+}
+
+// GetIssuance gets the issuance record of a credit, which is only found for credits issued by an issuer of their
+// class on this chain
+func (k Keeper) GetIssuance(ctx sdk.Context, credit CreditID) (issuance Issuance, found bool) {
+	issuance = Issuance{Credit: credit}
+	err := k.issuanceBucket.GetOne(ctx, &issuance)
+	if err != nil {
+		return issuance, false
+	}
+	return issuance, true
+}
+
+// IterateIssuances iterates over the issuance records of all credits
+func (k Keeper) IterateIssuances(ctx sdk.Context, callback func(issuance Issuance) (stop bool)) {
+	iterator, err := k.issuanceBucket.PrefixScan(ctx, nil, nil, false)
+	if err != nil {
+		return
+	}
+	defer iterator.Release()
+	for {
+		var issuance Issuance
+		_, err := iterator.LoadNext(&issuance)
+		if err != nil {
+			break
+		}
+		if callback(issuance) {
+			return
+		}
+	}
+}
+
+// IterateTransfers iterates over all transfers in the order they were made
+func (k Keeper) IterateTransfers(ctx sdk.Context, callback func(id TransferID, transfer CreditTransfer) (stop bool)) {
+	iterator, err := k.transferBucket.PrefixScan(ctx, nil, nil, false)
+	if err != nil {
+		return
+	}
+	defer iterator.Release()
+	for {
+		var transfer CreditTransfer
+		id, err := iterator.LoadNext(&transfer)
+		if err != nil {
+			break
+		}
+		if callback(id, transfer) {
+			return
+		}
+	}
+}
+
+// IterateTransfersByCredit iterates over all transfers of units of the credit
+func (k Keeper) IterateTransfersByCredit(ctx sdk.Context, credit CreditID, callback func(id TransferID, transfer CreditTransfer) (stop bool)) {
+	iterator, err := k.transferBucket.ByIndex(ctx, IndexByCredit, credit)
+	if iterator != nil {
+		defer iterator.Release()
+	}
+	if err != nil {
+		return
+	}
+	for {
+		var transfer CreditTransfer
+		id, err := iterator.LoadNext(&transfer)
+		if err != nil {
+			break
+		}
+		if callback(id, transfer) {
+			return
+		}
+	}
+}
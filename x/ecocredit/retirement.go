@@ -0,0 +1,118 @@
+package ecocredit
+
+import (
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"time"
+)
+
+// RetirementInfo describes on whose behalf and for what purpose credit units are retired
+type RetirementInfo struct {
+	// Beneficiary is the name of the entity claiming the offset, which may differ from the retiring account
+	Beneficiary string `json:"beneficiary"`
+	// Jurisdiction is the jurisdiction in which the offset is claimed, e.g. an ISO 3166 country or region code
+	Jurisdiction string `json:"jurisdiction"`
+	// Reason is a free-form description of why the units are retired
+	Reason string `json:"reason"`
+}
+
+func (info RetirementInfo) validate() sdk.Error {
+	if len(info.Beneficiary) > MaxRetirementInfoLength || len(info.Jurisdiction) > MaxRetirementInfoLength ||
+		len(info.Reason) > MaxRetirementInfoLength {
+		return ErrInvalidRetirementInfo(DefaultCodespace)
+	}
+	return nil
+}
+
+type RetirementID []byte
+
+// Retirement is a retirement certificate recording that units of a credit were burned (retired) by the holder on
+// behalf of a beneficiary. Certificates are never modified once created, so their ID can be given to auditors as
+// proof of an offset claim
+type Retirement struct {
+	Credit         CreditID       `json:"credit"`
+	Units          sdk.Dec        `json:"units"`
+	Holder         sdk.AccAddress `json:"holder"`
+	RetirementInfo `json:"retirement_info"`
+	Timestamp      time.Time `json:"timestamp"`
+}
+
+// RetirementCertificate pairs a retirement with its ID
+type RetirementCertificate struct {
+	ID         RetirementID `json:"id"`
+	Retirement Retirement   `json:"retirement"`
+}
+
+// createRetirement records a retirement certificate
+func (k Keeper) createRetirement(ctx sdk.Context, retirement Retirement) (RetirementID, error) {
+	id, err := k.retirementBucket.Create(ctx, retirement)
+	if err != nil {
+		return nil, err
+	}
+	ctx.EventManager().EmitEvent(sdk.NewEvent(
+		EventTypeRetireCredit,
+		sdk.NewAttribute(AttributeKeyRetirement, RetirementID(id).String()),
+		sdk.NewAttribute(AttributeKeyCredit, retirement.Credit.String()),
+		sdk.NewAttribute(AttributeKeyHolder, retirement.Holder.String()),
+		sdk.NewAttribute(AttributeKeyUnits, retirement.Units.String()),
+		sdk.NewAttribute(AttributeKeyBeneficiary, retirement.Beneficiary),
+	))
+	return id, nil
+}
+
+// GetRetirement gets a retirement certificate by its ID
+func (k Keeper) GetRetirement(ctx sdk.Context, id RetirementID) (retirement Retirement, found bool) {
+	err := k.retirementBucket.GetOne(ctx, id, &retirement)
+	if err != nil {
+		return retirement, false
+	}
+	return retirement, true
+}
+
+// IterateRetirements iterates over all retirement certificates in the order they were created
+func (k Keeper) IterateRetirements(ctx sdk.Context, callback func(id RetirementID, retirement Retirement) (stop bool)) {
+	iterator, err := k.retirementBucket.PrefixScan(ctx, nil, nil, false)
+	if err != nil {
+		return
+	}
+	defer iterator.Release()
+	for {
+		var retirement Retirement
+		id, err := iterator.LoadNext(&retirement)
+		if err != nil {
+			break
+		}
+		if callback(id, retirement) {
+			return
+		}
+	}
+}
+
+// IterateRetirementsByHolder iterates over all retirement certificates of units retired by the holder
+func (k Keeper) IterateRetirementsByHolder(ctx sdk.Context, holder sdk.AccAddress, callback func(id RetirementID, retirement Retirement) (stop bool)) {
+	k.iterateRetirements(ctx, IndexByHolder, holder, callback)
+}
+
+// IterateRetirementsByCredit iterates over all retirement certificates of units retired of the credit
+func (k Keeper) IterateRetirementsByCredit(ctx sdk.Context, credit CreditID, callback func(id RetirementID, retirement Retirement) (stop bool)) {
+	k.iterateRetirements(ctx, IndexByCredit, credit, callback)
+}
+
+func (k Keeper) iterateRetirements(ctx sdk.Context, index string, key []byte, callback func(id RetirementID, retirement Retirement) (stop bool)) {
+	iterator, err := k.retirementBucket.ByIndex(ctx, index, key)
+	if iterator != nil {
+		defer iterator.Release()
+	}
+	if err != nil {
+		return
+	}
+	for {
+		var retirement Retirement
+		id, err := iterator.LoadNext(&retirement)
+		if err != nil {
+			break
+		}
+		if callback(id, retirement) {
+			return
+		}
+	}
+}
@@ -0,0 +1,272 @@
+package ecocredit
+
+import (
+	"bytes"
+	"fmt"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/x/supply"
+	"time"
+)
+
+// BufferPoolAddress holds the units withheld at issuance in the buffer pools of all credit classes. The buffer pool
+// of a class consists of the holdings of this address of the credits of the class
+var BufferPoolAddress = supply.NewModuleAddress("ecocredit-buffer-pool")
+
+// MaxReversalReasonLength is the maximum length of the reason of a reversal
+const MaxReversalReasonLength = 256
+
+type ReversalID []byte
+
+// CreditCancellation records liquid units of a credit cancelled from one holding by a reversal
+type CreditCancellation struct {
+	Credit CreditID       `json:"credit"`
+	Holder sdk.AccAddress `json:"holder"`
+	Units  sdk.Dec        `json:"units"`
+}
+
+// Reversal records the invalidation of units of a credit whose ecosystem service was reversed, e.g. forest carbon
+// released by a fire. The units are cancelled from the buffer pool of the class first and the rest pro rata from
+// the holders of the credit, each cancellation is listed. Reversals are never modified once created
+type Reversal struct {
+	Credit        CreditID             `json:"credit"`
+	Units         sdk.Dec              `json:"units"`
+	Reverser      sdk.AccAddress       `json:"reverser"`
+	Reason        string               `json:"reason"`
+	Cancellations []CreditCancellation `json:"cancellations"`
+	Timestamp     time.Time            `json:"timestamp"`
+}
+
+// ReversalRecord pairs a reversal with its ID
+type ReversalRecord struct {
+	ID       ReversalID `json:"id"`
+	Reversal Reversal   `json:"reversal"`
+}
+
+// bufferUnits returns the part of liquid units withheld in the buffer pool of the class, rounded down to its precision
+func (m CreditClassMetadata) bufferUnits(units sdk.Dec) sdk.Dec {
+	if m.BufferRate.IsNil() || m.BufferRate.IsZero() {
+		return sdk.ZeroDec()
+	}
+	scaled := units.Mul(m.BufferRate).MulInt(precisionScale(m.Precision)).TruncateInt()
+	return sdk.NewDecFromIntWithPrec(scaled, int64(m.Precision))
+}
+
+// ReverseCredit invalidates units of a credit whose ecosystem service was reversed. Only the issuer of the credit or
+// the designer of its class can reverse it. The liquid units are cancelled from the buffer pool of the class first,
+// starting with the buffer of the credit itself, and the rest pro rata from the liquid holdings of the credit. Units
+// held in escrow for sell orders, auctions and wrapped coins are never cancelled as they back open offers and coins.
+// Vouchers of credits of other chains can only be reversed on their origin chain. The cancelled units move from the
+// liquid to the reversed supply of their credit and the reversal is recorded, its ID is returned
+func (k Keeper) ReverseCredit(ctx sdk.Context, credit CreditID, reverser sdk.AccAddress, units sdk.Dec, reason string) (ReversalID, error) {
+	metadata, found := k.GetCredit(ctx, credit)
+	if !found {
+		return nil, ErrInvalidCredit(DefaultCodespace, fmt.Sprintf("credit %s not found", credit))
+	}
+	if _, found := k.GetCreditVoucher(ctx, credit); found {
+		return nil, ErrInvalidReversal(DefaultCodespace, "vouchers can only be reversed on the origin chain of the credit")
+	}
+	class, found := k.GetCreditClass(ctx, metadata.CreditClass)
+	if !found {
+		return nil, ErrInvalidCreditClass(DefaultCodespace, fmt.Sprintf("credit class %s not found", metadata.CreditClass))
+	}
+	if !reverser.Equals(metadata.Issuer) && !reverser.Equals(class.Designer) {
+		return nil, sdk.ErrUnauthorized("only the issuer of the credit or the designer of its class can reverse it")
+	}
+	if err := class.CheckPrecision(units); err != nil {
+		return nil, err
+	}
+	cancellations := k.bufferCancellations(ctx, credit, metadata.CreditClass, units)
+	remaining := units
+	for _, cancellation := range cancellations {
+		remaining = remaining.Sub(cancellation.Units)
+	}
+	if remaining.IsPositive() {
+		holderCancellations, err := k.holderCancellations(ctx, credit, class.Precision, remaining)
+		if err != nil {
+			return nil, err
+		}
+		cancellations = append(cancellations, holderCancellations...)
+	}
+	for _, cancellation := range cancellations {
+		if err := k.cancelCredit(ctx, cancellation); err != nil {
+			return nil, err
+		}
+	}
+	id, err := k.reversalBucket.Create(ctx, Reversal{
+		Credit:        credit,
+		Units:         units,
+		Reverser:      reverser,
+		Reason:        reason,
+		Cancellations: cancellations,
+		Timestamp:     ctx.BlockHeader().Time,
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, cancellation := range cancellations {
+		ctx.EventManager().EmitEvent(sdk.NewEvent(
+			EventTypeCancelCredit,
+			sdk.NewAttribute(AttributeKeyReversal, ReversalID(id).String()),
+			sdk.NewAttribute(AttributeKeyCredit, cancellation.Credit.String()),
+			sdk.NewAttribute(AttributeKeyHolder, cancellation.Holder.String()),
+			sdk.NewAttribute(AttributeKeyUnits, cancellation.Units.String()),
+		))
+	}
+	ctx.EventManager().EmitEvent(sdk.NewEvent(
+		EventTypeReverseCredit,
+		sdk.NewAttribute(AttributeKeyReversal, ReversalID(id).String()),
+		sdk.NewAttribute(AttributeKeyCredit, credit.String()),
+		sdk.NewAttribute(AttributeKeyUnits, units.String()),
+		sdk.NewAttribute(AttributeKeyReason, reason),
+	))
+	return id, nil
+}
+
+// bufferCancellations takes up to units from the buffer pool of the class, first from the buffer of the credit and
+// then from the buffers of the other credits of the class in the order of the holdings
+func (k Keeper) bufferCancellations(ctx sdk.Context, credit CreditID, class CreditClassID, units sdk.Dec) []CreditCancellation {
+	var buffers []CreditHolding
+	k.IterateBufferPool(ctx, class, func(holding CreditHolding) (stop bool) {
+		if bytes.Equal(holding.Credit, credit) {
+			buffers = append([]CreditHolding{holding}, buffers...)
+		} else {
+			buffers = append(buffers, holding)
+		}
+		return false
+	})
+	var cancellations []CreditCancellation
+	remaining := units
+	for _, buffer := range buffers {
+		if !remaining.IsPositive() {
+			break
+		}
+		if !buffer.LiquidUnits.IsPositive() {
+			continue
+		}
+		cancelled := sdk.MinDec(buffer.LiquidUnits, remaining)
+		cancellations = append(cancellations, CreditCancellation{Credit: buffer.Credit, Holder: BufferPoolAddress, Units: cancelled})
+		remaining = remaining.Sub(cancelled)
+	}
+	return cancellations
+}
+
+// holderCancellations splits units pro rata over the liquid holdings of the credit outside of escrow. Shares are
+// rounded down to the precision of the class and the indivisible parts left over go one each to the holders in the
+// order of the holdings
+func (k Keeper) holderCancellations(ctx sdk.Context, credit CreditID, precision uint32, units sdk.Dec) ([]CreditCancellation, error) {
+	scale := precisionScale(precision)
+	var holdings []CreditHolding
+	total := sdk.ZeroInt()
+	k.IterateHoldersOfCredit(ctx, credit, func(holding CreditHolding) (stop bool) {
+		if k.isEscrow(ctx, holding.Holder) || !holding.LiquidUnits.IsPositive() {
+			return false
+		}
+		holdings = append(holdings, holding)
+		total = total.Add(holding.LiquidUnits.MulInt(scale).TruncateInt())
+		return false
+	})
+	remaining := units.MulInt(scale).TruncateInt()
+	if total.LT(remaining) {
+		return nil, ErrInvalidReversal(DefaultCodespace, fmt.Sprintf("only %s liquid units of credit %s can be reversed",
+			sdk.NewDecFromIntWithPrec(total, int64(precision)), credit))
+	}
+	shares := make([]sdk.Int, len(holdings))
+	leftover := remaining
+	for i, holding := range holdings {
+		shares[i] = remaining.Mul(holding.LiquidUnits.MulInt(scale).TruncateInt()).Quo(total)
+		leftover = leftover.Sub(shares[i])
+	}
+	for i, holding := range holdings {
+		if !leftover.IsPositive() {
+			break
+		}
+		if shares[i].LT(holding.LiquidUnits.MulInt(scale).TruncateInt()) {
+			shares[i] = shares[i].AddRaw(1)
+			leftover = leftover.SubRaw(1)
+		}
+	}
+	var cancellations []CreditCancellation
+	for i, holding := range holdings {
+		if shares[i].IsPositive() {
+			cancellations = append(cancellations, CreditCancellation{Credit: credit, Holder: holding.Holder,
+				Units: sdk.NewDecFromIntWithPrec(shares[i], int64(precision))})
+		}
+	}
+	return cancellations, nil
+}
+
+// cancelCredit removes the cancelled liquid units from the holding and moves them to the reversed supply
+func (k Keeper) cancelCredit(ctx sdk.Context, cancellation CreditCancellation) error {
+	holding, found := k.GetCreditHolding(ctx, cancellation.Credit, cancellation.Holder)
+	if !found {
+		return ErrInvalidCredit(DefaultCodespace, fmt.Sprintf("%s holds no units of credit %s", cancellation.Holder, cancellation.Credit))
+	}
+	holding.LiquidUnits = holding.LiquidUnits.Sub(cancellation.Units)
+	if holding.LiquidUnits.IsNegative() {
+		return fmt.Errorf("not enough units")
+	}
+	if err := k.creditHoldingsBucket.Save(ctx, holding); err != nil {
+		return err
+	}
+	return k.reverseCreditSupply(ctx, cancellation.Credit, cancellation.Units)
+}
+
+// IterateBufferPool iterates over the holdings of the buffer pool of the credit class
+func (k Keeper) IterateBufferPool(ctx sdk.Context, class CreditClassID, callback func(holding CreditHolding) (stop bool)) {
+	k.IterateHoldingsByHolder(ctx, BufferPoolAddress, func(holding CreditHolding) (stop bool) {
+		metadata, found := k.GetCredit(ctx, holding.Credit)
+		if !found || !bytes.Equal(metadata.CreditClass, class) {
+			return false
+		}
+		return callback(holding)
+	})
+}
+
+// GetReversal gets a reversal by its ID
+func (k Keeper) GetReversal(ctx sdk.Context, id ReversalID) (reversal Reversal, found bool) {
+	err := k.reversalBucket.GetOne(ctx, id, &reversal)
+	if err != nil {
+		return reversal, false
+	}
+	return reversal, true
+}
+
+// IterateReversals iterates over all reversals in the order they were created
+func (k Keeper) IterateReversals(ctx sdk.Context, callback func(id ReversalID, reversal Reversal) (stop bool)) {
+	iterator, err := k.reversalBucket.PrefixScan(ctx, nil, nil, false)
+	if err != nil {
+		return
+	}
+	defer iterator.Release()
+	for {
+		var reversal Reversal
+		id, err := iterator.LoadNext(&reversal)
+		if err != nil {
+			break
+		}
+		if callback(id, reversal) {
+			return
+		}
+	}
+}
+
+// IterateReversalsByCredit iterates over all reversals of the credit
+func (k Keeper) IterateReversalsByCredit(ctx sdk.Context, credit CreditID, callback func(id ReversalID, reversal Reversal) (stop bool)) {
+	iterator, err := k.reversalBucket.ByIndex(ctx, IndexByCredit, credit)
+	if iterator != nil {
+		defer iterator.Release()
+	}
+	if err != nil {
+		return
+	}
+	for {
+		var reversal Reversal
+		id, err := iterator.LoadNext(&reversal)
+		if err != nil {
+			break
+		}
+		if callback(id, reversal) {
+			return
+		}
+	}
+}
@@ -0,0 +1,112 @@
+package ecocredit
+
+import (
+	"fmt"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+// CreditSupply tracks the outstanding units of a credit across all holders. Issued is the total number of units ever
+// issued, Liquid the units which can still be transferred, Retired the units which were burned, at issuance or
+// afterwards, Reversed the liquid units cancelled by reversals and Expired the liquid units which expired at the end of
+// the validity period of the credit. Converted are the liquid units converted into derived credits by splits and
+// merges, which are issued as units of the derived credits. Sends move units between holders and leave the supply
+// unchanged
+type CreditSupply struct {
+	Credit    CreditID `json:"credit"`
+	Issued    sdk.Dec  `json:"issued"`
+	Liquid    sdk.Dec  `json:"liquid"`
+	Retired   sdk.Dec  `json:"retired"`
+	Reversed  sdk.Dec  `json:"reversed"`
+	Expired   sdk.Dec  `json:"expired"`
+	Converted sdk.Dec  `json:"converted"`
+}
+
+func (s CreditSupply) ID() []byte {
+	return s.Credit
+}
+
+// GetCreditSupply gets the supply of a credit
+func (k Keeper) GetCreditSupply(ctx sdk.Context, credit CreditID) (supply CreditSupply, found bool) {
+	supply = CreditSupply{Credit: credit}
+	err := k.creditSupplyBucket.GetOne(ctx, &supply)
+	if err != nil {
+		return supply, false
+	}
+	return supply, true
+}
+
+// IterateCreditSupplies iterates over the supplies of all credits
+func (k Keeper) IterateCreditSupplies(ctx sdk.Context, callback func(supply CreditSupply) (stop bool)) {
+	iterator, err := k.creditSupplyBucket.PrefixScan(ctx, nil, nil, false)
+	if err != nil {
+		return
+	}
+	defer iterator.Release()
+	for {
+		var supply CreditSupply
+		_, err := iterator.LoadNext(&supply)
+		if err != nil {
+			break
+		}
+		if callback(supply) {
+			return
+		}
+	}
+}
+
+// addCreditSupply adds newly issued liquid and retired units to the supply of a credit
+func (k Keeper) addCreditSupply(ctx sdk.Context, credit CreditID, liquid sdk.Dec, retired sdk.Dec) error {
+	supply, found := k.GetCreditSupply(ctx, credit)
+	if !found {
+		supply = CreditSupply{Credit: credit, Issued: sdk.ZeroDec(), Liquid: sdk.ZeroDec(), Retired: sdk.ZeroDec(),
+			Reversed: sdk.ZeroDec(), Expired: sdk.ZeroDec(), Converted: sdk.ZeroDec()}
+	}
+	supply.Issued = supply.Issued.Add(liquid).Add(retired)
+	supply.Liquid = supply.Liquid.Add(liquid)
+	supply.Retired = supply.Retired.Add(retired)
+	return k.creditSupplyBucket.Save(ctx, supply)
+}
+
+// retireCreditSupply moves burned units of a credit from its liquid to its retired supply
+func (k Keeper) retireCreditSupply(ctx sdk.Context, credit CreditID, units sdk.Dec) error {
+	supply, found := k.GetCreditSupply(ctx, credit)
+	if !found {
+		return ErrInvalidCredit(DefaultCodespace, fmt.Sprintf("no supply of credit %s", credit))
+	}
+	supply.Liquid = supply.Liquid.Sub(units)
+	supply.Retired = supply.Retired.Add(units)
+	return k.creditSupplyBucket.Save(ctx, supply)
+}
+
+// reverseCreditSupply moves cancelled units of a credit from its liquid to its reversed supply
+func (k Keeper) reverseCreditSupply(ctx sdk.Context, credit CreditID, units sdk.Dec) error {
+	supply, found := k.GetCreditSupply(ctx, credit)
+	if !found {
+		return ErrInvalidCredit(DefaultCodespace, fmt.Sprintf("no supply of credit %s", credit))
+	}
+	supply.Liquid = supply.Liquid.Sub(units)
+	supply.Reversed = supply.Reversed.Add(units)
+	return k.creditSupplyBucket.Save(ctx, supply)
+}
+
+// expireCreditSupply moves expired units of a credit from its liquid to its expired supply
+func (k Keeper) expireCreditSupply(ctx sdk.Context, credit CreditID, units sdk.Dec) error {
+	supply, found := k.GetCreditSupply(ctx, credit)
+	if !found {
+		return ErrInvalidCredit(DefaultCodespace, fmt.Sprintf("no supply of credit %s", credit))
+	}
+	supply.Liquid = supply.Liquid.Sub(units)
+	supply.Expired = supply.Expired.Add(units)
+	return k.creditSupplyBucket.Save(ctx, supply)
+}
+
+// convertCreditSupply moves units of a credit converted into derived credits from its liquid to its converted supply
+func (k Keeper) convertCreditSupply(ctx sdk.Context, credit CreditID, units sdk.Dec) error {
+	supply, found := k.GetCreditSupply(ctx, credit)
+	if !found {
+		return ErrInvalidCredit(DefaultCodespace, fmt.Sprintf("no supply of credit %s", credit))
+	}
+	supply.Liquid = supply.Liquid.Sub(units)
+	supply.Converted = supply.Converted.Add(units)
+	return k.creditSupplyBucket.Save(ctx, supply)
+}
@@ -0,0 +1,112 @@
+package ecocredit
+
+import (
+	"encoding/hex"
+	"fmt"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/x/supply"
+	"math/big"
+	"strings"
+)
+
+// WrappedDenomPrefix is the prefix of the bank denominations of wrapped credits
+const WrappedDenomPrefix = "eco/"
+
+// WrapEscrowAddress is the address of the module account which holds the units locked by wrapping in the credit
+// holdings bucket. The supply of the wrapped denom of each credit always equals the units it holds times the wrap
+// ratio of the credit
+var WrapEscrowAddress = supply.NewModuleAddress(ModuleName)
+
+// WrappedDenom returns the bank denomination of the wrapped units of a credit
+func WrappedDenom(credit CreditID) string {
+	return fmt.Sprintf("%s%x", WrappedDenomPrefix, credit)
+}
+
+// CreditFromWrappedDenom returns the credit whose wrapped units have the bank denomination
+func CreditFromWrappedDenom(denom string) (CreditID, error) {
+	if !strings.HasPrefix(denom, WrappedDenomPrefix) {
+		return nil, fmt.Errorf("%s is not a wrapped credit denomination", denom)
+	}
+	return hex.DecodeString(strings.TrimPrefix(denom, WrappedDenomPrefix))
+}
+
+// wrapRatio returns the number of wrapped coins per unit of a credit, which is fixed by the precision of its class so
+// that the smallest fraction of a unit is worth exactly one coin
+func (k Keeper) wrapRatio(ctx sdk.Context, credit CreditID) (sdk.Int, error) {
+	metadata, found := k.GetCredit(ctx, credit)
+	if !found {
+		return sdk.Int{}, ErrInvalidCredit(DefaultCodespace, fmt.Sprintf("credit %s not found", credit))
+	}
+	class, found := k.GetCreditClass(ctx, metadata.CreditClass)
+	if !found {
+		return sdk.Int{}, ErrInvalidCreditClass(DefaultCodespace, fmt.Sprintf("credit class %s not found", metadata.CreditClass))
+	}
+	ratio := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(class.Precision)), nil)
+	return sdk.NewIntFromBigInt(ratio), nil
+}
+
+// wrappedCoins returns the wrapped coins worth units of a credit
+func (k Keeper) wrappedCoins(ctx sdk.Context, credit CreditID, units sdk.Dec) (sdk.Coins, error) {
+	if err := k.checkPrecision(ctx, credit, units); err != nil {
+		return nil, err
+	}
+	ratio, err := k.wrapRatio(ctx, credit)
+	if err != nil {
+		return nil, err
+	}
+	return sdk.NewCoins(sdk.NewCoin(WrappedDenom(credit), units.MulInt(ratio).TruncateInt())), nil
+}
+
+// WrapCredit locks units of a credit held by the holder and mints the holder the corresponding wrapped coins, which
+// can be used with the bank and any other module handling coins. Units of expired credits can't be wrapped
+func (k Keeper) WrapCredit(ctx sdk.Context, holder sdk.AccAddress, credit CreditID, units sdk.Dec) (sdk.Coins, error) {
+	if err := k.checkCreditNotExpired(ctx, credit); err != nil {
+		return nil, err
+	}
+	coins, err := k.wrappedCoins(ctx, credit, units)
+	if err != nil {
+		return nil, err
+	}
+	if err := k.transferCredit(ctx, credit, holder, WrapEscrowAddress, units); err != nil {
+		return nil, err
+	}
+	if err := k.supplyKeeper.MintCoins(ctx, ModuleName, coins); err != nil {
+		return nil, err
+	}
+	if err := k.supplyKeeper.SendCoinsFromModuleToAccount(ctx, ModuleName, holder, coins); err != nil {
+		return nil, err
+	}
+	ctx.EventManager().EmitEvent(sdk.NewEvent(
+		EventTypeWrapCredit,
+		sdk.NewAttribute(AttributeKeyCredit, credit.String()),
+		sdk.NewAttribute(AttributeKeyHolder, holder.String()),
+		sdk.NewAttribute(AttributeKeyUnits, units.String()),
+		sdk.NewAttribute(AttributeKeyAmount, coins.String()),
+	))
+	return coins, nil
+}
+
+// UnwrapCredit burns the wrapped coins of units of a credit held by the holder and releases the locked units to it
+func (k Keeper) UnwrapCredit(ctx sdk.Context, holder sdk.AccAddress, credit CreditID, units sdk.Dec) error {
+	coins, err := k.wrappedCoins(ctx, credit, units)
+	if err != nil {
+		return err
+	}
+	if err := k.supplyKeeper.SendCoinsFromAccountToModule(ctx, holder, ModuleName, coins); err != nil {
+		return err
+	}
+	if err := k.supplyKeeper.BurnCoins(ctx, ModuleName, coins); err != nil {
+		return err
+	}
+	if err := k.transferCredit(ctx, credit, WrapEscrowAddress, holder, units); err != nil {
+		return err
+	}
+	ctx.EventManager().EmitEvent(sdk.NewEvent(
+		EventTypeUnwrapCredit,
+		sdk.NewAttribute(AttributeKeyCredit, credit.String()),
+		sdk.NewAttribute(AttributeKeyHolder, holder.String()),
+		sdk.NewAttribute(AttributeKeyUnits, units.String()),
+		sdk.NewAttribute(AttributeKeyAmount, coins.String()),
+	))
+	return nil
+}
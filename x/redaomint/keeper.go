@@ -90,8 +90,8 @@ func NewKeeper(cdc *codec.Codec, storeKey sdk.StoreKey, accountKeeper auth.Accou
 		}),
 		proposalBucket: orm.NewAutoIDBucket(storeKey, "proposal", cdc, nil, nil),
 		votesBucket: orm.NewNaturalKeyBucket(storeKey, "votes", cdc, []orm.Index{
-			{IndexByProposal,
-				func(key []byte, value interface{}) (indexValue []byte, err error) {
+			{Name: IndexByProposal,
+				Indexer: func(key []byte, value interface{}) (indexValue []byte, err error) {
 					vote := value.(Vote)
 					return vote.Proposal, nil
 				},
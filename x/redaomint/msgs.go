@@ -3,6 +3,7 @@ package redaomint
 import (
 	"fmt"
 	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/gaia/geo"
 	"github.com/cosmos/gaia/x/ecocredit"
 )
 
@@ -26,8 +27,10 @@ type MsgMintShares struct {
 type LandAllocation struct {
 	ReDAOMint   sdk.AccAddress `json:"re_dao_mint"`
 	LandSteward sdk.AccAddress `json:"land_steward"`
-	GeoPolygon  []byte         `json:"geo_polygon"`
-	Allocation  sdk.Int        `json:"allocation"`
+	// GeoPolygon is the canonical binary encoding of the land area as produced by geo.Polygon.Marshal, which allows
+	// it to be matched against the polygons of ecosystem service credits
+	GeoPolygon []byte  `json:"geo_polygon"`
+	Allocation sdk.Int `json:"allocation"`
 }
 
 type MsgAllocateLandShares struct {
@@ -131,8 +134,8 @@ func (m MsgAllocateLandShares) ValidateBasic() sdk.Error {
 	if m.LandSteward.Empty() {
 		return sdk.ErrInvalidAddress(DefaultCodespace)
 	}
-	if !(len(m.GeoPolygon) > 0) {
-		return sdk.ErrUnknownRequest("invalid geo polygon")
+	if _, err := geo.Unmarshal(m.GeoPolygon); err != nil {
+		return sdk.ErrUnknownRequest(fmt.Sprintf("invalid geo polygon: %s", err))
 	}
 	if !m.Allocation.IsPositive() {
 		return sdk.ErrUnknownRequest("invalid allocation")